@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gomockserver/mockserver/internal/api"
+	"github.com/gomockserver/mockserver/internal/cache"
 	"github.com/gomockserver/mockserver/internal/config"
 	"github.com/gomockserver/mockserver/internal/engine"
 	"github.com/gomockserver/mockserver/internal/executor"
+	"github.com/gomockserver/mockserver/internal/middleware"
 	"github.com/gomockserver/mockserver/internal/repository"
 	"github.com/gomockserver/mockserver/internal/service"
 	"github.com/gomockserver/mockserver/pkg/logger"
@@ -52,25 +56,156 @@ func main() {
 	logger.Info("database connected successfully")
 
 	// 创建仓库
-	ruleRepo := repository.NewRuleRepository()
+	ruleStore := repository.NewRuleRepository()
 	projectRepo := repository.NewProjectRepository()
-	environmentRepo := repository.NewEnvironmentRepository()
+	environmentStore := repository.NewEnvironmentRepository()
+	scenarioRepo := repository.NewScenarioRepository()
+	protoRepo := repository.NewProtoRepository()
+	tlsCertificateRepo := repository.NewTLSCertificateRepository()
+	hostBindingRepo := repository.NewHostBindingRepository()
+
+	// Redis：规则 L2 缓存与跨实例失效传播共用同一个连接
+	redisL2 := newRedisL2Cache(cfg)
+	if redisL2 != nil && !cfg.Performance.Cache.Enabled {
+		// 启用规则缓存时由缓存管理器停止时关闭
+		defer redisL2.Close()
+	}
+
+	// 创建匹配引擎与执行器，规则写入后清除规则缓存并增量更新规则索引，环境写入后清除环境缓存
+	matchEngine := engine.NewMatchEngine(ruleStore)
+	if cfg.Performance.Cache.IndexTTL > 0 {
+		matchEngine.SetRuleIndexTTL(time.Duration(cfg.Performance.Cache.IndexTTL) * time.Second)
+	}
+	matchEngine.SetEnvironmentRepository(environmentStore)
+	matchEngine.SetScenarioRepository(scenarioRepo)
+	mockExecutor := executor.NewMockExecutor()
+	mockExecutor.SetEnvironmentRepository(environmentStore)
+	contractService := service.NewContractService(environmentStore)
+	clientAuthService := service.NewClientAuthService(environmentStore)
+	fallbackService := service.NewFallbackService(environmentStore)
+	routingService := service.NewRoutingService(hostBindingRepo, environmentStore)
+	envListeners := []repository.EnvironmentChangeListener{matchEngine, mockExecutor, contractService, clientAuthService, fallbackService, routingService}
+
+	ruleListeners := []repository.RuleChangeListener{}
+	var cacheService *service.CacheService
+	var ruleCache *service.CacheRuleService
+	if cfg.Performance.Cache.Enabled {
+		cacheService = newCacheService(cfg, redisL2)
+		if err := cacheService.Start(context.Background()); err != nil {
+			logger.Fatal("failed to start cache service", zap.Error(err))
+		}
+		defer cacheService.Stop(context.Background())
+
+		ruleCache = service.NewCacheRuleService(cacheService, ruleStore, logger.Get())
+		if cfg.Performance.Cache.RuleTTL > 0 {
+			ruleCache.SetRulesTTL(time.Duration(cfg.Performance.Cache.RuleTTL) * time.Second)
+		}
+		matchEngine.SetRuleLoader(ruleCache)
+		ruleListeners = append(ruleListeners, ruleCache)
+	}
+	ruleListeners = append(ruleListeners, matchEngine)
+
+	// 跨实例失效传播：放在本地监听器之后，发布时本地缓存与 L2 均已清除
+	var cacheStatsService *service.CacheStatsService
+	if cfg.Performance.Cache.Enabled || cfg.Performance.Cache.Propagation {
+		cacheStatsService = service.NewCacheStatsService(cacheService, logger.Get())
+	}
+	envRepoListeners := append([]repository.EnvironmentChangeListener{}, envListeners...)
+	if cfg.Performance.Cache.Propagation {
+		if redisL2 == nil {
+			logger.Warn("cache propagation requires redis, disabled")
+		} else {
+			channel := cfg.Performance.Cache.PropagationChannel
+			if channel == "" {
+				channel = service.DefaultInvalidationChannel
+			}
+			pubsub := cache.NewRedisPubSub(redisL2.GetClient(), channel, logger.Get().Named("pubsub"))
+			propagator := service.NewInvalidationPropagator(pubsub, matchEngine, logger.Get(), envListeners...)
+			if ruleCache != nil {
+				propagator.SetRuleCache(ruleCache)
+			}
+			propagator.Start(context.Background())
+			defer propagator.Stop()
+
+			ruleListeners = append(ruleListeners, propagator)
+			envRepoListeners = append(envRepoListeners, propagator)
+			cacheStatsService.SetPropagation(propagator)
+			logger.Info("cache propagation enabled", zap.String("channel", channel))
+		}
+	}
+
+	// 规则写入（RuleHandler、导入导出、录制，以及以 ruleRepo 创建的 BatchOperationService）都必须经过 ruleRepo，才能清除缓存
+	ruleRepo := repository.NewObservedRuleRepository(ruleStore, ruleListeners...)
+	environmentRepo := repository.NewObservedEnvironmentRepository(environmentStore, envRepoListeners...)
 
 	// 创建处理器
 	ruleHandler := api.NewRuleHandler(ruleRepo, projectRepo, environmentRepo)
 	projectHandler := api.NewProjectHandler(projectRepo, environmentRepo)
-	statisticsHandler := api.NewStatisticsHandler(repository.NewMongoRequestLogRepository(repository.GetDatabase()), repository.GetDatabase())
+	requestLogRepo := repository.NewMongoRequestLogRepository(repository.GetDatabase())
+	statisticsHandler := api.NewStatisticsHandler(requestLogRepo, repository.GetDatabase())
 
 	// 创建导入导出服务
 	importExportService := service.NewImportExportService(ruleRepo, projectRepo, environmentRepo, logger.Get())
 
 	// 创建服务
 	adminService := service.NewAdminService(ruleHandler, projectHandler, statisticsHandler, importExportService)
+	requestLogger := middleware.NewRequestLoggerMiddleware(requestLogRepo)
+	adminService.SetVerificationService(service.NewVerificationService(requestLogRepo, requestLogger))
+	if cacheStatsService != nil {
+		adminService.SetCacheStatsService(cacheStatsService)
+	}
 
 	// 同时启动 Mock 服务器
-	matchEngine := engine.NewMatchEngine(ruleRepo)
-	mockExecutor := executor.NewMockExecutor()
+	adminService.SetScenarioHandler(api.NewScenarioHandler(matchEngine.Scenarios()))
+	adminService.SetCounterHandler(api.NewCounterHandler(mockExecutor))
 	mockService := service.NewMockService(matchEngine, mockExecutor)
+	mockService.SetRequestLogger(requestLogger)
+	mockService.SetRecorder(service.NewRecordingService(ruleRepo, environmentRepo))
+	mockService.SetContractProvider(contractService)
+	mockService.SetClientAuthProvider(clientAuthService)
+	mockService.SetFallbackProvider(fallbackService)
+
+	// 请求路由：按主机名绑定、路由请求头或默认环境确定项目和环境
+	routingService.SetHeaders(cfg.Server.Mock.ProjectHeader, cfg.Server.Mock.EnvironmentHeader)
+	routingService.SetDefaultTarget(cfg.Server.Mock.DefaultProject, cfg.Server.Mock.DefaultEnvironment)
+	if err := routingService.Reload(context.Background()); err != nil {
+		logger.Error("failed to load host bindings", zap.Error(err))
+	}
+	mockService.SetRoutingService(routingService)
+	adminService.SetRoutingHandler(api.NewRoutingHandler(hostBindingRepo, environmentRepo, routingService))
+	adminService.SetWebSocketHandler(api.NewWebSocketHandler(mockService.WebSocketAdapter()))
+
+	// gRPC Mock 服务按项目上传的描述文件解析请求，描述文件变更后清除已编译的描述符
+	grpcService := service.NewGRPCService(matchEngine, mockExecutor, protoRepo)
+	grpcService.SetRequestLogger(requestLogger)
+	grpcService.SetDefaultTarget(cfg.Server.GRPC.DefaultProject, cfg.Server.GRPC.DefaultEnvironment)
+	adminService.SetProtoHandler(api.NewProtoHandler(protoRepo, grpcService))
+
+	// 根 CA 与证书：HTTPS Mock 服务和正向代理解密 HTTPS 请求共用
+	var tlsService *service.TLSService
+	if cfg.Server.TLS.Enabled || cfg.Server.Proxy.Enabled {
+		tlsService, err = service.NewTLSService(cfg.Server.TLS.CADir, cfg.Server.TLS.Hostnames, tlsCertificateRepo)
+		if err != nil {
+			logger.Fatal("failed to initialize tls certificates", zap.Error(err))
+		}
+		if err := tlsService.Reload(context.Background()); err != nil {
+			logger.Error("failed to load uploaded tls certificates", zap.Error(err))
+		}
+		adminService.SetTLSHandler(api.NewTLSHandler(tlsCertificateRepo, tlsService))
+	}
+
+	// 正向代理：Mock 服务端口同时作为 HTTP_PROXY / HTTPS_PROXY
+	if cfg.Server.Proxy.Enabled {
+		hosts := make([]service.ProxyHost, 0, len(cfg.Server.Proxy.Hosts))
+		for _, host := range cfg.Server.Proxy.Hosts {
+			hosts = append(hosts, service.ProxyHost{
+				Host:          host.Host,
+				ProjectID:     host.ProjectID,
+				EnvironmentID: host.EnvironmentID,
+			})
+		}
+		mockService.SetForwardProxy(service.NewForwardProxy(hosts, tlsService))
+	}
 
 	// 启动 Mock 服务器（在 goroutine 中）
 	go func() {
@@ -80,6 +215,25 @@ func main() {
 		}
 	}()
 
+	// 启动 HTTPS Mock 服务器，证书由本地根 CA 签发或使用环境上传的证书
+	if cfg.Server.TLS.Enabled {
+		go func() {
+			logger.Info("starting https mock server", zap.String("address", cfg.GetTLSAddress()))
+			if err := service.StartMockTLSServer(cfg.GetTLSAddress(), mockService, tlsService); err != nil {
+				logger.Fatal("failed to start https mock server", zap.Error(err))
+			}
+		}()
+	}
+
+	// 启动 gRPC Mock 服务器
+	if cfg.Server.GRPC.Enabled {
+		go func() {
+			if err := service.StartGRPCServer(cfg.GetGRPCAddress(), grpcService); err != nil {
+				logger.Fatal("failed to start grpc mock server", zap.Error(err))
+			}
+		}()
+	}
+
 	// 启动管理服务器（主 goroutine）
 	go func() {
 		logger.Info("starting admin server", zap.String("address", cfg.GetAdminAddress()))
@@ -95,3 +249,60 @@ func main() {
 
 	logger.Info("shutting down mockserver...")
 }
+
+// newRedisL2Cache 启用 Redis 时创建 Redis 连接，未启用或连接失败时返回 nil
+func newRedisL2Cache(cfg *config.Config) *cache.RedisL2Cache {
+	if !cfg.Redis.Enabled {
+		return nil
+	}
+
+	redisConfig := cache.DefaultRedisConfig()
+	redisConfig.Host = cfg.Redis.Host
+	redisConfig.Port = cfg.Redis.Port
+	redisConfig.Password = cfg.Redis.Password
+	redisConfig.Database = cfg.Redis.DB
+	if cfg.Redis.Pool.Max > 0 {
+		redisConfig.PoolSize = cfg.Redis.Pool.Max
+	}
+	if cfg.Redis.Pool.Min > 0 {
+		redisConfig.MinIdleConns = cfg.Redis.Pool.Min
+	}
+	if cfg.Performance.Cache.KeyPrefix != "" {
+		redisConfig.KeyPrefix = cfg.Performance.Cache.KeyPrefix
+	}
+
+	redisL2, err := cache.NewRedisL2Cache(redisConfig, logger.Get().Named("l2_redis"))
+	if err != nil {
+		logger.Warn("failed to connect to redis", zap.Error(err))
+		return nil
+	}
+	return redisL2
+}
+
+// newCacheService 根据 performance.cache 配置创建三级缓存，Redis 可用时使用 Redis 作为 L2，否则只用内存缓存
+func newCacheService(cfg *config.Config, redisL2 *cache.RedisL2Cache) *service.CacheService {
+	cacheCfg := cfg.Performance.Cache
+	strategy := cache.DefaultCacheStrategy()
+	if cacheCfg.L1MaxEntries > 0 {
+		strategy.L1MaxEntries = cacheCfg.L1MaxEntries
+	}
+	if cacheCfg.L1MaxMemoryMB > 0 {
+		strategy.L1MaxMemoryMB = cacheCfg.L1MaxMemoryMB
+	}
+	if cacheCfg.L1TTL > 0 {
+		strategy.L1TTL = time.Duration(cacheCfg.L1TTL) * time.Second
+	}
+	if cacheCfg.RuleTTL > 0 {
+		strategy.L2TTL = time.Duration(cacheCfg.RuleTTL) * time.Second
+	}
+	// 规则键在启动时无法预知，不做预热
+	strategy.PreloadEnabled = false
+
+	if redisL2 != nil {
+		logger.Info("rule cache enabled", zap.String("l2", "redis"))
+		return service.NewCacheServiceWithL2(redisL2, strategy, logger.Get())
+	}
+
+	logger.Info("rule cache enabled", zap.String("l2", "none"))
+	return service.NewMemoryCacheService(strategy, logger.Get())
+}
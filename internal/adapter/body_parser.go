@@ -0,0 +1,152 @@
+package adapter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+)
+
+// 表单内容类型
+const (
+	MIMEFormURLEncoded = "application/x-www-form-urlencoded"
+	MIMEMultipartForm  = "multipart/form-data"
+)
+
+// maxFormFieldSize 单个 multipart 文本字段读取上限
+const maxFormFieldSize = 1 << 20
+
+// FormFile multipart 文件部分信息
+type FormFile struct {
+	FieldName   string `json:"field_name"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// FormData 解析后的表单数据
+type FormData struct {
+	Fields map[string][]string `json:"fields"`
+	Files  []FormFile          `json:"files,omitempty"`
+}
+
+// Value 获取字段的第一个值
+func (f *FormData) Value(name string) (string, bool) {
+	values, exists := f.Fields[name]
+	if !exists || len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// FirstValues 获取所有字段的第一个值
+func (f *FormData) FirstValues() map[string]string {
+	result := make(map[string]string, len(f.Fields))
+	for name, values := range f.Fields {
+		if len(values) > 0 {
+			result[name] = values[0]
+		}
+	}
+	return result
+}
+
+// GetForm 获取请求的表单数据，非表单请求返回 nil；解析结果缓存到 ParsedBody
+func GetForm(request *Request) (*FormData, error) {
+	if form, ok := request.ParsedBody.(*FormData); ok {
+		return form, nil
+	}
+
+	form, err := ParseFormBody(HeaderValue(request.Headers, "Content-Type"), request.Body)
+	if err != nil || form == nil {
+		return nil, err
+	}
+
+	request.ParsedBody = form
+	return form, nil
+}
+
+// ParseFormBody 按 Content-Type 解析 urlencoded 或 multipart 请求体，其他类型返回 nil
+func ParseFormBody(contentType string, body []byte) (*FormData, error) {
+	if contentType == "" {
+		return nil, nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+
+	switch strings.ToLower(mediaType) {
+	case MIMEFormURLEncoded:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse form body: %w", err)
+		}
+		return &FormData{Fields: values}, nil
+	case MIMEMultipartForm:
+		boundary := params["boundary"]
+		if boundary == "" {
+			return nil, fmt.Errorf("multipart boundary not found in content type")
+		}
+		return parseMultipart(body, boundary)
+	default:
+		return nil, nil
+	}
+}
+
+// parseMultipart 解析 multipart 请求体，文件部分仅记录元数据
+func parseMultipart(body []byte, boundary string) (*FormData, error) {
+	form := &FormData{Fields: make(map[string][]string)}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse multipart body: %w", err)
+		}
+
+		name := part.FormName()
+		if filename := part.FileName(); filename != "" {
+			size, err := io.Copy(io.Discard, part)
+			if err != nil {
+				part.Close()
+				return nil, fmt.Errorf("failed to read multipart file %q: %w", filename, err)
+			}
+			form.Files = append(form.Files, FormFile{
+				FieldName:   name,
+				Filename:    filename,
+				ContentType: part.Header.Get("Content-Type"),
+				Size:        size,
+			})
+		} else if name != "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxFormFieldSize))
+			if err != nil {
+				part.Close()
+				return nil, fmt.Errorf("failed to read multipart field %q: %w", name, err)
+			}
+			form.Fields[name] = append(form.Fields[name], string(value))
+		}
+		part.Close()
+	}
+
+	return form, nil
+}
+
+// HeaderValue 不区分大小写获取请求头
+func HeaderValue(headers map[string]string, key string) string {
+	if value, ok := headers[key]; ok {
+		return value
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package adapter
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildMultipartBody 构建 multipart 请求体
+func buildMultipartBody(t *testing.T) ([]byte, string) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	require.NoError(t, writer.WriteField("username", "alice"))
+	require.NoError(t, writer.WriteField("tags", "a"))
+	require.NoError(t, writer.WriteField("tags", "b"))
+	part, err := writer.CreateFormFile("avatar", "me.png")
+	require.NoError(t, err)
+	_, err = part.Write(bytes.Repeat([]byte{0x1}, 128))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes(), writer.FormDataContentType()
+}
+
+func TestParseFormBody_URLEncoded(t *testing.T) {
+	form, err := ParseFormBody("application/x-www-form-urlencoded; charset=utf-8", []byte("username=alice&tags=a&tags=b"))
+	require.NoError(t, err)
+	require.NotNil(t, form)
+
+	value, ok := form.Value("username")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", value)
+	assert.Equal(t, []string{"a", "b"}, form.Fields["tags"])
+	assert.Equal(t, map[string]string{"username": "alice", "tags": "a"}, form.FirstValues())
+}
+
+func TestParseFormBody_Multipart(t *testing.T) {
+	body, contentType := buildMultipartBody(t)
+
+	form, err := ParseFormBody(contentType, body)
+	require.NoError(t, err)
+	require.NotNil(t, form)
+
+	assert.Equal(t, []string{"alice"}, form.Fields["username"])
+	assert.Equal(t, []string{"a", "b"}, form.Fields["tags"])
+	require.Len(t, form.Files, 1)
+	assert.Equal(t, "avatar", form.Files[0].FieldName)
+	assert.Equal(t, "me.png", form.Files[0].Filename)
+	assert.Equal(t, int64(128), form.Files[0].Size)
+}
+
+func TestParseFormBody_Unsupported(t *testing.T) {
+	form, err := ParseFormBody("application/json", []byte(`{"a":1}`))
+	assert.NoError(t, err)
+	assert.Nil(t, form)
+
+	form, err = ParseFormBody("", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, form)
+
+	_, err = ParseFormBody("multipart/form-data", []byte("x"))
+	assert.Error(t, err)
+}
+
+func TestHTTPAdapter_Parse_FormBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body, contentType := buildMultipartBody(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/upload", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+
+	request, err := NewHTTPAdapter().Parse(c)
+	require.NoError(t, err)
+
+	form, ok := request.ParsedBody.(*FormData)
+	require.True(t, ok)
+	assert.Equal(t, []string{"alice"}, form.Fields["username"])
+
+	// GetForm 复用已解析的结果
+	cached, err := GetForm(request)
+	require.NoError(t, err)
+	assert.Same(t, form, cached)
+}
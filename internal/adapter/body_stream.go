@@ -0,0 +1,92 @@
+package adapter
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// throttleSlicesPerSecond 限速时每秒写出的次数，决定限速的平滑程度
+const throttleSlicesPerSecond = 20
+
+// BodyStream 分段发送的响应体，HTTP 响应 Metadata 中 body_stream 的值
+type BodyStream struct {
+	Chunks         []BodyChunk
+	Chunked        bool // 为 false 时设置 Content-Length
+	BytesPerSecond int  // 带宽限制，0 表示不限速
+}
+
+// BodyChunk 响应体的一段
+type BodyChunk struct {
+	Data  []byte
+	Delay time.Duration // 发送前等待的时间
+}
+
+// Size 响应体总字节数
+func (s *BodyStream) Size() int {
+	size := 0
+	for _, chunk := range s.Chunks {
+		size += len(chunk.Data)
+	}
+	return size
+}
+
+// writeBodyStream 按段写入并刷新响应体，限速时将每段拆分为小片按带宽写出，客户端断开时停止
+func (a *HTTPAdapter) writeBodyStream(c *gin.Context, response *Response, stream *BodyStream) {
+	for key, value := range response.Headers {
+		c.Header(key, value)
+	}
+	if stream.Chunked {
+		c.Writer.Header().Del("Content-Length")
+	} else {
+		c.Header("Content-Length", strconv.Itoa(stream.Size()))
+	}
+	c.Status(response.StatusCode)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	done := c.Request.Context().Done()
+	slice := 0
+	if stream.BytesPerSecond > 0 {
+		slice = max(stream.BytesPerSecond/throttleSlicesPerSecond, 1)
+	}
+
+	for _, chunk := range stream.Chunks {
+		if !sleepUntilDone(done, chunk.Delay) {
+			return
+		}
+
+		data := chunk.Data
+		for len(data) > 0 {
+			n := len(data)
+			if slice > 0 {
+				n = min(n, slice)
+			}
+			if _, err := c.Writer.Write(data[:n]); err != nil {
+				return
+			}
+			c.Writer.Flush()
+			data = data[n:]
+
+			if slice > 0 && !sleepUntilDone(done, time.Duration(n)*time.Second/time.Duration(stream.BytesPerSecond)) {
+				return
+			}
+		}
+	}
+}
+
+// sleepUntilDone 等待 d，done 关闭（客户端断开）时提前返回 false
+func sleepUntilDone(done <-chan struct{}, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
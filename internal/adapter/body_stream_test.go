@@ -0,0 +1,81 @@
+package adapter
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startBodyStreamServer 启动按 stream 写入响应体的测试服务器
+func startBodyStreamServer(t *testing.T, stream *BodyStream) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	httpAdapter := NewHTTPAdapter()
+	router := gin.New()
+	router.GET("/download", func(c *gin.Context) {
+		httpAdapter.WriteResponse(c, &Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/x-ndjson"},
+			Metadata:   map[string]interface{}{"body_stream": stream},
+		})
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPAdapter_WriteBodyStreamChunked(t *testing.T) {
+	server := startBodyStreamServer(t, &BodyStream{
+		Chunked: true,
+		Chunks: []BodyChunk{
+			{Data: []byte("{\"n\":1}\n")},
+			{Data: []byte("{\"n\":2}\n"), Delay: 300 * time.Millisecond},
+		},
+	})
+
+	resp, err := http.Get(server.URL + "/download")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+	assert.Equal(t, int64(-1), resp.ContentLength)
+
+	// 第一行在延迟的第二行之前到达
+	reader := bufio.NewReader(resp.Body)
+	start := time.Now()
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":1}\n", line)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":2}\n", line)
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+}
+
+func TestHTTPAdapter_WriteBodyStreamThrottled(t *testing.T) {
+	body := strings.Repeat("x", 2000)
+	server := startBodyStreamServer(t, &BodyStream{
+		Chunks:         []BodyChunk{{Data: []byte(body)}},
+		BytesPerSecond: 8000,
+	})
+
+	start := time.Now()
+	resp, err := http.Get(server.URL + "/download")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, int64(len(body)), resp.ContentLength)
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(data))
+	// 2000 字节按 8000 字节/秒约需 250ms
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
@@ -0,0 +1,64 @@
+package adapter
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"time"
+)
+
+// ClientCertificate 客户端证书（mTLS）信息，保存在 Request.Metadata 的 client_cert 中
+type ClientCertificate struct {
+	Subject      string    `json:"subject"`     // 完整主题 DN，如 CN=partner-a,O=Acme
+	SubjectCN    string    `json:"subject_cn"`  // 主题 CN
+	Issuer       string    `json:"issuer"`      // 完整颁发者 DN
+	IssuerCN     string    `json:"issuer_cn"`   // 颁发者 CN
+	SANs         []string  `json:"sans"`        // DNS 名称、IP 地址、邮箱和 URI
+	Fingerprint  string    `json:"fingerprint"` // SHA-256 指纹，小写十六进制
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+}
+
+// NewClientCertificate 提取证书的主题、颁发者、SAN 和指纹
+func NewClientCertificate(cert *x509.Certificate) *ClientCertificate {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	return &ClientCertificate{
+		Subject:      cert.Subject.String(),
+		SubjectCN:    cert.Subject.CommonName,
+		Issuer:       cert.Issuer.String(),
+		IssuerCN:     cert.Issuer.CommonName,
+		SANs:         sans,
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		SerialNumber: cert.SerialNumber.String(),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	}
+}
+
+// clientCertificateFromTLS 获取连接上客户端出示的证书，未使用 TLS 或客户端未出示证书时返回 nil
+func clientCertificateFromTLS(state *tls.ConnectionState) *ClientCertificate {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return NewClientCertificate(state.PeerCertificates[0])
+}
+
+// GetClientCertificate 获取请求的客户端证书，未出示证书时返回 nil
+func GetClientCertificate(request *Request) *ClientCertificate {
+	if request == nil || request.Metadata == nil {
+		return nil
+	}
+	cert, _ := request.Metadata["client_cert"].(*ClientCertificate)
+	return cert
+}
@@ -0,0 +1,67 @@
+package adapter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPAdapter_Parse_ClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	spiffe, _ := url.Parse("spiffe://example.test/partner-a")
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(42),
+		Subject:        pkix.Name{CommonName: "partner-a", Organization: []string{"Acme"}},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		DNSNames:       []string{"partner-a.example.test"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		EmailAddresses: []string{"ops@example.test"},
+		URIs:           []*url.URL{spiffe},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "https://localhost/api/orders", nil)
+	c.Request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+
+	request, err := NewHTTPAdapter().Parse(c)
+	require.NoError(t, err)
+	clientCert := GetClientCertificate(request)
+	require.NotNil(t, clientCert)
+
+	fingerprint := sha256.Sum256(der)
+	assert.Equal(t, "partner-a", clientCert.SubjectCN)
+	assert.Equal(t, "CN=partner-a,O=Acme", clientCert.Subject)
+	assert.Equal(t, "partner-a", clientCert.IssuerCN)
+	assert.Equal(t, []string{"partner-a.example.test", "10.0.0.1", "ops@example.test", "spiffe://example.test/partner-a"}, clientCert.SANs)
+	assert.Equal(t, hex.EncodeToString(fingerprint[:]), clientCert.Fingerprint)
+	assert.Equal(t, "42", clientCert.SerialNumber)
+
+	// 未出示证书或非 HTTPS 请求
+	c.Request = httptest.NewRequest("GET", "/api/orders", nil)
+	request, err = NewHTTPAdapter().Parse(c)
+	require.NoError(t, err)
+	assert.Nil(t, GetClientCertificate(request))
+}
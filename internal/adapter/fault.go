@@ -0,0 +1,118 @@
+package adapter
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// defaultMalformedBytes malformed_response 未配置 bytes 时发送的随机字节数
+const defaultMalformedBytes = 64
+
+// writeFault 劫持连接注入传输层故障，返回前关闭连接；HTTP/2 连接无法劫持，返回 false 由调用方按正常响应写入
+//
+// 劫持前设置规则原本的状态码和上下文中的 fault，供请求日志记录。
+func (a *HTTPAdapter) writeFault(c *gin.Context, response *Response, fault *models.FaultConfig) bool {
+	if c.Request.ProtoMajor != 1 {
+		logger.Warn("transport faults require HTTP/1.x, writing the response normally",
+			zap.String("fault", string(fault.Type)),
+			zap.String("proto", c.Request.Proto))
+		return false
+	}
+
+	raw, headerSize := serializeResponse(response)
+	c.Status(response.StatusCode)
+	c.Set("fault", fault.Type)
+	conn, buf, err := c.Writer.Hijack()
+	if err != nil {
+		logger.Error("failed to hijack connection for fault injection", zap.Error(err))
+		return true
+	}
+	defer conn.Close()
+
+	switch fault.Type {
+	case models.FaultEmptyResponse:
+		// 不发送任何数据
+	case models.FaultConnectionReset:
+		n := fault.Bytes
+		if n == 0 {
+			n = headerSize + len(response.Body)/2
+		}
+		conn.Write(raw[:min(n, len(raw))])
+		resetConn(conn)
+	case models.FaultMalformedResponse:
+		n := fault.Bytes
+		if n == 0 {
+			n = defaultMalformedBytes
+		}
+		garbage := make([]byte, n)
+		rand.Read(garbage)
+		conn.Write(garbage)
+	case models.FaultHang:
+		conn.Write(raw[:headerSize])
+		waitClientClose(buf, time.Duration(fault.Duration)*time.Millisecond)
+	case models.FaultCloseAfterBytes:
+		conn.Write(raw[:min(fault.Bytes, len(raw))])
+	}
+	return true
+}
+
+// serializeResponse 按 HTTP/1.1 格式编码响应，返回完整字节和响应头（含空行）的长度
+func serializeResponse(response *Response) ([]byte, int) {
+	header := make(http.Header)
+	for key, value := range response.Headers {
+		header.Set(key, value)
+	}
+	header.Set("Content-Type", getContentType(response.Headers))
+	header.Set("Content-Length", strconv.Itoa(len(response.Body)))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/1.1 %d %s\r\n", response.StatusCode, http.StatusText(response.StatusCode))
+	header.Write(&buf)
+	buf.WriteString("\r\n")
+	headerSize := buf.Len()
+	buf.Write(response.Body)
+	return buf.Bytes(), headerSize
+}
+
+// resetConn 关闭连接并发送 RST 而不是 FIN
+func resetConn(conn net.Conn) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// waitClientClose 等待客户端关闭连接，timeout 大于 0 时最多等待 timeout
+func waitClientClose(reader io.Reader, timeout time.Duration) {
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, reader)
+		close(closed)
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-closed:
+	case <-expired:
+	}
+}
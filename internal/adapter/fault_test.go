@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startFaultServer 启动按 fault 注入故障的测试服务器，recorded 接收上下文中记录的故障和状态码
+func startFaultServer(t *testing.T, fault *models.FaultConfig) (string, chan string) {
+	gin.SetMode(gin.TestMode)
+	recorded := make(chan string, 1)
+	httpAdapter := NewHTTPAdapter()
+	router := gin.New()
+	router.GET("/fault", func(c *gin.Context) {
+		httpAdapter.WriteResponse(c, &Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       []byte(`{"message":"hello world"}`),
+			Metadata:   map[string]interface{}{"fault": fault},
+		})
+		value, _ := c.Get("fault")
+		select {
+		case recorded <- string(value.(models.FaultType)) + " " + http.StatusText(c.Writer.Status()):
+		default:
+		}
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://"), recorded
+}
+
+// rawGet 发送原始 HTTP 请求，返回读到的全部字节和读取结束时的错误
+func rawGet(t *testing.T, addr string) ([]byte, error) {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	_, err = conn.Write([]byte("GET /fault HTTP/1.1\r\nHost: mock\r\n\r\n"))
+	require.NoError(t, err)
+	data, err := io.ReadAll(conn)
+	return data, err
+}
+
+func TestHTTPAdapter_FaultEmptyResponse(t *testing.T) {
+	addr, recorded := startFaultServer(t, &models.FaultConfig{Type: models.FaultEmptyResponse, Probability: 1})
+
+	data, err := rawGet(t, addr)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+	assert.Equal(t, "empty_response OK", <-recorded)
+
+	_, err = http.Get("http://" + addr + "/fault")
+	assert.Error(t, err)
+}
+
+func TestHTTPAdapter_FaultConnectionReset(t *testing.T) {
+	addr, recorded := startFaultServer(t, &models.FaultConfig{Type: models.FaultConnectionReset, Probability: 1})
+
+	resp, err := http.Get("http://" + addr + "/fault")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// 默认发送响应头和一半响应体
+	_, err = io.ReadAll(resp.Body)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+	assert.Equal(t, "connection_reset OK", <-recorded)
+}
+
+func TestHTTPAdapter_FaultMalformedResponse(t *testing.T) {
+	addr, _ := startFaultServer(t, &models.FaultConfig{Type: models.FaultMalformedResponse, Probability: 1, Bytes: 32})
+
+	data, err := rawGet(t, addr)
+	require.NoError(t, err)
+	assert.Len(t, data, 32)
+
+	_, err = http.Get("http://" + addr + "/fault")
+	assert.Error(t, err)
+}
+
+func TestHTTPAdapter_FaultHang(t *testing.T) {
+	addr, recorded := startFaultServer(t, &models.FaultConfig{Type: models.FaultHang, Probability: 1, Duration: 100})
+
+	start := time.Now()
+	resp, err := http.Get("http://" + addr + "/fault")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(len(`{"message":"hello world"}`)), resp.ContentLength)
+
+	// 响应头之后没有响应体，挂起结束时连接关闭
+	_, err = io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Equal(t, "hang OK", <-recorded)
+}
+
+func TestHTTPAdapter_FaultHangUntilClientCloses(t *testing.T) {
+	addr, recorded := startFaultServer(t, &models.FaultConfig{Type: models.FaultHang, Probability: 1})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET /fault HTTP/1.1\r\nHost: mock\r\n\r\n"))
+	require.NoError(t, err)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 200 OK\r\n", status)
+
+	select {
+	case <-recorded:
+		t.Fatal("hang finished before the client closed the connection")
+	case <-time.After(50 * time.Millisecond):
+	}
+	conn.Close()
+	select {
+	case value := <-recorded:
+		assert.Equal(t, "hang OK", value)
+	case <-time.After(2 * time.Second):
+		t.Fatal("hang did not finish after the client closed the connection")
+	}
+}
+
+func TestHTTPAdapter_FaultCloseAfterBytes(t *testing.T) {
+	addr, _ := startFaultServer(t, &models.FaultConfig{Type: models.FaultCloseAfterBytes, Probability: 1, Bytes: 12})
+
+	data, err := rawGet(t, addr)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 200", string(data))
+}
@@ -0,0 +1,355 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtoRegistry 项目的 protobuf 描述符集合
+type ProtoRegistry struct {
+	files    *protoregistry.Files
+	types    *protoregistry.Types
+	services map[string][]string // 文件路径 -> 文件中定义的服务全名
+}
+
+// CompileProtoFiles 编译项目上传的 .proto 源文件和 FileDescriptorSet
+//
+// 源文件之间可以互相 import，也可以 import google/protobuf 下的标准文件和描述集中的文件；
+// 描述集建议使用 protoc --include_imports 生成，缺少的依赖同样按上述顺序查找。
+func CompileProtoFiles(files []*models.ProtoFile) (*ProtoRegistry, error) {
+	sources := make(map[string]string)
+	descriptors := make(map[string]*descriptorpb.FileDescriptorProto)
+	var names []string
+	for _, file := range files {
+		switch file.Kind {
+		case models.ProtoFileKindDescriptorSet:
+			var set descriptorpb.FileDescriptorSet
+			if err := proto.Unmarshal(file.Content, &set); err != nil {
+				return nil, fmt.Errorf("invalid descriptor set %s: %w", file.Name, err)
+			}
+			for _, fd := range set.GetFile() {
+				if _, exists := descriptors[fd.GetName()]; !exists {
+					names = append(names, fd.GetName())
+				}
+				descriptors[fd.GetName()] = fd
+			}
+		case models.ProtoFileKindSource, "":
+			sources[file.Name] = string(file.Content)
+			names = append(names, file.Name)
+		default:
+			return nil, fmt.Errorf("unsupported proto file kind: %s", file.Kind)
+		}
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(protocompile.CompositeResolver{
+			&protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(sources)},
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				if fd, ok := descriptors[path]; ok {
+					return protocompile.SearchResult{Proto: fd}, nil
+				}
+				return protocompile.SearchResult{}, protoregistry.NotFound
+			}),
+		}),
+	}
+	compiled, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &ProtoRegistry{
+		files:    new(protoregistry.Files),
+		types:    new(protoregistry.Types),
+		services: make(map[string][]string),
+	}
+	for _, fd := range compiled {
+		if err := registry.register(fd); err != nil {
+			return nil, err
+		}
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			registry.services[fd.Path()] = append(registry.services[fd.Path()], string(services.Get(i).FullName()))
+		}
+	}
+	return registry, nil
+}
+
+// DescriptorSetFiles 获取 FileDescriptorSet 中的文件路径
+func DescriptorSetFiles(content []byte) ([]string, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(content, &set); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(set.GetFile()))
+	for _, fd := range set.GetFile() {
+		names = append(names, fd.GetName())
+	}
+	return names, nil
+}
+
+// register 注册文件及其依赖的描述符和动态消息类型
+func (r *ProtoRegistry) register(fd protoreflect.FileDescriptor) error {
+	if _, err := r.files.FindFileByPath(fd.Path()); err == nil {
+		return nil
+	}
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if err := r.register(imports.Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	if err := r.files.RegisterFile(fd); err != nil {
+		return err
+	}
+	return r.registerTypes(fd.Messages(), fd.Enums(), fd.Extensions())
+}
+
+// registerTypes 注册消息、枚举和扩展类型，用于解析 Any 和反射服务查询扩展
+func (r *ProtoRegistry) registerTypes(messages protoreflect.MessageDescriptors, enums protoreflect.EnumDescriptors, extensions protoreflect.ExtensionDescriptors) error {
+	for i := 0; i < enums.Len(); i++ {
+		if err := r.types.RegisterEnum(dynamicpb.NewEnumType(enums.Get(i))); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < extensions.Len(); i++ {
+		if err := r.types.RegisterExtension(dynamicpb.NewExtensionType(extensions.Get(i))); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+		if err := r.types.RegisterMessage(dynamicpb.NewMessageType(md)); err != nil {
+			return err
+		}
+		if err := r.registerTypes(md.Messages(), md.Enums(), md.Extensions()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files 获取文件描述符集合
+func (r *ProtoRegistry) Files() *protoregistry.Files {
+	return r.files
+}
+
+// Types 获取消息、枚举和扩展类型集合
+func (r *ProtoRegistry) Types() *protoregistry.Types {
+	return r.types
+}
+
+// Services 获取上传文件中定义的所有服务全名（已排序）
+func (r *ProtoRegistry) Services() []string {
+	var services []string
+	for _, names := range r.services {
+		services = append(services, names...)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// FileServices 获取指定文件中定义的服务全名
+func (r *ProtoRegistry) FileServices(path string) []string {
+	return r.services[path]
+}
+
+// FindMethod 按完整方法名（/package.Service/Method）查找方法描述符
+func (r *ProtoRegistry) FindMethod(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	service, method, ok := SplitGRPCMethod(fullMethod)
+	if !ok {
+		return nil, fmt.Errorf("malformed method name: %s", fullMethod)
+	}
+	desc, err := r.files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %s", service)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("unknown service %s", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("unknown method %s for service %s", method, service)
+	}
+	return md, nil
+}
+
+// MarshalMessage 按 protobuf JSON 映射序列化消息
+func (r *ProtoRegistry) MarshalMessage(message proto.Message) ([]byte, error) {
+	return protojson.MarshalOptions{Resolver: r.types}.Marshal(message)
+}
+
+// UnmarshalMessage 按 protobuf JSON 映射把 JSON 转换为指定类型的消息，body 为空时返回空消息
+func (r *ProtoRegistry) UnmarshalMessage(md protoreflect.MessageDescriptor, body []byte) (proto.Message, error) {
+	message := dynamicpb.NewMessage(md)
+	if len(body) == 0 || string(body) == "null" {
+		return message, nil
+	}
+	if err := (protojson.UnmarshalOptions{Resolver: r.types}).Unmarshal(body, message); err != nil {
+		return nil, fmt.Errorf("invalid %s message: %w", md.FullName(), err)
+	}
+	return message, nil
+}
+
+// SplitGRPCMethod 拆分完整方法名 /package.Service/Method
+func SplitGRPCMethod(fullMethod string) (service, method string, ok bool) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	pos := strings.LastIndex(name, "/")
+	if pos <= 0 || pos == len(name)-1 {
+		return "", "", false
+	}
+	return name[:pos], name[pos+1:], true
+}
+
+// ParseGRPCCode 解析 gRPC 状态码，支持名称（如 "NOT_FOUND"，不区分大小写）或数值，空值为 OK
+func ParseGRPCCode(value interface{}) (codes.Code, error) {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		return codes.OK, nil
+	case string:
+		name := strings.ToUpper(strings.TrimSpace(v))
+		switch {
+		case name == "":
+			return codes.OK, nil
+		case name == "CANCELED":
+			name = "CANCELLED"
+		}
+		if _, err := strconv.ParseUint(name, 10, 32); err == nil {
+			raw = name
+		} else {
+			raw = strconv.Quote(name)
+		}
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return codes.Unknown, err
+		}
+		raw = string(data)
+	}
+
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(raw)); err != nil {
+		return codes.Unknown, fmt.Errorf("invalid grpc status code: %v", value)
+	}
+	return code, nil
+}
+
+// GRPCCall gRPC 调用（GRPCAdapter.Parse 的输入）
+type GRPCCall struct {
+	FullMethod string // /package.Service/Method
+	Message    proto.Message
+	Metadata   metadata.MD
+	Peer       net.Addr
+	Registry   *ProtoRegistry
+}
+
+// GRPCReply gRPC 响应（GRPCAdapter.Build 的输出），Messages 为依次发送的 JSON 形式的响应消息
+type GRPCReply struct {
+	Code     codes.Code
+	Message  string
+	Header   metadata.MD
+	Trailer  metadata.MD
+	Messages []GRPCStreamMessage
+}
+
+// GRPCStreamMessage 待发送的响应消息
+type GRPCStreamMessage struct {
+	Body  []byte        // JSON 形式的响应消息
+	Delay time.Duration // 发送前等待的时间
+}
+
+// GRPCAdapter gRPC 协议适配器
+type GRPCAdapter struct{}
+
+// NewGRPCAdapter 创建 gRPC 适配器
+func NewGRPCAdapter() *GRPCAdapter {
+	return &GRPCAdapter{}
+}
+
+// Parse 解析 gRPC 调用为统一模型：Path 为完整方法名，Headers 为请求元数据，Body 为 JSON 形式的请求消息
+func (a *GRPCAdapter) Parse(rawRequest interface{}) (*Request, error) {
+	call, ok := rawRequest.(*GRPCCall)
+	if !ok {
+		return nil, nil
+	}
+
+	var body []byte
+	if call.Message != nil {
+		var err error
+		if body, err = call.Registry.MarshalMessage(call.Message); err != nil {
+			return nil, err
+		}
+	}
+
+	headers := make(map[string]string)
+	for key, values := range call.Metadata {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
+	}
+
+	service, method, _ := SplitGRPCMethod(call.FullMethod)
+	request := &Request{
+		ID:         uuid.New().String(),
+		Protocol:   models.ProtocolGRPC,
+		Path:       call.FullMethod,
+		Headers:    headers,
+		Body:       body,
+		ReceivedAt: time.Now(),
+		Metadata: map[string]interface{}{
+			"grpc_service": service,
+			"grpc_method":  method,
+		},
+	}
+	if addr, ok := call.Peer.(*net.TCPAddr); ok {
+		request.SourceIP = addr.IP.String()
+		request.SourcePort = addr.Port
+	}
+
+	return request, nil
+}
+
+// Build 构建 gRPC 响应：StatusCode 为 gRPC 状态码，Headers 为响应头元数据，
+// Metadata 中的 grpc_message、grpc_trailers 分别为状态描述和尾部元数据，
+// grpc_stream 为流式响应消息，未设置时 Body 作为唯一的响应消息
+func (a *GRPCAdapter) Build(response *Response) (interface{}, error) {
+	reply := &GRPCReply{
+		Code:    codes.Code(response.StatusCode),
+		Header:  metadata.New(response.Headers),
+		Trailer: metadata.MD{},
+	}
+	if response.StatusCode < 0 || response.StatusCode > int(codes.Unauthenticated) {
+		return nil, fmt.Errorf("invalid grpc status code: %d", response.StatusCode)
+	}
+	if message, ok := response.Metadata["grpc_message"].(string); ok {
+		reply.Message = message
+	}
+	if trailers, ok := response.Metadata["grpc_trailers"].(map[string]string); ok {
+		reply.Trailer = metadata.New(trailers)
+	}
+	if stream, ok := response.Metadata["grpc_stream"].([]GRPCStreamMessage); ok && len(stream) > 0 {
+		reply.Messages = stream
+	} else if response.Body != nil {
+		reply.Messages = []GRPCStreamMessage{{Body: response.Body}}
+	}
+	return reply, nil
+}
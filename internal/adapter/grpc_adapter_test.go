@@ -0,0 +1,231 @@
+package adapter
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const testCommonProto = `syntax = "proto3";
+package acme.common.v1;
+
+message Page {
+  int32 size = 1;
+  string token = 2;
+}
+`
+
+const testUserProto = `syntax = "proto3";
+package acme.user.v1;
+
+import "acme/common/v1/common.proto";
+import "google/protobuf/timestamp.proto";
+
+service UserService {
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc ListUsers(acme.common.v1.Page) returns (stream User);
+}
+
+message GetUserRequest {
+  string user_id = 1;
+}
+
+message User {
+  string user_id = 1;
+  string display_name = 2;
+  google.protobuf.Timestamp created_at = 3;
+}
+`
+
+func testProtoFiles() []*models.ProtoFile {
+	return []*models.ProtoFile{
+		{Name: "acme/user/v1/user.proto", Kind: models.ProtoFileKindSource, Content: []byte(testUserProto)},
+		{Name: "acme/common/v1/common.proto", Kind: models.ProtoFileKindSource, Content: []byte(testCommonProto)},
+	}
+}
+
+func TestCompileProtoFiles_Sources(t *testing.T) {
+	registry, err := CompileProtoFiles(testProtoFiles())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"acme.user.v1.UserService"}, registry.Services())
+	assert.Equal(t, []string{"acme.user.v1.UserService"}, registry.FileServices("acme/user/v1/user.proto"))
+	assert.Empty(t, registry.FileServices("acme/common/v1/common.proto"))
+
+	method, err := registry.FindMethod("/acme.user.v1.UserService/GetUser")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("acme.user.v1.GetUserRequest"), method.Input().FullName())
+	assert.False(t, method.IsStreamingServer())
+
+	method, err = registry.FindMethod("/acme.user.v1.UserService/ListUsers")
+	require.NoError(t, err)
+	assert.True(t, method.IsStreamingServer())
+
+	// 依赖文件与标准文件同样注册，供服务反射查询
+	_, err = registry.Files().FindFileByPath("google/protobuf/timestamp.proto")
+	assert.NoError(t, err)
+	_, err = registry.Types().FindMessageByName("acme.common.v1.Page")
+	assert.NoError(t, err)
+
+	for _, name := range []string{"/acme.user.v1.UserService", "/acme.user.v1.UserService/Missing", "/acme.user.v1.Missing/GetUser", "/acme.user.v1.User/GetUser"} {
+		_, err := registry.FindMethod(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCompileProtoFiles_DescriptorSet(t *testing.T) {
+	source, err := CompileProtoFiles(testProtoFiles())
+	require.NoError(t, err)
+
+	// 模拟 protoc --include_imports --descriptor_set_out 生成的描述集
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, path := range []string{"google/protobuf/timestamp.proto", "acme/common/v1/common.proto", "acme/user/v1/user.proto"} {
+		fd, err := source.Files().FindFileByPath(path)
+		require.NoError(t, err)
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	content, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	names, err := DescriptorSetFiles(content)
+	require.NoError(t, err)
+	assert.Len(t, names, 3)
+
+	// 源文件可以 import 描述集中的文件
+	files := []*models.ProtoFile{
+		{Name: "user.pb", Kind: models.ProtoFileKindDescriptorSet, Content: content},
+		{Name: "acme/admin/v1/admin.proto", Kind: models.ProtoFileKindSource, Content: []byte(`syntax = "proto3";
+package acme.admin.v1;
+import "acme/user/v1/user.proto";
+service AdminService {
+  rpc BanUser(acme.user.v1.GetUserRequest) returns (acme.user.v1.User);
+}
+`)},
+	}
+	registry, err := CompileProtoFiles(files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme.admin.v1.AdminService", "acme.user.v1.UserService"}, registry.Services())
+	_, err = registry.FindMethod("/acme.user.v1.UserService/GetUser")
+	assert.NoError(t, err)
+}
+
+func TestCompileProtoFiles_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []*models.ProtoFile
+	}{
+		{"语法错误", []*models.ProtoFile{{Name: "a.proto", Content: []byte(`syntax = "proto3"; message {`)}}},
+		{"缺少依赖", []*models.ProtoFile{{Name: "acme/user/v1/user.proto", Content: []byte(testUserProto)}}},
+		{"无效描述集", []*models.ProtoFile{{Name: "a.pb", Kind: models.ProtoFileKindDescriptorSet, Content: []byte("not a descriptor set")}}},
+		{"未知类型", []*models.ProtoFile{{Name: "a.txt", Kind: "yaml", Content: []byte("x")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileProtoFiles(tt.files)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseGRPCCode(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		code  codes.Code
+		ok    bool
+	}{
+		{nil, codes.OK, true},
+		{"", codes.OK, true},
+		{"NOT_FOUND", codes.NotFound, true},
+		{"unavailable", codes.Unavailable, true},
+		{"Canceled", codes.Canceled, true},
+		{"14", codes.Unavailable, true},
+		{float64(5), codes.NotFound, true},
+		{16, codes.Unauthenticated, true},
+		{"TEAPOT", codes.Unknown, false},
+		{17, codes.Unknown, false},
+		{2.5, codes.Unknown, false},
+		{true, codes.Unknown, false},
+	}
+	for _, tt := range tests {
+		code, err := ParseGRPCCode(tt.value)
+		if tt.ok {
+			assert.NoError(t, err, "%v", tt.value)
+			assert.Equal(t, tt.code, code, "%v", tt.value)
+		} else {
+			assert.Error(t, err, "%v", tt.value)
+		}
+	}
+}
+
+func TestGRPCAdapter_ParseAndBuild(t *testing.T) {
+	registry, err := CompileProtoFiles(testProtoFiles())
+	require.NoError(t, err)
+	method, err := registry.FindMethod("/acme.user.v1.UserService/GetUser")
+	require.NoError(t, err)
+
+	in, err := registry.UnmarshalMessage(method.Input(), []byte(`{"userId":"42"}`))
+	require.NoError(t, err)
+
+	a := NewGRPCAdapter()
+	request, err := a.Parse(&GRPCCall{
+		FullMethod: "/acme.user.v1.UserService/GetUser",
+		Message:    in,
+		Metadata:   metadata.Pairs("x-tenant", "a"),
+		Peer:       &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000},
+		Registry:   registry,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ProtocolGRPC, request.Protocol)
+	assert.Equal(t, "/acme.user.v1.UserService/GetUser", request.Path)
+	assert.JSONEq(t, `{"userId":"42"}`, string(request.Body))
+	assert.Equal(t, "a", request.Headers["x-tenant"])
+	assert.Equal(t, "10.0.0.1", request.SourceIP)
+	assert.Equal(t, "acme.user.v1.UserService", request.Metadata["grpc_service"])
+	assert.Equal(t, "GetUser", request.Metadata["grpc_method"])
+
+	built, err := a.Build(&Response{
+		StatusCode: int(codes.PermissionDenied),
+		Headers:    map[string]string{"X-Request-Id": "abc"},
+		Metadata: map[string]interface{}{
+			"grpc_message":  "denied",
+			"grpc_trailers": map[string]string{"x-retry-after": "5"},
+		},
+	})
+	require.NoError(t, err)
+	reply := built.(*GRPCReply)
+	assert.Equal(t, codes.PermissionDenied, reply.Code)
+	assert.Equal(t, "denied", reply.Message)
+	assert.Equal(t, []string{"abc"}, reply.Header.Get("x-request-id"))
+	assert.Equal(t, []string{"5"}, reply.Trailer.Get("x-retry-after"))
+
+	assert.Empty(t, reply.Messages)
+
+	// 未设置流式响应消息时 Body 作为唯一的响应消息
+	built, err = a.Build(&Response{Body: []byte(`{"userId":"42"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, []GRPCStreamMessage{{Body: []byte(`{"userId":"42"}`)}}, built.(*GRPCReply).Messages)
+
+	stream := []GRPCStreamMessage{{Body: []byte(`{}`)}, {Body: []byte(`{}`), Delay: time.Second}}
+	built, err = a.Build(&Response{Metadata: map[string]interface{}{"grpc_stream": stream}})
+	require.NoError(t, err)
+	assert.Equal(t, stream, built.(*GRPCReply).Messages)
+
+	_, err = a.Build(&Response{StatusCode: 404})
+	assert.Error(t, err)
+
+	// 响应消息按 JSON 映射转换，未知字段报错
+	_, err = registry.UnmarshalMessage(method.Output(), []byte(`{"userId":"42","createdAt":"2024-01-02T03:04:05Z"}`))
+	assert.NoError(t, err)
+	_, err = registry.UnmarshalMessage(method.Output(), []byte(`{"unknown":1}`))
+	assert.Error(t, err)
+}
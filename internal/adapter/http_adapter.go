@@ -81,6 +81,17 @@ func (a *HTTPAdapter) Parse(rawRequest interface{}) (*Request, error) {
 		},
 	}
 
+	// HTTPS 连接上客户端出示的证书，供 mTLS 规则匹配和模板渲染使用
+	if cert := clientCertificateFromTLS(c.Request.TLS); cert != nil {
+		request.Metadata["client_cert"] = cert
+	}
+
+	// 解析表单请求体，供规则匹配和模板渲染复用
+	form, err := ParseFormBody(c.GetHeader("Content-Type"), body)
+	if err == nil && form != nil {
+		request.ParsedBody = form
+	}
+
 	return request, nil
 }
 
@@ -92,6 +103,23 @@ func (a *HTTPAdapter) Build(response *Response) (interface{}, error) {
 
 // WriteResponse 将响应写入 gin.Context
 func (a *HTTPAdapter) WriteResponse(c *gin.Context, response *Response) {
+	// 注入传输层故障时连接被劫持，不再写入正常响应
+	if fault, ok := response.Metadata["fault"].(*models.FaultConfig); ok && a.writeFault(c, response, fault) {
+		return
+	}
+
+	// SSE 响应逐个事件刷新，不一次性写入响应体
+	if stream, ok := response.Metadata["sse_stream"].(*SSEStream); ok {
+		a.writeSSE(c, response, stream)
+		return
+	}
+
+	// 分块或限速的响应体逐段刷新
+	if stream, ok := response.Metadata["body_stream"].(*BodyStream); ok {
+		a.writeBodyStream(c, response, stream)
+		return
+	}
+
 	// 设置响应头
 	for key, value := range response.Headers {
 		c.Header(key, value)
@@ -0,0 +1,78 @@
+package adapter
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SSEEvent 待发送的 Server-Sent Events 事件
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string // 多行文本拆分为多个 data 字段，为空时不写 data 字段
+	Retry int
+	Delay time.Duration // 发送前等待的时间
+}
+
+// SSEStream Server-Sent Events 响应流，HTTP 响应 Metadata 中 sse_stream 的值
+type SSEStream struct {
+	Events   []SSEEvent
+	Loop     bool // 循环发送事件直到客户端断开
+	KeepOpen bool // 事件发送完后保持连接直到客户端断开
+}
+
+// FormatSSEEvent 按 text/event-stream 格式编码事件，以空行结束
+func FormatSSEEvent(event SSEEvent) []byte {
+	var buf bytes.Buffer
+	if event.ID != "" {
+		buf.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Event != "" {
+		buf.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Retry > 0 {
+		buf.WriteString("retry: " + strconv.Itoa(event.Retry) + "\n")
+	}
+	if event.Data != "" {
+		data := strings.ReplaceAll(event.Data, "\r\n", "\n")
+		for _, line := range strings.Split(data, "\n") {
+			buf.WriteString("data: " + line + "\n")
+		}
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// writeSSE 逐个事件写入并刷新响应，客户端断开时停止
+func (a *HTTPAdapter) writeSSE(c *gin.Context, response *Response, stream *SSEStream) {
+	for key, value := range response.Headers {
+		c.Header(key, value)
+	}
+	c.Status(response.StatusCode)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	done := c.Request.Context().Done()
+	for {
+		for _, event := range stream.Events {
+			if !sleepUntilDone(done, event.Delay) {
+				return
+			}
+			if _, err := c.Writer.Write(FormatSSEEvent(event)); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+		if !stream.Loop {
+			break
+		}
+	}
+
+	if stream.KeepOpen {
+		<-done
+	}
+}
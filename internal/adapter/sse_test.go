@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSSEEvent(t *testing.T) {
+	assert.Equal(t, "id: 7\nevent: update\nretry: 1000\ndata: a\ndata: b\n\n",
+		string(FormatSSEEvent(SSEEvent{ID: "7", Event: "update", Retry: 1000, Data: "a\r\nb"})))
+	assert.Equal(t, "retry: 500\n\n", string(FormatSSEEvent(SSEEvent{Retry: 500})))
+}
+
+// startSSEServer 启动按 stream 写入 SSE 响应的测试服务器
+func startSSEServer(t *testing.T, stream *SSEStream) (*httptest.Server, chan struct{}) {
+	gin.SetMode(gin.TestMode)
+	finished := make(chan struct{})
+	httpAdapter := NewHTTPAdapter()
+	router := gin.New()
+	router.GET("/events", func(c *gin.Context) {
+		defer close(finished)
+		httpAdapter.WriteResponse(c, &Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "text/event-stream"},
+			Metadata:   map[string]interface{}{"sse_stream": stream},
+		})
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, finished
+}
+
+func TestHTTPAdapter_WriteSSE(t *testing.T) {
+	server, finished := startSSEServer(t, &SSEStream{Events: []SSEEvent{
+		{ID: "1", Data: "first"},
+		{ID: "2", Data: "second", Delay: 300 * time.Millisecond},
+	}})
+
+	resp, err := http.Get(server.URL + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// 第一个事件在延迟的事件之前刷新到客户端
+	reader := bufio.NewReader(resp.Body)
+	start := time.Now()
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "id: 1\n", line)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "data: first\n\nid: 2\ndata: second\n\n", string(rest))
+	<-finished
+}
+
+func TestHTTPAdapter_WriteSSELoopStopsOnDisconnect(t *testing.T) {
+	server, finished := startSSEServer(t, &SSEStream{
+		Events: []SSEEvent{{Data: "tick", Delay: 5 * time.Millisecond}},
+		Loop:   true,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	ticks := 0
+	for ticks < 3 && scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "data: tick") {
+			ticks++
+		}
+	}
+	assert.Equal(t, 3, ticks)
+
+	cancel()
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("looping sse stream did not stop after the client disconnected")
+	}
+}
+
+func TestHTTPAdapter_WriteSSEKeepOpen(t *testing.T) {
+	server, finished := startSSEServer(t, &SSEStream{Events: []SSEEvent{{Data: "ready"}}, KeepOpen: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: ready\n", line)
+
+	select {
+	case <-finished:
+		t.Fatal("keep_open stream closed before the client disconnected")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("keep_open stream did not stop after the client disconnected")
+	}
+}
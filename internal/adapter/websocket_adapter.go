@@ -1,8 +1,12 @@
 package adapter
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -35,16 +39,124 @@ type WebSocketAdapter struct {
 
 // WebSocketConnection WebSocket 连接
 type WebSocketConnection struct {
-	ID        string
-	Conn      *websocket.Conn
-	ProjectID string
-	EnvID     string
-	Send      chan []byte
-	Done      chan struct{}
-	LastPing  time.Time
-	LastPong  time.Time
-	Metadata  map[string]interface{}
-	mu        sync.RWMutex
+	ID          string
+	Conn        *websocket.Conn
+	ProjectID   string
+	EnvID       string
+	Path        string            // 握手请求路径（不含项目、环境前缀）
+	Headers     map[string]string // 握手请求头
+	Query       map[string]string // 握手请求查询参数
+	SourceIP    string
+	ConnectedAt time.Time
+	Send        chan []byte         // 待发送的文本消息
+	Frames      chan WebSocketFrame // 待发送的任意类型帧（规则回复、关闭帧）
+	Done        chan struct{}
+	LastPing    time.Time
+	LastPong    time.Time
+	Metadata    map[string]interface{}
+	mu          sync.RWMutex
+}
+
+// WebSocketFrame 待发送的 WebSocket 帧
+type WebSocketFrame struct {
+	Type  int           // websocket.TextMessage、BinaryMessage 或 CloseMessage
+	Data  []byte        // 关闭帧为 websocket.FormatCloseMessage 编码的关闭码和原因
+	Delay time.Duration // 发送前等待的时间
+}
+
+// WebSocketReply WebSocket 响应（WebSocketAdapter.Build 的输出），Frames 依次发送，
+// Periodic 不为空时之后按间隔周期推送
+type WebSocketReply struct {
+	Frames   []WebSocketFrame
+	Periodic *models.WebSocketPeriodic
+}
+
+// WebSocketConnectionInfo 连接信息快照，用于管理接口查询
+type WebSocketConnectionInfo struct {
+	ID            string            `json:"id"`
+	ProjectID     string            `json:"project_id"`
+	EnvironmentID string            `json:"environment_id"`
+	Path          string            `json:"path"`
+	Query         map[string]string `json:"query,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	SourceIP      string            `json:"source_ip"`
+	ConnectedAt   time.Time         `json:"connected_at"`
+	LastPing      time.Time         `json:"last_ping"`
+	LastPong      time.Time         `json:"last_pong"`
+}
+
+// Info 获取连接信息快照
+func (c *WebSocketConnection) Info() WebSocketConnectionInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return WebSocketConnectionInfo{
+		ID:            c.ID,
+		ProjectID:     c.ProjectID,
+		EnvironmentID: c.EnvID,
+		Path:          c.Path,
+		Query:         c.Query,
+		Headers:       c.Headers,
+		SourceIP:      c.SourceIP,
+		ConnectedAt:   c.ConnectedAt,
+		LastPing:      c.LastPing,
+		LastPong:      c.LastPong,
+	}
+}
+
+// NewWebSocketCloseFrame 创建关闭帧
+func NewWebSocketCloseFrame(code int, reason string) WebSocketFrame {
+	return WebSocketFrame{Type: websocket.CloseMessage, Data: websocket.FormatCloseMessage(code, reason)}
+}
+
+// ValidWebSocketCloseCode 判断关闭码能否由服务端发送（1000-4999，不含 1004-1006、1015 等保留码）
+func ValidWebSocketCloseCode(code int) bool {
+	switch {
+	case code < 1000 || code > 4999:
+		return false
+	case code == 1004 || code == 1005 || code == 1006 || code == 1015:
+		return false
+	case code > 1015 && code < 3000:
+		return false
+	}
+	return true
+}
+
+// NewWebSocketMessageFrame 将回复消息转换为帧：text 的 data 为字符串，json 的 data 序列化为文本帧，
+// binary 的 data 为 base64 编码的字符串
+func NewWebSocketMessageFrame(message models.WebSocketReplyMessage) (WebSocketFrame, error) {
+	frame := WebSocketFrame{
+		Type:  websocket.TextMessage,
+		Delay: time.Duration(message.Delay) * time.Millisecond,
+	}
+
+	switch message.Type {
+	case "", models.WebSocketMessageText:
+		text, ok := message.Data.(string)
+		if !ok {
+			return frame, fmt.Errorf("websocket text message data must be a string")
+		}
+		frame.Data = []byte(text)
+	case models.WebSocketMessageJSON:
+		encoded, err := json.Marshal(message.Data)
+		if err != nil {
+			return frame, err
+		}
+		frame.Data = encoded
+	case models.WebSocketMessageBinary:
+		encoded, ok := message.Data.(string)
+		if !ok {
+			return frame, fmt.Errorf("websocket binary message data must be a base64 string")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return frame, fmt.Errorf("invalid base64 websocket binary message: %w", err)
+		}
+		frame.Type = websocket.BinaryMessage
+		frame.Data = decoded
+	default:
+		return frame, fmt.Errorf("unsupported websocket message type: %s", message.Type)
+	}
+	return frame, nil
 }
 
 // WebSocketMessage WebSocket 消息
@@ -92,15 +204,27 @@ func (a *WebSocketAdapter) Parse(rawRequest interface{}) (*Request, error) {
 	// 生成连接 ID
 	connID := uuid.New().String()
 
+	// 与 HTTP 请求相同，路径不含 /:projectID/:environmentID 前缀
+	path := c.Param("path")
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+
 	// 创建连接对象
 	wsConn := &WebSocketConnection{
-		ID:       connID,
-		Conn:     conn,
-		Send:     make(chan []byte, 256),
-		Done:     make(chan struct{}),
-		LastPing: time.Now(),
-		LastPong: time.Now(),
-		Metadata: make(map[string]interface{}),
+		ID:          connID,
+		Conn:        conn,
+		Path:        path,
+		Headers:     extractHeaders(c.Request.Header),
+		Query:       extractQuery(c.Request.URL.Query()),
+		SourceIP:    c.ClientIP(),
+		ConnectedAt: time.Now(),
+		Send:        make(chan []byte, 256),
+		Frames:      make(chan WebSocketFrame, 256),
+		Done:        make(chan struct{}),
+		LastPing:    time.Now(),
+		LastPong:    time.Now(),
+		Metadata:    make(map[string]interface{}),
 	}
 
 	// 从 URL 参数获取项目和环境 ID
@@ -109,8 +233,10 @@ func (a *WebSocketAdapter) Parse(rawRequest interface{}) (*Request, error) {
 	wsConn.ProjectID = projectID
 	wsConn.EnvID = envID
 
-	// 保存连接
-	a.addConnection(wsConn)
+	// 保存连接，超过连接数上限时连接已被关闭
+	if err := a.addConnection(wsConn); err != nil {
+		return nil, err
+	}
 
 	// 启动读写协程
 	go a.readPump(wsConn)
@@ -121,15 +247,15 @@ func (a *WebSocketAdapter) Parse(rawRequest interface{}) (*Request, error) {
 	request := &Request{
 		ID:         connID,
 		Protocol:   models.ProtocolWebSocket,
-		Path:       c.Request.URL.Path,
-		Headers:    extractHeaders(c.Request.Header),
-		SourceIP:   c.ClientIP(),
-		ReceivedAt: time.Now(),
+		Path:       wsConn.Path,
+		Headers:    wsConn.Headers,
+		SourceIP:   wsConn.SourceIP,
+		ReceivedAt: wsConn.ConnectedAt,
 		Metadata: map[string]interface{}{
 			"connection_id":  connID,
 			"project_id":     projectID,
 			"environment_id": envID,
-			"query":          extractQuery(c.Request.URL.Query()),
+			"query":          wsConn.Query,
 			"event":          "connect",
 		},
 	}
@@ -143,10 +269,26 @@ func (a *WebSocketAdapter) Parse(rawRequest interface{}) (*Request, error) {
 	return request, nil
 }
 
-// Build 构建 WebSocket 响应
+// Build 构建 WebSocket 响应：Metadata 中的 websocket_frames 为依次发送的消息帧，
+// websocket_close 为最后发送的关闭帧，websocket_periodic 为周期推送配置
 func (a *WebSocketAdapter) Build(response *Response) (interface{}, error) {
-	// WebSocket 响应通过 Send channel 发送，这里不需要处理
-	return response, nil
+	reply := &WebSocketReply{}
+	if frames, ok := response.Metadata["websocket_frames"].([]WebSocketFrame); ok {
+		reply.Frames = append(reply.Frames, frames...)
+	}
+	if closeFrame, ok := response.Metadata["websocket_close"].(*models.WebSocketClose); ok && closeFrame != nil {
+		if !ValidWebSocketCloseCode(closeFrame.Code) {
+			return nil, fmt.Errorf("invalid websocket close code: %d", closeFrame.Code)
+		}
+		reply.Frames = append(reply.Frames, NewWebSocketCloseFrame(closeFrame.Code, closeFrame.Reason))
+	}
+	if periodic, ok := response.Metadata["websocket_periodic"].(*models.WebSocketPeriodic); ok && periodic != nil {
+		if periodic.Interval <= 0 {
+			return nil, fmt.Errorf("websocket periodic interval must be positive")
+		}
+		reply.Periodic = periodic
+	}
+	return reply, nil
 }
 
 // addConnection 添加连接
@@ -225,6 +367,87 @@ func (a *WebSocketAdapter) SendToConnection(connID string, message []byte) error
 	}
 }
 
+// ListConnections 列出当前连接，按建立时间排序
+func (a *WebSocketAdapter) ListConnections() []WebSocketConnectionInfo {
+	a.connectionsLock.RLock()
+	infos := make([]WebSocketConnectionInfo, 0, len(a.connections))
+	for _, conn := range a.connections {
+		infos = append(infos, conn.Info())
+	}
+	a.connectionsLock.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].ConnectedAt.Equal(infos[j].ConnectedAt) {
+			return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// SendFrameToConnection 发送任意类型的帧到指定连接，与规则回复按顺序发送
+func (a *WebSocketAdapter) SendFrameToConnection(connID string, frame WebSocketFrame) error {
+	conn, exists := a.GetConnection(connID)
+	if !exists {
+		return ErrConnectionNotFound
+	}
+	return a.SendFrames(conn, []WebSocketFrame{frame})
+}
+
+// BroadcastFrame 向 match 返回 true 的连接广播帧，返回成功放入发送队列的连接数
+func (a *WebSocketAdapter) BroadcastFrame(frame WebSocketFrame, match func(conn *WebSocketConnection) bool) int {
+	a.connectionsLock.RLock()
+	defer a.connectionsLock.RUnlock()
+
+	sent := 0
+	for _, conn := range a.connections {
+		if match != nil && !match(conn) {
+			continue
+		}
+		select {
+		case conn.Frames <- frame:
+			sent++
+		default:
+			logger.Warn("failed to send broadcast frame, channel full",
+				zap.String("connection_id", conn.ID))
+		}
+	}
+	return sent
+}
+
+// CloseConnection 以指定关闭码立即断开连接，不等待队列中未发送的帧
+func (a *WebSocketAdapter) CloseConnection(connID string, code int, reason string) error {
+	conn, exists := a.GetConnection(connID)
+	if !exists {
+		return ErrConnectionNotFound
+	}
+	if !ValidWebSocketCloseCode(code) {
+		return fmt.Errorf("invalid websocket close code: %d", code)
+	}
+
+	// WriteControl 可与写协程并发调用；关闭底层连接后读协程退出并移除连接
+	err := conn.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(a.writeWait))
+	conn.Conn.Close()
+	if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+		logger.Warn("failed to send close frame", zap.String("connection_id", connID), zap.Error(err))
+	}
+	return nil
+}
+
+// SendFrames 按顺序向连接发送帧，帧的延迟由写协程等待，不阻塞消息读取
+func (a *WebSocketAdapter) SendFrames(conn *WebSocketConnection, frames []WebSocketFrame) error {
+	for _, frame := range frames {
+		select {
+		case conn.Frames <- frame:
+		case <-conn.Done:
+			return ErrConnectionNotFound
+		case <-time.After(a.writeWait):
+			return ErrSendTimeout
+		}
+	}
+	return nil
+}
+
 // SetMessageHandler 设置消息处理器
 func (a *WebSocketAdapter) SetMessageHandler(handler MessageHandler) {
 	a.messageHandler = handler
@@ -265,26 +488,34 @@ func (a *WebSocketAdapter) readPump(conn *WebSocketConnection) {
 
 		// 将消息传递给消息处理器
 		if a.messageHandler != nil {
-			request := a.createMessageRequest(conn, message)
+			request := a.createMessageRequest(conn, messageType, message)
 			a.messageHandler(request, conn)
 		}
 	}
 }
 
-// createMessageRequest 创建消息请求
-func (a *WebSocketAdapter) createMessageRequest(conn *WebSocketConnection, message []byte) *Request {
+// createMessageRequest 创建消息请求，路径、请求头与查询参数沿用握手请求，供规则按连接条件匹配
+func (a *WebSocketAdapter) createMessageRequest(conn *WebSocketConnection, messageType int, message []byte) *Request {
+	messageTypeName := models.WebSocketMessageText
+	if messageType == websocket.BinaryMessage {
+		messageTypeName = models.WebSocketMessageBinary
+	}
+
 	return &Request{
 		ID:         uuid.New().String(),
 		Protocol:   models.ProtocolWebSocket,
-		Path:       "", // WebSocket 没有路径概念
+		Path:       conn.Path,
+		Headers:    conn.Headers,
 		Body:       message,
-		SourceIP:   "", // 从连接获取
+		SourceIP:   conn.SourceIP,
 		ReceivedAt: time.Now(),
 		Metadata: map[string]interface{}{
 			"connection_id":  conn.ID,
 			"project_id":     conn.ProjectID,
 			"environment_id": conn.EnvID,
+			"query":          conn.Query,
 			"event":          "message",
+			"message_type":   messageTypeName,
 		},
 	}
 }
@@ -313,6 +544,27 @@ func (a *WebSocketAdapter) writePump(conn *WebSocketConnection) {
 				return
 			}
 
+		case frame := <-conn.Frames:
+			if frame.Delay > 0 {
+				timer := time.NewTimer(frame.Delay)
+				select {
+				case <-timer.C:
+				case <-conn.Done:
+					timer.Stop()
+					return
+				}
+			}
+
+			conn.Conn.SetWriteDeadline(time.Now().Add(a.writeWait))
+			if err := conn.Conn.WriteMessage(frame.Type, frame.Data); err != nil {
+				logger.Error("websocket write error", zap.Error(err))
+				return
+			}
+			// 关闭帧发送后关闭连接，读协程随之退出并移除连接
+			if frame.Type == websocket.CloseMessage {
+				return
+			}
+
 		case <-conn.Done:
 			return
 		}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 )
@@ -222,3 +223,23 @@ func TestWebSocketAdapter_Upgrade(t *testing.T) {
 	}
 	// 注意：在某些测试环境中 WebSocket 升级可能失败，这是正常的
 }
+
+func TestWebSocketAdapter_Build(t *testing.T) {
+	adapter := NewWebSocketAdapter()
+	frames := []WebSocketFrame{{Type: websocket.TextMessage, Data: []byte("hi")}}
+
+	built, err := adapter.Build(&Response{Metadata: map[string]interface{}{
+		"websocket_frames": frames,
+		"websocket_close":  &models.WebSocketClose{Code: 1008, Reason: "unauthorized"},
+	}})
+	assert.NoError(t, err)
+	reply := built.(*WebSocketReply)
+	assert.Len(t, reply.Frames, 2)
+	assert.Equal(t, frames[0], reply.Frames[0])
+	assert.Equal(t, NewWebSocketCloseFrame(1008, "unauthorized"), reply.Frames[1])
+
+	_, err = adapter.Build(&Response{Metadata: map[string]interface{}{
+		"websocket_close": &models.WebSocketClose{Code: 42},
+	}})
+	assert.Error(t, err)
+}
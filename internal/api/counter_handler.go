@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RuleCounterStore 规则调用计数器接口
+type RuleCounterStore interface {
+	GetRuleCounters(ruleID string) map[string]int64
+	ResetRuleCounters(ruleID string)
+}
+
+// CounterHandler 规则调用计数器处理器
+type CounterHandler struct {
+	store RuleCounterStore
+}
+
+// NewCounterHandler 创建规则调用计数器处理器
+func NewCounterHandler(store RuleCounterStore) *CounterHandler {
+	return &CounterHandler{
+		store: store,
+	}
+}
+
+// GetRuleCounters 获取规则的调用计数
+func (h *CounterHandler) GetRuleCounters(c *gin.Context) {
+	ruleID := c.Param("id")
+	if ruleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rule_id":  ruleID,
+		"counters": h.store.GetRuleCounters(ruleID),
+	})
+}
+
+// ResetRuleCounters 重置规则的调用计数
+func (h *CounterHandler) ResetRuleCounters(c *gin.Context) {
+	ruleID := c.Param("id")
+	if ruleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	h.store.ResetRuleCounters(ruleID)
+	c.JSON(http.StatusOK, gin.H{"message": "Rule counters reset successfully"})
+}
+
+// ResetAllCounters 重置所有规则的调用计数
+func (h *CounterHandler) ResetAllCounters(c *gin.Context) {
+	h.store.ResetRuleCounters("")
+	c.JSON(http.StatusOK, gin.H{"message": "All rule counters reset successfully"})
+}
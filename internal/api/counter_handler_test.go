@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCounterStore 内存计数器存储
+type fakeCounterStore struct {
+	counters map[string]int64
+}
+
+func (s *fakeCounterStore) GetRuleCounters(ruleID string) map[string]int64 {
+	return map[string]int64{"sequence": s.counters[ruleID]}
+}
+
+func (s *fakeCounterStore) ResetRuleCounters(ruleID string) {
+	if ruleID == "" {
+		s.counters = map[string]int64{}
+		return
+	}
+	delete(s.counters, ruleID)
+}
+
+func TestCounterHandler(t *testing.T) {
+	store := &fakeCounterStore{counters: map[string]int64{"r1": 3, "r2": 5}}
+	handler := NewCounterHandler(store)
+
+	router := setupTestRouter()
+	router.GET("/rules/:id/counters", handler.GetRuleCounters)
+	router.DELETE("/rules/:id/counters", handler.ResetRuleCounters)
+	router.DELETE("/counters", handler.ResetAllCounters)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rules/r1/counters", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		RuleID   string           `json:"rule_id"`
+		Counters map[string]int64 `json:"counters"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "r1", body.RuleID)
+	assert.Equal(t, int64(3), body.Counters["sequence"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/rules/r1/counters", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, store.counters, "r1")
+	assert.Contains(t, store.counters, "r2")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/counters", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, store.counters)
+}
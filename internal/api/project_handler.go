@@ -1,7 +1,9 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gomockserver/mockserver/internal/models"
@@ -113,6 +115,14 @@ func (h *ProjectHandler) CreateEnvironment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateClientAuth(environment.ClientAuth); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateFallback(environment.Fallback); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// 从URL参数中获取项目ID并设置到环境对象中
 	projectID := c.Param("id")
@@ -185,6 +195,14 @@ func (h *ProjectHandler) UpdateEnvironment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateClientAuth(environment.ClientAuth); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateFallback(environment.Fallback); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	environment.ID = id
 
@@ -209,3 +227,38 @@ func (h *ProjectHandler) DeleteEnvironment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Environment deleted successfully"})
 }
+
+// validateClientAuth 校验环境的客户端证书模式
+func validateClientAuth(mode models.ClientAuthMode) error {
+	switch mode {
+	case "", models.ClientAuthRequest, models.ClientAuthRequire, models.ClientAuthIgnore:
+		return nil
+	default:
+		return fmt.Errorf("invalid client_auth %q, must be request, require or ignore", mode)
+	}
+}
+
+// validateFallback 校验环境的回退策略，response 模式需要响应内容，proxy 模式需要 HTTP(S) 上游地址
+func validateFallback(fallback *models.FallbackConfig) error {
+	if fallback == nil {
+		return nil
+	}
+	switch fallback.Mode {
+	case "", models.FallbackNotFound:
+		return nil
+	case models.FallbackResponse:
+		if len(fallback.Response) == 0 {
+			return fmt.Errorf("fallback.response is required for response mode")
+		}
+		return nil
+	case models.FallbackProxy:
+		target, _ := fallback.Proxy["target_url"].(string)
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("fallback.proxy.target_url must be an http(s) URL")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid fallback mode %q, must be not_found, response or proxy", fallback.Mode)
+	}
+}
@@ -369,6 +369,67 @@ func TestProjectHandler_CreateEnvironment(t *testing.T) {
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "要求客户端证书",
+			requestBody: models.Environment{
+				Name:       "合作方环境",
+				ClientAuth: models.ClientAuthRequire,
+			},
+			mockSetup: func(m *MockEnvironmentRepository) {
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.Environment")).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "无效的客户端证书模式",
+			requestBody: models.Environment{
+				Name:       "合作方环境",
+				ClientAuth: "verify",
+			},
+			mockSetup:      func(m *MockEnvironmentRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "未匹配请求转发到上游",
+			requestBody: models.Environment{
+				Name: "联调环境",
+				Fallback: &models.FallbackConfig{
+					Mode:  models.FallbackProxy,
+					Proxy: map[string]interface{}{"target_url": "https://api.example.com"},
+				},
+			},
+			mockSetup: func(m *MockEnvironmentRepository) {
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.Environment")).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "回退代理缺少上游地址",
+			requestBody: models.Environment{
+				Name:     "联调环境",
+				Fallback: &models.FallbackConfig{Mode: models.FallbackProxy, Proxy: map[string]interface{}{"timeout": 5}},
+			},
+			mockSetup:      func(m *MockEnvironmentRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "回退响应缺少内容",
+			requestBody: models.Environment{
+				Name:     "联调环境",
+				Fallback: &models.FallbackConfig{Mode: models.FallbackResponse},
+			},
+			mockSetup:      func(m *MockEnvironmentRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "无效的回退模式",
+			requestBody: models.Environment{
+				Name:     "联调环境",
+				Fallback: &models.FallbackConfig{Mode: "redirect"},
+			},
+			mockSetup:      func(m *MockEnvironmentRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
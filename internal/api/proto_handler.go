@@ -0,0 +1,280 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxProtoFileSize 单个描述文件大小上限
+const maxProtoFileSize = 4 << 20
+
+// ProtoChangeListener 项目描述文件变更监听器（如 gRPC 服务的描述符缓存）
+type ProtoChangeListener interface {
+	ProtosChanged(projectID string)
+}
+
+// ProtoHandler protobuf 描述文件处理器
+type ProtoHandler struct {
+	repo      repository.ProtoRepository
+	listeners []ProtoChangeListener
+}
+
+// NewProtoHandler 创建 protobuf 描述文件处理器
+func NewProtoHandler(repo repository.ProtoRepository, listeners ...ProtoChangeListener) *ProtoHandler {
+	return &ProtoHandler{
+		repo:      repo,
+		listeners: listeners,
+	}
+}
+
+// UploadProtoRequest JSON 形式的描述文件上传请求，描述集的 content 为 base64 编码
+type UploadProtoRequest struct {
+	Name    string               `json:"name" binding:"required"`
+	Kind    models.ProtoFileKind `json:"kind"`
+	Content []byte               `json:"content"`
+}
+
+// UploadProtos 上传描述文件
+//
+// 支持 multipart/form-data（files 字段可包含多个文件，上传文件名不含目录，
+// 可按顺序为每个文件提供一个 name 字段指定 import 路径）
+// 或 JSON 请求体。扩展名为 .proto 的文件按源文件处理，其他文件按 FileDescriptorSet 处理。
+// 上传的文件与项目已有文件一起编译，编译失败时不保存。
+func (h *ProtoHandler) UploadProtos(c *gin.Context) {
+	projectID := c.Param("id")
+
+	uploads, err := h.readUploads(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, file := range uploads {
+		file.ProjectID = projectID
+		if err := validateProtoName(file.Name); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	existing, err := h.repo.FindByProject(ctx, projectID)
+	if err != nil {
+		logger.Error("failed to list proto files", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list proto files"})
+		return
+	}
+
+	registry, err := adapter.CompileProtoFiles(mergeProtoFiles(existing, uploads))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to compile proto files: " + err.Error()})
+		return
+	}
+
+	for _, file := range uploads {
+		file.Services = protoFileServices(registry, file)
+		if err := h.repo.Save(ctx, file); err != nil {
+			logger.Error("failed to save proto file", zap.String("name", file.Name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save proto file"})
+			return
+		}
+	}
+	h.notify(projectID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"files":    uploads,
+		"services": registry.Services(),
+	})
+}
+
+// ListProtos 列出项目的描述文件
+func (h *ProtoHandler) ListProtos(c *gin.Context) {
+	files, err := h.repo.FindByProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		logger.Error("failed to list proto files", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list proto files"})
+		return
+	}
+	if files == nil {
+		files = []*models.ProtoFile{}
+	}
+
+	c.JSON(http.StatusOK, files)
+}
+
+// GetProto 下载描述文件原始内容
+func (h *ProtoHandler) GetProto(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("name"), "/")
+	files, err := h.repo.FindByProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		logger.Error("failed to list proto files", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list proto files"})
+		return
+	}
+
+	for _, file := range files {
+		if file.Name == name {
+			contentType := "application/octet-stream"
+			if file.Kind != models.ProtoFileKindDescriptorSet {
+				contentType = "text/plain; charset=utf-8"
+			}
+			c.Data(http.StatusOK, contentType, file.Content)
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Proto file not found"})
+}
+
+// DeleteProto 删除描述文件，其他文件仍 import 它时拒绝删除
+func (h *ProtoHandler) DeleteProto(c *gin.Context) {
+	projectID := c.Param("id")
+	name := strings.TrimPrefix(c.Param("name"), "/")
+	ctx := c.Request.Context()
+
+	files, err := h.repo.FindByProject(ctx, projectID)
+	if err != nil {
+		logger.Error("failed to list proto files", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list proto files"})
+		return
+	}
+	remaining := make([]*models.ProtoFile, 0, len(files))
+	for _, file := range files {
+		if file.Name != name {
+			remaining = append(remaining, file)
+		}
+	}
+	if len(remaining) == len(files) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proto file not found"})
+		return
+	}
+	if _, err := adapter.CompileProtoFiles(remaining); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Proto file is still required: " + err.Error()})
+		return
+	}
+
+	if _, err := h.repo.Delete(ctx, projectID, name); err != nil {
+		logger.Error("failed to delete proto file", zap.String("name", name), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete proto file"})
+		return
+	}
+	h.notify(projectID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Proto file deleted successfully"})
+}
+
+// readUploads 读取上传的描述文件
+func (h *ProtoHandler) readUploads(c *gin.Context) ([]*models.ProtoFile, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		var req UploadProtoRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		if req.Kind == "" {
+			req.Kind = protoFileKind(req.Name)
+		}
+		return []*models.ProtoFile{{Name: req.Name, Kind: req.Kind, Content: req.Content}}, nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	headers := form.File["files"]
+	if len(headers) == 0 {
+		return nil, errors.New("no files uploaded")
+	}
+	names := form.Value["name"]
+	if len(names) > 0 && len(names) != len(headers) {
+		return nil, errors.New("name must be specified for every uploaded file")
+	}
+
+	uploads := make([]*models.ProtoFile, 0, len(headers))
+	for i, header := range headers {
+		if header.Size > maxProtoFileSize {
+			return nil, errors.New("file too large: " + header.Filename)
+		}
+		f, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		name := header.Filename
+		if len(names) > 0 {
+			name = names[i]
+		}
+		uploads = append(uploads, &models.ProtoFile{Name: name, Kind: protoFileKind(name), Content: content})
+	}
+	return uploads, nil
+}
+
+// notify 通知描述文件已变更
+func (h *ProtoHandler) notify(projectID string) {
+	for _, listener := range h.listeners {
+		listener.ProtosChanged(projectID)
+	}
+}
+
+// protoFileKind 按扩展名判断描述文件类型
+func protoFileKind(name string) models.ProtoFileKind {
+	if strings.EqualFold(path.Ext(name), ".proto") {
+		return models.ProtoFileKindSource
+	}
+	return models.ProtoFileKindDescriptorSet
+}
+
+// validateProtoName 校验描述文件名（即 import 路径）
+func validateProtoName(name string) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") || path.Clean(name) != name || strings.HasPrefix(name, "../") {
+		return errors.New("invalid proto file name: " + name)
+	}
+	if strings.HasPrefix(name, "google/protobuf/") {
+		return errors.New("proto file name is reserved for standard imports: " + name)
+	}
+	return nil
+}
+
+// mergeProtoFiles 用上传的文件替换或补充已有文件
+func mergeProtoFiles(existing, uploads []*models.ProtoFile) []*models.ProtoFile {
+	replaced := make(map[string]bool, len(uploads))
+	for _, file := range uploads {
+		replaced[file.Name] = true
+	}
+	merged := make([]*models.ProtoFile, 0, len(existing)+len(uploads))
+	for _, file := range existing {
+		if !replaced[file.Name] {
+			merged = append(merged, file)
+		}
+	}
+	return append(merged, uploads...)
+}
+
+// protoFileServices 获取上传文件定义的服务，描述集包含其中所有文件的服务
+func protoFileServices(registry *adapter.ProtoRegistry, file *models.ProtoFile) []string {
+	if file.Kind != models.ProtoFileKindDescriptorSet {
+		return registry.FileServices(file.Name)
+	}
+	set, err := adapter.DescriptorSetFiles(file.Content)
+	if err != nil {
+		return nil
+	}
+	var services []string
+	for _, name := range set {
+		services = append(services, registry.FileServices(name)...)
+	}
+	return services
+}
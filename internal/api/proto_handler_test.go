@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryProtoRepository 内存描述文件仓库
+type memoryProtoRepository struct {
+	files map[string]*models.ProtoFile
+}
+
+func newMemoryProtoRepository() *memoryProtoRepository {
+	return &memoryProtoRepository{files: make(map[string]*models.ProtoFile)}
+}
+
+func (r *memoryProtoRepository) Save(ctx context.Context, file *models.ProtoFile) error {
+	r.files[file.ProjectID+"/"+file.Name] = file
+	return nil
+}
+
+func (r *memoryProtoRepository) Delete(ctx context.Context, projectID, name string) (bool, error) {
+	key := projectID + "/" + name
+	_, exists := r.files[key]
+	delete(r.files, key)
+	return exists, nil
+}
+
+func (r *memoryProtoRepository) FindByProject(ctx context.Context, projectID string) ([]*models.ProtoFile, error) {
+	var files []*models.ProtoFile
+	for _, file := range r.files {
+		if file.ProjectID == projectID {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// recordingProtoListener 记录描述文件变更通知
+type recordingProtoListener struct {
+	changed []string
+}
+
+func (l *recordingProtoListener) ProtosChanged(projectID string) {
+	l.changed = append(l.changed, projectID)
+}
+
+const handlerCommonProto = `syntax = "proto3";
+package acme.common.v1;
+message Empty {}
+`
+
+const handlerPingProto = `syntax = "proto3";
+package acme.ping.v1;
+import "acme/common/v1/common.proto";
+service PingService {
+  rpc Ping(acme.common.v1.Empty) returns (acme.common.v1.Empty);
+}
+`
+
+func setupProtoRouter(handler *ProtoHandler) *gin.Engine {
+	router := setupTestRouter()
+	protos := router.Group("/projects/:id/protos")
+	protos.GET("", handler.ListProtos)
+	protos.POST("", handler.UploadProtos)
+	protos.GET("/*name", handler.GetProto)
+	protos.DELETE("/*name", handler.DeleteProto)
+	return router
+}
+
+func uploadProtoJSON(router *gin.Engine, name, content string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(UploadProtoRequest{Name: name, Content: []byte(content)})
+	req := httptest.NewRequest(http.MethodPost, "/projects/p1/protos", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestProtoHandler_UploadListDelete(t *testing.T) {
+	repo := newMemoryProtoRepository()
+	listener := &recordingProtoListener{}
+	router := setupProtoRouter(NewProtoHandler(repo, listener))
+
+	// 缺少依赖时编译失败，不保存
+	w := uploadProtoJSON(router, "acme/ping/v1/ping.proto", handlerPingProto)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, repo.files)
+
+	// multipart 同时上传多个文件
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("files", "common.proto")
+	part.Write([]byte(handlerCommonProto))
+	part, _ = writer.CreateFormFile("files", "ping.proto")
+	part.Write([]byte(handlerPingProto))
+	writer.WriteField("name", "acme/common/v1/common.proto")
+	writer.WriteField("name", "acme/ping/v1/ping.proto")
+	writer.Close()
+	req := httptest.NewRequest(http.MethodPost, "/projects/p1/protos", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "acme.ping.v1.PingService")
+	assert.Equal(t, []string{"p1"}, listener.changed)
+	assert.Equal(t, []string{"acme.ping.v1.PingService"}, repo.files["p1/acme/ping/v1/ping.proto"].Services)
+
+	req = httptest.NewRequest(http.MethodGet, "/projects/p1/protos", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var files []models.ProtoFile
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &files))
+	require.Len(t, files, 2)
+	assert.Equal(t, "acme/common/v1/common.proto", files[0].Name)
+	assert.Equal(t, models.ProtoFileKindSource, files[0].Kind)
+
+	req = httptest.NewRequest(http.MethodGet, "/projects/p1/protos/acme/ping/v1/ping.proto", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handlerPingProto, w.Body.String())
+
+	// 仍被 import 的文件不能删除
+	req = httptest.NewRequest(http.MethodDelete, "/projects/p1/protos/acme/common/v1/common.proto", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/projects/p1/protos/acme/ping/v1/ping.proto", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, repo.files, 1)
+	assert.Equal(t, []string{"p1", "p1"}, listener.changed)
+
+	req = httptest.NewRequest(http.MethodDelete, "/projects/p1/protos/acme/ping/v1/ping.proto", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProtoHandler_InvalidName(t *testing.T) {
+	router := setupProtoRouter(NewProtoHandler(newMemoryProtoRepository()))
+
+	for _, name := range []string{"/abs.proto", "../up.proto", "a/../b.proto", "google/protobuf/empty.proto"} {
+		w := uploadProtoJSON(router, name, handlerCommonProto)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+}
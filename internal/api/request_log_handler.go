@@ -44,12 +44,14 @@ type ListRequestLogsRequest struct {
 	Path          string `form:"path"`
 	StatusCode    int    `form:"status_code"`
 	SourceIP      string `form:"source_ip"`
-	StartTime     string `form:"start_time"` // RFC3339 格式
-	EndTime       string `form:"end_time"`   // RFC3339 格式
-	Page          int    `form:"page"`
-	PageSize      int    `form:"page_size"`
-	SortBy        string `form:"sort_by"`
-	SortOrder     string `form:"sort_order"` // asc, desc
+	// ContractViolated 仅返回响应不符合 OpenAPI 契约的日志
+	ContractViolated bool   `form:"contract_violated"`
+	StartTime        string `form:"start_time"` // RFC3339 格式
+	EndTime          string `form:"end_time"`   // RFC3339 格式
+	Page             int    `form:"page"`
+	PageSize         int    `form:"page_size"`
+	SortBy           string `form:"sort_by"`
+	SortOrder        string `form:"sort_order"` // asc, desc
 }
 
 // ListRequestLogsResponse 列表查询响应
@@ -73,6 +75,7 @@ type ListRequestLogsResponse struct {
 // @Param path query string false "路径（支持正则）"
 // @Param status_code query int false "状态码"
 // @Param source_ip query string false "来源IP"
+// @Param contract_violated query bool false "仅查询存在契约违规的日志"
 // @Param start_time query string false "开始时间（RFC3339格式）"
 // @Param end_time query string false "结束时间（RFC3339格式）"
 // @Param page query int false "页码" default(1)
@@ -98,17 +101,18 @@ func (h *RequestLogHandler) ListRequestLogs(c *gin.Context) {
 
 	// 构建过滤器
 	filter := repository.RequestLogFilter{
-		ProjectID:     req.ProjectID,
-		EnvironmentID: req.EnvironmentID,
-		RuleID:        req.RuleID,
-		Protocol:      models.ProtocolType(req.Protocol),
-		Method:        req.Method,
-		Path:          req.Path,
-		StatusCode:    req.StatusCode,
-		SourceIP:      req.SourceIP,
-		Page:          req.Page,
-		PageSize:      req.PageSize,
-		SortBy:        req.SortBy,
+		ProjectID:        req.ProjectID,
+		EnvironmentID:    req.EnvironmentID,
+		RuleID:           req.RuleID,
+		Protocol:         models.ProtocolType(req.Protocol),
+		Method:           req.Method,
+		Path:             req.Path,
+		StatusCode:       req.StatusCode,
+		SourceIP:         req.SourceIP,
+		ContractViolated: req.ContractViolated,
+		Page:             req.Page,
+		PageSize:         req.PageSize,
+		SortBy:           req.SortBy,
 	}
 
 	// 解析时间
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// HostBindingListener 主机名绑定变更监听器（如 service.RoutingService）
+type HostBindingListener interface {
+	BindingsChanged(ctx context.Context)
+}
+
+// RoutingHandler 主机名路由绑定处理器
+type RoutingHandler struct {
+	repo     repository.HostBindingRepository
+	envRepo  repository.EnvironmentRepository
+	listener HostBindingListener
+}
+
+// NewRoutingHandler 创建主机名路由绑定处理器
+func NewRoutingHandler(repo repository.HostBindingRepository, envRepo repository.EnvironmentRepository, listener HostBindingListener) *RoutingHandler {
+	return &RoutingHandler{
+		repo:     repo,
+		envRepo:  envRepo,
+		listener: listener,
+	}
+}
+
+// SaveHostBindingRequest 主机名绑定请求
+type SaveHostBindingRequest struct {
+	ProjectID     string `json:"project_id" binding:"required"`
+	EnvironmentID string `json:"environment_id" binding:"required"`
+}
+
+// ListHostBindings 查询所有主机名绑定
+func (h *RoutingHandler) ListHostBindings(c *gin.Context) {
+	bindings, err := h.repo.List(c.Request.Context())
+	if err != nil {
+		logger.Error("failed to list host bindings", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list host bindings"})
+		return
+	}
+	if bindings == nil {
+		bindings = []*models.HostBinding{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": bindings})
+}
+
+// SaveHostBinding 将主机名绑定到环境，覆盖已有绑定
+func (h *RoutingHandler) SaveHostBinding(c *gin.Context) {
+	host, ok := normalizeBindingHost(c.Param("host"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid host, expected a hostname without port such as payments.mock.local or *.mock.local"})
+		return
+	}
+
+	var req SaveHostBindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	env, err := h.envRepo.FindByID(ctx, req.EnvironmentID)
+	if err != nil || env == nil || env.ProjectID != req.ProjectID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Environment not found in project"})
+		return
+	}
+
+	binding := &models.HostBinding{
+		Host:          host,
+		ProjectID:     req.ProjectID,
+		EnvironmentID: req.EnvironmentID,
+	}
+	if err := h.repo.Save(ctx, binding); err != nil {
+		logger.Error("failed to save host binding", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save host binding"})
+		return
+	}
+	h.listener.BindingsChanged(ctx)
+
+	c.JSON(http.StatusOK, binding)
+}
+
+// DeleteHostBinding 删除主机名绑定
+func (h *RoutingHandler) DeleteHostBinding(c *gin.Context) {
+	host, ok := normalizeBindingHost(c.Param("host"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid host"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	deleted, err := h.repo.Delete(ctx, host)
+	if err != nil {
+		logger.Error("failed to delete host binding", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete host binding"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host binding not found"})
+		return
+	}
+	h.listener.BindingsChanged(ctx)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Host binding deleted successfully"})
+}
+
+// normalizeBindingHost 校验并规范化绑定的主机名（小写、无端口），通配符只允许 *. 前缀
+func normalizeBindingHost(host string) (string, bool) {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if host == "" || strings.ContainsAny(host, ":/ ") {
+		return "", false
+	}
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+		return "", false
+	}
+	return host, true
+}
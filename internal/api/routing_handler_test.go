@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHostBindingRepository 内存主机名绑定仓库
+type memoryHostBindingRepository struct {
+	bindings map[string]*models.HostBinding
+}
+
+func newMemoryHostBindingRepository() *memoryHostBindingRepository {
+	return &memoryHostBindingRepository{bindings: make(map[string]*models.HostBinding)}
+}
+
+func (r *memoryHostBindingRepository) Save(ctx context.Context, binding *models.HostBinding) error {
+	r.bindings[binding.Host] = binding
+	return nil
+}
+
+func (r *memoryHostBindingRepository) Delete(ctx context.Context, host string) (bool, error) {
+	_, exists := r.bindings[host]
+	delete(r.bindings, host)
+	return exists, nil
+}
+
+func (r *memoryHostBindingRepository) List(ctx context.Context) ([]*models.HostBinding, error) {
+	bindings := make([]*models.HostBinding, 0, len(r.bindings))
+	for _, binding := range r.bindings {
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
+
+// recordingBindingListener 记录主机名绑定变更次数
+type recordingBindingListener struct {
+	changed int
+}
+
+func (l *recordingBindingListener) BindingsChanged(ctx context.Context) {
+	l.changed++
+}
+
+func setupRoutingRouter(handler *RoutingHandler) *gin.Engine {
+	router := setupTestRouter()
+	hosts := router.Group("/routing/hosts")
+	hosts.GET("", handler.ListHostBindings)
+	hosts.PUT("/:host", handler.SaveHostBinding)
+	hosts.DELETE("/:host", handler.DeleteHostBinding)
+	return router
+}
+
+// TestRoutingHandler_HostBindings 测试绑定、查询与删除主机名
+func TestRoutingHandler_HostBindings(t *testing.T) {
+	repo := newMemoryHostBindingRepository()
+	envRepo := new(MockEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", ProjectID: "p1"}, nil)
+	listener := &recordingBindingListener{}
+	router := setupRoutingRouter(NewRoutingHandler(repo, envRepo, listener))
+
+	w := doTLSRequest(router, http.MethodPut, "/routing/hosts/Payments.Mock.Local", SaveHostBindingRequest{ProjectID: "p1", EnvironmentID: "e1"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1, listener.changed)
+	require.Contains(t, repo.bindings, "payments.mock.local")
+	assert.Equal(t, "e1", repo.bindings["payments.mock.local"].EnvironmentID)
+
+	w = doTLSRequest(router, http.MethodGet, "/routing/hosts", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "payments.mock.local")
+
+	w = doTLSRequest(router, http.MethodDelete, "/routing/hosts/payments.mock.local", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, listener.changed)
+	assert.Empty(t, repo.bindings)
+
+	w = doTLSRequest(router, http.MethodDelete, "/routing/hosts/payments.mock.local", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestRoutingHandler_SaveHostBinding_Invalid 测试无效的主机名绑定
+func TestRoutingHandler_SaveHostBinding_Invalid(t *testing.T) {
+	envRepo := new(MockEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", ProjectID: "p1"}, nil)
+	envRepo.On("FindByID", mock.Anything, "missing").Return(nil, nil)
+	listener := &recordingBindingListener{}
+	router := setupRoutingRouter(NewRoutingHandler(newMemoryHostBindingRepository(), envRepo, listener))
+
+	tests := []struct {
+		name string
+		host string
+		req  interface{}
+	}{
+		{"host with port", "payments.mock.local:8080", SaveHostBindingRequest{ProjectID: "p1", EnvironmentID: "e1"}},
+		{"wildcard in middle", "api.*.mock.local", SaveHostBindingRequest{ProjectID: "p1", EnvironmentID: "e1"}},
+		{"missing environment id", "payments.mock.local", map[string]string{"project_id": "p1"}},
+		{"environment not found", "payments.mock.local", SaveHostBindingRequest{ProjectID: "p1", EnvironmentID: "missing"}},
+		{"environment in other project", "payments.mock.local", SaveHostBindingRequest{ProjectID: "p2", EnvironmentID: "e1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doTLSRequest(router, http.MethodPut, "/routing/hosts/"+tt.host, tt.req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+	assert.Equal(t, 0, listener.changed)
+}
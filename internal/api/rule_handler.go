@@ -127,6 +127,9 @@ func (h *RuleHandler) UpdateRule(c *gin.Context) {
 	if rule.Tags == nil {
 		rule.Tags = existingRule.Tags
 	}
+	if rule.Scenario == nil {
+		rule.Scenario = existingRule.Scenario
+	}
 	if rule.Creator == "" {
 		rule.Creator = existingRule.Creator
 	}
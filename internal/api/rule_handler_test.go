@@ -338,6 +338,34 @@ func TestRuleHandler_UpdateRule(t *testing.T) {
 	}
 }
 
+// TestRuleHandler_UpdateRule_KeepsScenario 测试未携带 scenario 的更新保留规则的场景配置
+func TestRuleHandler_UpdateRule_KeepsScenario(t *testing.T) {
+	scenario := &models.RuleScenario{Name: "checkout", RequiredState: "Started", NewState: "Paid"}
+	mockRepo := new(MockRuleRepository)
+	mockRepo.On("FindByID", mock.Anything, "rule-001").Return(&models.Rule{
+		ID:       "rule-001",
+		Name:     "原始规则",
+		Protocol: models.ProtocolHTTP,
+		Scenario: scenario,
+	}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(rule *models.Rule) bool {
+		return rule.Name == "更新后的规则" && assert.ObjectsAreEqual(scenario, rule.Scenario)
+	})).Return(nil)
+
+	handler := NewRuleHandler(mockRepo, new(MockProjectRepository), new(MockEnvironmentRepository))
+	router := setupTestRouter()
+	router.PUT("/rules/:id", handler.UpdateRule)
+
+	req := httptest.NewRequest(http.MethodPut, "/rules/rule-001", bytes.NewBufferString(`{"name":"更新后的规则"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"checkout"`)
+	mockRepo.AssertExpectations(t)
+}
+
 // TestRuleHandler_DeleteRule 测试删除规则
 func TestRuleHandler_DeleteRule(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ScenarioStateStore 场景状态存储接口
+type ScenarioStateStore interface {
+	List(ctx context.Context, projectID, environmentID string) ([]*models.ScenarioState, error)
+	SetState(ctx context.Context, state *models.ScenarioState) error
+	Reset(ctx context.Context, projectID, environmentID, scenario string) error
+}
+
+// ScenarioHandler 场景状态处理器
+type ScenarioHandler struct {
+	store ScenarioStateStore
+}
+
+// NewScenarioHandler 创建场景状态处理器
+func NewScenarioHandler(store ScenarioStateStore) *ScenarioHandler {
+	return &ScenarioHandler{
+		store: store,
+	}
+}
+
+// SetScenarioStateRequest 设置场景状态请求
+type SetScenarioStateRequest struct {
+	State    string `json:"state" binding:"required"`
+	ClientID string `json:"client_id"`
+}
+
+// ListScenarios 列出环境下的场景状态
+func (h *ScenarioHandler) ListScenarios(c *gin.Context) {
+	projectID := c.Param("id")
+	environmentID := c.Param("env_id")
+
+	states, err := h.store.List(c.Request.Context(), projectID, environmentID)
+	if err != nil {
+		logger.Error("failed to list scenario states", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list scenario states"})
+		return
+	}
+
+	c.JSON(http.StatusOK, states)
+}
+
+// SetScenarioState 设置场景状态
+func (h *ScenarioHandler) SetScenarioState(c *gin.Context) {
+	var req SetScenarioStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	state := &models.ScenarioState{
+		ProjectID:     c.Param("id"),
+		EnvironmentID: c.Param("env_id"),
+		Scenario:      c.Param("name"),
+		ClientID:      req.ClientID,
+		State:         req.State,
+	}
+
+	if err := h.store.SetState(c.Request.Context(), state); err != nil {
+		logger.Error("failed to set scenario state", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set scenario state"})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// ResetScenarios 重置场景状态，未指定场景名称时重置环境下所有场景
+func (h *ScenarioHandler) ResetScenarios(c *gin.Context) {
+	projectID := c.Param("id")
+	environmentID := c.Param("env_id")
+	scenario := c.Param("name")
+
+	if err := h.store.Reset(c.Request.Context(), projectID, environmentID, scenario); err != nil {
+		logger.Error("failed to reset scenario states", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset scenario states"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scenario states reset successfully"})
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/engine"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingScenarioStore 总是返回错误的场景状态存储
+type failingScenarioStore struct{}
+
+func (failingScenarioStore) List(ctx context.Context, projectID, environmentID string) ([]*models.ScenarioState, error) {
+	return nil, errors.New("db down")
+}
+
+func (failingScenarioStore) SetState(ctx context.Context, state *models.ScenarioState) error {
+	return errors.New("db down")
+}
+
+func (failingScenarioStore) Reset(ctx context.Context, projectID, environmentID, scenario string) error {
+	return errors.New("db down")
+}
+
+func setupScenarioRouter(handler *ScenarioHandler) *gin.Engine {
+	router := setupTestRouter()
+	scenarios := router.Group("/projects/:id/environments/:env_id/scenarios")
+	scenarios.GET("", handler.ListScenarios)
+	scenarios.DELETE("", handler.ResetScenarios)
+	scenarios.PUT("/:name", handler.SetScenarioState)
+	scenarios.DELETE("/:name", handler.ResetScenarios)
+	return router
+}
+
+func TestScenarioHandler_SetListReset(t *testing.T) {
+	store := engine.NewScenarioStore(nil)
+	router := setupScenarioRouter(NewScenarioHandler(store))
+
+	body, _ := json.Marshal(SetScenarioStateRequest{State: "approved"})
+	req := httptest.NewRequest(http.MethodPut, "/projects/p1/environments/e1/scenarios/order", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "approved", store.State(context.Background(), "p1", "e1", "order", ""))
+
+	req = httptest.NewRequest(http.MethodGet, "/projects/p1/environments/e1/scenarios", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var states []models.ScenarioState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Len(t, states, 1)
+	assert.Equal(t, "order", states[0].Scenario)
+	assert.Equal(t, "approved", states[0].State)
+
+	req = httptest.NewRequest(http.MethodDelete, "/projects/p1/environments/e1/scenarios/order", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ScenarioStateStarted, store.State(context.Background(), "p1", "e1", "order", ""))
+}
+
+func TestScenarioHandler_SetStateValidation(t *testing.T) {
+	router := setupScenarioRouter(NewScenarioHandler(engine.NewScenarioStore(nil)))
+
+	req := httptest.NewRequest(http.MethodPut, "/projects/p1/environments/e1/scenarios/order", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestScenarioHandler_StoreErrors(t *testing.T) {
+	router := setupScenarioRouter(NewScenarioHandler(failingScenarioStore{}))
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/projects/p1/environments/e1/scenarios", ""},
+		{http.MethodPut, "/projects/p1/environments/e1/scenarios/order", `{"state":"x"}`},
+		{http.MethodDelete, "/projects/p1/environments/e1/scenarios", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code, tt.method+" "+tt.path)
+	}
+}
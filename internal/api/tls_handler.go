@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TLSCertificateManager HTTPS Mock 服务证书管理器（如 service.TLSService）
+type TLSCertificateManager interface {
+	CACertificatePEM() []byte
+	CertificatesChanged(ctx context.Context)
+}
+
+// TLSHandler HTTPS 证书处理器
+type TLSHandler struct {
+	repo  repository.TLSCertificateRepository
+	certs TLSCertificateManager
+}
+
+// NewTLSHandler 创建 HTTPS 证书处理器
+func NewTLSHandler(repo repository.TLSCertificateRepository, certs TLSCertificateManager) *TLSHandler {
+	return &TLSHandler{
+		repo:  repo,
+		certs: certs,
+	}
+}
+
+// UploadTLSCertificateRequest 环境证书上传请求，证书与私钥均为 PEM 编码
+type UploadTLSCertificateRequest struct {
+	Certificate string `json:"certificate" binding:"required"` // 证书链，第一个为服务器证书
+	PrivateKey  string `json:"private_key" binding:"required"`
+}
+
+// DownloadCA 下载根 CA 证书
+func (h *TLSHandler) DownloadCA(c *gin.Context) {
+	c.Header("Content-Disposition", `attachment; filename="mockserver-ca.crt"`)
+	c.Data(http.StatusOK, "application/x-pem-file", h.certs.CACertificatePEM())
+}
+
+// GetCertificate 获取环境上传的证书（不含私钥）
+func (h *TLSHandler) GetCertificate(c *gin.Context) {
+	cert, err := h.repo.FindByEnvironment(c.Request.Context(), c.Param("env_id"))
+	if err != nil {
+		logger.Error("failed to get tls certificate", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get certificate"})
+		return
+	}
+	if cert == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cert)
+}
+
+// UploadCertificate 上传环境证书，覆盖已有证书；证书的 DNS 名称与 IP 地址用于按 SNI 选择证书
+func (h *TLSHandler) UploadCertificate(c *gin.Context) {
+	var req UploadTLSCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cert, err := parseTLSCertificate(req.Certificate, req.PrivateKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cert.ProjectID = c.Param("id")
+	cert.EnvironmentID = c.Param("env_id")
+
+	ctx := c.Request.Context()
+	if err := h.repo.Save(ctx, cert); err != nil {
+		logger.Error("failed to save tls certificate", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save certificate"})
+		return
+	}
+	h.certs.CertificatesChanged(ctx)
+
+	c.JSON(http.StatusOK, cert)
+}
+
+// DeleteCertificate 删除环境证书，之后该环境的主机名使用根 CA 签发的证书
+func (h *TLSHandler) DeleteCertificate(c *gin.Context) {
+	ctx := c.Request.Context()
+	deleted, err := h.repo.Delete(ctx, c.Param("env_id"))
+	if err != nil {
+		logger.Error("failed to delete tls certificate", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete certificate"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
+		return
+	}
+	h.certs.CertificatesChanged(ctx)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate deleted successfully"})
+}
+
+// parseTLSCertificate 校验证书与私钥匹配，解析证书的主机名和过期时间；允许上传已过期的证书以模拟证书过期
+func parseTLSCertificate(certPEM, keyPEM string) (*models.TLSCertificate, error) {
+	pair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate or private key: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	hostnames := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		hostnames = append(hostnames, ip.String())
+	}
+	if len(hostnames) == 0 {
+		return nil, fmt.Errorf("certificate has no DNS or IP subject alternative names")
+	}
+
+	return &models.TLSCertificate{
+		Hostnames:   hostnames,
+		Certificate: certPEM,
+		PrivateKey:  keyPEM,
+		NotAfter:    leaf.NotAfter,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTLSCertificateRepository 内存环境证书仓库
+type memoryTLSCertificateRepository struct {
+	certs map[string]*models.TLSCertificate
+}
+
+func newMemoryTLSCertificateRepository() *memoryTLSCertificateRepository {
+	return &memoryTLSCertificateRepository{certs: make(map[string]*models.TLSCertificate)}
+}
+
+func (r *memoryTLSCertificateRepository) Save(ctx context.Context, cert *models.TLSCertificate) error {
+	r.certs[cert.EnvironmentID] = cert
+	return nil
+}
+
+func (r *memoryTLSCertificateRepository) Delete(ctx context.Context, environmentID string) (bool, error) {
+	_, exists := r.certs[environmentID]
+	delete(r.certs, environmentID)
+	return exists, nil
+}
+
+func (r *memoryTLSCertificateRepository) FindByEnvironment(ctx context.Context, environmentID string) (*models.TLSCertificate, error) {
+	return r.certs[environmentID], nil
+}
+
+func (r *memoryTLSCertificateRepository) List(ctx context.Context) ([]*models.TLSCertificate, error) {
+	certs := make([]*models.TLSCertificate, 0, len(r.certs))
+	for _, cert := range r.certs {
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// recordingCertificateManager 固定 CA 并记录证书变更次数
+type recordingCertificateManager struct {
+	changed int
+}
+
+func (m *recordingCertificateManager) CACertificatePEM() []byte {
+	return []byte("-----BEGIN CERTIFICATE-----\ntest\n-----END CERTIFICATE-----\n")
+}
+
+func (m *recordingCertificateManager) CertificatesChanged(ctx context.Context) {
+	m.changed++
+}
+
+// testCertificate 生成 PEM 编码的证书和私钥
+func testCertificate(t *testing.T, notAfter time.Time, dnsNames []string, ips []net.IP) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func setupTLSRouter(handler *TLSHandler) *gin.Engine {
+	router := setupTestRouter()
+	router.GET("/tls/ca.crt", handler.DownloadCA)
+	certificates := router.Group("/projects/:id/environments/:env_id/tls")
+	certificates.GET("", handler.GetCertificate)
+	certificates.PUT("", handler.UploadCertificate)
+	certificates.DELETE("", handler.DeleteCertificate)
+	return router
+}
+
+func doTLSRequest(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestTLSHandler_DownloadCA 测试下载根 CA 证书
+func TestTLSHandler_DownloadCA(t *testing.T) {
+	manager := &recordingCertificateManager{}
+	router := setupTLSRouter(NewTLSHandler(newMemoryTLSCertificateRepository(), manager))
+
+	w := doTLSRequest(router, http.MethodGet, "/tls/ca.crt", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-pem-file", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "mockserver-ca.crt")
+	assert.Equal(t, string(manager.CACertificatePEM()), w.Body.String())
+}
+
+// TestTLSHandler_UploadCertificate 测试上传、查询与删除环境证书
+func TestTLSHandler_UploadCertificate(t *testing.T) {
+	repo := newMemoryTLSCertificateRepository()
+	manager := &recordingCertificateManager{}
+	router := setupTLSRouter(NewTLSHandler(repo, manager))
+	path := "/projects/p1/environments/e1/tls"
+
+	w := doTLSRequest(router, http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// 已过期的证书也允许上传，用于模拟证书过期
+	expiry := time.Now().Add(-time.Hour).Truncate(time.Second)
+	certPEM, keyPEM := testCertificate(t, expiry, []string{"api.example.test"}, []net.IP{net.ParseIP("127.0.0.2")})
+	w = doTLSRequest(router, http.MethodPut, path, UploadTLSCertificateRequest{Certificate: certPEM, PrivateKey: keyPEM})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "PRIVATE KEY")
+	assert.Equal(t, 1, manager.changed)
+
+	saved := repo.certs["e1"]
+	require.NotNil(t, saved)
+	assert.Equal(t, "p1", saved.ProjectID)
+	assert.Equal(t, []string{"api.example.test", "127.0.0.2"}, saved.Hostnames)
+	assert.Equal(t, keyPEM, saved.PrivateKey)
+	assert.True(t, saved.NotAfter.Equal(expiry))
+
+	w = doTLSRequest(router, http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "api.example.test")
+
+	w = doTLSRequest(router, http.MethodDelete, path, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, manager.changed)
+	assert.Empty(t, repo.certs)
+
+	w = doTLSRequest(router, http.MethodDelete, path, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestTLSHandler_UploadCertificate_Invalid 测试上传无效证书
+func TestTLSHandler_UploadCertificate_Invalid(t *testing.T) {
+	manager := &recordingCertificateManager{}
+	router := setupTLSRouter(NewTLSHandler(newMemoryTLSCertificateRepository(), manager))
+	path := "/projects/p1/environments/e1/tls"
+
+	certPEM, _ := testCertificate(t, time.Now().Add(time.Hour), []string{"api.example.test"}, nil)
+	_, otherKeyPEM := testCertificate(t, time.Now().Add(time.Hour), []string{"api.example.test"}, nil)
+	noSANCertPEM, noSANKeyPEM := testCertificate(t, time.Now().Add(time.Hour), nil, nil)
+
+	tests := []struct {
+		name string
+		req  interface{}
+	}{
+		{"missing private key", map[string]string{"certificate": certPEM}},
+		{"mismatched key", UploadTLSCertificateRequest{Certificate: certPEM, PrivateKey: otherKeyPEM}},
+		{"not pem", UploadTLSCertificateRequest{Certificate: "cert", PrivateKey: "key"}},
+		{"no subject alternative names", UploadTLSCertificateRequest{Certificate: noSANCertPEM, PrivateKey: noSANKeyPEM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doTLSRequest(router, http.MethodPut, path, tt.req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+	assert.Equal(t, 0, manager.changed)
+}
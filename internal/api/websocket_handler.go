@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// WebSocketHandler Mock 服务 WebSocket 连接管理处理器
+type WebSocketHandler struct {
+	connections *adapter.WebSocketAdapter
+}
+
+// NewWebSocketHandler 创建 WebSocket 连接管理处理器
+func NewWebSocketHandler(connections *adapter.WebSocketAdapter) *WebSocketHandler {
+	return &WebSocketHandler{
+		connections: connections,
+	}
+}
+
+// WebSocketBroadcastRequest 广播请求，project_id、environment_id、path 为空时不按该项过滤
+type WebSocketBroadcastRequest struct {
+	ProjectID     string `json:"project_id"`
+	EnvironmentID string `json:"environment_id"`
+	Path          string `json:"path"`
+	models.WebSocketReplyMessage
+}
+
+// GetStats 获取 WebSocket 连接统计
+func (h *WebSocketHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"active_connections": h.connections.GetConnectionCount(),
+	})
+}
+
+// ListConnections 列出当前连接，可按 project_id、environment_id、path 查询参数过滤
+func (h *WebSocketHandler) ListConnections(c *gin.Context) {
+	projectID := c.Query("project_id")
+	environmentID := c.Query("environment_id")
+	path := c.Query("path")
+
+	connections := make([]adapter.WebSocketConnectionInfo, 0)
+	for _, info := range h.connections.ListConnections() {
+		if (projectID == "" || info.ProjectID == projectID) &&
+			(environmentID == "" || info.EnvironmentID == environmentID) &&
+			(path == "" || info.Path == path) {
+			connections = append(connections, info)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":       len(connections),
+		"connections": connections,
+	})
+}
+
+// GetConnection 获取连接信息
+func (h *WebSocketHandler) GetConnection(c *gin.Context) {
+	conn, exists := h.connections.GetConnection(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conn.Info())
+}
+
+// SendMessage 向指定连接发送一条消息，消息格式与 WebSocket 规则的回复消息相同
+func (h *WebSocketHandler) SendMessage(c *gin.Context) {
+	var message models.WebSocketReplyMessage
+	if err := c.ShouldBindJSON(&message); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	frame, err := adapter.NewWebSocketMessageFrame(message)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.connections.SendFrameToConnection(c.Param("id"), frame); err != nil {
+		if errors.Is(err, adapter.ErrConnectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message sent successfully"})
+}
+
+// Broadcast 向路径上的所有连接广播一条消息
+func (h *WebSocketHandler) Broadcast(c *gin.Context) {
+	var req WebSocketBroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	frame, err := adapter.NewWebSocketMessageFrame(req.WebSocketReplyMessage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sent := h.connections.BroadcastFrame(frame, func(conn *adapter.WebSocketConnection) bool {
+		return (req.ProjectID == "" || conn.ProjectID == req.ProjectID) &&
+			(req.EnvironmentID == "" || conn.EnvID == req.EnvironmentID) &&
+			(req.Path == "" || conn.Path == req.Path)
+	})
+
+	c.JSON(http.StatusOK, gin.H{"sent": sent})
+}
+
+// CloseConnection 以 code 查询参数指定的关闭码（默认 1000）和 reason 断开连接
+func (h *WebSocketHandler) CloseConnection(c *gin.Context) {
+	code := 1000
+	if value := c.Query("code"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || !adapter.ValidWebSocketCloseCode(parsed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid close code: " + value})
+			return
+		}
+		code = parsed
+	}
+
+	if err := h.connections.CloseConnection(c.Param("id"), code, c.Query("reason")); err != nil {
+		if errors.Is(err, adapter.ErrConnectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Connection closed successfully"})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialWebSocket 通过挂载适配器的测试服务器建立连接，返回客户端连接和服务端连接 ID
+func dialWebSocket(t *testing.T, ws *adapter.WebSocketAdapter, baseURL, path string) (*websocket.Conn, string) {
+	before := make(map[string]bool)
+	for _, info := range ws.ListConnections() {
+		before[info.ID] = true
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(baseURL+path, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	var connID string
+	require.Eventually(t, func() bool {
+		for _, info := range ws.ListConnections() {
+			if !before[info.ID] {
+				connID = info.ID
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+	return conn, connID
+}
+
+func setupWebSocketRouter(t *testing.T) (*gin.Engine, *adapter.WebSocketAdapter, string) {
+	ws := adapter.NewWebSocketAdapter()
+	mockRouter := setupTestRouter()
+	mockRouter.GET("/:projectID/:environmentID/*path", func(c *gin.Context) {
+		ws.Parse(c)
+	})
+	server := httptest.NewServer(mockRouter)
+	t.Cleanup(server.Close)
+
+	handler := NewWebSocketHandler(ws)
+	router := setupTestRouter()
+	router.GET("/websocket/stats", handler.GetStats)
+	router.GET("/websocket/connections", handler.ListConnections)
+	router.GET("/websocket/connections/:id", handler.GetConnection)
+	router.POST("/websocket/connections/:id/messages", handler.SendMessage)
+	router.DELETE("/websocket/connections/:id", handler.CloseConnection)
+	router.POST("/websocket/broadcast", handler.Broadcast)
+	return router, ws, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func readTextMessage(t *testing.T, conn *websocket.Conn) string {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	return string(data)
+}
+
+func doWebSocketRequest(router *gin.Engine, method, url string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Buffer
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewBuffer(data)
+	} else {
+		reader = bytes.NewBuffer(nil)
+	}
+	req := httptest.NewRequest(method, url, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebSocketHandler_ListAndGetConnections(t *testing.T) {
+	router, ws, baseURL := setupWebSocketRouter(t)
+	_, chatID := dialWebSocket(t, ws, baseURL, "/p1/e1/ws/chat?room=1")
+	dialWebSocket(t, ws, baseURL, "/p1/e1/ws/feed")
+
+	w := doWebSocketRequest(router, http.MethodGet, "/websocket/stats", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"active_connections":2}`, w.Body.String())
+
+	w = doWebSocketRequest(router, http.MethodGet, "/websocket/connections?path=/ws/chat", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Total       int                               `json:"total"`
+		Connections []adapter.WebSocketConnectionInfo `json:"connections"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 1, list.Total)
+	assert.Equal(t, chatID, list.Connections[0].ID)
+
+	w = doWebSocketRequest(router, http.MethodGet, "/websocket/connections/"+chatID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var info adapter.WebSocketConnectionInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, "p1", info.ProjectID)
+	assert.Equal(t, "e1", info.EnvironmentID)
+	assert.Equal(t, "/ws/chat", info.Path)
+	assert.Equal(t, "1", info.Query["room"])
+
+	w = doWebSocketRequest(router, http.MethodGet, "/websocket/connections/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebSocketHandler_SendAndBroadcast(t *testing.T) {
+	router, ws, baseURL := setupWebSocketRouter(t)
+	chat1, chat1ID := dialWebSocket(t, ws, baseURL, "/p1/e1/ws/chat")
+	chat2, _ := dialWebSocket(t, ws, baseURL, "/p1/e1/ws/chat")
+	feed, _ := dialWebSocket(t, ws, baseURL, "/p1/e1/ws/feed")
+
+	w := doWebSocketRequest(router, http.MethodPost, "/websocket/connections/"+chat1ID+"/messages", map[string]interface{}{
+		"type": "json", "data": map[string]interface{}{"event": "hello"},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"event":"hello"}`, readTextMessage(t, chat1))
+
+	w = doWebSocketRequest(router, http.MethodPost, "/websocket/connections/missing/messages", map[string]interface{}{"data": "hi"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doWebSocketRequest(router, http.MethodPost, "/websocket/connections/"+chat1ID+"/messages", map[string]interface{}{"type": "binary", "data": "not base64!"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 只广播到路径匹配的连接
+	w = doWebSocketRequest(router, http.MethodPost, "/websocket/broadcast", map[string]interface{}{
+		"project_id": "p1", "path": "/ws/chat", "data": "announcement",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"sent":2}`, w.Body.String())
+	assert.Equal(t, "announcement", readTextMessage(t, chat1))
+	assert.Equal(t, "announcement", readTextMessage(t, chat2))
+
+	feed.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, err := feed.ReadMessage()
+	assert.Error(t, err)
+}
+
+func TestWebSocketHandler_CloseConnection(t *testing.T) {
+	router, ws, baseURL := setupWebSocketRouter(t)
+	conn, connID := dialWebSocket(t, ws, baseURL, "/p1/e1/ws/chat")
+
+	w := doWebSocketRequest(router, http.MethodDelete, "/websocket/connections/"+connID+"?code=1005", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doWebSocketRequest(router, http.MethodDelete, "/websocket/connections/"+connID+"?code=4001&reason=kicked", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4001, closeErr.Code)
+	assert.Equal(t, "kicked", closeErr.Text)
+
+	assert.Eventually(t, func() bool { return ws.GetConnectionCount() == 0 }, 2*time.Second, 10*time.Millisecond)
+
+	w = doWebSocketRequest(router, http.MethodDelete, "/websocket/connections/"+connID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// NoopL2Cache 空L2缓存，未启用 Redis 时使三级缓存只使用L1内存缓存
+type NoopL2Cache struct{}
+
+// NewNoopL2Cache 创建空L2缓存
+func NewNoopL2Cache() *NoopL2Cache {
+	return &NoopL2Cache{}
+}
+
+// Get 获取缓存值，始终未命中
+func (n *NoopL2Cache) Get(ctx context.Context, key string) (interface{}, error) {
+	return nil, nil
+}
+
+// Set 设置缓存值（忽略）
+func (n *NoopL2Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return nil
+}
+
+// Delete 删除缓存（忽略）
+func (n *NoopL2Cache) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+// Exists 检查键是否存在，始终不存在
+func (n *NoopL2Cache) Exists(ctx context.Context, key string) (bool, error) {
+	return false, nil
+}
+
+// Clear 清空缓存（忽略）
+func (n *NoopL2Cache) Clear(ctx context.Context) error {
+	return nil
+}
+
+// Ping 检查连接
+func (n *NoopL2Cache) Ping(ctx context.Context) error {
+	return nil
+}
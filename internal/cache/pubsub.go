@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// RedisPubSub 基于 Redis pub/sub 的消息频道，用于在多个实例间广播缓存失效事件
+type RedisPubSub struct {
+	client  *redis.Client
+	channel string
+	logger  *zap.Logger
+}
+
+// NewRedisPubSub 创建 Redis 消息频道
+func NewRedisPubSub(client *redis.Client, channel string, logger *zap.Logger) *RedisPubSub {
+	return &RedisPubSub{
+		client:  client,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+// Publish 发布消息
+func (p *RedisPubSub) Publish(ctx context.Context, payload []byte) error {
+	if err := p.client.Publish(ctx, p.channel, payload).Err(); err != nil {
+		return fmt.Errorf("redis publish error: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 订阅消息直到 ctx 结束
+//
+// pub/sub 不保证投递：连接断开期间发布的消息会丢失。go-redis 会自动重连并重新订阅，
+// 每次（重新）订阅成功时调用 onSubscribe，调用方应在其中做全量失效以弥补丢失的消息。
+func (p *RedisPubSub) Subscribe(ctx context.Context, onSubscribe func(), onMessage func(payload []byte)) error {
+	pubsub := p.client.Subscribe(ctx, p.channel)
+	defer pubsub.Close()
+
+	// 等待首次订阅确认，连接失败时直接返回
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("redis subscribe error: %w", err)
+	}
+	onSubscribe()
+
+	messages := pubsub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return fmt.Errorf("redis subscription to %s closed", p.channel)
+			}
+			switch m := msg.(type) {
+			case *redis.Subscription:
+				if m.Kind == "subscribe" {
+					p.logger.Info("redis subscription re-established", zap.String("channel", p.channel))
+					onSubscribe()
+				}
+			case *redis.Message:
+				onMessage([]byte(m.Payload))
+			}
+		}
+	}
+}
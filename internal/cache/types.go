@@ -40,12 +40,30 @@ type CacheStats struct {
 	TotalEntries    int64         `json:"total_entries"`
 	L1Entries       int64         `json:"l1_entries"`
 	L2Entries       int64         `json:"l2_entries"`
+
+	Propagation *PropagationStats `json:"propagation,omitempty"` // 跨实例失效传播统计（未启用时为空）
+}
+
+// PropagationStats 跨实例失效传播统计
+//
+// 延迟按发布方与接收方的系统时钟计算，实例间时钟偏差会计入延迟。
+type PropagationStats struct {
+	InstanceID     string    `json:"instance_id"`      // 本实例ID
+	Published      int64     `json:"published"`        // 已发布事件数
+	PublishErrors  int64     `json:"publish_errors"`   // 发布失败次数
+	Received       int64     `json:"received"`         // 收到的其他实例事件数
+	Resyncs        int64     `json:"resyncs"`          // 订阅（重新）建立后全量失效次数
+	LastLagMs      float64   `json:"last_lag_ms"`      // 最近一次传播延迟，单位：毫秒
+	AvgLagMs       float64   `json:"avg_lag_ms"`       // 平均传播延迟，单位：毫秒
+	MaxLagMs       float64   `json:"max_lag_ms"`       // 最大传播延迟，单位：毫秒
+	LastReceivedAt time.Time `json:"last_received_at"` // 最近收到事件的时间
 }
 
 // CacheStrategy 缓存策略配置
 type CacheStrategy struct {
 	// L1缓存配置
 	L1MaxEntries      int           `json:"l1_max_entries"`
+	L1MaxMemoryMB     int           `json:"l1_max_memory_mb"`
 	L1TTL             time.Duration `json:"l1_ttl"`
 	L1CleanupInterval time.Duration `json:"l1_cleanup_interval"`
 
@@ -68,6 +86,7 @@ type CacheStrategy struct {
 func DefaultCacheStrategy() *CacheStrategy {
 	return &CacheStrategy{
 		L1MaxEntries:       10000,
+		L1MaxMemoryMB:      100,
 		L1TTL:              1 * time.Minute,
 		L1CleanupInterval:  5 * time.Minute,
 		L2TTL:              10 * time.Minute,
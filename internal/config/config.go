@@ -22,6 +22,9 @@ type Config struct {
 type ServerConfig struct {
 	Admin AdminServerConfig `mapstructure:"admin"`
 	Mock  MockServerConfig  `mapstructure:"mock"`
+	GRPC  GRPCServerConfig  `mapstructure:"grpc"`
+	TLS   TLSServerConfig   `mapstructure:"tls"`
+	Proxy ProxyServerConfig `mapstructure:"proxy"`
 }
 
 // AdminServerConfig 管理 API 服务配置
@@ -31,9 +34,54 @@ type AdminServerConfig struct {
 }
 
 // MockServerConfig Mock 服务配置
+//
+// 除 /:projectID/:environmentID/*path 前缀路由外，请求依次按管理 API 配置的主机名绑定、
+// 路由请求头和默认环境确定项目与环境，并去掉环境 BaseURL 的路径前缀。
 type MockServerConfig struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host               string `mapstructure:"host"`
+	Port               int    `mapstructure:"port"`
+	ProjectHeader      string `mapstructure:"project_header"`      // 指定项目的请求头，默认 X-Mock-Project，缺省时取环境所属项目
+	EnvironmentHeader  string `mapstructure:"environment_header"`  // 指定环境的请求头，默认 X-Mock-Environment
+	DefaultProject     string `mapstructure:"default_project"`     // 未命中主机名绑定和请求头时使用的项目
+	DefaultEnvironment string `mapstructure:"default_environment"` // 未命中主机名绑定和请求头时使用的环境，为空时只使用前缀路由
+}
+
+// GRPCServerConfig gRPC Mock 服务配置
+type GRPCServerConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	Host               string `mapstructure:"host"`
+	Port               int    `mapstructure:"port"`
+	DefaultProject     string `mapstructure:"default_project"`     // 请求未携带 x-mock-project 元数据时使用的项目
+	DefaultEnvironment string `mapstructure:"default_environment"` // 请求未携带 x-mock-environment 元数据时使用的环境
+}
+
+// TLSServerConfig HTTPS Mock 服务配置
+//
+// 证书由本地生成的根 CA 为 hostnames 中的主机名签发（支持 *.example.test 通配符），
+// 环境上传的证书优先；根 CA 可从管理 API 下载后加入客户端信任列表。
+type TLSServerConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	Host      string   `mapstructure:"host"`
+	Port      int      `mapstructure:"port"`
+	CADir     string   `mapstructure:"ca_dir"`    // 根 CA 证书（ca.crt）和私钥（ca.key）目录，不存在时生成；为空时每次启动重新生成
+	Hostnames []string `mapstructure:"hostnames"` // 自动签发证书的主机名，localhost 和 IP 地址总是签发
+}
+
+// ProxyServerConfig 正向代理配置
+//
+// 启用后 Mock 服务端口同时作为 HTTP(S) 正向代理（HTTP_PROXY / HTTPS_PROXY），
+// 发往 hosts 中主机的请求由对应项目和环境的规则应答，未匹配的请求转发到原始主机；
+// HTTPS 请求通过 CONNECT 由本地根 CA 签发证书解密。其他主机的请求原样转发。
+type ProxyServerConfig struct {
+	Enabled bool              `mapstructure:"enabled"`
+	Hosts   []ProxyHostConfig `mapstructure:"hosts"`
+}
+
+// ProxyHostConfig 正向代理拦截的主机
+type ProxyHostConfig struct {
+	Host          string `mapstructure:"host"` // 主机名，不含端口，支持 *.example.com 通配符
+	ProjectID     string `mapstructure:"project_id"`
+	EnvironmentID string `mapstructure:"environment_id"`
 }
 
 // DatabaseConfig 数据库配置
@@ -114,8 +162,17 @@ type PerformanceConfig struct {
 
 // CacheConfig 缓存配置
 type CacheConfig struct {
-	RuleTTL   int `mapstructure:"rule_ttl"`
-	ConfigTTL int `mapstructure:"config_ttl"`
+	Enabled       bool   `mapstructure:"enabled"`          // 启用规则三级缓存（L1 内存，redis.enabled 时 L2 使用 Redis）
+	RuleTTL       int    `mapstructure:"rule_ttl"`         // 规则缓存时间，单位：秒
+	ConfigTTL     int    `mapstructure:"config_ttl"`       // 配置缓存时间，单位：秒
+	IndexTTL      int    `mapstructure:"index_ttl"`        // 匹配引擎规则索引最长有效期，单位：秒
+	L1MaxEntries  int    `mapstructure:"l1_max_entries"`   // L1 最大条目数
+	L1MaxMemoryMB int    `mapstructure:"l1_max_memory_mb"` // L1 内存上限，单位：MB
+	L1TTL         int    `mapstructure:"l1_ttl"`           // L1 缓存时间，单位：秒
+	KeyPrefix     string `mapstructure:"key_prefix"`       // Redis 键前缀
+
+	Propagation        bool   `mapstructure:"propagation"`         // 通过 Redis pub/sub 向其他实例广播规则/环境失效（需 redis.enabled）
+	PropagationChannel string `mapstructure:"propagation_channel"` // 失效事件频道
 }
 
 // RateLimitConfig 限流配置
@@ -180,3 +237,13 @@ func (c *Config) GetAdminAddress() string {
 func (c *Config) GetMockAddress() string {
 	return fmt.Sprintf("%s:%d", c.Server.Mock.Host, c.Server.Mock.Port)
 }
+
+// GetGRPCAddress 获取 gRPC Mock 服务地址
+func (c *Config) GetGRPCAddress() string {
+	return fmt.Sprintf("%s:%d", c.Server.GRPC.Host, c.Server.GRPC.Port)
+}
+
+// GetTLSAddress 获取 HTTPS Mock 服务地址
+func (c *Config) GetTLSAddress() string {
+	return fmt.Sprintf("%s:%d", c.Server.TLS.Host, c.Server.TLS.Port)
+}
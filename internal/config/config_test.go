@@ -385,8 +385,14 @@ logging:
 performance:
   log_retention_days: 90
   cache:
+    enabled: true
     rule_ttl: 600
     config_ttl: 1200
+    index_ttl: 30
+    l1_max_entries: 5000
+    l1_max_memory_mb: 64
+    l1_ttl: 120
+    key_prefix: "mock:"
   rate_limit:
     enabled: true
     ip_limit: 200
@@ -434,6 +440,12 @@ features:
 	assert.Equal(t, 90, cfg.Performance.LogRetentionDays)
 	assert.Equal(t, 600, cfg.Performance.Cache.RuleTTL)
 	assert.Equal(t, 1200, cfg.Performance.Cache.ConfigTTL)
+	assert.True(t, cfg.Performance.Cache.Enabled)
+	assert.Equal(t, 30, cfg.Performance.Cache.IndexTTL)
+	assert.Equal(t, 5000, cfg.Performance.Cache.L1MaxEntries)
+	assert.Equal(t, 64, cfg.Performance.Cache.L1MaxMemoryMB)
+	assert.Equal(t, 120, cfg.Performance.Cache.L1TTL)
+	assert.Equal(t, "mock:", cfg.Performance.Cache.KeyPrefix)
 	assert.True(t, cfg.Performance.RateLimit.Enabled)
 	assert.Equal(t, 200, cfg.Performance.RateLimit.IPLimit)
 	assert.Equal(t, 5000, cfg.Performance.RateLimit.GlobalLimit)
@@ -0,0 +1,571 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 路径条件比较操作符（JSONPath、XPath、表单字段共用）
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpContains = "contains"
+	OpRegex    = "regex"
+	OpExists   = "exists"
+)
+
+// BodyMatchCondition 请求体匹配条件（对应 HTTPMatchCondition.Body）
+//
+//	"body": {
+//	  "json": {"user": {"type": "vip"}},
+//	  "json_path": [{"path": "$.items[0].price", "op": "gt", "value": 100}],
+//	  "json_schema": {"type": "object", "required": ["user"]},
+//	  "form": {"username": "alice", "age": {"op": "gt", "value": 18}},
+//	  "files": [{"field": "avatar", "filename": "*.png", "max_size": 102400}],
+//	  "xpath": [{"path": "//soap:Body/GetUser/id", "value": "42"}]
+//	}
+//
+// 如果 body 中不包含任何保留键，则整个 body 视为 json 子集匹配条件。
+//
+// json_path、json_schema、xpath 均为内置实现，只支持常用子集，
+// 超出子集的表达式或关键字会在规则编译时报错，规则不会被匹配：
+//   - json_path：$、.key、['key']、[n]、[*]、.*、..key；不支持过滤表达式、切片、联合
+//   - json_schema：见 JSONSchema，不支持 patternProperties、if/then/else、远程 $ref 等
+//   - xpath：/、//、*、@attr、text()、位置/属性/子元素/text() 等值谓词；
+//     忽略命名空间前缀，不支持轴、函数与 != 等比较
+type BodyMatchCondition struct {
+	JSON       interface{}            `json:"json,omitempty"`        // JSON 子集相等匹配
+	JSONPath   []PathCondition        `json:"json_path,omitempty"`   // JSONPath 表达式匹配
+	JSONSchema map[string]interface{} `json:"json_schema,omitempty"` // JSON Schema 校验
+	Form       map[string]interface{} `json:"form,omitempty"`        // 表单字段匹配（urlencoded/multipart）
+	Files      []FileCondition        `json:"files,omitempty"`       // multipart 文件部分匹配
+	XPath      []PathCondition        `json:"xpath,omitempty"`       // XPath 表达式匹配
+}
+
+// PathCondition 路径匹配条件（JSONPath 或 XPath）
+type PathCondition struct {
+	Path  string      `json:"path"`
+	Op    string      `json:"op,omitempty"` // eq, ne, gt, gte, lt, lte, contains, regex, exists
+	Value interface{} `json:"value,omitempty"`
+}
+
+// FileCondition multipart 文件匹配条件
+type FileCondition struct {
+	Field       string `json:"field"`                  // 表单字段名
+	Filename    string `json:"filename,omitempty"`     // 文件名，支持通配符（如 *.png）
+	ContentType string `json:"content_type,omitempty"` // 文件部分的 Content-Type
+	MinSize     int64  `json:"min_size,omitempty"`     // 最小字节数
+	MaxSize     int64  `json:"max_size,omitempty"`     // 最大字节数
+}
+
+// bodyMatcherKeys 请求体匹配条件保留键
+var bodyMatcherKeys = []string{"json", "json_path", "json_schema", "form", "files", "xpath"}
+
+// parseBodyCondition 解析请求体匹配条件
+func parseBodyCondition(body map[string]interface{}) (*BodyMatchCondition, error) {
+	structured := false
+	for _, key := range bodyMatcherKeys {
+		if _, exists := body[key]; exists {
+			structured = true
+			break
+		}
+	}
+
+	// 未使用保留键时，整个 body 作为 JSON 子集匹配
+	if !structured {
+		return &BodyMatchCondition{JSON: body}, nil
+	}
+
+	conditionBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var condition BodyMatchCondition
+	if err := json.Unmarshal(conditionBytes, &condition); err != nil {
+		return nil, fmt.Errorf("invalid body match condition: %w", err)
+	}
+	return &condition, nil
+}
+
+// compiledBody 预编译的请求体匹配条件：JSONPath、JSON Schema、XPath 只解析一次
+type compiledBody struct {
+	condition *BodyMatchCondition
+	jsonPaths []*JSONPath
+	schema    *JSONSchema
+	xpaths    []*XPath
+}
+
+// compileBodyCondition 解析并编译请求体匹配条件
+func compileBodyCondition(body map[string]interface{}) (*compiledBody, error) {
+	condition, err := parseBodyCondition(body)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &compiledBody{condition: condition}
+	for _, pathCondition := range condition.JSONPath {
+		jsonPath, err := ParseJSONPath(pathCondition.Path)
+		if err != nil {
+			return nil, err
+		}
+		compiled.jsonPaths = append(compiled.jsonPaths, jsonPath)
+	}
+	if condition.JSONSchema != nil {
+		schema, err := CompileJSONSchema(condition.JSONSchema)
+		if err != nil {
+			return nil, err
+		}
+		compiled.schema = schema
+	}
+	for _, pathCondition := range condition.XPath {
+		xpath, err := ParseXPath(pathCondition.Path)
+		if err != nil {
+			return nil, err
+		}
+		compiled.xpaths = append(compiled.xpaths, xpath)
+	}
+	return compiled, nil
+}
+
+// matchBody 匹配请求体，compiled 为空时按需编译 body 条件
+func (e *MatchEngine) matchBody(request *adapter.Request, body map[string]interface{}, compiled *compiledBody) (bool, error) {
+	if compiled == nil {
+		var err error
+		if compiled, err = compileBodyCondition(body); err != nil {
+			return false, err
+		}
+	}
+	condition := compiled.condition
+
+	if condition.JSON != nil || len(condition.JSONPath) > 0 || condition.JSONSchema != nil {
+		matched, err := e.matchJSONBody(request, compiled)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	if len(condition.Form) > 0 || len(condition.Files) > 0 {
+		matched, err := e.matchFormBody(request, condition)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	if len(condition.XPath) > 0 {
+		matched, err := e.matchXMLBody(request, compiled)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// matchJSONBody 匹配 JSON 请求体
+func (e *MatchEngine) matchJSONBody(request *adapter.Request, compiled *compiledBody) (bool, error) {
+	condition := compiled.condition
+
+	// 解析请求体 JSON
+	var data interface{}
+	if len(request.Body) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(request.Body, &data); err != nil {
+		// 非 JSON 请求体无法满足 JSON 条件
+		return false, nil
+	}
+
+	// JSON 子集相等
+	if condition.JSON != nil {
+		if !jsonSubsetMatch(condition.JSON, data) {
+			return false, nil
+		}
+	}
+
+	// JSONPath 表达式
+	for i, pathCondition := range condition.JSONPath {
+		matched, err := e.evaluatePathCondition(pathCondition, compiled.jsonPaths[i].Find(data))
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	// JSON Schema
+	if compiled.schema != nil {
+		if violations := compiled.schema.Validate(data); len(violations) > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchFormBody 匹配 urlencoded/multipart 表单请求体
+func (e *MatchEngine) matchFormBody(request *adapter.Request, condition *BodyMatchCondition) (bool, error) {
+	form, err := adapter.GetForm(request)
+	if err != nil {
+		logger.Warn("failed to parse form body", zap.Error(err))
+		return false, nil
+	}
+	if form == nil {
+		return false, nil
+	}
+
+	// 表单字段：字符串/数字表示相等，数组表示全部值相等，对象表示操作符条件
+	for field, expected := range condition.Form {
+		values := make([]interface{}, 0, len(form.Fields[field]))
+		for _, v := range form.Fields[field] {
+			values = append(values, v)
+		}
+
+		var matched bool
+		switch exp := expected.(type) {
+		case map[string]interface{}:
+			pathCondition := PathCondition{Path: field, Value: exp["value"]}
+			pathCondition.Op, _ = exp["op"].(string)
+			matched, err = e.evaluatePathCondition(pathCondition, values)
+			if err != nil {
+				return false, err
+			}
+		case []interface{}:
+			matched = len(exp) == len(values)
+			for i := 0; matched && i < len(exp); i++ {
+				matched = stringifyValue(exp[i]) == values[i]
+			}
+		default:
+			matched = len(values) > 0 && values[0] == stringifyValue(exp)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	// 文件部分
+	for _, fileCondition := range condition.Files {
+		if !matchFormFile(fileCondition, form.Files) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchFormFile 判断是否存在满足条件的文件部分
+func matchFormFile(condition FileCondition, files []adapter.FormFile) bool {
+	for _, file := range files {
+		if condition.Field != "" && file.FieldName != condition.Field {
+			continue
+		}
+		if condition.Filename != "" {
+			if matched, err := path.Match(condition.Filename, file.Filename); err != nil || !matched {
+				continue
+			}
+		}
+		if condition.ContentType != "" && !strings.EqualFold(condition.ContentType, file.ContentType) {
+			continue
+		}
+		if condition.MinSize > 0 && file.Size < condition.MinSize {
+			continue
+		}
+		if condition.MaxSize > 0 && file.Size > condition.MaxSize {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// matchXMLBody 匹配 XML 请求体
+func (e *MatchEngine) matchXMLBody(request *adapter.Request, compiled *compiledBody) (bool, error) {
+	if len(request.Body) == 0 {
+		return false, nil
+	}
+
+	document, err := parseXMLDocument(request.Body)
+	if err != nil {
+		// 非 XML 请求体无法满足 XPath 条件
+		return false, nil
+	}
+
+	for i, pathCondition := range compiled.condition.XPath {
+		matched, err := e.evaluatePathCondition(pathCondition, compiled.xpaths[i].Find(document))
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// evaluatePathCondition 对路径选中的值应用操作符，正则使用引擎缓存
+func (e *MatchEngine) evaluatePathCondition(condition PathCondition, values []interface{}) (bool, error) {
+	return evaluatePathCondition(condition, values, e.compileRegex)
+}
+
+// EvaluatePathCondition 对路径选中的值应用操作符
+func EvaluatePathCondition(condition PathCondition, values []interface{}) (bool, error) {
+	return evaluatePathCondition(condition, values, regexp.Compile)
+}
+
+// evaluatePathCondition 对路径选中的值应用操作符
+func evaluatePathCondition(condition PathCondition, values []interface{}, compile func(string) (*regexp.Regexp, error)) (bool, error) {
+	op := strings.ToLower(condition.Op)
+	if op == "" {
+		if condition.Value == nil {
+			op = OpExists
+		} else {
+			op = OpEq
+		}
+	}
+
+	switch op {
+	case OpExists:
+		// value 为 false 时表示要求路径不存在
+		expected := true
+		if b, ok := condition.Value.(bool); ok {
+			expected = b
+		}
+		return (len(values) > 0) == expected, nil
+	case OpNe:
+		for _, v := range values {
+			if compareValue(OpEq, v, condition.Value) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case OpRegex:
+		pattern, ok := condition.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("regex operator requires a string pattern")
+		}
+		re, err := compile(pattern)
+		if err != nil {
+			logger.Warn("failed to compile regex pattern for body path",
+				zap.String("path", condition.Path),
+				zap.String("pattern", pattern),
+				zap.Error(err))
+			return false, nil
+		}
+		for _, v := range values {
+			if re.MatchString(stringifyValue(v)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OpEq, OpGt, OpGte, OpLt, OpLte, OpContains:
+		for _, v := range values {
+			if compareValue(op, v, condition.Value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unsupported path operator: %s", condition.Op)
+	}
+}
+
+// compareValue 按操作符比较实际值与期望值
+func compareValue(op string, actual, expected interface{}) bool {
+	switch op {
+	case OpEq:
+		// XML 与表单的值均为字符串，与数字/布尔期望值按字符串形式比较
+		if str, ok := actual.(string); ok {
+			switch expected.(type) {
+			case nil, string, map[string]interface{}, []interface{}:
+			default:
+				return str == stringifyValue(expected)
+			}
+		}
+		return jsonEqual(actual, expected)
+	case OpGt, OpGte, OpLt, OpLte:
+		a, ok1 := toNumber(actual)
+		b, ok2 := toNumber(expected)
+		if !ok1 || !ok2 {
+			return false
+		}
+		switch op {
+		case OpGt:
+			return a > b
+		case OpGte:
+			return a >= b
+		case OpLt:
+			return a < b
+		default:
+			return a <= b
+		}
+	case OpContains:
+		switch v := actual.(type) {
+		case string:
+			return strings.Contains(v, stringifyValue(expected))
+		case []interface{}:
+			for _, item := range v {
+				if jsonEqual(item, expected) {
+					return true
+				}
+			}
+			return false
+		case map[string]interface{}:
+			key, ok := expected.(string)
+			if !ok {
+				return false
+			}
+			_, exists := v[key]
+			return exists
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+}
+
+// jsonSubsetMatch 判断 actual 是否包含 expected（对象按子集比较，数组按元素逐个比较）
+func jsonSubsetMatch(expected, actual interface{}) bool {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, expValue := range exp {
+			actValue, exists := act[key]
+			if !exists {
+				return false
+			}
+			if !jsonSubsetMatch(expValue, actValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok || len(act) != len(exp) {
+			return false
+		}
+		for i := range exp {
+			if !jsonSubsetMatch(exp[i], act[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return jsonEqual(expected, actual)
+	}
+}
+
+// jsonEqual 深度比较两个 JSON 值（数字按数值比较）
+func jsonEqual(a, b interface{}) bool {
+	if na, ok := toFloat64(a); ok {
+		nb, ok := toFloat64(b)
+		return ok && na == nb
+	}
+
+	switch va := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		vb, ok := b.(bool)
+		return ok && va == vb
+	case string:
+		vb, ok := b.(string)
+		return ok && va == vb
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for key, value := range va {
+			other, exists := vb[key]
+			if !exists || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !jsonEqual(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return fmt.Sprint(a) == fmt.Sprint(b)
+	}
+}
+
+// toFloat64 将数值类型转换为 float64（不解析字符串）
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// toNumber 将数值或数字字符串转换为 float64
+func toNumber(v interface{}) (float64, bool) {
+	if f, ok := toFloat64(v); ok {
+		return f, true
+	}
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// stringifyValue 将 JSON 值转换为字符串
+func stringifyValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	default:
+		if f, ok := toFloat64(val); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return fmt.Sprint(val)
+	}
+}
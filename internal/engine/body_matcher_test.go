@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newBodyRequest(body string) *adapter.Request {
+	return &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/orders",
+		Body:     []byte(body),
+		Metadata: map[string]interface{}{"method": "POST"},
+	}
+}
+
+func newBodyRule(body map[string]interface{}) *models.Rule {
+	return &models.Rule{
+		Protocol:  models.ProtocolHTTP,
+		MatchType: models.MatchTypeSimple,
+		MatchCondition: map[string]interface{}{
+			"method": "POST",
+			"path":   "/api/orders",
+			"body":   body,
+		},
+	}
+}
+
+// TestMatchBody_JSONSubset 测试 JSON 子集相等匹配
+func TestMatchBody_JSONSubset(t *testing.T) {
+	engine := NewMatchEngine(nil)
+	payload := `{"user": {"id": 7, "type": "vip"}, "items": [1, 2], "note": "x"}`
+
+	tests := []struct {
+		name     string
+		body     map[string]interface{}
+		expected bool
+	}{
+		{"显式 json 子集", map[string]interface{}{"json": map[string]interface{}{"user": map[string]interface{}{"type": "vip"}}}, true},
+		{"隐式子集", map[string]interface{}{"user": map[string]interface{}{"id": 7}}, true},
+		{"数组完全相等", map[string]interface{}{"items": []interface{}{1, 2}}, true},
+		{"数组长度不同", map[string]interface{}{"items": []interface{}{1}}, false},
+		{"字段值不同", map[string]interface{}{"user": map[string]interface{}{"type": "normal"}}, false},
+		{"字段缺失", map[string]interface{}{"missing": true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := engine.simpleMatch(newBodyRequest(payload), newBodyRule(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+// TestMatchBody_JSONPathOperators 测试 JSONPath 操作符
+func TestMatchBody_JSONPathOperators(t *testing.T) {
+	engine := NewMatchEngine(nil)
+	payload := `{"amount": 150, "currency": "CNY", "tags": ["new", "promo"], "email": "a@example.com", "coupon": null}`
+
+	tests := []struct {
+		name     string
+		cond     map[string]interface{}
+		expected bool
+	}{
+		{"eq", map[string]interface{}{"path": "$.currency", "op": "eq", "value": "CNY"}, true},
+		{"默认 eq", map[string]interface{}{"path": "$.amount", "value": 150}, true},
+		{"ne", map[string]interface{}{"path": "$.currency", "op": "ne", "value": "USD"}, true},
+		{"ne 不满足", map[string]interface{}{"path": "$.currency", "op": "ne", "value": "CNY"}, false},
+		{"gt", map[string]interface{}{"path": "$.amount", "op": "gt", "value": 100}, true},
+		{"lt 不满足", map[string]interface{}{"path": "$.amount", "op": "lt", "value": 100}, false},
+		{"数组 contains", map[string]interface{}{"path": "$.tags", "op": "contains", "value": "promo"}, true},
+		{"字符串 contains", map[string]interface{}{"path": "$.email", "op": "contains", "value": "@example"}, true},
+		{"regex", map[string]interface{}{"path": "$.email", "op": "regex", "value": "^[a-z]+@"}, true},
+		{"exists", map[string]interface{}{"path": "$.coupon", "op": "exists"}, true},
+		{"默认 exists", map[string]interface{}{"path": "$.amount"}, true},
+		{"exists false", map[string]interface{}{"path": "$.discount", "op": "exists", "value": false}, true},
+		{"路径不存在", map[string]interface{}{"path": "$.discount", "op": "gt", "value": 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := newBodyRule(map[string]interface{}{"json_path": []interface{}{tt.cond}})
+			matched, err := engine.simpleMatch(newBodyRequest(payload), rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+
+	t.Run("不支持的操作符", func(t *testing.T) {
+		rule := newBodyRule(map[string]interface{}{"json_path": []interface{}{
+			map[string]interface{}{"path": "$.amount", "op": "between", "value": 1},
+		}})
+		_, err := engine.simpleMatch(newBodyRequest(payload), rule)
+		assert.Error(t, err)
+	})
+}
+
+// TestMatchBody_JSONSchema 测试 JSON Schema 校验匹配
+func TestMatchBody_JSONSchema(t *testing.T) {
+	engine := NewMatchEngine(nil)
+	rule := newBodyRule(map[string]interface{}{
+		"json_schema": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"name"},
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{"type": "string", "minLength": 2},
+			},
+		},
+	})
+
+	matched, err := engine.simpleMatch(newBodyRequest(`{"name": "alice"}`), rule)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = engine.simpleMatch(newBodyRequest(`{"name": "a"}`), rule)
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	matched, err = engine.simpleMatch(newBodyRequest(`not json`), rule)
+	require.NoError(t, err)
+	assert.False(t, matched)
+}
+
+// TestMatchBody_UnsupportedSchemaKeyword 测试不支持的 JSON Schema 关键字报错而不是静默通过
+func TestMatchBody_UnsupportedSchemaKeyword(t *testing.T) {
+	engine := NewMatchEngine(nil)
+	rule := newBodyRule(map[string]interface{}{
+		"json_schema": map[string]interface{}{
+			"type":              "object",
+			"patternProperties": map[string]interface{}{"^x-": map[string]interface{}{"type": "string"}},
+		},
+	})
+
+	matched, err := engine.simpleMatch(newBodyRequest(`{"x-a": 1}`), rule)
+	assert.Error(t, err)
+	assert.False(t, matched)
+}
+
+// TestMatch_SamePathDifferentBody 测试相同路径根据请求体返回不同规则
+func TestMatch_SamePathDifferentBody(t *testing.T) {
+	mockRepo := new(MockRuleRepository)
+
+	vipRule := newBodyRule(map[string]interface{}{
+		"json_path": []interface{}{map[string]interface{}{"path": "$.user.level", "op": "gte", "value": 3}},
+	})
+	vipRule.ID = "rule-vip"
+	vipRule.Priority = 100
+
+	regexRule := &models.Rule{
+		ID:        "rule-refund",
+		Protocol:  models.ProtocolHTTP,
+		MatchType: models.MatchTypeRegex,
+		Priority:  50,
+		MatchCondition: map[string]interface{}{
+			"path_regex": "^/api/orders$",
+			"body":       map[string]interface{}{"json": map[string]interface{}{"type": "refund"}},
+		},
+	}
+
+	mockRepo.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").
+		Return([]*models.Rule{vipRule, regexRule}, nil)
+
+	engine := NewMatchEngine(mockRepo)
+
+	rule, err := engine.Match(context.Background(), newBodyRequest(`{"user": {"level": 5}}`), "p1", "e1")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-vip", rule.ID)
+
+	rule, err = engine.Match(context.Background(), newBodyRequest(`{"user": {"level": 1}, "type": "refund"}`), "p1", "e1")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-refund", rule.ID)
+
+	rule, err = engine.Match(context.Background(), newBodyRequest(`{"user": {"level": 1}}`), "p1", "e1")
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+}
+
+// TestMatchBody_Form 测试 urlencoded 表单匹配
+func TestMatchBody_Form(t *testing.T) {
+	engine := NewMatchEngine(nil)
+	request := newBodyRequest("username=alice&age=30&tags=a&tags=b")
+	request.Headers = map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+
+	tests := []struct {
+		name     string
+		form     map[string]interface{}
+		expected bool
+	}{
+		{"字段相等", map[string]interface{}{"username": "alice"}, true},
+		{"数字值按字符串比较", map[string]interface{}{"age": 30}, true},
+		{"多值字段", map[string]interface{}{"tags": []interface{}{"a", "b"}}, true},
+		{"操作符", map[string]interface{}{"age": map[string]interface{}{"op": "gt", "value": 18}}, true},
+		{"字段不同", map[string]interface{}{"username": "bob"}, false},
+		{"字段缺失", map[string]interface{}{"email": map[string]interface{}{"op": "exists"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := engine.simpleMatch(request, newBodyRule(map[string]interface{}{"form": tt.form}))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+
+	t.Run("非表单请求", func(t *testing.T) {
+		jsonRequest := newBodyRequest(`{"username":"alice"}`)
+		jsonRequest.Headers = map[string]string{"Content-Type": "application/json"}
+		matched, err := engine.simpleMatch(jsonRequest, newBodyRule(map[string]interface{}{"form": map[string]interface{}{"username": "alice"}}))
+		require.NoError(t, err)
+		assert.False(t, matched)
+	})
+}
+
+// TestMatchBody_MultipartFiles 测试 multipart 文件匹配
+func TestMatchBody_MultipartFiles(t *testing.T) {
+	engine := NewMatchEngine(nil)
+	body := "--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"title\"\r\n\r\nreport\r\n" +
+		"--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"file\"; filename=\"q3.pdf\"\r\n" +
+		"Content-Type: application/pdf\r\n\r\n0123456789\r\n" +
+		"--XYZ--\r\n"
+	request := newBodyRequest(body)
+	request.Headers = map[string]string{"content-type": "multipart/form-data; boundary=XYZ"}
+
+	tests := []struct {
+		name     string
+		cond     map[string]interface{}
+		expected bool
+	}{
+		{"字段与文件", map[string]interface{}{
+			"form":  map[string]interface{}{"title": "report"},
+			"files": []interface{}{map[string]interface{}{"field": "file", "filename": "*.pdf", "content_type": "application/pdf"}},
+		}, true},
+		{"文件大小范围", map[string]interface{}{
+			"files": []interface{}{map[string]interface{}{"field": "file", "min_size": 5, "max_size": 10}},
+		}, true},
+		{"文件过大", map[string]interface{}{
+			"files": []interface{}{map[string]interface{}{"field": "file", "max_size": 5}},
+		}, false},
+		{"文件名不匹配", map[string]interface{}{
+			"files": []interface{}{map[string]interface{}{"filename": "*.png"}},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := engine.simpleMatch(request, newBodyRule(tt.cond))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+// TestMatchBody_XPath 测试 XML XPath 匹配
+func TestMatchBody_XPath(t *testing.T) {
+	engine := NewMatchEngine(nil)
+	request := newBodyRequest(soapEnvelope)
+
+	tests := []struct {
+		name     string
+		xpath    []interface{}
+		expected bool
+	}{
+		{"值相等", []interface{}{map[string]interface{}{"path": "//GetUser/id", "value": "42"}}, true},
+		{"数字比较", []interface{}{map[string]interface{}{"path": "//GetUser/id", "op": "gte", "value": 40}}, true},
+		{"存在", []interface{}{map[string]interface{}{"path": "//soap:Header/auth/@token"}}, true},
+		{"多条件", []interface{}{
+			map[string]interface{}{"path": "//GetUser/id", "value": 42},
+			map[string]interface{}{"path": "//role/@type", "op": "contains", "value": "adm"},
+		}, true},
+		{"值不同", []interface{}{map[string]interface{}{"path": "//GetUser/id", "value": "7"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := engine.simpleMatch(request, newBodyRule(map[string]interface{}{"xpath": tt.xpath}))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+
+	t.Run("非XML请求体", func(t *testing.T) {
+		matched, err := engine.simpleMatch(newBodyRequest(`{"id":42}`), newBodyRule(map[string]interface{}{
+			"xpath": []interface{}{map[string]interface{}{"path": "//id"}},
+		}))
+		require.NoError(t, err)
+		assert.False(t, matched)
+	})
+}
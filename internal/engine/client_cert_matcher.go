@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// matchClientCert 匹配客户端证书条件
+func matchClientCert(request *adapter.Request, condition *models.ClientCertCondition) bool {
+	cert := adapter.GetClientCertificate(request)
+	if condition.Present != nil && *condition.Present != (cert != nil) {
+		return false
+	}
+	if cert == nil {
+		// 只配置 present: false 时匹配未出示证书的请求，其他条件都要求出示证书
+		return condition.SubjectCN == "" && len(condition.SANs) == 0 && condition.Issuer == "" && condition.Fingerprint == ""
+	}
+
+	if condition.SubjectCN != "" && condition.SubjectCN != cert.SubjectCN {
+		return false
+	}
+	if condition.Issuer != "" && condition.Issuer != cert.IssuerCN && condition.Issuer != cert.Issuer {
+		return false
+	}
+	if condition.Fingerprint != "" && normalizeFingerprint(condition.Fingerprint) != cert.Fingerprint {
+		return false
+	}
+	for _, san := range condition.SANs {
+		found := false
+		for _, certSAN := range cert.SANs {
+			if strings.EqualFold(san, certSAN) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeFingerprint 指纹转为不含冒号和空格的小写十六进制
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.NewReplacer(":", "", " ", "").Replace(fingerprint)
+	return strings.ToLower(fingerprint)
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clientCertRequest(cert *adapter.ClientCertificate) *adapter.Request {
+	request := &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/orders",
+		Metadata: map[string]interface{}{"method": "GET"},
+	}
+	if cert != nil {
+		request.Metadata["client_cert"] = cert
+	}
+	return request
+}
+
+func TestMatchClientCert(t *testing.T) {
+	present := true
+	absent := false
+	cert := &adapter.ClientCertificate{
+		SubjectCN:   "partner-a",
+		Issuer:      "CN=Partner CA,O=Acme",
+		IssuerCN:    "Partner CA",
+		SANs:        []string{"partner-a.example.test", "10.0.0.1", "ops@example.test"},
+		Fingerprint: "ab01cd02",
+	}
+
+	tests := []struct {
+		name      string
+		condition models.ClientCertCondition
+		cert      *adapter.ClientCertificate
+		expected  bool
+	}{
+		{"出示证书", models.ClientCertCondition{Present: &present}, cert, true},
+		{"要求出示但未出示", models.ClientCertCondition{Present: &present}, nil, false},
+		{"要求未出示", models.ClientCertCondition{Present: &absent}, nil, true},
+		{"要求未出示但已出示", models.ClientCertCondition{Present: &absent}, cert, false},
+		{"主题 CN", models.ClientCertCondition{SubjectCN: "partner-a"}, cert, true},
+		{"主题 CN 不同", models.ClientCertCondition{SubjectCN: "partner-b"}, cert, false},
+		{"主题 CN 未出示证书", models.ClientCertCondition{SubjectCN: "partner-a"}, nil, false},
+		{"颁发者 CN", models.ClientCertCondition{Issuer: "Partner CA"}, cert, true},
+		{"颁发者 DN", models.ClientCertCondition{Issuer: "CN=Partner CA,O=Acme"}, cert, true},
+		{"颁发者不同", models.ClientCertCondition{Issuer: "Other CA"}, cert, false},
+		{"SAN 全部包含", models.ClientCertCondition{SANs: []string{"Partner-A.example.test", "10.0.0.1"}}, cert, true},
+		{"SAN 缺少", models.ClientCertCondition{SANs: []string{"partner-a.example.test", "10.0.0.2"}}, cert, false},
+		{"指纹带冒号大写", models.ClientCertCondition{Fingerprint: "AB:01:CD:02"}, cert, true},
+		{"指纹不同", models.ClientCertCondition{Fingerprint: "ab01cd03"}, cert, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := tt.condition
+			assert.Equal(t, tt.expected, matchClientCert(clientCertRequest(tt.cert), &condition))
+		})
+	}
+}
+
+func TestMatch_ClientCertRules(t *testing.T) {
+	rule := func(id string, priority int, matchType models.MatchType, condition map[string]interface{}) *models.Rule {
+		return &models.Rule{
+			ID:             id,
+			ProjectID:      "p1",
+			EnvironmentID:  "e1",
+			Protocol:       models.ProtocolHTTP,
+			MatchType:      matchType,
+			Priority:       priority,
+			Enabled:        true,
+			MatchCondition: condition,
+		}
+	}
+	rules := []*models.Rule{
+		rule("partner-a", 30, models.MatchTypeSimple, map[string]interface{}{
+			"path":        "/api/orders",
+			"client_cert": map[string]interface{}{"subject_cn": "partner-a"},
+		}),
+		rule("partner-regex", 20, models.MatchTypeRegex, map[string]interface{}{
+			"path_regex":  "^/api/.*",
+			"client_cert": map[string]interface{}{"issuer": "Partner CA"},
+		}),
+		rule("anonymous", 10, models.MatchTypeSimple, map[string]interface{}{
+			"path":        "/api/orders",
+			"client_cert": map[string]interface{}{"present": false},
+		}),
+	}
+	repo := new(MockRuleRepository)
+	repo.On("FindEnabledByEnvironment", context.Background(), "p1", "e1").Return(rules, nil)
+	e := NewMatchEngine(repo)
+
+	tests := []struct {
+		name   string
+		cert   *adapter.ClientCertificate
+		ruleID string
+	}{
+		{"主题 CN", &adapter.ClientCertificate{SubjectCN: "partner-a", IssuerCN: "Partner CA"}, "partner-a"},
+		{"正则规则按颁发者", &adapter.ClientCertificate{SubjectCN: "partner-b", IssuerCN: "Partner CA"}, "partner-regex"},
+		{"未出示证书", nil, "anonymous"},
+		{"其他证书", &adapter.ClientCertificate{SubjectCN: "partner-b", IssuerCN: "Other CA"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := e.Match(context.Background(), clientCertRequest(tt.cert), "p1", "e1")
+			require.NoError(t, err)
+			if tt.ruleID == "" {
+				assert.Nil(t, matched)
+				return
+			}
+			require.NotNil(t, matched)
+			assert.Equal(t, tt.ruleID, matched.ID)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultEnvVariablesTTL 环境变量缓存默认有效期
+const DefaultEnvVariablesTTL = 30 * time.Second
+
+// envVariablesEntry 环境变量缓存项
+type envVariablesEntry struct {
+	variables map[string]interface{}
+	expiresAt time.Time
+}
+
+// EnvVariablesCache 环境变量短期缓存，避免每次脚本执行都查询数据库
+type EnvVariablesCache struct {
+	envRepo repository.EnvironmentRepository
+	ttl     time.Duration
+	entries map[string]envVariablesEntry
+	mu      sync.RWMutex
+}
+
+// NewEnvVariablesCache 创建环境变量缓存
+func NewEnvVariablesCache(envRepo repository.EnvironmentRepository, ttl time.Duration) *EnvVariablesCache {
+	return &EnvVariablesCache{
+		envRepo: envRepo,
+		ttl:     ttl,
+		entries: make(map[string]envVariablesEntry),
+	}
+}
+
+// Get 获取环境变量，缓存为空或加载失败时返回 nil
+func (c *EnvVariablesCache) Get(environmentID string) map[string]interface{} {
+	if c == nil || c.envRepo == nil || environmentID == "" {
+		return nil
+	}
+
+	c.mu.RLock()
+	entry, exists := c.entries[environmentID]
+	c.mu.RUnlock()
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.variables
+	}
+
+	env, err := c.envRepo.FindByID(context.Background(), environmentID)
+	if err != nil {
+		logger.Warn("failed to load environment variables",
+			zap.String("environment_id", environmentID),
+			zap.Error(err))
+		return nil
+	}
+
+	var variables map[string]interface{}
+	if env != nil {
+		variables = env.Variables
+	}
+
+	c.mu.Lock()
+	c.entries[environmentID] = envVariablesEntry{
+		variables: variables,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+	c.mu.Unlock()
+
+	return variables
+}
+
+// Invalidate 使指定环境的缓存失效，environmentID 为空时清空全部
+func (c *EnvVariablesCache) Invalidate(environmentID string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if environmentID == "" {
+		c.entries = make(map[string]envVariablesEntry)
+		return
+	}
+	delete(c.entries, environmentID)
+}
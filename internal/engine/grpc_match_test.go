@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func grpcRule(id string, priority int, matchType models.MatchType, condition map[string]interface{}) *models.Rule {
+	return &models.Rule{
+		ID:             id,
+		ProjectID:      "p1",
+		EnvironmentID:  "e1",
+		Protocol:       models.ProtocolGRPC,
+		MatchType:      matchType,
+		Priority:       priority,
+		Enabled:        true,
+		MatchCondition: condition,
+	}
+}
+
+func grpcRequest(fullMethod, body string, headers map[string]string) *adapter.Request {
+	return &adapter.Request{
+		Protocol: models.ProtocolGRPC,
+		Path:     fullMethod,
+		Headers:  headers,
+		Body:     []byte(body),
+		Metadata: map[string]interface{}{},
+	}
+}
+
+func TestMatch_GRPCRules(t *testing.T) {
+	rules := []*models.Rule{
+		grpcRule("by-body", 30, models.MatchTypeSimple, map[string]interface{}{
+			"service": "acme.user.v1.UserService",
+			"method":  "GetUser",
+			"body": map[string]interface{}{
+				"json_path": []interface{}{map[string]interface{}{"path": "$.userId", "value": "42"}},
+			},
+		}),
+		grpcRule("by-metadata", 20, models.MatchTypeSimple, map[string]interface{}{
+			"service":  "acme.user.v1.UserService",
+			"method":   "GetUser",
+			"metadata": map[string]interface{}{"x-tenant": "a"},
+		}),
+		grpcRule("regex", 10, models.MatchTypeRegex, map[string]interface{}{
+			"service": `acme\.user\.v1\.UserService`,
+			"method":  "List.*",
+		}),
+		grpcRule("any-method", 0, models.MatchTypeSimple, map[string]interface{}{
+			"service": "acme.user.v1.UserService",
+		}),
+		grpcRule("no-service", 100, models.MatchTypeSimple, map[string]interface{}{
+			"method": "GetUser",
+		}),
+	}
+	repo := new(MockRuleRepository)
+	repo.On("FindEnabledByEnvironment", context.Background(), "p1", "e1").Return(rules, nil)
+	e := NewMatchEngine(repo)
+
+	tests := []struct {
+		name    string
+		request *adapter.Request
+		ruleID  string
+	}{
+		{"请求消息字段", grpcRequest("/acme.user.v1.UserService/GetUser", `{"userId":"42"}`, nil), "by-body"},
+		{"元数据", grpcRequest("/acme.user.v1.UserService/GetUser", `{"userId":"7"}`, map[string]string{"x-tenant": "a"}), "by-metadata"},
+		{"正则方法名", grpcRequest("/acme.user.v1.UserService/ListUsers", `{}`, nil), "regex"},
+		{"服务的任意方法", grpcRequest("/acme.user.v1.UserService/DeleteUser", `{}`, nil), "any-method"},
+		{"其他服务", grpcRequest("/acme.order.v1.OrderService/GetUser", `{}`, nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := e.Match(context.Background(), tt.request, "p1", "e1")
+			require.NoError(t, err)
+			if tt.ruleID == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			assert.Equal(t, tt.ruleID, rule.ID)
+		})
+	}
+
+	// HTTP 请求即使路径相同也不匹配 gRPC 规则
+	httpRequest := grpcRequest("/acme.user.v1.UserService/GetUser", `{"userId":"42"}`, nil)
+	httpRequest.Protocol = models.ProtocolHTTP
+	httpRequest.Metadata["method"] = "POST"
+	rule, err := e.Match(context.Background(), httpRequest, "p1", "e1")
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+}
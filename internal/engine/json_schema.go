@@ -0,0 +1,647 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaViolation JSON Schema 校验错误
+type SchemaViolation struct {
+	Path    string `json:"path"`    // 出错位置（JSONPath 形式）
+	Message string `json:"message"` // 错误描述
+}
+
+// String 格式化错误
+func (v SchemaViolation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// JSONSchema JSON Schema 校验器
+//
+// 支持常用关键字：type、enum、const、properties、required、additionalProperties、
+// items、minItems、maxItems、uniqueItems、minLength、maxLength、pattern、format、
+// minimum、maximum、exclusiveMinimum、exclusiveMaximum、multipleOf、
+// allOf、anyOf、oneOf、not、nullable（OpenAPI）以及本地 $ref（#/...）。
+// format 仅支持 date-time、date、ipv4、email、uuid、hostname、uri。
+// 不支持 patternProperties、dependencies、if/then/else、contains、远程 $ref 等，
+// 通过 CompileJSONSchema 创建时遇到这些关键字会返回错误，而不是静默通过。
+type JSONSchema struct {
+	schema map[string]interface{}
+	root   map[string]interface{}
+
+	patterns   map[string]*regexp.Regexp
+	patternsMu sync.Mutex
+}
+
+// NewJSONSchema 创建 JSON Schema 校验器，$ref 相对 schema 自身解析
+func NewJSONSchema(schema map[string]interface{}) *JSONSchema {
+	return NewJSONSchemaWithRoot(schema, schema)
+}
+
+// NewJSONSchemaWithRoot 创建 JSON Schema 校验器，$ref 相对 root 文档解析（如 OpenAPI 文档）
+func NewJSONSchemaWithRoot(schema, root map[string]interface{}) *JSONSchema {
+	return &JSONSchema{
+		schema:   schema,
+		root:     root,
+		patterns: make(map[string]*regexp.Regexp),
+	}
+}
+
+// CompileJSONSchema 创建 JSON Schema 校验器并检查 schema 只使用了支持的关键字
+func CompileJSONSchema(schema map[string]interface{}) (*JSONSchema, error) {
+	s := NewJSONSchema(schema)
+	if err := s.check(schema, "#", 0); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	return s, nil
+}
+
+// schemaKeywords 支持的关键字及其取值形式
+var schemaKeywords = map[string]schemaKeywordKind{
+	"$ref": keywordPlain, "type": keywordPlain, "enum": keywordPlain, "const": keywordPlain,
+	"required": keywordPlain, "minProperties": keywordPlain, "maxProperties": keywordPlain,
+	"minItems": keywordPlain, "maxItems": keywordPlain, "uniqueItems": keywordPlain,
+	"minLength": keywordPlain, "maxLength": keywordPlain, "pattern": keywordPlain, "format": keywordPlain,
+	"minimum": keywordPlain, "maximum": keywordPlain, "exclusiveMinimum": keywordPlain,
+	"exclusiveMaximum": keywordPlain, "multipleOf": keywordPlain, "nullable": keywordPlain,
+	"properties": keywordSchemaMap, "definitions": keywordSchemaMap, "$defs": keywordSchemaMap,
+	"additionalProperties": keywordSchema, "items": keywordSchema, "not": keywordSchema,
+	"allOf": keywordSchemaList, "anyOf": keywordSchemaList, "oneOf": keywordSchemaList,
+	// 注解关键字，不参与校验
+	"$schema": keywordPlain, "$id": keywordPlain, "$comment": keywordPlain, "title": keywordPlain,
+	"description": keywordPlain, "default": keywordPlain, "examples": keywordPlain, "example": keywordPlain,
+	"deprecated": keywordPlain, "readOnly": keywordPlain, "writeOnly": keywordPlain,
+}
+
+// schemaKeywordKind 关键字取值形式
+type schemaKeywordKind int
+
+const (
+	keywordPlain      schemaKeywordKind = iota // 普通值
+	keywordSchema                              // 子 schema（items 也可以是数组，additionalProperties 也可以是布尔）
+	keywordSchemaMap                           // 名称到子 schema 的映射
+	keywordSchemaList                          // 子 schema 数组
+)
+
+// schemaTypes 支持的 type 取值
+var schemaTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true,
+}
+
+// check 递归检查 schema 是否只使用了支持的关键字和取值
+func (s *JSONSchema) check(schema map[string]interface{}, path string, depth int) error {
+	if depth > maxSchemaDepth {
+		return fmt.Errorf("%s: schema nesting too deep", path)
+	}
+
+	for key, value := range schema {
+		kind, supported := schemaKeywords[key]
+		if !supported {
+			if strings.HasPrefix(key, "x-") {
+				continue
+			}
+			return fmt.Errorf("%s: unsupported keyword %q", path, key)
+		}
+		keyPath := path + "/" + key
+
+		switch kind {
+		case keywordSchema:
+			switch sub := value.(type) {
+			case map[string]interface{}:
+				if err := s.check(sub, keyPath, depth+1); err != nil {
+					return err
+				}
+			case []interface{}:
+				if key != "items" {
+					return fmt.Errorf("%s: must be a schema", keyPath)
+				}
+				if err := s.checkList(sub, keyPath, depth); err != nil {
+					return err
+				}
+			case bool:
+				if key != "additionalProperties" {
+					return fmt.Errorf("%s: must be a schema", keyPath)
+				}
+			default:
+				return fmt.Errorf("%s: must be a schema", keyPath)
+			}
+		case keywordSchemaMap:
+			subs, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: must be an object", keyPath)
+			}
+			for name, sub := range subs {
+				subSchema, ok := sub.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("%s/%s: must be a schema", keyPath, name)
+				}
+				if err := s.check(subSchema, keyPath+"/"+name, depth+1); err != nil {
+					return err
+				}
+			}
+		case keywordSchemaList:
+			subs, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("%s: must be an array", keyPath)
+			}
+			if err := s.checkList(subs, keyPath, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.checkValues(schema, path)
+}
+
+// checkList 检查子 schema 数组
+func (s *JSONSchema) checkList(subs []interface{}, path string, depth int) error {
+	for i, sub := range subs {
+		subSchema, ok := sub.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s/%d: must be a schema", path, i)
+		}
+		if err := s.check(subSchema, fmt.Sprintf("%s/%d", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkValues 检查 $ref、type、format、pattern 的取值
+func (s *JSONSchema) checkValues(schema map[string]interface{}, path string) error {
+	if ref, exists := schema["$ref"]; exists {
+		refStr, ok := ref.(string)
+		if !ok {
+			return fmt.Errorf("%s/$ref: must be a string", path)
+		}
+		if _, err := s.resolveRef(refStr); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	switch typ := schema["type"].(type) {
+	case nil:
+	case string:
+		if !schemaTypes[typ] {
+			return fmt.Errorf("%s/type: unsupported type %q", path, typ)
+		}
+	case []interface{}:
+		for _, item := range typ {
+			if name, ok := item.(string); !ok || !schemaTypes[name] {
+				return fmt.Errorf("%s/type: unsupported type %v", path, item)
+			}
+		}
+	default:
+		return fmt.Errorf("%s/type: must be a string or an array of strings", path)
+	}
+
+	if format, exists := schema["format"]; exists {
+		name, ok := format.(string)
+		if !ok || !supportedStringFormat(name) {
+			return fmt.Errorf("%s/format: unsupported format %v", path, format)
+		}
+	}
+
+	if pattern, exists := schema["pattern"]; exists {
+		expr, ok := pattern.(string)
+		if !ok {
+			return fmt.Errorf("%s/pattern: must be a string", path)
+		}
+		if _, err := s.compilePattern(expr); err != nil {
+			return fmt.Errorf("%s/pattern: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Validate 校验数据，返回所有违反约束的位置
+func (s *JSONSchema) Validate(value interface{}) []SchemaViolation {
+	var violations []SchemaViolation
+	s.validate(s.schema, value, "$", &violations, 0)
+	return violations
+}
+
+// maxSchemaDepth 最大 $ref 展开深度，防止循环引用导致栈溢出
+const maxSchemaDepth = 64
+
+// validate 递归校验
+func (s *JSONSchema) validate(schema map[string]interface{}, value interface{}, path string, violations *[]SchemaViolation, depth int) {
+	if schema == nil {
+		return
+	}
+	if depth > maxSchemaDepth {
+		s.addViolation(violations, path, "schema nesting too deep")
+		return
+	}
+
+	// $ref 引用
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := s.resolveRef(ref)
+		if err != nil {
+			s.addViolation(violations, path, err.Error())
+			return
+		}
+		s.validate(resolved, value, path, violations, depth+1)
+		return
+	}
+
+	// nullable（OpenAPI 3.0 扩展）
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return
+		}
+	}
+
+	// type
+	if typ, exists := schema["type"]; exists {
+		if !matchSchemaType(typ, value) {
+			s.addViolation(violations, path, fmt.Sprintf("expected type %v, got %s", typ, jsonTypeOf(value)))
+			return
+		}
+	}
+
+	// enum
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.addViolation(violations, path, fmt.Sprintf("value %v is not one of %v", value, enum))
+		}
+	}
+
+	// const
+	if constant, exists := schema["const"]; exists {
+		if !jsonEqual(constant, value) {
+			s.addViolation(violations, path, fmt.Sprintf("value must be %v", constant))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(schema, v, path, violations, depth)
+	case []interface{}:
+		s.validateArray(schema, v, path, violations, depth)
+	case string:
+		s.validateString(schema, v, path, violations)
+	default:
+		if number, ok := toFloat64(value); ok {
+			s.validateNumber(schema, number, path, violations)
+		}
+	}
+
+	// 组合关键字
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				s.validate(subSchema, value, path, violations, depth+1)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if s.countMatches(anyOf, value, path, depth) == 0 {
+			s.addViolation(violations, path, "value does not match any schema in anyOf")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if count := s.countMatches(oneOf, value, path, depth); count != 1 {
+			s.addViolation(violations, path, fmt.Sprintf("value must match exactly one schema in oneOf, matched %d", count))
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok {
+		var sub []SchemaViolation
+		s.validate(not, value, path, &sub, depth+1)
+		if len(sub) == 0 {
+			s.addViolation(violations, path, "value must not match schema in not")
+		}
+	}
+}
+
+// validateObject 校验对象
+func (s *JSONSchema) validateObject(schema map[string]interface{}, obj map[string]interface{}, path string, violations *[]SchemaViolation, depth int) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, exists := obj[key]; !exists {
+					s.addViolation(violations, childPath(path, key), "required property is missing")
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for key, val := range obj {
+		if propSchema, ok := properties[key].(map[string]interface{}); ok {
+			s.validate(propSchema, val, childPath(path, key), violations, depth+1)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				s.addViolation(violations, childPath(path, key), "additional property is not allowed")
+			}
+		case map[string]interface{}:
+			s.validate(additional, val, childPath(path, key), violations, depth+1)
+		}
+	}
+
+	if min, ok := schemaInt(schema, "minProperties"); ok && len(obj) < min {
+		s.addViolation(violations, path, fmt.Sprintf("object must have at least %d properties", min))
+	}
+	if max, ok := schemaInt(schema, "maxProperties"); ok && len(obj) > max {
+		s.addViolation(violations, path, fmt.Sprintf("object must have at most %d properties", max))
+	}
+}
+
+// validateArray 校验数组
+func (s *JSONSchema) validateArray(schema map[string]interface{}, arr []interface{}, path string, violations *[]SchemaViolation, depth int) {
+	switch items := schema["items"].(type) {
+	case map[string]interface{}:
+		for i, item := range arr {
+			s.validate(items, item, fmt.Sprintf("%s[%d]", path, i), violations, depth+1)
+		}
+	case []interface{}:
+		// 元组形式
+		for i, item := range arr {
+			if i >= len(items) {
+				break
+			}
+			if itemSchema, ok := items[i].(map[string]interface{}); ok {
+				s.validate(itemSchema, item, fmt.Sprintf("%s[%d]", path, i), violations, depth+1)
+			}
+		}
+	}
+
+	if min, ok := schemaInt(schema, "minItems"); ok && len(arr) < min {
+		s.addViolation(violations, path, fmt.Sprintf("array must have at least %d items", min))
+	}
+	if max, ok := schemaInt(schema, "maxItems"); ok && len(arr) > max {
+		s.addViolation(violations, path, fmt.Sprintf("array must have at most %d items", max))
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := 0; i < len(arr); i++ {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					s.addViolation(violations, path, fmt.Sprintf("array items at %d and %d are not unique", i, j))
+					return
+				}
+			}
+		}
+	}
+}
+
+// validateString 校验字符串
+func (s *JSONSchema) validateString(schema map[string]interface{}, str string, path string, violations *[]SchemaViolation) {
+	length := len([]rune(str))
+	if min, ok := schemaInt(schema, "minLength"); ok && length < min {
+		s.addViolation(violations, path, fmt.Sprintf("string length must be at least %d", min))
+	}
+	if max, ok := schemaInt(schema, "maxLength"); ok && length > max {
+		s.addViolation(violations, path, fmt.Sprintf("string length must be at most %d", max))
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := s.compilePattern(pattern)
+		if err != nil {
+			s.addViolation(violations, path, fmt.Sprintf("invalid pattern %q", pattern))
+		} else if !re.MatchString(str) {
+			s.addViolation(violations, path, fmt.Sprintf("string does not match pattern %q", pattern))
+		}
+	}
+	if format, ok := schema["format"].(string); ok {
+		if !matchStringFormat(format, str) {
+			s.addViolation(violations, path, fmt.Sprintf("string is not a valid %s", format))
+		}
+	}
+}
+
+// validateNumber 校验数字
+func (s *JSONSchema) validateNumber(schema map[string]interface{}, number float64, path string, violations *[]SchemaViolation) {
+	if min, ok := toFloat64(schema["minimum"]); ok {
+		// OpenAPI 3.0 / Draft 4 使用布尔型 exclusiveMinimum
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive {
+			if number <= min {
+				s.addViolation(violations, path, fmt.Sprintf("value must be greater than %v", min))
+			}
+		} else if number < min {
+			s.addViolation(violations, path, fmt.Sprintf("value must be at least %v", min))
+		}
+	}
+	if max, ok := toFloat64(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive {
+			if number >= max {
+				s.addViolation(violations, path, fmt.Sprintf("value must be less than %v", max))
+			}
+		} else if number > max {
+			s.addViolation(violations, path, fmt.Sprintf("value must be at most %v", max))
+		}
+	}
+	if min, ok := toFloat64(schema["exclusiveMinimum"]); ok && number <= min {
+		s.addViolation(violations, path, fmt.Sprintf("value must be greater than %v", min))
+	}
+	if max, ok := toFloat64(schema["exclusiveMaximum"]); ok && number >= max {
+		s.addViolation(violations, path, fmt.Sprintf("value must be less than %v", max))
+	}
+	if multipleOf, ok := toFloat64(schema["multipleOf"]); ok && multipleOf > 0 {
+		quotient := number / multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			s.addViolation(violations, path, fmt.Sprintf("value must be a multiple of %v", multipleOf))
+		}
+	}
+}
+
+// countMatches 统计满足的子 schema 数量
+func (s *JSONSchema) countMatches(schemas []interface{}, value interface{}, path string, depth int) int {
+	count := 0
+	for _, sub := range schemas {
+		subSchema, ok := sub.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var subViolations []SchemaViolation
+		s.validate(subSchema, value, path, &subViolations, depth+1)
+		if len(subViolations) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// resolveRef 解析本地 $ref（#/a/b/c）
+func (s *JSONSchema) resolveRef(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are supported", ref)
+	}
+
+	var current interface{} = s.root
+	pointer := strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/")
+	if pointer != "" {
+		for _, token := range strings.Split(pointer, "/") {
+			// JSON Pointer 转义
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+			current, ok = obj[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+		}
+	}
+
+	resolved, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("$ref %q does not point to a schema", ref)
+	}
+	return resolved, nil
+}
+
+// compilePattern 编译并缓存 pattern
+func (s *JSONSchema) compilePattern(pattern string) (*regexp.Regexp, error) {
+	s.patternsMu.Lock()
+	defer s.patternsMu.Unlock()
+
+	if re, exists := s.patterns[pattern]; exists {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[pattern] = re
+	return re, nil
+}
+
+// addViolation 记录校验错误
+func (s *JSONSchema) addViolation(violations *[]SchemaViolation, path, message string) {
+	*violations = append(*violations, SchemaViolation{Path: path, Message: message})
+}
+
+// childPath 构建子属性路径
+func childPath(path, key string) string {
+	return path + "." + key
+}
+
+// schemaInt 读取整数型关键字
+func schemaInt(schema map[string]interface{}, key string) (int, bool) {
+	if v, ok := toFloat64(schema[key]); ok {
+		return int(v), true
+	}
+	return 0, false
+}
+
+// matchSchemaType 检查值是否满足 type 关键字（字符串或字符串数组）
+func matchSchemaType(typ interface{}, value interface{}) bool {
+	switch t := typ.(type) {
+	case string:
+		return matchSingleType(t, value)
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && matchSingleType(name, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// matchSingleType 检查单一类型
+func matchSingleType(typ string, value interface{}) bool {
+	actual := jsonTypeOf(value)
+	switch typ {
+	case "number":
+		return actual == "integer" || actual == "number"
+	default:
+		return actual == typ
+	}
+}
+
+// jsonTypeOf 获取值的 JSON 类型名
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		if number, ok := toFloat64(v); ok {
+			if number == math.Trunc(number) && !math.IsInf(number, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return reflect.TypeOf(value).String()
+	}
+}
+
+// formatPatterns 常用 format 校验
+var formatPatterns = map[string]*regexp.Regexp{
+	"email":    regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`),
+	"uuid":     regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+	"hostname": regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`),
+	"uri":      regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:[^\s]*$`),
+}
+
+// supportedStringFormat 检查 format 是否受支持
+func supportedStringFormat(format string) bool {
+	switch format {
+	case "date-time", "date", "ipv4":
+		return true
+	default:
+		_, ok := formatPatterns[format]
+		return ok
+	}
+}
+
+// matchStringFormat 校验字符串格式，未知 format 视为通过（OpenAPI 契约中的 int32、binary 等仅为注解）
+func matchStringFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "ipv4":
+		return isIPv4(value)
+	default:
+		if re, ok := formatPatterns[format]; ok {
+			return re.MatchString(value)
+		}
+		return true
+	}
+}
+
+// isIPv4 检查 IPv4 地址格式
+func isIPv4(value string) bool {
+	parts := strings.Split(value, ".")
+	if len(parts) != 4 {
+		return false
+	}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || n > 255 || (len(part) > 1 && part[0] == '0') {
+			return false
+		}
+	}
+	return true
+}
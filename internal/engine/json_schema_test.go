@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema_Validate(t *testing.T) {
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["id", "email", "items"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"email": {"type": "string", "format": "email"},
+			"status": {"enum": ["active", "inactive"]},
+			"items": {
+				"type": "array",
+				"minItems": 1,
+				"items": {"$ref": "#/definitions/item"}
+			}
+		},
+		"additionalProperties": false,
+		"definitions": {
+			"item": {
+				"type": "object",
+				"required": ["sku"],
+				"properties": {
+					"sku": {"type": "string", "pattern": "^SKU-[0-9]+$"},
+					"qty": {"type": "integer", "exclusiveMinimum": 0}
+				}
+			}
+		}
+	}`), &schema))
+
+	validator := NewJSONSchema(schema)
+
+	tests := []struct {
+		name       string
+		data       string
+		violations []string
+	}{
+		{
+			name: "合法数据",
+			data: `{"id": 1, "email": "a@b.com", "status": "active", "items": [{"sku": "SKU-1", "qty": 2}]}`,
+		},
+		{
+			name:       "缺少必填字段",
+			data:       `{"id": 1, "items": [{"sku": "SKU-1"}]}`,
+			violations: []string{"$.email"},
+		},
+		{
+			name:       "类型与格式错误",
+			data:       `{"id": 1.5, "email": "not-an-email", "items": [{"sku": "SKU-1"}]}`,
+			violations: []string{"$.id", "$.email"},
+		},
+		{
+			name:       "嵌套引用校验",
+			data:       `{"id": 1, "email": "a@b.com", "items": [{"sku": "bad", "qty": 0}]}`,
+			violations: []string{"$.items[0].sku", "$.items[0].qty"},
+		},
+		{
+			name:       "不允许的额外属性与枚举",
+			data:       `{"id": 1, "email": "a@b.com", "status": "deleted", "items": [{"sku": "SKU-1"}], "extra": true}`,
+			violations: []string{"$.status", "$.extra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &data))
+
+			violations := validator.Validate(data)
+			paths := make([]string, 0, len(violations))
+			for _, v := range violations {
+				paths = append(paths, v.Path)
+			}
+			assert.ElementsMatch(t, tt.violations, paths)
+		})
+	}
+}
+
+func TestJSONSchema_Combinators(t *testing.T) {
+	schema := map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "integer"},
+		},
+		"not": map[string]interface{}{"const": "forbidden"},
+	}
+	validator := NewJSONSchema(schema)
+
+	assert.Empty(t, validator.Validate("ok"))
+	assert.Empty(t, validator.Validate(float64(3)))
+	assert.NotEmpty(t, validator.Validate(true))
+	assert.NotEmpty(t, validator.Validate("forbidden"))
+}
+
+func TestCompileJSONSchema_UnsupportedKeywords(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"未知关键字", `{"type": "object", "patternProperties": {"^a": {"type": "string"}}}`},
+		{"嵌套未知关键字", `{"properties": {"a": {"if": {"type": "string"}}}}`},
+		{"未知format", `{"type": "string", "format": "ipv6"}`},
+		{"未知type", `{"type": "int"}`},
+		{"非法pattern", `{"type": "string", "pattern": "("}`},
+		{"远程引用", `{"$ref": "http://example.com/schema.json"}`},
+		{"无法解析的引用", `{"items": {"$ref": "#/definitions/missing"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+			_, err := CompileJSONSchema(schema)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompileJSONSchema_SupportedKeywords(t *testing.T) {
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "order",
+		"type": ["object", "null"],
+		"properties": {
+			"id": {"type": "string", "format": "uuid", "x-internal": true},
+			"tags": {"type": "array", "items": [{"type": "string"}], "uniqueItems": true}
+		},
+		"additionalProperties": false,
+		"anyOf": [{"required": ["id"]}, {"$ref": "#/$defs/empty"}],
+		"$defs": {"empty": {"maxProperties": 0}}
+	}`), &schema))
+
+	validator, err := CompileJSONSchema(schema)
+	require.NoError(t, err)
+	assert.Empty(t, validator.Validate(map[string]interface{}{}))
+}
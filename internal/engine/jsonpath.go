@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegmentType JSONPath 片段类型
+type jsonPathSegmentType int
+
+const (
+	segmentKey       jsonPathSegmentType = iota // .key 或 ['key']
+	segmentIndex                                // [n]
+	segmentWildcard                             // .* 或 [*]
+	segmentRecursive                            // ..key 或 ..*
+)
+
+// jsonPathSegment JSONPath 片段
+type jsonPathSegment struct {
+	typ   jsonPathSegmentType
+	key   string
+	index int
+}
+
+// JSONPath 已解析的 JSONPath 表达式
+//
+// 支持的语法子集：$、.key、['key']、[n]（支持负数下标）、[*]、.*、..key、..*。
+// 不支持过滤表达式 [?(...)]、脚本 [(...)]、切片 [a:b]、联合 [a,b] 与函数调用，解析时返回错误。
+type JSONPath struct {
+	expr     string
+	segments []jsonPathSegment
+}
+
+// ParseJSONPath 解析 JSONPath 表达式
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	path := strings.TrimSpace(expr)
+	if path == "" {
+		return nil, fmt.Errorf("empty json path")
+	}
+
+	// 允许省略前导 $
+	if strings.HasPrefix(path, "$") {
+		path = path[1:]
+	} else if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+
+	var segments []jsonPathSegment
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			recursive := false
+			i++
+			if i < len(path) && path[i] == '.' {
+				recursive = true
+				i++
+			}
+			if i < len(path) && path[i] == '[' && recursive {
+				// ..[...] 形式，交由下一轮处理后转为递归
+				segments = append(segments, jsonPathSegment{typ: segmentRecursive, key: "*"})
+				continue
+			}
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			name := path[start:i]
+			if name == "" {
+				return nil, fmt.Errorf("invalid json path %q: empty key at position %d", expr, start)
+			}
+			if strings.ContainsAny(name, "()?@") {
+				return nil, fmt.Errorf("invalid json path %q: unsupported key %q", expr, name)
+			}
+			switch {
+			case recursive:
+				segments = append(segments, jsonPathSegment{typ: segmentRecursive, key: name})
+			case name == "*":
+				segments = append(segments, jsonPathSegment{typ: segmentWildcard})
+			default:
+				segments = append(segments, jsonPathSegment{typ: segmentKey, key: name})
+			}
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed bracket", expr)
+			}
+			content := strings.TrimSpace(path[i+1 : i+end])
+			i += end + 1
+
+			switch {
+			case content == "*":
+				segments = append(segments, jsonPathSegment{typ: segmentWildcard})
+			case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+				segments = append(segments, jsonPathSegment{typ: segmentKey, key: content[1 : len(content)-1]})
+			default:
+				index, err := strconv.Atoi(content)
+				if err != nil {
+					return nil, fmt.Errorf("invalid json path %q: unsupported selector [%s]", expr, content)
+				}
+				segments = append(segments, jsonPathSegment{typ: segmentIndex, index: index})
+			}
+		default:
+			return nil, fmt.Errorf("invalid json path %q: unexpected character %q", expr, path[i])
+		}
+	}
+
+	return &JSONPath{expr: expr, segments: segments}, nil
+}
+
+// String 返回原始表达式
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Find 在 JSON 数据中查找所有匹配的值
+func (p *JSONPath) Find(data interface{}) []interface{} {
+	current := []interface{}{data}
+	for _, seg := range p.segments {
+		var next []interface{}
+		for _, value := range current {
+			next = append(next, applySegment(seg, value)...)
+		}
+		current = next
+		if len(current) == 0 {
+			break
+		}
+	}
+	return current
+}
+
+// applySegment 对单个值应用片段
+func applySegment(seg jsonPathSegment, value interface{}) []interface{} {
+	switch seg.typ {
+	case segmentKey:
+		if obj, ok := value.(map[string]interface{}); ok {
+			if v, exists := obj[seg.key]; exists {
+				return []interface{}{v}
+			}
+		}
+		return nil
+	case segmentIndex:
+		if arr, ok := value.([]interface{}); ok {
+			index := seg.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				return []interface{}{arr[index]}
+			}
+		}
+		return nil
+	case segmentWildcard:
+		return childValues(value)
+	case segmentRecursive:
+		var results []interface{}
+		collectRecursive(seg.key, value, &results)
+		return results
+	default:
+		return nil
+	}
+}
+
+// childValues 获取对象或数组的所有子值（对象按键排序，保证结果稳定）
+func childValues(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		results := make([]interface{}, 0, len(v))
+		for _, key := range keys {
+			results = append(results, v[key])
+		}
+		return results
+	case []interface{}:
+		return append([]interface{}{}, v...)
+	default:
+		return nil
+	}
+}
+
+// collectRecursive 递归收集匹配键的值
+func collectRecursive(key string, value interface{}, results *[]interface{}) {
+	if key == "*" {
+		for _, child := range childValues(value) {
+			*results = append(*results, child)
+			collectRecursive(key, child, results)
+		}
+		return
+	}
+
+	if obj, ok := value.(map[string]interface{}); ok {
+		if v, exists := obj[key]; exists {
+			*results = append(*results, v)
+		}
+	}
+	for _, child := range childValues(value) {
+		collectRecursive(key, child, results)
+	}
+}
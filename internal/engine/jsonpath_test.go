@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath_Find(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"user": {"name": "alice", "tags": ["a", "b"]},
+		"items": [{"id": 1, "price": 10}, {"id": 2, "price": 25}],
+		"meta.key": "dotted"
+	}`), &data))
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []interface{}
+	}{
+		{"根节点属性", "$.user.name", []interface{}{"alice"}},
+		{"省略前导$", "user.name", []interface{}{"alice"}},
+		{"数组下标", "$.items[1].price", []interface{}{float64(25)}},
+		{"负数下标", "$.items[-1].id", []interface{}{float64(2)}},
+		{"数组通配符", "$.items[*].id", []interface{}{float64(1), float64(2)}},
+		{"点通配符", "$.user.tags.*", []interface{}{"a", "b"}},
+		{"括号键", "$['meta.key']", []interface{}{"dotted"}},
+		{"递归下降", "$..price", []interface{}{float64(10), float64(25)}},
+		{"不存在的路径", "$.user.age", nil},
+		{"越界下标", "$.items[5]", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ParseJSONPath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, path.Find(data))
+		})
+	}
+}
+
+func TestJSONPath_ParseErrors(t *testing.T) {
+	invalid := []string{
+		"", "$.items[", "$.items[abc]", "$..", "$#",
+		"$.items[?(@.price > 1)]", "$.items[0:2]", "$.items[0,1]", "$.items.length()",
+	}
+	for _, expr := range invalid {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseJSONPath(expr)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gomockserver/mockserver/internal/adapter"
 	"github.com/gomockserver/mockserver/internal/models"
 	"github.com/gomockserver/mockserver/internal/repository"
 	"github.com/gomockserver/mockserver/pkg/logger"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 // RegexCacheStats 缓存统计信息
@@ -23,40 +25,95 @@ type RegexCacheStats struct {
 	Size   int
 }
 
+// RuleLoader 环境启用规则加载器，如带缓存的规则服务
+type RuleLoader interface {
+	GetEnabledRulesByEnvironment(ctx context.Context, projectID, environmentID string) ([]*models.Rule, error)
+}
+
 // MatchEngine 规则匹配引擎
 type MatchEngine struct {
 	ruleRepo   repository.RuleRepository
+	ruleLoader RuleLoader
 	regexCache *LRURegexCache
 	cacheStats RegexCacheStats
 	statsMu    sync.RWMutex
+
+	// 脚本匹配
+	scriptEngine *ScriptEngine
+	envVars      *EnvVariablesCache
+
+	// 场景状态
+	scenarios *ScenarioStore
+
+	// 按环境缓存的规则索引
+	indexes   map[string]*ruleIndex
+	indexesMu sync.RWMutex
+	indexTTL  time.Duration
+	// 规则变更代数：重建期间收到变更通知时重新加载，避免旧数据覆盖增量更新
+	indexGens  map[string]uint64
+	globalGen  uint64
+	indexLoads singleflight.Group
 }
 
 // NewMatchEngine 创建匹配引擎
 func NewMatchEngine(ruleRepo repository.RuleRepository) *MatchEngine {
 	return &MatchEngine{
-		ruleRepo:   ruleRepo,
-		regexCache: NewLRURegexCache(1000), // 默认缓存容量1000
+		ruleRepo:     ruleRepo,
+		regexCache:   NewLRURegexCache(1000), // 默认缓存容量1000
+		scriptEngine: NewScriptEngine(),
+		scenarios:    NewScenarioStore(nil),
+		indexes:      make(map[string]*ruleIndex),
+		indexTTL:     DefaultRuleIndexTTL,
+		indexGens:    make(map[string]uint64),
 	}
 }
 
+// SetRuleLoader 设置规则加载器，重建规则索引时代替规则仓库读取规则
+func (e *MatchEngine) SetRuleLoader(loader RuleLoader) {
+	e.ruleLoader = loader
+}
+
+// SetEnvironmentRepository 设置环境仓库，用于向脚本注入环境变量
+func (e *MatchEngine) SetEnvironmentRepository(envRepo repository.EnvironmentRepository) {
+	e.envVars = NewEnvVariablesCache(envRepo, DefaultEnvVariablesTTL)
+}
+
+// EnvironmentChanged 环境变更后清除环境变量缓存（实现 repository.EnvironmentChangeListener）
+func (e *MatchEngine) EnvironmentChanged(ctx context.Context, environmentID string) {
+	e.envVars.Invalidate(environmentID)
+}
+
+// SetScenarioRepository 设置场景状态仓库，启用状态持久化
+func (e *MatchEngine) SetScenarioRepository(repo repository.ScenarioRepository) {
+	e.scenarios = NewScenarioStore(repo)
+}
+
+// Scenarios 获取场景状态存储
+func (e *MatchEngine) Scenarios() *ScenarioStore {
+	return e.scenarios
+}
+
 // Match 匹配规则
 func (e *MatchEngine) Match(ctx context.Context, request *adapter.Request, projectID, environmentID string) (*models.Rule, error) {
-	// 加载环境下所有启用的规则
-	rules, err := e.ruleRepo.FindEnabledByEnvironment(ctx, projectID, environmentID)
+	// 获取环境的规则索引
+	idx, err := e.ruleIndex(ctx, projectID, environmentID)
 	if err != nil {
 		logger.Error("failed to load rules", zap.Error(err))
 		return nil, err
 	}
 
-	// 按优先级已排序，逐条匹配
-	for _, rule := range rules {
+	// 只匹配方法和路径可能命中的候选规则，候选规则按优先级排序
+	method, _ := request.Metadata["method"].(string)
+	for _, cr := range idx.candidates(method, request.Path) {
+		rule := cr.rule
+
 		// 检查协议类型
 		if rule.Protocol != request.Protocol {
 			continue
 		}
 
 		// 根据匹配类型执行匹配
-		matched, err := e.matchRule(request, rule)
+		matched, err := e.matchCompiled(request, cr)
 		if err != nil {
 			logger.Warn("rule match error",
 				zap.String("rule_id", rule.ID),
@@ -64,6 +121,12 @@ func (e *MatchEngine) Match(ctx context.Context, request *adapter.Request, proje
 			continue
 		}
 
+		// 检查并推进场景状态
+		if matched && rule.Scenario != nil && rule.Scenario.Name != "" {
+			clientID := ScenarioClientID(request, rule.Scenario)
+			matched = e.scenarios.Advance(ctx, projectID, environmentID, rule.Scenario, clientID)
+		}
+
 		if matched {
 			logger.Info("rule matched",
 				zap.String("rule_id", rule.ID),
@@ -76,6 +139,178 @@ func (e *MatchEngine) Match(ctx context.Context, request *adapter.Request, proje
 	return nil, nil
 }
 
+// ruleIndex 获取环境的规则索引，不存在或过期时从仓库加载并重建
+func (e *MatchEngine) ruleIndex(ctx context.Context, projectID, environmentID string) (*ruleIndex, error) {
+	key := indexKey(projectID, environmentID)
+
+	e.indexesMu.RLock()
+	idx, exists := e.indexes[key]
+	e.indexesMu.RUnlock()
+	if exists && time.Since(idx.builtAt) < e.indexTTL {
+		return idx, nil
+	}
+
+	// 同一环境的并发重建只加载一次规则
+	value, err, _ := e.indexLoads.Do(key, func() (interface{}, error) {
+		return e.rebuildIndex(ctx, projectID, environmentID, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*ruleIndex), nil
+}
+
+// rebuildIndex 加载规则并重建索引；加载期间规则发生变更时重新加载，
+// 多次重试仍有变更时保存一个已过期的索引，下次匹配时再次重建
+func (e *MatchEngine) rebuildIndex(ctx context.Context, projectID, environmentID, key string) (*ruleIndex, error) {
+	var idx *ruleIndex
+	for attempt := 0; attempt < maxRuleIndexRebuilds; attempt++ {
+		gen := e.generation(key)
+
+		var rules []*models.Rule
+		var err error
+		if e.ruleLoader != nil {
+			rules, err = e.ruleLoader.GetEnabledRulesByEnvironment(ctx, projectID, environmentID)
+		} else {
+			rules, err = e.ruleRepo.FindEnabledByEnvironment(ctx, projectID, environmentID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		idx = newRuleIndex(rules)
+
+		e.indexesMu.Lock()
+		if e.indexGens[key]+e.globalGen == gen {
+			e.indexes[key] = idx
+			e.indexesMu.Unlock()
+			return idx, nil
+		}
+		e.indexesMu.Unlock()
+	}
+
+	idx.builtAt = time.Time{}
+	e.indexesMu.Lock()
+	e.indexes[key] = idx
+	e.indexesMu.Unlock()
+	return idx, nil
+}
+
+// maxRuleIndexRebuilds 加载期间规则持续变更时的最大重试次数
+const maxRuleIndexRebuilds = 3
+
+// generation 获取环境的规则变更代数
+func (e *MatchEngine) generation(key string) uint64 {
+	e.indexesMu.RLock()
+	defer e.indexesMu.RUnlock()
+	return e.indexGens[key] + e.globalGen
+}
+
+// indexKey 规则索引键
+func indexKey(projectID, environmentID string) string {
+	return projectID + "/" + environmentID
+}
+
+// SetRuleIndexTTL 设置规则索引最长有效期
+func (e *MatchEngine) SetRuleIndexTTL(ttl time.Duration) {
+	e.indexTTL = ttl
+}
+
+// InvalidateRules 使环境的规则索引失效，下次匹配时重建
+func (e *MatchEngine) InvalidateRules(projectID, environmentID string) {
+	key := indexKey(projectID, environmentID)
+	e.indexesMu.Lock()
+	delete(e.indexes, key)
+	e.indexGens[key]++
+	e.indexesMu.Unlock()
+}
+
+// InvalidateAllRules 使所有规则索引失效
+func (e *MatchEngine) InvalidateAllRules() {
+	e.indexesMu.Lock()
+	e.indexes = make(map[string]*ruleIndex)
+	e.globalGen++
+	e.indexesMu.Unlock()
+}
+
+// RuleSaved 规则创建或更新后增量更新索引（实现 repository.RuleChangeListener）
+func (e *MatchEngine) RuleSaved(ctx context.Context, rule *models.Rule) {
+	if rule == nil || rule.ID == "" {
+		return
+	}
+	key := indexKey(rule.ProjectID, rule.EnvironmentID)
+	saved := *rule
+
+	// 持有写锁推进代数并更新索引，正在进行的重建会检测到变更并重新加载
+	e.indexesMu.Lock()
+	defer e.indexesMu.Unlock()
+	e.indexGens[key]++
+	for k, idx := range e.indexes {
+		if k == key {
+			idx.upsert(&saved)
+		} else if idx.contains(rule.ID) {
+			// 规则被移动到其他环境
+			idx.delete(rule.ID)
+		}
+	}
+}
+
+// RuleDeleted 规则删除后从索引中移除（实现 repository.RuleChangeListener）
+func (e *MatchEngine) RuleDeleted(ctx context.Context, rule *models.Rule) {
+	if rule == nil {
+		return
+	}
+	e.indexesMu.Lock()
+	defer e.indexesMu.Unlock()
+	if rule.ProjectID != "" {
+		e.indexGens[indexKey(rule.ProjectID, rule.EnvironmentID)]++
+	} else {
+		// 不知道规则所在环境，所有正在进行的重建都需要重新加载
+		e.globalGen++
+	}
+	for _, idx := range e.indexes {
+		if idx.delete(rule.ID) {
+			return
+		}
+	}
+}
+
+// matchCompiled 使用预编译的条件匹配规则
+func (e *MatchEngine) matchCompiled(request *adapter.Request, cr *compiledRule) (bool, error) {
+	if cr.err != nil {
+		return false, cr.err
+	}
+
+	switch cr.rule.MatchType {
+	case models.MatchTypeSimple:
+		if !conditionProtocol(cr.rule.Protocol) {
+			return false, nil
+		}
+		matched, err := e.matchSimpleCondition(request, cr.condition, cr.body)
+		if err != nil || !matched {
+			return false, err
+		}
+		return cr.websocket.match(request), nil
+	case models.MatchTypeRegex:
+		if !conditionProtocol(cr.rule.Protocol) {
+			return false, nil
+		}
+		matched, err := e.matchRegexCondition(request, cr.condition, cr.body, func(pattern string) (*regexp.Regexp, error) {
+			if re, exists := cr.regexes[pattern]; exists {
+				return re, nil
+			}
+			return e.compileRegex(pattern)
+		})
+		if err != nil || !matched {
+			return false, err
+		}
+		return cr.websocket.match(request), nil
+	case models.MatchTypeScript:
+		return e.scriptMatch(request, cr.rule)
+	default:
+		return false, fmt.Errorf("unsupported match type: %s", cr.rule.MatchType)
+	}
+}
+
 // matchRule 匹配单条规则
 func (e *MatchEngine) matchRule(request *adapter.Request, rule *models.Rule) (bool, error) {
 	switch rule.MatchType {
@@ -92,21 +327,61 @@ func (e *MatchEngine) matchRule(request *adapter.Request, rule *models.Rule) (bo
 
 // simpleMatch 简单匹配
 func (e *MatchEngine) simpleMatch(request *adapter.Request, rule *models.Rule) (bool, error) {
-	if rule.Protocol != models.ProtocolHTTP {
+	if !conditionProtocol(rule.Protocol) {
 		return false, nil
 	}
 
-	// 解析 HTTP 匹配条件
-	conditionBytes, err := json.Marshal(rule.MatchCondition)
+	condition, err := parseMatchCondition(rule)
 	if err != nil {
 		return false, err
 	}
 
+	matched, err := e.matchSimpleCondition(request, condition, nil)
+	if err != nil || !matched {
+		return false, err
+	}
+	return e.matchWebSocketCondition(request, rule)
+}
+
+// conditionProtocol 判断协议的简单、正则匹配条件是否可按 HTTP 匹配条件执行
+func conditionProtocol(protocol models.ProtocolType) bool {
+	return protocol == models.ProtocolHTTP || protocol == models.ProtocolGRPC || protocol == models.ProtocolWebSocket
+}
+
+// parseMatchCondition 解析规则的匹配条件，gRPC、WebSocket 条件转换为等价的 HTTP 匹配条件
+func parseMatchCondition(rule *models.Rule) (*models.HTTPMatchCondition, error) {
+	conditionBytes, err := json.Marshal(rule.MatchCondition)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Protocol == models.ProtocolGRPC {
+		var condition models.GRPCMatchCondition
+		if err := json.Unmarshal(conditionBytes, &condition); err != nil {
+			return nil, err
+		}
+		if condition.Service == "" {
+			return nil, fmt.Errorf("grpc match condition requires service")
+		}
+		return condition.HTTPCondition(rule.MatchType == models.MatchTypeRegex), nil
+	}
+	if rule.Protocol == models.ProtocolWebSocket {
+		condition, err := parseWebSocketCondition(rule)
+		if err != nil {
+			return nil, err
+		}
+		return condition.HTTPCondition(rule.MatchType == models.MatchTypeRegex), nil
+	}
+
 	var condition models.HTTPMatchCondition
 	if err := json.Unmarshal(conditionBytes, &condition); err != nil {
-		return false, err
+		return nil, err
 	}
+	return &condition, nil
+}
 
+// matchSimpleCondition 按已解析的条件执行简单匹配，body 为预编译的请求体条件（可为空）
+func (e *MatchEngine) matchSimpleCondition(request *adapter.Request, condition *models.HTTPMatchCondition, body *compiledBody) (bool, error) {
 	// 获取请求方法
 	method, _ := request.Metadata["method"].(string)
 
@@ -139,6 +414,14 @@ func (e *MatchEngine) simpleMatch(request *adapter.Request, rule *models.Rule) (
 		}
 	}
 
+	// 匹配请求体
+	if len(condition.Body) > 0 {
+		matched, err := e.matchBody(request, condition.Body, body)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
 	// 匹配 IP 白名单
 	if len(condition.IPWhitelist) > 0 {
 		if !matchIPWhitelist(request.SourceIP, condition.IPWhitelist) {
@@ -146,6 +429,13 @@ func (e *MatchEngine) simpleMatch(request *adapter.Request, rule *models.Rule) (
 		}
 	}
 
+	// 匹配客户端证书
+	if condition.ClientCert != nil {
+		if !matchClientCert(request, condition.ClientCert) {
+			return false, nil
+		}
+	}
+
 	return true, nil
 }
 
@@ -186,21 +476,36 @@ func (e *MatchEngine) GetCacheStats() RegexCacheStats {
 
 // regexMatch 正则表达式匹配
 func (e *MatchEngine) regexMatch(request *adapter.Request, rule *models.Rule) (bool, error) {
-	if rule.Protocol != models.ProtocolHTTP {
+	if !conditionProtocol(rule.Protocol) {
 		return false, nil
 	}
 
-	// 解析 HTTP 匹配条件
-	conditionBytes, err := json.Marshal(rule.MatchCondition)
+	condition, err := parseMatchCondition(rule)
 	if err != nil {
 		return false, err
 	}
 
-	var condition models.HTTPMatchCondition
-	if err := json.Unmarshal(conditionBytes, &condition); err != nil {
+	matched, err := e.matchRegexCondition(request, condition, nil, e.compileRegex)
+	if err != nil || !matched {
+		return false, err
+	}
+	return e.matchWebSocketCondition(request, rule)
+}
+
+// matchWebSocketCondition 匹配 WebSocket 规则的事件与消息内容，其他协议的规则总是匹配
+func (e *MatchEngine) matchWebSocketCondition(request *adapter.Request, rule *models.Rule) (bool, error) {
+	if rule.Protocol != models.ProtocolWebSocket {
+		return true, nil
+	}
+	condition, err := compileWebSocketCondition(rule)
+	if err != nil {
 		return false, err
 	}
+	return condition.match(request), nil
+}
 
+// matchRegexCondition 按已解析的条件执行正则匹配，body 为预编译的请求体条件（可为空），compile 用于获取已编译的正则
+func (e *MatchEngine) matchRegexCondition(request *adapter.Request, condition *models.HTTPMatchCondition, body *compiledBody, compile func(string) (*regexp.Regexp, error)) (bool, error) {
 	// 获取请求方法
 	method, _ := request.Metadata["method"].(string)
 
@@ -214,7 +519,7 @@ func (e *MatchEngine) regexMatch(request *adapter.Request, rule *models.Rule) (b
 	// 匹配 Path (支持正则表达式)
 	if condition.PathRegex != "" {
 		// 编译正则表达式
-		re, err := e.compileRegex(condition.PathRegex)
+		re, err := compile(condition.PathRegex)
 		if err != nil {
 			logger.Warn("failed to compile regex pattern for path",
 				zap.String("pattern", condition.PathRegex),
@@ -243,7 +548,7 @@ func (e *MatchEngine) regexMatch(request *adapter.Request, rule *models.Rule) (b
 			}
 
 			// 编译正则表达式
-			re, err := e.compileRegex(pattern)
+			re, err := compile(pattern)
 			if err != nil {
 				logger.Warn("failed to compile regex pattern for query",
 					zap.String("key", key),
@@ -278,7 +583,7 @@ func (e *MatchEngine) regexMatch(request *adapter.Request, rule *models.Rule) (b
 			}
 
 			// 编译正则表达式
-			re, err := e.compileRegex(pattern)
+			re, err := compile(pattern)
 			if err != nil {
 				logger.Warn("failed to compile regex pattern for header",
 					zap.String("key", key),
@@ -294,6 +599,14 @@ func (e *MatchEngine) regexMatch(request *adapter.Request, rule *models.Rule) (b
 		}
 	}
 
+	// 匹配请求体
+	if len(condition.Body) > 0 {
+		matched, err := e.matchBody(request, condition.Body, body)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
 	// 匹配 IP 白名单
 	if len(condition.IPWhitelist) > 0 {
 		if !matchIPWhitelist(request.SourceIP, condition.IPWhitelist) {
@@ -301,13 +614,19 @@ func (e *MatchEngine) regexMatch(request *adapter.Request, rule *models.Rule) (b
 		}
 	}
 
+	// 匹配客户端证书
+	if condition.ClientCert != nil {
+		if !matchClientCert(request, condition.ClientCert) {
+			return false, nil
+		}
+	}
+
 	return true, nil
 }
 
-// scriptMatch 脚本匹配（阶段一暂不实现）
+// scriptMatch 脚本匹配
 func (e *MatchEngine) scriptMatch(request *adapter.Request, rule *models.Rule) (bool, error) {
-	// TODO: 阶段三实现
-	return false, fmt.Errorf("script match not implemented yet")
+	return e.scriptEngine.MatchWithEnv(request, rule, e.envVars.Get(rule.EnvironmentID))
 }
 
 // matchMethod 匹配请求方法
@@ -329,6 +648,11 @@ func matchMethod(requestMethod string, conditionMethod interface{}) bool {
 	}
 }
 
+// MatchPath 匹配路径，支持 /api/users/:id 形式的路径参数
+func MatchPath(requestPath, conditionPath string) bool {
+	return matchPath(requestPath, conditionPath)
+}
+
 // matchPath 匹配路径（支持简单通配符）
 func matchPath(requestPath, conditionPath string) bool {
 	// 标准化路径：处理尾部斜杠
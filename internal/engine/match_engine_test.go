@@ -501,7 +501,7 @@ func TestMatchRule(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name: "脚本匹配类型(未实现)",
+			name: "脚本匹配类型(缺少脚本)",
 			request: &adapter.Request{
 				Protocol: models.ProtocolHTTP,
 				Path:     "/api/test",
@@ -726,13 +726,13 @@ func TestSimpleMatch_EdgeCases(t *testing.T) {
 			hasError: false,
 		},
 		{
-			name: "非HTTP协议",
+			name: "不支持匹配条件的协议",
 			request: &adapter.Request{
-				Protocol: models.ProtocolWebSocket,
-				Path:     "/ws",
+				Protocol: models.ProtocolTCP,
+				Path:     "/tcp",
 			},
 			rule: &models.Rule{
-				Protocol:  models.ProtocolWebSocket,
+				Protocol:  models.ProtocolTCP,
 				MatchType: models.MatchTypeSimple,
 			},
 			expected: false,
@@ -754,3 +754,100 @@ func TestSimpleMatch_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// MockEnvironmentRepository Mock 环境仓库
+type MockEnvironmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockEnvironmentRepository) Create(ctx context.Context, environment *models.Environment) error {
+	args := m.Called(ctx, environment)
+	return args.Error(0)
+}
+
+func (m *MockEnvironmentRepository) Update(ctx context.Context, environment *models.Environment) error {
+	args := m.Called(ctx, environment)
+	return args.Error(0)
+}
+
+func (m *MockEnvironmentRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEnvironmentRepository) FindByID(ctx context.Context, id string) (*models.Environment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Environment), args.Error(1)
+}
+
+func (m *MockEnvironmentRepository) FindByProject(ctx context.Context, projectID string) ([]*models.Environment, error) {
+	args := m.Called(ctx, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Environment), args.Error(1)
+}
+
+// TestMatch_ScriptRule 测试脚本规则通过 Match 生效并读取环境变量
+func TestMatch_ScriptRule(t *testing.T) {
+	mockRepo := new(MockRuleRepository)
+	mockEnvRepo := new(MockEnvironmentRepository)
+
+	scriptRule := &models.Rule{
+		ID:            "rule-script",
+		Name:          "VIP Rule",
+		EnvironmentID: "env-1",
+		Protocol:      models.ProtocolHTTP,
+		MatchType:     models.MatchTypeScript,
+		Priority:      100,
+		Enabled:       true,
+		MatchCondition: map[string]interface{}{
+			"script": `request.method === "POST" && request.json.level >= env.vip_level`,
+		},
+	}
+	fallbackRule := &models.Rule{
+		ID:        "rule-fallback",
+		Name:      "Fallback Rule",
+		Protocol:  models.ProtocolHTTP,
+		MatchType: models.MatchTypeSimple,
+		Priority:  10,
+		Enabled:   true,
+		MatchCondition: map[string]interface{}{
+			"path": "/api/users",
+		},
+	}
+
+	mockRepo.On("FindEnabledByEnvironment", mock.Anything, "project-1", "env-1").
+		Return([]*models.Rule{scriptRule, fallbackRule}, nil)
+	mockEnvRepo.On("FindByID", mock.Anything, "env-1").
+		Return(&models.Environment{ID: "env-1", Variables: map[string]interface{}{"vip_level": 3}}, nil).Once()
+
+	engine := NewMatchEngine(mockRepo)
+	engine.SetEnvironmentRepository(mockEnvRepo)
+
+	vipRequest := &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/users",
+		Body:     []byte(`{"level":5}`),
+		Metadata: map[string]interface{}{"method": "POST"},
+	}
+	rule, err := engine.Match(context.Background(), vipRequest, "project-1", "env-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "rule-script", rule.ID)
+
+	normalRequest := &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/users",
+		Body:     []byte(`{"level":1}`),
+		Metadata: map[string]interface{}{"method": "POST"},
+	}
+	rule, err = engine.Match(context.Background(), normalRequest, "project-1", "env-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "rule-fallback", rule.ID)
+
+	// 环境变量在有效期内只加载一次
+	mockEnvRepo.AssertNumberOfCalls(t, "FindByID", 1)
+}
@@ -0,0 +1,321 @@
+package engine
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// DefaultRuleIndexTTL 规则索引最长有效期，变更通知丢失时兜底重建
+const DefaultRuleIndexTTL = time.Minute
+
+// compiledRule 预编译的规则：匹配条件只解析一次，正则与请求体条件提前编译
+type compiledRule struct {
+	rule      *models.Rule
+	seq       int                        // 加入索引的顺序，优先级相同时保持加载顺序
+	condition *models.HTTPMatchCondition // 脚本匹配规则为 nil
+	regexes   map[string]*regexp.Regexp  // 正则匹配规则的 pattern -> 已编译正则
+	body      *compiledBody              // 预编译的请求体条件，未配置 body 时为 nil
+	websocket *websocketCondition        // WebSocket 规则的事件与消息内容条件
+	err       error                      // 编译错误，匹配时跳过该规则
+	nodes     []*pathTrieNode            // 规则所在的前缀树节点，用于增量删除
+}
+
+// compileRule 编译规则
+func compileRule(rule *models.Rule, seq int) *compiledRule {
+	cr := &compiledRule{rule: rule, seq: seq}
+	if rule.MatchType != models.MatchTypeSimple && rule.MatchType != models.MatchTypeRegex {
+		return cr
+	}
+
+	condition, err := parseMatchCondition(rule)
+	if err != nil {
+		cr.err = err
+		return cr
+	}
+	cr.condition = condition
+
+	if rule.Protocol == models.ProtocolWebSocket {
+		websocket, err := compileWebSocketCondition(rule)
+		if err != nil {
+			cr.err = err
+			return cr
+		}
+		cr.websocket = websocket
+	}
+
+	if len(condition.Body) > 0 {
+		body, err := compileBodyCondition(condition.Body)
+		if err != nil {
+			cr.err = err
+			return cr
+		}
+		cr.body = body
+	}
+
+	if rule.MatchType == models.MatchTypeRegex {
+		// 编译失败的 pattern 不放入 map，匹配时回退到按需编译并记录警告
+		cr.regexes = make(map[string]*regexp.Regexp)
+		patterns := []string{condition.PathRegex}
+		for _, pattern := range condition.Query {
+			patterns = append(patterns, pattern)
+		}
+		for _, pattern := range condition.Headers {
+			patterns = append(patterns, pattern)
+		}
+		for _, pattern := range patterns {
+			if pattern == "" {
+				continue
+			}
+			if re, err := regexp.Compile(pattern); err == nil {
+				cr.regexes[pattern] = re
+			}
+		}
+	}
+	return cr
+}
+
+// indexPath 返回用于前缀树索引的路径，无法按路径索引时返回 false
+func (cr *compiledRule) indexPath() (string, bool) {
+	if cr.err != nil || cr.condition == nil || cr.rule.Protocol != models.ProtocolHTTP {
+		return "", false
+	}
+	if cr.rule.MatchType == models.MatchTypeRegex && cr.condition.PathRegex != "" {
+		return "", false
+	}
+	if cr.condition.Path == "" {
+		return "", false
+	}
+	return cr.condition.Path, true
+}
+
+// indexMethods 返回用于索引的请求方法，nil 表示匹配任意方法
+func (cr *compiledRule) indexMethods() ([]string, bool) {
+	switch v := cr.condition.Method.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return []string{strings.ToUpper(v)}, true
+	case []interface{}:
+		methods := make([]string, 0, len(v))
+		for _, m := range v {
+			if method, ok := m.(string); ok {
+				methods = append(methods, strings.ToUpper(method))
+			}
+		}
+		return methods, true
+	default:
+		// 无法识别的方法条件永远不会匹配
+		return nil, false
+	}
+}
+
+// pathTrieNode 路径前缀树节点，按路径段索引，:param 段匹配任意值
+type pathTrieNode struct {
+	static map[string]*pathTrieNode
+	param  *pathTrieNode
+	rules  []*compiledRule
+}
+
+// splitPath 按 matchPath 的规则标准化并切分路径
+func splitPath(path string) []string {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return strings.Split(path, "/")
+}
+
+// insert 插入规则
+func (n *pathTrieNode) insert(segments []string, cr *compiledRule) {
+	node := n
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			if node.param == nil {
+				node.param = &pathTrieNode{}
+			}
+			node = node.param
+			continue
+		}
+		if node.static == nil {
+			node.static = make(map[string]*pathTrieNode)
+		}
+		child, exists := node.static[segment]
+		if !exists {
+			child = &pathTrieNode{}
+			node.static[segment] = child
+		}
+		node = child
+	}
+	node.rules = append(node.rules, cr)
+	cr.nodes = append(cr.nodes, node)
+}
+
+// collect 收集路径可能匹配的规则
+func (n *pathTrieNode) collect(segments []string, out []*compiledRule) []*compiledRule {
+	if len(segments) == 0 {
+		return append(out, n.rules...)
+	}
+	if child, exists := n.static[segments[0]]; exists {
+		out = child.collect(segments[1:], out)
+	}
+	if n.param != nil {
+		out = n.param.collect(segments[1:], out)
+	}
+	return out
+}
+
+// remove 从节点中移除规则
+func (n *pathTrieNode) remove(ruleID string) {
+	kept := n.rules[:0]
+	for _, cr := range n.rules {
+		if cr.rule.ID != ruleID {
+			kept = append(kept, cr)
+		}
+	}
+	for i := len(kept); i < len(n.rules); i++ {
+		n.rules[i] = nil
+	}
+	n.rules = kept
+}
+
+// ruleIndex 单个环境的规则索引：方法 + 路径前缀树，无法按路径索引的规则每次都参与匹配
+type ruleIndex struct {
+	mu        sync.RWMutex
+	methods   map[string]*pathTrieNode // 大写方法 -> 前缀树
+	anyMethod *pathTrieNode            // 未限制方法的规则
+	unindexed []*compiledRule          // 路径正则、脚本匹配、非 HTTP 等规则
+	byID      map[string]*compiledRule
+	nextSeq   int
+	builtAt   time.Time
+}
+
+// newRuleIndex 根据按优先级排序的规则构建索引
+func newRuleIndex(rules []*models.Rule) *ruleIndex {
+	idx := &ruleIndex{
+		methods:   make(map[string]*pathTrieNode),
+		anyMethod: &pathTrieNode{},
+		byID:      make(map[string]*compiledRule, len(rules)),
+		builtAt:   time.Now(),
+	}
+	for _, rule := range rules {
+		idx.add(rule)
+	}
+	return idx
+}
+
+// add 添加规则（调用方需持有写锁或独占索引）
+func (idx *ruleIndex) add(rule *models.Rule) {
+	idx.insert(rule, idx.nextSeq)
+	idx.nextSeq++
+}
+
+// insert 以指定的加入顺序添加规则（调用方需持有写锁或独占索引）
+func (idx *ruleIndex) insert(rule *models.Rule, seq int) {
+	cr := compileRule(rule, seq)
+	if rule.ID != "" {
+		idx.byID[rule.ID] = cr
+	}
+
+	path, ok := cr.indexPath()
+	if !ok {
+		idx.unindexed = append(idx.unindexed, cr)
+		return
+	}
+	methods, ok := cr.indexMethods()
+	if !ok {
+		return
+	}
+
+	segments := splitPath(path)
+	if methods == nil {
+		idx.anyMethod.insert(segments, cr)
+		return
+	}
+	for _, method := range methods {
+		root, exists := idx.methods[method]
+		if !exists {
+			root = &pathTrieNode{}
+			idx.methods[method] = root
+		}
+		root.insert(segments, cr)
+	}
+}
+
+// remove 移除规则（调用方需持有写锁）
+func (idx *ruleIndex) remove(ruleID string) bool {
+	cr, exists := idx.byID[ruleID]
+	if !exists {
+		return false
+	}
+	delete(idx.byID, ruleID)
+	for _, node := range cr.nodes {
+		node.remove(ruleID)
+	}
+
+	kept := idx.unindexed[:0]
+	for _, item := range idx.unindexed {
+		if item != cr {
+			kept = append(kept, item)
+		}
+	}
+	idx.unindexed = kept
+	return true
+}
+
+// upsert 增量更新规则，禁用的规则只移除；已在索引中的规则保留原加入顺序，
+// 避免编辑后在同优先级规则中的匹配顺序改变
+func (idx *ruleIndex) upsert(rule *models.Rule) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	existing, exists := idx.byID[rule.ID]
+	idx.remove(rule.ID)
+	if !rule.Enabled {
+		return
+	}
+	if exists {
+		idx.insert(rule, existing.seq)
+		return
+	}
+	idx.add(rule)
+}
+
+// delete 删除规则
+func (idx *ruleIndex) delete(ruleID string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.remove(ruleID)
+}
+
+// contains 检查规则是否在索引中
+func (idx *ruleIndex) contains(ruleID string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	_, exists := idx.byID[ruleID]
+	return exists
+}
+
+// candidates 返回可能匹配请求的规则，按优先级从高到低排序
+func (idx *ruleIndex) candidates(method, path string) []*compiledRule {
+	segments := splitPath(path)
+
+	idx.mu.RLock()
+	out := make([]*compiledRule, 0, len(idx.unindexed)+4)
+	out = append(out, idx.unindexed...)
+	if root, exists := idx.methods[strings.ToUpper(method)]; exists {
+		out = root.collect(segments, out)
+	}
+	out = idx.anyMethod.collect(segments, out)
+	idx.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].rule.Priority != out[j].rule.Priority {
+			return out[i].rule.Priority > out[j].rule.Priority
+		}
+		return out[i].seq < out[j].seq
+	})
+	return out
+}
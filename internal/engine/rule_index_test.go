@@ -0,0 +1,402 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func indexRule(id string, priority int, matchType models.MatchType, condition map[string]interface{}) *models.Rule {
+	return &models.Rule{
+		ID:             id,
+		ProjectID:      "p1",
+		EnvironmentID:  "e1",
+		Protocol:       models.ProtocolHTTP,
+		MatchType:      matchType,
+		Priority:       priority,
+		Enabled:        true,
+		MatchCondition: condition,
+	}
+}
+
+func candidateIDs(candidates []*compiledRule) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, cr := range candidates {
+		ids = append(ids, cr.rule.ID)
+	}
+	return ids
+}
+
+func TestRuleIndex_Candidates(t *testing.T) {
+	idx := newRuleIndex([]*models.Rule{
+		indexRule("users-get", 100, models.MatchTypeSimple, map[string]interface{}{"method": "GET", "path": "/api/users"}),
+		indexRule("user-by-id", 90, models.MatchTypeSimple, map[string]interface{}{"method": []interface{}{"get", "PUT"}, "path": "/api/users/:id"}),
+		indexRule("any-method", 80, models.MatchTypeSimple, map[string]interface{}{"path": "/api/users/"}),
+		indexRule("regex-path", 70, models.MatchTypeRegex, map[string]interface{}{"path_regex": "^/api/.*"}),
+		indexRule("regex-exact", 60, models.MatchTypeRegex, map[string]interface{}{"method": "GET", "path": "/api/orders"}),
+		indexRule("script", 50, models.MatchTypeScript, map[string]interface{}{"script": "true"}),
+		indexRule("no-path", 40, models.MatchTypeSimple, map[string]interface{}{"method": "DELETE"}),
+		indexRule("root", 30, models.MatchTypeSimple, map[string]interface{}{"path": "/"}),
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		expected []string
+	}{
+		{"GET", "/api/users", []string{"users-get", "any-method", "regex-path", "script", "no-path"}},
+		{"get", "/api/users/", []string{"users-get", "any-method", "regex-path", "script", "no-path"}},
+		{"PUT", "/api/users/42", []string{"user-by-id", "regex-path", "script", "no-path"}},
+		{"POST", "/api/users/42", []string{"regex-path", "script", "no-path"}},
+		{"GET", "/api/orders", []string{"regex-path", "regex-exact", "script", "no-path"}},
+		{"GET", "/", []string{"regex-path", "script", "no-path", "root"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, candidateIDs(idx.candidates(tt.method, tt.path)))
+		})
+	}
+}
+
+func TestRuleIndex_UpsertAndDelete(t *testing.T) {
+	idx := newRuleIndex([]*models.Rule{
+		indexRule("a", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"}),
+		indexRule("b", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"}),
+	})
+	assert.Equal(t, []string{"a", "b"}, candidateIDs(idx.candidates("GET", "/a")))
+
+	// 提高优先级并移动路径
+	idx.upsert(indexRule("b", 60, models.MatchTypeSimple, map[string]interface{}{"path": "/a/:id"}))
+	assert.Equal(t, []string{"a"}, candidateIDs(idx.candidates("GET", "/a")))
+	assert.Equal(t, []string{"b"}, candidateIDs(idx.candidates("GET", "/a/1")))
+
+	// 新增同优先级规则排在已有规则之后
+	idx.upsert(indexRule("c", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"}))
+	assert.Equal(t, []string{"a", "c"}, candidateIDs(idx.candidates("GET", "/a")))
+
+	// 禁用即移除
+	disabled := indexRule("a", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"})
+	disabled.Enabled = false
+	idx.upsert(disabled)
+	assert.Equal(t, []string{"c"}, candidateIDs(idx.candidates("GET", "/a")))
+
+	assert.True(t, idx.delete("c"))
+	assert.False(t, idx.delete("c"))
+	assert.Empty(t, idx.candidates("GET", "/a"))
+}
+
+func TestRuleIndex_UpsertKeepsOrder(t *testing.T) {
+	idx := newRuleIndex([]*models.Rule{
+		indexRule("a", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"}),
+		indexRule("b", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"}),
+		indexRule("c", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"}),
+		indexRule("d", 50, models.MatchTypeScript, map[string]interface{}{"script": "true"}),
+	})
+	expected := []string{"a", "b", "c", "d"}
+	assert.Equal(t, expected, candidateIDs(idx.candidates("GET", "/a")))
+
+	// 编辑同优先级规则（包括改为按方法索引和脚本规则）后匹配顺序不变
+	idx.upsert(indexRule("a", 50, models.MatchTypeSimple, map[string]interface{}{"method": "GET", "path": "/a"}))
+	idx.upsert(indexRule("b", 50, models.MatchTypeRegex, map[string]interface{}{"path_regex": "^/a$"}))
+	idx.upsert(indexRule("d", 50, models.MatchTypeScript, map[string]interface{}{"script": "request.path === '/a'"}))
+	assert.Equal(t, expected, candidateIDs(idx.candidates("GET", "/a")))
+
+	// 重新启用的规则视为新增，排在同优先级规则之后
+	disabled := indexRule("a", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"})
+	disabled.Enabled = false
+	idx.upsert(disabled)
+	idx.upsert(indexRule("a", 50, models.MatchTypeSimple, map[string]interface{}{"path": "/a"}))
+	assert.Equal(t, []string{"b", "c", "d", "a"}, candidateIDs(idx.candidates("GET", "/a")))
+}
+
+func TestRuleIndex_EquivalentToMatchPath(t *testing.T) {
+	conditionPaths := []string{"/", "/a", "/a/", "/a/:id", "/a/:id/b", "/:x/:y", "a/b", "/a//b"}
+	requestPaths := []string{"/", "/a", "/a/", "/a/1", "/a/1/b", "/a/1/c", "/x/y", "a/b", "/a//b", "/a/b", ""}
+
+	for _, conditionPath := range conditionPaths {
+		idx := newRuleIndex([]*models.Rule{indexRule("r", 1, models.MatchTypeSimple, map[string]interface{}{"path": conditionPath})})
+		for _, requestPath := range requestPaths {
+			indexed := len(idx.candidates("GET", requestPath)) > 0
+			assert.Equal(t, matchPath(requestPath, conditionPath), indexed, "condition %q request %q", conditionPath, requestPath)
+		}
+	}
+}
+
+func TestMatchEngine_RuleIndexCaching(t *testing.T) {
+	mockRepo := new(MockRuleRepository)
+	mockRepo.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").Return([]*models.Rule{
+		indexRule("users", 100, models.MatchTypeSimple, map[string]interface{}{"method": "GET", "path": "/users"}),
+	}, nil).Once()
+
+	engine := NewMatchEngine(mockRepo)
+	ctx := context.Background()
+	request := &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/users", Metadata: map[string]interface{}{"method": "GET"}}
+
+	rule, err := engine.Match(ctx, request, "p1", "e1")
+	require.NoError(t, err)
+	assert.Equal(t, "users", rule.ID)
+
+	// 规则变更通知增量更新索引，无需重新加载
+	updated := indexRule("users-v2", 200, models.MatchTypeSimple, map[string]interface{}{"method": "GET", "path": "/users"})
+	engine.RuleSaved(ctx, updated)
+	rule, err = engine.Match(ctx, request, "p1", "e1")
+	require.NoError(t, err)
+	assert.Equal(t, "users-v2", rule.ID)
+
+	// 移动到其他环境的规则从原索引移除
+	moved := *updated
+	moved.EnvironmentID = "e2"
+	engine.RuleSaved(ctx, &moved)
+	rule, _ = engine.Match(ctx, request, "p1", "e1")
+	assert.Equal(t, "users", rule.ID)
+
+	engine.RuleDeleted(ctx, &models.Rule{ID: "users"})
+	rule, err = engine.Match(ctx, request, "p1", "e1")
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+	mockRepo.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 1)
+
+	// 失效后重新加载
+	mockRepo.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").Return([]*models.Rule{}, nil).Once()
+	engine.InvalidateRules("p1", "e1")
+	_, err = engine.Match(ctx, request, "p1", "e1")
+	require.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 2)
+}
+
+// benchmarkRules 生成 n 条规则，只有最后一条能匹配基准请求
+func benchmarkRules(n int) []*models.Rule {
+	rules := make([]*models.Rule, 0, n)
+	for i := 0; i < n-1; i++ {
+		matchType := models.MatchTypeSimple
+		condition := map[string]interface{}{
+			"method": []interface{}{"GET", "POST"},
+			"path":   fmt.Sprintf("/api/v1/resource%d/:id", i),
+		}
+		if i%10 == 0 {
+			matchType = models.MatchTypeRegex
+			condition["headers"] = map[string]interface{}{"X-Tenant": "^tenant-[0-9]+$"}
+		}
+		rules = append(rules, indexRule(fmt.Sprintf("r%d", i), n-i, matchType, condition))
+	}
+	return append(rules, indexRule("target", 0, models.MatchTypeSimple, map[string]interface{}{
+		"method": "GET",
+		"path":   "/api/v1/target/:id",
+		"query":  map[string]interface{}{"page": "1"},
+	}))
+}
+
+func benchmarkRequest() *adapter.Request {
+	return &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/v1/target/42",
+		Headers:  map[string]string{"X-Tenant": "tenant-1"},
+		Metadata: map[string]interface{}{"method": "GET", "query": map[string]string{"page": "1"}},
+	}
+}
+
+// BenchmarkMatch_LinearScan 原有方式：逐条解析匹配条件并匹配所有规则（不含数据库加载耗时）
+func BenchmarkMatch_LinearScan(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("rules=%d", n), func(b *testing.B) {
+			engine := NewMatchEngine(new(MockRuleRepository))
+			rules := benchmarkRules(n)
+			request := benchmarkRequest()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var matched *models.Rule
+				for _, rule := range rules {
+					if ok, _ := engine.matchRule(request, rule); ok {
+						matched = rule
+						break
+					}
+				}
+				if matched == nil || matched.ID != "target" {
+					b.Fatal("target rule not matched")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMatch_Indexed 规则索引：只匹配方法和路径可能命中的候选规则
+func BenchmarkMatch_Indexed(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("rules=%d", n), func(b *testing.B) {
+			mockRepo := new(MockRuleRepository)
+			mockRepo.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").Return(benchmarkRules(n), nil)
+			engine := NewMatchEngine(mockRepo)
+			request := benchmarkRequest()
+			ctx := context.Background()
+
+			// 预先构建索引，只统计匹配耗时
+			if _, err := engine.Match(ctx, request, "p1", "e1"); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rule, err := engine.Match(ctx, request, "p1", "e1")
+				if err != nil || rule == nil || rule.ID != "target" {
+					b.Fatal("target rule not matched")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRuleIndex_Build 构建规则索引
+func BenchmarkRuleIndex_Build(b *testing.B) {
+	rules := benchmarkRules(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newRuleIndex(rules)
+	}
+}
+
+// stubRuleLoader 固定返回规则的加载器
+type stubRuleLoader struct {
+	rules []*models.Rule
+	calls int
+}
+
+func (l *stubRuleLoader) GetEnabledRulesByEnvironment(ctx context.Context, projectID, environmentID string) ([]*models.Rule, error) {
+	l.calls++
+	return l.rules, nil
+}
+
+func TestMatchEngine_RuleLoader(t *testing.T) {
+	mockRepo := new(MockRuleRepository)
+	loader := &stubRuleLoader{rules: []*models.Rule{
+		indexRule("cached", 100, models.MatchTypeSimple, map[string]interface{}{"path": "/users"}),
+	}}
+
+	engine := NewMatchEngine(mockRepo)
+	engine.SetRuleLoader(loader)
+	request := &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/users", Metadata: map[string]interface{}{"method": "GET"}}
+
+	rule, err := engine.Match(context.Background(), request, "p1", "e1")
+	require.NoError(t, err)
+	assert.Equal(t, "cached", rule.ID)
+	assert.Equal(t, 1, loader.calls)
+	mockRepo.AssertNotCalled(t, "FindEnabledByEnvironment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// blockingRuleLoader 加载时阻塞直到 release 关闭，之后返回当前规则
+type blockingRuleLoader struct {
+	mu      sync.Mutex
+	rules   []*models.Rule
+	calls   int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (l *blockingRuleLoader) GetEnabledRulesByEnvironment(ctx context.Context, projectID, environmentID string) ([]*models.Rule, error) {
+	l.mu.Lock()
+	rules := l.rules
+	l.mu.Unlock()
+	if atomic.AddInt32(&l.calls, 1) == 1 {
+		close(l.started)
+		<-l.release
+	}
+	return rules, nil
+}
+
+func TestMatchEngine_RuleIndexRebuildSingleflight(t *testing.T) {
+	loader := &blockingRuleLoader{
+		rules:   []*models.Rule{indexRule("users", 100, models.MatchTypeSimple, map[string]interface{}{"path": "/users"})},
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	engine := NewMatchEngine(new(MockRuleRepository))
+	engine.SetRuleLoader(loader)
+	request := &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/users", Metadata: map[string]interface{}{"method": "GET"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rule, err := engine.Match(context.Background(), request, "p1", "e1")
+			assert.NoError(t, err)
+			if assert.NotNil(t, rule) {
+				assert.Equal(t, "users", rule.ID)
+			}
+		}()
+	}
+	<-loader.started
+	close(loader.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loader.calls))
+}
+
+func TestMatchEngine_RuleSavedDuringRebuild(t *testing.T) {
+	stale := indexRule("users", 100, models.MatchTypeSimple, map[string]interface{}{"path": "/users"})
+	loader := &blockingRuleLoader{
+		rules:   []*models.Rule{stale},
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	engine := NewMatchEngine(new(MockRuleRepository))
+	engine.SetRuleLoader(loader)
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = engine.Match(ctx, &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/users"}, "p1", "e1")
+	}()
+
+	// 第一次加载读到旧规则后，规则被修改为 /members
+	<-loader.started
+	updated := indexRule("users", 100, models.MatchTypeSimple, map[string]interface{}{"path": "/members"})
+	loader.mu.Lock()
+	loader.rules = []*models.Rule{updated}
+	loader.mu.Unlock()
+	engine.RuleSaved(ctx, updated)
+	close(loader.release)
+	<-done
+
+	// 重建检测到变更后重新加载，索引不会被旧数据覆盖
+	rule, err := engine.Match(ctx, &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/members"}, "p1", "e1")
+	require.NoError(t, err)
+	require.NotNil(t, rule)
+	assert.Equal(t, "users", rule.ID)
+
+	rule, err = engine.Match(ctx, &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/users"}, "p1", "e1")
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loader.calls))
+}
+
+func TestMatchEngine_BodyConditionCompiledOnce(t *testing.T) {
+	rule := indexRule("body", 100, models.MatchTypeSimple, map[string]interface{}{
+		"path": "/orders",
+		"body": map[string]interface{}{
+			"json_path": []interface{}{map[string]interface{}{"path": "$.total", "op": "gt", "value": 100}},
+		},
+	})
+	cr := compileRule(rule, 0)
+	require.NoError(t, cr.err)
+	require.NotNil(t, cr.body)
+	assert.Len(t, cr.body.jsonPaths, 1)
+
+	invalid := compileRule(indexRule("bad", 100, models.MatchTypeSimple, map[string]interface{}{
+		"path": "/orders",
+		"body": map[string]interface{}{
+			"json_path": []interface{}{map[string]interface{}{"path": "$.items[?(@.price > 1)]"}},
+		},
+	}), 1)
+	assert.Error(t, invalid.err)
+}
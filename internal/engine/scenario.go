@@ -0,0 +1,326 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// scenarioKey 场景状态键
+type scenarioKey struct {
+	projectID     string
+	environmentID string
+	scenario      string
+	clientID      string
+}
+
+const (
+	// DefaultScenarioStateCapacity 内存中最多保留的场景状态数（按客户端区分的状态各占一项）
+	DefaultScenarioStateCapacity = 10000
+	// DefaultScenarioStateIdleTTL 场景状态在内存中的最长闲置时间
+	DefaultScenarioStateIdleTTL = 24 * time.Hour
+	// scenarioPersistStripes 持久化锁分段数
+	scenarioPersistStripes = 64
+)
+
+// scenarioEntry 内存中的场景状态
+type scenarioEntry struct {
+	key      scenarioKey
+	state    *models.ScenarioState
+	lastUsed time.Time
+}
+
+// ScenarioStore 场景状态存储
+//
+// 状态以内存为快速路径，配置仓库时写穿到 MongoDB，
+// 内存未命中时从仓库加载，保证重启后状态不丢失。
+// 内存按 LRU 保留最多 DefaultScenarioStateCapacity 项，闲置超过 DefaultScenarioStateIdleTTL 的状态被移除；
+// 未配置仓库时，被移除的状态会回到 Started。
+type ScenarioStore struct {
+	repo     repository.ScenarioRepository
+	mu       sync.Mutex
+	states   map[scenarioKey]*list.Element
+	lru      *list.List
+	capacity int
+	idleTTL  time.Duration
+
+	// 同一状态的持久化按顺序执行，保证仓库中的状态与内存一致
+	persistLocks [scenarioPersistStripes]sync.Mutex
+}
+
+// NewScenarioStore 创建场景状态存储，repo 为 nil 时仅使用内存
+func NewScenarioStore(repo repository.ScenarioRepository) *ScenarioStore {
+	return &ScenarioStore{
+		repo:     repo,
+		states:   make(map[scenarioKey]*list.Element),
+		lru:      list.New(),
+		capacity: DefaultScenarioStateCapacity,
+		idleTTL:  DefaultScenarioStateIdleTTL,
+	}
+}
+
+// get 获取内存中的状态并标记为最近使用（调用方需持有 mu）
+func (s *ScenarioStore) get(key scenarioKey) (*models.ScenarioState, bool) {
+	element, exists := s.states[key]
+	if !exists {
+		return nil, false
+	}
+	entry := element.Value.(*scenarioEntry)
+	now := time.Now()
+	if now.Sub(entry.lastUsed) > s.idleTTL {
+		s.lru.Remove(element)
+		delete(s.states, key)
+		return nil, false
+	}
+	entry.lastUsed = now
+	s.lru.MoveToFront(element)
+	return entry.state, true
+}
+
+// put 写入内存状态，超出容量时移除最久未使用的状态（调用方需持有 mu）
+func (s *ScenarioStore) put(key scenarioKey, state *models.ScenarioState) {
+	if element, exists := s.states[key]; exists {
+		entry := element.Value.(*scenarioEntry)
+		entry.state = state
+		entry.lastUsed = time.Now()
+		s.lru.MoveToFront(element)
+		return
+	}
+
+	s.states[key] = s.lru.PushFront(&scenarioEntry{key: key, state: state, lastUsed: time.Now()})
+	for s.lru.Len() > s.capacity {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.states, oldest.Value.(*scenarioEntry).key)
+	}
+}
+
+// remove 移除内存状态（调用方需持有 mu）
+func (s *ScenarioStore) remove(key scenarioKey) {
+	if element, exists := s.states[key]; exists {
+		s.lru.Remove(element)
+		delete(s.states, key)
+	}
+}
+
+// persistLock 获取状态的持久化锁
+func (s *ScenarioStore) persistLock(key scenarioKey) *sync.Mutex {
+	h := fnv.New32a()
+	for _, part := range []string{key.projectID, key.environmentID, key.scenario, key.clientID} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return &s.persistLocks[h.Sum32()%scenarioPersistStripes]
+}
+
+// ScenarioClientID 根据规则场景配置从请求中提取客户端标识，未配置时返回空字符串
+func ScenarioClientID(request *adapter.Request, scenario *models.RuleScenario) string {
+	if scenario == nil {
+		return ""
+	}
+	if scenario.ClientHeader != "" {
+		if value := adapter.HeaderValue(request.Headers, scenario.ClientHeader); value != "" {
+			return value
+		}
+	}
+	if scenario.ClientCookie != "" {
+		cookies, err := http.ParseCookie(adapter.HeaderValue(request.Headers, "Cookie"))
+		if err == nil {
+			for _, cookie := range cookies {
+				if cookie.Name == scenario.ClientCookie {
+					return cookie.Value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// State 获取场景当前状态，未设置时返回 Started
+func (s *ScenarioStore) State(ctx context.Context, projectID, environmentID, scenario, clientID string) string {
+	key := scenarioKey{projectID, environmentID, scenario, clientID}
+
+	s.mu.Lock()
+	state, exists := s.get(key)
+	s.mu.Unlock()
+	if exists {
+		return state.State
+	}
+
+	return s.load(ctx, key).State
+}
+
+// load 从仓库加载状态并写入内存
+func (s *ScenarioStore) load(ctx context.Context, key scenarioKey) *models.ScenarioState {
+	state := &models.ScenarioState{
+		ProjectID:     key.projectID,
+		EnvironmentID: key.environmentID,
+		Scenario:      key.scenario,
+		ClientID:      key.clientID,
+		State:         models.ScenarioStateStarted,
+	}
+
+	if s.repo != nil {
+		stored, err := s.repo.GetState(ctx, key.projectID, key.environmentID, key.scenario, key.clientID)
+		if err != nil {
+			logger.Warn("failed to load scenario state",
+				zap.String("scenario", key.scenario),
+				zap.Error(err))
+		} else if stored != nil {
+			state = stored
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 并发加载时以先写入的为准
+	if existing, exists := s.get(key); exists {
+		return existing
+	}
+	s.put(key, state)
+	return state
+}
+
+// Advance 检查场景是否处于规则要求的状态，满足时切换到规则的新状态
+//
+// 检查与切换在同一把锁内完成，并发请求中只有一个能完成同一次状态迁移。
+func (s *ScenarioStore) Advance(ctx context.Context, projectID, environmentID string, scenario *models.RuleScenario, clientID string) bool {
+	key := scenarioKey{projectID, environmentID, scenario.Name, clientID}
+
+	s.mu.Lock()
+	current, exists := s.get(key)
+	if !exists {
+		// 加载期间不持有锁，加载后重新读取，状态可能已被其他请求切换
+		s.mu.Unlock()
+		loaded := s.load(ctx, key)
+		s.mu.Lock()
+		if current, exists = s.get(key); !exists {
+			// 刚加载的状态已被淘汰
+			current = loaded
+			s.put(key, current)
+		}
+	}
+
+	if scenario.RequiredState != "" && current.State != scenario.RequiredState {
+		s.mu.Unlock()
+		return false
+	}
+	if scenario.NewState == "" || scenario.NewState == current.State {
+		s.mu.Unlock()
+		return true
+	}
+	next := *current
+	next.State = scenario.NewState
+	next.UpdatedAt = time.Now()
+	s.put(key, &next)
+	s.mu.Unlock()
+
+	s.persist(ctx, key, &next)
+	return true
+}
+
+// SetState 直接设置场景状态
+func (s *ScenarioStore) SetState(ctx context.Context, state *models.ScenarioState) error {
+	key := scenarioKey{state.ProjectID, state.EnvironmentID, state.Scenario, state.ClientID}
+	state.UpdatedAt = time.Now()
+
+	lock := s.persistLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if s.repo != nil {
+		if err := s.repo.SetState(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	stored := *state
+	s.mu.Lock()
+	s.put(key, &stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// List 列出环境下所有场景状态
+func (s *ScenarioStore) List(ctx context.Context, projectID, environmentID string) ([]*models.ScenarioState, error) {
+	if s.repo != nil {
+		return s.repo.ListStates(ctx, projectID, environmentID)
+	}
+
+	s.mu.Lock()
+	states := make([]*models.ScenarioState, 0)
+	now := time.Now()
+	for key, element := range s.states {
+		entry := element.Value.(*scenarioEntry)
+		if key.projectID == projectID && key.environmentID == environmentID && now.Sub(entry.lastUsed) <= s.idleTTL {
+			copied := *entry.state
+			states = append(states, &copied)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Scenario != states[j].Scenario {
+			return states[i].Scenario < states[j].Scenario
+		}
+		return states[i].ClientID < states[j].ClientID
+	})
+	return states, nil
+}
+
+// Reset 将场景重置为初始状态，scenario 为空时重置环境下所有场景
+func (s *ScenarioStore) Reset(ctx context.Context, projectID, environmentID, scenario string) error {
+	s.mu.Lock()
+	for key := range s.states {
+		if key.projectID == projectID && key.environmentID == environmentID &&
+			(scenario == "" || key.scenario == scenario) {
+			s.remove(key)
+		}
+	}
+	s.mu.Unlock()
+
+	if s.repo != nil {
+		if _, err := s.repo.DeleteStates(ctx, projectID, environmentID, scenario); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persist 持久化状态，失败时仅记录日志（内存状态仍然有效）
+//
+// 同一状态的持久化串行执行，并写入持锁时内存中的最新状态，
+// 并发迁移不会因写入乱序让仓库停留在旧状态。
+func (s *ScenarioStore) persist(ctx context.Context, key scenarioKey, state *models.ScenarioState) {
+	if s.repo == nil {
+		return
+	}
+
+	lock := s.persistLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	stored := *state
+	s.mu.Lock()
+	if element, exists := s.states[key]; exists {
+		stored = *element.Value.(*scenarioEntry).state
+	}
+	s.mu.Unlock()
+
+	if err := s.repo.SetState(ctx, &stored); err != nil {
+		logger.Warn("failed to persist scenario state",
+			zap.String("scenario", stored.Scenario),
+			zap.String("state", stored.State),
+			zap.Error(err))
+	}
+}
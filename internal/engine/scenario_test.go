@@ -0,0 +1,237 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockScenarioRepository Mock 场景状态仓库
+type MockScenarioRepository struct {
+	mock.Mock
+}
+
+func (m *MockScenarioRepository) GetState(ctx context.Context, projectID, environmentID, scenario, clientID string) (*models.ScenarioState, error) {
+	args := m.Called(ctx, projectID, environmentID, scenario, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ScenarioState), args.Error(1)
+}
+
+func (m *MockScenarioRepository) SetState(ctx context.Context, state *models.ScenarioState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func (m *MockScenarioRepository) ListStates(ctx context.Context, projectID, environmentID string) ([]*models.ScenarioState, error) {
+	args := m.Called(ctx, projectID, environmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ScenarioState), args.Error(1)
+}
+
+func (m *MockScenarioRepository) DeleteStates(ctx context.Context, projectID, environmentID, scenario string) (int64, error) {
+	args := m.Called(ctx, projectID, environmentID, scenario)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestScenarioStore_AdvanceInMemory(t *testing.T) {
+	store := NewScenarioStore(nil)
+	ctx := context.Background()
+
+	assert.Equal(t, models.ScenarioStateStarted, store.State(ctx, "p", "e", "order", ""))
+
+	approve := &models.RuleScenario{Name: "order", RequiredState: models.ScenarioStateStarted, NewState: "approved"}
+	assert.True(t, store.Advance(ctx, "p", "e", approve, ""))
+	assert.Equal(t, "approved", store.State(ctx, "p", "e", "order", ""))
+
+	// 状态已变化，同一迁移不再满足
+	assert.False(t, store.Advance(ctx, "p", "e", approve, ""))
+
+	// 未要求状态的规则总是满足
+	assert.True(t, store.Advance(ctx, "p", "e", &models.RuleScenario{Name: "order"}, ""))
+
+	states, err := store.List(ctx, "p", "e")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "approved", states[0].State)
+
+	require.NoError(t, store.Reset(ctx, "p", "e", "order"))
+	assert.Equal(t, models.ScenarioStateStarted, store.State(ctx, "p", "e", "order", ""))
+}
+
+func TestScenarioStore_PerClientState(t *testing.T) {
+	store := NewScenarioStore(nil)
+	ctx := context.Background()
+	login := &models.RuleScenario{Name: "session", RequiredState: models.ScenarioStateStarted, NewState: "logged_in"}
+
+	assert.True(t, store.Advance(ctx, "p", "e", login, "alice"))
+	assert.Equal(t, "logged_in", store.State(ctx, "p", "e", "session", "alice"))
+	assert.Equal(t, models.ScenarioStateStarted, store.State(ctx, "p", "e", "session", "bob"))
+}
+
+func TestScenarioStore_WithRepository(t *testing.T) {
+	repo := new(MockScenarioRepository)
+	ctx := context.Background()
+
+	repo.On("GetState", mock.Anything, "p", "e", "order", "").
+		Return(&models.ScenarioState{ProjectID: "p", EnvironmentID: "e", Scenario: "order", State: "pending"}, nil).Once()
+	repo.On("SetState", mock.Anything, mock.MatchedBy(func(s *models.ScenarioState) bool {
+		return s.Scenario == "order" && s.State == "approved"
+	})).Return(nil).Once()
+	repo.On("DeleteStates", mock.Anything, "p", "e", "").Return(int64(1), nil).Once()
+
+	store := NewScenarioStore(repo)
+
+	// 首次从仓库加载，之后走内存
+	assert.Equal(t, "pending", store.State(ctx, "p", "e", "order", ""))
+	assert.True(t, store.Advance(ctx, "p", "e",
+		&models.RuleScenario{Name: "order", RequiredState: "pending", NewState: "approved"}, ""))
+	assert.Equal(t, "approved", store.State(ctx, "p", "e", "order", ""))
+
+	require.NoError(t, store.Reset(ctx, "p", "e", ""))
+	repo.AssertExpectations(t)
+}
+
+func TestScenarioStore_LoadErrorFallsBackToStarted(t *testing.T) {
+	repo := new(MockScenarioRepository)
+	repo.On("GetState", mock.Anything, "p", "e", "order", "").Return(nil, errors.New("db down"))
+
+	store := NewScenarioStore(repo)
+	assert.Equal(t, models.ScenarioStateStarted, store.State(context.Background(), "p", "e", "order", ""))
+}
+
+func TestScenarioClientID(t *testing.T) {
+	request := &adapter.Request{
+		Headers: map[string]string{
+			"X-Client-Id": "client-1",
+			"Cookie":      "theme=dark; session=abc123",
+		},
+	}
+
+	assert.Equal(t, "", ScenarioClientID(request, &models.RuleScenario{Name: "s"}))
+	assert.Equal(t, "client-1", ScenarioClientID(request, &models.RuleScenario{Name: "s", ClientHeader: "x-client-id"}))
+	assert.Equal(t, "abc123", ScenarioClientID(request, &models.RuleScenario{Name: "s", ClientCookie: "session"}))
+	assert.Equal(t, "", ScenarioClientID(request, &models.RuleScenario{Name: "s", ClientCookie: "missing"}))
+}
+
+func TestMatch_ScenarioFlow(t *testing.T) {
+	mockRepo := new(MockRuleRepository)
+
+	approvedRule := &models.Rule{
+		ID:             "rule-approved",
+		Protocol:       models.ProtocolHTTP,
+		MatchType:      models.MatchTypeSimple,
+		Priority:       100,
+		Enabled:        true,
+		MatchCondition: map[string]interface{}{"method": "GET", "path": "/orders/1"},
+		Scenario:       &models.RuleScenario{Name: "order", RequiredState: "approved"},
+	}
+	pendingRule := &models.Rule{
+		ID:             "rule-pending",
+		Protocol:       models.ProtocolHTTP,
+		MatchType:      models.MatchTypeSimple,
+		Priority:       90,
+		Enabled:        true,
+		MatchCondition: map[string]interface{}{"method": "GET", "path": "/orders/1"},
+		Scenario:       &models.RuleScenario{Name: "order", RequiredState: models.ScenarioStateStarted},
+	}
+	approveRule := &models.Rule{
+		ID:             "rule-approve",
+		Protocol:       models.ProtocolHTTP,
+		MatchType:      models.MatchTypeSimple,
+		Priority:       80,
+		Enabled:        true,
+		MatchCondition: map[string]interface{}{"method": "POST", "path": "/orders/1/approve"},
+		Scenario:       &models.RuleScenario{Name: "order", RequiredState: models.ScenarioStateStarted, NewState: "approved"},
+	}
+
+	mockRepo.On("FindEnabledByEnvironment", mock.Anything, "project-1", "env-1").
+		Return([]*models.Rule{approvedRule, pendingRule, approveRule}, nil)
+
+	engine := NewMatchEngine(mockRepo)
+	ctx := context.Background()
+
+	get := &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/orders/1", Metadata: map[string]interface{}{"method": "GET"}}
+	post := &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/orders/1/approve", Metadata: map[string]interface{}{"method": "POST"}}
+
+	rule, err := engine.Match(ctx, get, "project-1", "env-1")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-pending", rule.ID)
+
+	rule, err = engine.Match(ctx, post, "project-1", "env-1")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-approve", rule.ID)
+
+	rule, err = engine.Match(ctx, get, "project-1", "env-1")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-approved", rule.ID)
+
+	// 第二次审批不再满足场景状态
+	rule, err = engine.Match(ctx, post, "project-1", "env-1")
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+
+	require.NoError(t, engine.Scenarios().Reset(ctx, "project-1", "env-1", "order"))
+	rule, err = engine.Match(ctx, get, "project-1", "env-1")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-pending", rule.ID)
+}
+
+func TestScenarioStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewScenarioStore(nil)
+	store.capacity = 2
+	ctx := context.Background()
+	login := &models.RuleScenario{Name: "session", RequiredState: models.ScenarioStateStarted, NewState: "logged_in"}
+
+	assert.True(t, store.Advance(ctx, "p", "e", login, "alice"))
+	assert.True(t, store.Advance(ctx, "p", "e", login, "bob"))
+	assert.Equal(t, "logged_in", store.State(ctx, "p", "e", "session", "alice"))
+	assert.True(t, store.Advance(ctx, "p", "e", login, "carol"))
+
+	// bob 最久未使用，被移除后回到初始状态
+	assert.Len(t, store.states, 2)
+	assert.Equal(t, models.ScenarioStateStarted, store.State(ctx, "p", "e", "session", "bob"))
+	assert.Equal(t, "logged_in", store.State(ctx, "p", "e", "session", "carol"))
+}
+
+func TestScenarioStore_IdleStatesExpire(t *testing.T) {
+	store := NewScenarioStore(nil)
+	store.idleTTL = -1
+	ctx := context.Background()
+
+	assert.True(t, store.Advance(ctx, "p", "e",
+		&models.RuleScenario{Name: "order", RequiredState: models.ScenarioStateStarted, NewState: "approved"}, ""))
+	assert.Equal(t, models.ScenarioStateStarted, store.State(ctx, "p", "e", "order", ""))
+	states, err := store.List(ctx, "p", "e")
+	require.NoError(t, err)
+	assert.Empty(t, states)
+}
+
+func TestScenarioStore_PersistWritesLatestState(t *testing.T) {
+	repo := new(MockScenarioRepository)
+	ctx := context.Background()
+	store := NewScenarioStore(repo)
+
+	repo.On("GetState", mock.Anything, "p", "e", "order", "").Return(nil, nil).Once()
+	var persisted []string
+	repo.On("SetState", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		persisted = append(persisted, args.Get(1).(*models.ScenarioState).State)
+	})
+
+	assert.True(t, store.Advance(ctx, "p", "e",
+		&models.RuleScenario{Name: "order", RequiredState: models.ScenarioStateStarted, NewState: "approved"}, ""))
+
+	// 模拟较早的迁移在较新的迁移之后才持久化：写入的仍是内存中的最新状态
+	key := scenarioKey{"p", "e", "order", ""}
+	store.persist(ctx, key, &models.ScenarioState{ProjectID: "p", EnvironmentID: "e", Scenario: "order", State: models.ScenarioStateStarted})
+	assert.Equal(t, []string{"approved", "approved"}, persisted)
+}
@@ -1,7 +1,7 @@
 package engine
 
 import (
-	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -20,6 +20,9 @@ type ScriptEngine struct {
 	maxMemory        int64
 	// 审计日志
 	auditLog bool
+	// 运行时池与已编译脚本缓存
+	vmPool   *VMPool
+	programs *ProgramCache
 }
 
 // ScriptMatchConfig 脚本匹配配置
@@ -40,11 +43,18 @@ func NewScriptEngine() *ScriptEngine {
 		maxExecutionTime: 5 * time.Second,  // 默认最大执行时间 5 秒
 		maxMemory:        10 * 1024 * 1024, // 默认最大内存 10MB
 		auditLog:         true,
+		vmPool:           NewVMPool(),
+		programs:         NewProgramCache(500), // 默认缓存500个已编译脚本
 	}
 }
 
 // Match 执行脚本匹配
 func (e *ScriptEngine) Match(request *adapter.Request, rule *models.Rule) (bool, error) {
+	return e.MatchWithEnv(request, rule, nil)
+}
+
+// MatchWithEnv 执行脚本匹配，并向脚本注入环境变量
+func (e *ScriptEngine) MatchWithEnv(request *adapter.Request, rule *models.Rule, env map[string]interface{}) (bool, error) {
 	// 检查规则类型
 	if rule.MatchType != models.MatchTypeScript {
 		return false, errors.New("not a script match rule")
@@ -56,11 +66,15 @@ func (e *ScriptEngine) Match(request *adapter.Request, rule *models.Rule) (bool,
 		return false, errors.New("script not found in match condition")
 	}
 
+	if env == nil {
+		env = make(map[string]interface{})
+	}
+
 	// 创建脚本上下文
 	ctx := &ScriptContext{
 		Request: request,
 		Rule:    rule,
-		Env:     make(map[string]interface{}),
+		Env:     env,
 	}
 
 	// 执行脚本
@@ -84,36 +98,31 @@ func (e *ScriptEngine) Match(request *adapter.Request, rule *models.Rule) (bool,
 
 // executeScript 执行 JavaScript 脚本
 func (e *ScriptEngine) executeScript(script string, ctx *ScriptContext) (bool, error) {
-	// 创建超时上下文
-	execCtx, cancel := context.WithTimeout(context.Background(), e.maxExecutionTime)
-	defer cancel()
-
-	// 创建 goja 运行时
-	vm := goja.New()
-
-	// 设置中断处理
-	done := make(chan struct{})
-	defer close(done)
-
-	go func() {
-		select {
-		case <-execCtx.Done():
-			vm.Interrupt("execution timeout")
-		case <-done:
-		}
-	}()
+	// 编译脚本（命中缓存时复用）
+	program, err := e.programs.Compile(script)
+	if err != nil {
+		logger.Error("script compile error",
+			zap.String("script", script),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("script compile failed: %w", err)
+	}
+
+	// 从池中获取运行时，归还时清除脚本新增的全局属性
+	vm := e.vmPool.Get(program)
+	defer e.vmPool.Put(vm)
 
 	// 注入安全 API
-	e.injectSecureAPI(vm, ctx)
+	InjectSecureAPI(vm.Runtime, ctx)
 
 	// 执行脚本
-	result, err := vm.RunString(script)
+	result, err := RunProgram(vm.Runtime, program.Program, e.maxExecutionTime)
 	if err != nil {
 		logger.Error("script execution error",
 			zap.String("script", script),
 			zap.Error(err),
 		)
-		return false, fmt.Errorf("script execution failed: %w", err)
+		return false, err
 	}
 
 	// 转换结果为布尔值
@@ -125,18 +134,13 @@ func (e *ScriptEngine) executeScript(script string, ctx *ScriptContext) (bool, e
 	return matched, nil
 }
 
-// injectSecureAPI 注入安全的 API 到脚本环境
-func (e *ScriptEngine) injectSecureAPI(vm *goja.Runtime, ctx *ScriptContext) {
+// InjectSecureAPI 注入安全的 API 到脚本环境（匹配脚本与响应脚本共用）
+func InjectSecureAPI(vm *goja.Runtime, ctx *ScriptContext) {
 	// 注入 request 对象
-	vm.Set("request", map[string]interface{}{
-		"id":       ctx.Request.ID,
-		"protocol": string(ctx.Request.Protocol),
-		"path":     ctx.Request.Path,
-		"headers":  ctx.Request.Headers,
-		"body":     string(ctx.Request.Body),
-		"sourceIP": ctx.Request.SourceIP,
-		"metadata": ctx.Request.Metadata,
-	})
+	vm.Set("request", BuildScriptRequest(ctx.Request))
+
+	// 注入环境变量副本，脚本修改不会影响缓存中的环境变量
+	vm.Set("env", copyScriptValue(ctx.Env))
 
 	// 注入 rule 对象（只读）
 	vm.Set("rule", map[string]interface{}{
@@ -188,6 +192,80 @@ func (e *ScriptEngine) injectSecureAPI(vm *goja.Runtime, ctx *ScriptContext) {
 	vm.Set("Function", goja.Undefined())
 }
 
+// BuildScriptRequest 构建注入脚本的 request 对象
+func BuildScriptRequest(request *adapter.Request) map[string]interface{} {
+	method := ""
+	query := make(map[string]string)
+	if request.Metadata != nil {
+		if m, ok := request.Metadata["method"].(string); ok {
+			method = m
+		}
+		if q, ok := request.Metadata["query"].(map[string]string); ok {
+			query = q
+		}
+	}
+
+	// 尝试将请求体解析为 JSON，解析失败时为 null
+	var jsonBody interface{}
+	if len(request.Body) > 0 {
+		if err := json.Unmarshal(request.Body, &jsonBody); err != nil {
+			jsonBody = nil
+		}
+	}
+
+	// 表单请求体（urlencoded/multipart）
+	var form map[string]string
+	if formData, err := adapter.GetForm(request); err == nil && formData != nil {
+		form = formData.FirstValues()
+	}
+
+	return map[string]interface{}{
+		"id":       request.ID,
+		"protocol": string(request.Protocol),
+		"method":   method,
+		"path":     request.Path,
+		"query":    copyScriptValue(query),
+		"headers":  copyScriptValue(request.Headers),
+		"body":     string(request.Body),
+		"json":     jsonBody,
+		"form":     form,
+		"sourceIP": request.SourceIP,
+		"metadata": copyScriptValue(request.Metadata),
+	}
+}
+
+// copyScriptValue 深拷贝注入脚本的 map/slice，避免脚本修改共享的 Go 数据
+func copyScriptValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyScriptValue(item)
+		}
+		return copied
+	case map[string]string:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = item
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyScriptValue(item)
+		}
+		return copied
+	case []string:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = item
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
 // contains 检查字符串是否包含子串
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
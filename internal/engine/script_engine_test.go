@@ -269,3 +269,186 @@ func TestScriptEngine_Match_MissingScript(t *testing.T) {
 	assert.Contains(t, err.Error(), "script not found")
 	assert.False(t, matched)
 }
+
+func TestScriptEngine_MatchWithEnv_RequestAPI(t *testing.T) {
+	engine := NewScriptEngine()
+
+	request := &adapter.Request{
+		ID:       "test-123",
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/orders",
+		Body:     []byte(`{"amount":150,"currency":"CNY"}`),
+		Metadata: map[string]interface{}{
+			"method": "POST",
+			"query": map[string]string{
+				"channel": "app",
+			},
+		},
+	}
+
+	rule := &models.Rule{
+		ID:        "rule-11",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `request.method === "POST" &&
+				request.query.channel === "app" &&
+				request.json.amount > env.threshold &&
+				request.json.currency === "CNY"`,
+		},
+	}
+
+	matched, err := engine.MatchWithEnv(request, rule, map[string]interface{}{"threshold": 100})
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = engine.MatchWithEnv(request, rule, map[string]interface{}{"threshold": 200})
+	assert.NoError(t, err)
+	assert.False(t, matched)
+}
+
+func TestScriptEngine_Match_NonJSONBody(t *testing.T) {
+	engine := NewScriptEngine()
+
+	request := &adapter.Request{
+		ID:       "test-123",
+		Protocol: models.ProtocolHTTP,
+		Body:     []byte(`plain text`),
+	}
+
+	rule := &models.Rule{
+		ID:        "rule-12",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `request.json === null && request.body === "plain text"`,
+		},
+	}
+
+	matched, err := engine.Match(request, rule)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+}
+
+func TestScriptEngine_ProgramCacheAndPoolReuse(t *testing.T) {
+	engine := NewScriptEngine()
+	engine.maxExecutionTime = 100 * time.Millisecond
+
+	request := &adapter.Request{
+		ID:       "test-123",
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/users",
+	}
+
+	timeoutRule := &models.Rule{
+		ID:        "rule-13",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `while(true) {}`,
+		},
+	}
+	rule := &models.Rule{
+		ID:        "rule-14",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `request.path === "/api/users"`,
+		},
+	}
+
+	// 超时中断后归还池中的运行时仍然可以正常执行
+	_, err := engine.Match(request, timeoutRule)
+	assert.Error(t, err)
+
+	for i := 0; i < 10; i++ {
+		matched, err := engine.Match(request, rule)
+		assert.NoError(t, err)
+		assert.True(t, matched)
+	}
+
+	// 相同脚本只编译一次
+	assert.Equal(t, 2, engine.programs.Size())
+}
+
+func TestScriptEngine_ScriptsDoNotShareState(t *testing.T) {
+	engine := NewScriptEngine()
+	request := &adapter.Request{ID: "test-123", Protocol: models.ProtocolHTTP, Path: "/api/users"}
+
+	tamper := &models.Rule{
+		ID:        "rule-tamper",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `var leaked = 42; let declared = 1; Math.floor = function() { return 7 }; true`,
+		},
+	}
+	probe := &models.Rule{
+		ID:        "rule-probe",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `typeof leaked === "undefined" && Math.floor(1.5) === 1`,
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		// 顶层 let 重复执行不会报重复声明
+		matched, err := engine.Match(request, tamper)
+		assert.NoError(t, err)
+		assert.True(t, matched)
+
+		matched, err = engine.Match(request, probe)
+		assert.NoError(t, err)
+		assert.True(t, matched)
+	}
+}
+
+func TestScriptEngine_PooledScriptsDoNotShareState(t *testing.T) {
+	engine := NewScriptEngine()
+	request := &adapter.Request{ID: "test-123", Protocol: models.ProtocolHTTP, Path: "/api/users"}
+
+	// 没有顶层声明的脚本在池中的运行时执行
+	tamper := &models.Rule{
+		ID:        "rule-tamper",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `leaked = 42; Math.floor = function() { return 7 }; Array.prototype.extra = 1; JSON = null; true`,
+		},
+	}
+	probe := &models.Rule{
+		ID:        "rule-probe",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `typeof leaked === "undefined" && Math.floor(1.5) === 1 && [].extra === undefined && typeof JSON.stringify === "function"`,
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		matched, err := engine.Match(request, tamper)
+		assert.NoError(t, err)
+		assert.True(t, matched)
+
+		matched, err = engine.Match(request, probe)
+		assert.NoError(t, err)
+		assert.True(t, matched)
+	}
+}
+
+func TestScriptEngine_EnvIsCopied(t *testing.T) {
+	engine := NewScriptEngine()
+	request := &adapter.Request{
+		ID:       "test-123",
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/users",
+		Headers:  map[string]string{"X-Token": "abc"},
+	}
+	rule := &models.Rule{
+		ID:        "rule-env",
+		MatchType: models.MatchTypeScript,
+		MatchCondition: map[string]interface{}{
+			"script": `env.k = "mutated"; env.added = 1; env.nested.v = 2; request.headers["X-Token"] = "x"; true`,
+		},
+	}
+	env := map[string]interface{}{"k": "v", "nested": map[string]interface{}{"v": 1}}
+
+	matched, err := engine.MatchWithEnv(request, rule, env)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, map[string]interface{}{"k": "v", "nested": map[string]interface{}{"v": 1}}, env)
+	assert.Equal(t, "abc", request.Headers["X-Token"])
+}
@@ -0,0 +1,289 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+)
+
+// sandboxProgram 冻结内置对象并锁定全局内置绑定，禁用 eval 与 Function 构造器
+var sandboxProgram = goja.MustCompile("sandbox", `(function (global) {
+	var names = Object.getOwnPropertyNames(global);
+	var freeze = function (value) {
+		if (value === null || value === undefined || value === global) {
+			return;
+		}
+		if (typeof value !== "object" && typeof value !== "function") {
+			return;
+		}
+		Object.freeze(value);
+		var proto = value.prototype;
+		if (proto !== null && (typeof proto === "object" || typeof proto === "function")) {
+			Object.freeze(proto);
+		}
+	};
+	names.forEach(function (name) { freeze(global[name]); });
+	var arrayIterator = Object.getPrototypeOf([][Symbol.iterator]());
+	[
+		arrayIterator,
+		Object.getPrototypeOf(arrayIterator),
+		Object.getPrototypeOf(new Map()[Symbol.iterator]()),
+		Object.getPrototypeOf(new Set()[Symbol.iterator]()),
+		Object.getPrototypeOf(""[Symbol.iterator]()),
+		Object.getPrototypeOf(function* () {}),
+		Object.getPrototypeOf(function* () {}).prototype,
+		Object.getPrototypeOf(async function () {}),
+		Object.getPrototypeOf(Uint8Array)
+	].forEach(freeze);
+	global.eval = undefined;
+	global.Function = undefined;
+	names.forEach(function (name) {
+		Object.defineProperty(global, name, {writable: false, configurable: false});
+	});
+})`, false)
+
+// ScriptRuntime 脚本运行时，由 VMPool 分配
+type ScriptRuntime struct {
+	*goja.Runtime
+	pooled       bool
+	globals      map[string]struct{}
+	symbols      int
+	isExtensible goja.Callable
+}
+
+// VMPool goja 运行时池，避免高并发下每次执行都创建新的运行时
+//
+// 池中运行时的内置对象（Math、JSON、Array.prototype 等）在创建时冻结，全局内置绑定只读，
+// 脚本对它们的修改不生效；归还时删除脚本新增的全局属性（包括注入的 request、env 等），
+// 无法恢复的运行时（如全局对象被冻结）直接丢弃。顶层声明 var/let/const/function/class 的脚本
+// 使用不入池的新运行时：这些声明不能删除，复用会泄漏给其他脚本或在再次执行时报重复声明。
+type VMPool struct {
+	pool sync.Pool
+}
+
+// NewVMPool 创建运行时池
+func NewVMPool() *VMPool {
+	return &VMPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return newPooledRuntime()
+			},
+		},
+	}
+}
+
+// Get 获取执行 program 的运行时
+func (p *VMPool) Get(program *ScriptProgram) *ScriptRuntime {
+	if program.declaresGlobals {
+		return &ScriptRuntime{Runtime: goja.New()}
+	}
+	return p.pool.Get().(*ScriptRuntime)
+}
+
+// Put 归还运行时，清除脚本新增的全局属性后放回池中
+func (p *VMPool) Put(rt *ScriptRuntime) {
+	if !rt.pooled {
+		return
+	}
+	// 清除可能残留的中断标记，保证下次可正常执行
+	rt.ClearInterrupt()
+	if rt.reset() {
+		p.pool.Put(rt)
+	}
+}
+
+// newPooledRuntime 创建冻结内置对象的运行时，并记录初始全局属性
+func newPooledRuntime() *ScriptRuntime {
+	vm := goja.New()
+	sandbox, err := vm.RunProgram(sandboxProgram)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize script sandbox: %v", err))
+	}
+	lock, _ := goja.AssertFunction(sandbox)
+	global := vm.GlobalObject()
+	if _, err := lock(goja.Undefined(), global); err != nil {
+		panic(fmt.Sprintf("failed to initialize script sandbox: %v", err))
+	}
+	isExtensible, _ := goja.AssertFunction(vm.Get("Object").ToObject(vm).Get("isExtensible"))
+
+	names := global.GetOwnPropertyNames()
+	globals := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		globals[name] = struct{}{}
+	}
+	return &ScriptRuntime{
+		Runtime:      vm,
+		pooled:       true,
+		globals:      globals,
+		symbols:      len(global.Symbols()),
+		isExtensible: isExtensible,
+	}
+}
+
+// reset 删除脚本新增的全局属性，运行时无法恢复到初始状态时返回 false
+func (rt *ScriptRuntime) reset() bool {
+	global := rt.GlobalObject()
+	for _, name := range global.GetOwnPropertyNames() {
+		if _, ok := rt.globals[name]; ok {
+			continue
+		}
+		if err := global.Delete(name); err != nil {
+			return false
+		}
+	}
+	if len(global.Symbols()) != rt.symbols {
+		return false
+	}
+	extensible, err := rt.isExtensible(goja.Undefined(), global)
+	return err == nil && extensible.ToBoolean()
+}
+
+// ScriptProgram 已编译脚本
+type ScriptProgram struct {
+	*goja.Program
+	// declaresGlobals 脚本顶层声明了变量、函数或类
+	declaresGlobals bool
+}
+
+// declaresGlobals 判断脚本顶层是否有声明，块内的 var 在归还运行时时检测
+func declaresGlobals(program *ast.Program) bool {
+	for _, statement := range program.Body {
+		switch statement.(type) {
+		case *ast.VariableStatement, *ast.LexicalDeclaration, *ast.FunctionDeclaration, *ast.ClassDeclaration:
+			return true
+		}
+	}
+	return false
+}
+
+// ProgramCache 已编译脚本的 LRU 缓存
+type ProgramCache struct {
+	capacity int
+	cache    map[string]*list.Element
+	list     *list.List
+	mu       sync.Mutex
+}
+
+// programCacheItem 缓存项
+type programCacheItem struct {
+	key     string
+	source  string
+	program *ScriptProgram
+}
+
+// NewProgramCache 创建已编译脚本缓存
+func NewProgramCache(capacity int) *ProgramCache {
+	return &ProgramCache{
+		capacity: capacity,
+		cache:    make(map[string]*list.Element),
+		list:     list.New(),
+	}
+}
+
+// Compile 编译脚本，命中缓存时直接返回已编译结果
+func (c *ProgramCache) Compile(source string) (*ScriptProgram, error) {
+	return c.CompileFor(source, source)
+}
+
+// CompileFor 按指定键（如规则ID）编译并缓存脚本，脚本内容变化时重新编译
+func (c *ProgramCache) CompileFor(key, source string) (*ScriptProgram, error) {
+	c.mu.Lock()
+	if element, exists := c.cache[key]; exists {
+		item := element.Value.(*programCacheItem)
+		if item.source == source {
+			c.list.MoveToFront(element)
+			c.mu.Unlock()
+			return item.program, nil
+		}
+	}
+	c.mu.Unlock()
+
+	// 以键作为脚本名称，便于在错误堆栈中定位规则
+	name := key
+	if key == source {
+		name = ""
+	}
+	parsed, err := parser.ParseFile(nil, name, source, 0)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := goja.CompileAST(parsed, false)
+	if err != nil {
+		return nil, err
+	}
+	program := &ScriptProgram{Program: compiled, declaresGlobals: declaresGlobals(parsed)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.cache[key]; exists {
+		// 更新现有项
+		c.list.MoveToFront(element)
+		item := element.Value.(*programCacheItem)
+		item.source = source
+		item.program = program
+		return program, nil
+	}
+
+	if c.list.Len() >= c.capacity {
+		// 移除最久未使用的项
+		if back := c.list.Back(); back != nil {
+			c.list.Remove(back)
+			delete(c.cache, back.Value.(*programCacheItem).key)
+		}
+	}
+
+	element := c.list.PushFront(&programCacheItem{key: key, source: source, program: program})
+	c.cache[key] = element
+
+	return program, nil
+}
+
+// Size 获取缓存大小
+func (c *ProgramCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.cache)
+}
+
+// Clear 清空缓存
+func (c *ProgramCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache = make(map[string]*list.Element)
+	c.list = list.New()
+}
+
+// RunProgram 在指定运行时中执行已编译脚本，超时后中断执行
+func RunProgram(vm *goja.Runtime, program *goja.Program, timeout time.Duration) (goja.Value, error) {
+	execCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 设置中断处理；等待监控协程退出后再返回，避免运行时归还池后被误中断
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		select {
+		case <-execCtx.Done():
+			vm.Interrupt("execution timeout")
+		case <-done:
+		}
+	}()
+
+	result, err := vm.RunProgram(program)
+	close(done)
+	<-stopped
+	if err != nil {
+		return nil, fmt.Errorf("script execution failed: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgramCache_DeclaresGlobals(t *testing.T) {
+	cache := NewProgramCache(10)
+	tests := []struct {
+		script   string
+		expected bool
+	}{
+		{`request.path === "/api"`, false},
+		{`(function () { var local = 1; return local === 1 })()`, false},
+		{`if (true) { let scoped = 1 } true`, false},
+		{`var total = 1; total > 0`, true},
+		{`let total = 1; total > 0`, true},
+		{`const total = 1; total > 0`, true},
+		{`function check() { return true } check()`, true},
+		{`class Check {} true`, true},
+	}
+	for _, tt := range tests {
+		program, err := cache.Compile(tt.script)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, program.declaresGlobals, tt.script)
+	}
+}
+
+func TestVMPool_Reset(t *testing.T) {
+	cache := NewProgramCache(10)
+	run := func(rt *ScriptRuntime, script string) {
+		program, err := cache.Compile(script)
+		require.NoError(t, err)
+		_, err = rt.RunProgram(program.Program)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		reusable bool
+	}{
+		{"新增的全局属性被删除", `leaked = 1; this.other = 2; true`, true},
+		{"内置对象不可修改", `Math.floor = null; Object.prototype.polluted = 1; Math = null; true`, true},
+		{"块内 var 声明不能删除", `if (true) { var nested = 1 } true`, false},
+		{"全局对象不可扩展", `Object.preventExtensions(this); true`, false},
+		{"新增 Symbol 属性", `this[Symbol.for("leak")] = 1; true`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newPooledRuntime()
+			rt.Set("request", map[string]interface{}{"path": "/api"})
+			run(rt, tt.script)
+
+			require.Equal(t, tt.reusable, rt.reset())
+			if !tt.reusable {
+				return
+			}
+			value, err := rt.RunString(`typeof leaked === "undefined" && typeof other === "undefined" && typeof request === "undefined" &&
+				Math.floor(1.5) === 1 && ({}).polluted === undefined && typeof eval === "undefined"`)
+			require.NoError(t, err)
+			assert.True(t, value.ToBoolean())
+		})
+	}
+}
+
+func TestVMPool_DeclaringScriptsUseFreshRuntime(t *testing.T) {
+	pool := NewVMPool()
+	cache := NewProgramCache(10)
+	program, err := cache.Compile(`let declared = 1; declared === 1`)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		rt := pool.Get(program)
+		assert.False(t, rt.pooled)
+		value, err := rt.RunProgram(program.Program)
+		require.NoError(t, err)
+		assert.True(t, value.ToBoolean())
+		pool.Put(rt)
+	}
+}
+
+func BenchmarkVMPool_Run(b *testing.B) {
+	pool := NewVMPool()
+	program, err := NewProgramCache(10).Compile(`JSON.stringify(request).length > 0 && /api/.test(request.path) && Math.max(1, 2) === 2`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	request := map[string]interface{}{"path": "/api"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rt := pool.Get(program)
+		rt.Set("request", request)
+		if _, err := rt.RunProgram(program.Program); err != nil {
+			b.Fatal(err)
+		}
+		pool.Put(rt)
+	}
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// websocketCondition 预编译的 WebSocket 事件与消息内容条件，路径、请求头与 body 按 HTTP 条件匹配
+type websocketCondition struct {
+	message *models.WebSocketMessageCondition // 为空时只匹配连接建立
+	text    *regexp.Regexp                    // 正则匹配规则的 message.text
+	binary  []byte                            // 解码后的 message.binary
+}
+
+// parseWebSocketCondition 解析规则的 WebSocket 匹配条件
+func parseWebSocketCondition(rule *models.Rule) (*models.WebSocketMatchCondition, error) {
+	conditionBytes, err := json.Marshal(rule.MatchCondition)
+	if err != nil {
+		return nil, err
+	}
+	var condition models.WebSocketMatchCondition
+	if err := json.Unmarshal(conditionBytes, &condition); err != nil {
+		return nil, err
+	}
+	return &condition, nil
+}
+
+// compileWebSocketCondition 编译 WebSocket 规则的事件与消息内容条件
+func compileWebSocketCondition(rule *models.Rule) (*websocketCondition, error) {
+	condition, err := parseWebSocketCondition(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &websocketCondition{message: condition.Message}
+	if condition.Message == nil {
+		return compiled, nil
+	}
+	switch condition.Message.Type {
+	case "", models.WebSocketMessageText, models.WebSocketMessageBinary:
+	default:
+		return nil, fmt.Errorf("unsupported websocket message type: %s", condition.Message.Type)
+	}
+	if condition.Message.Text != "" && rule.MatchType == models.MatchTypeRegex {
+		if compiled.text, err = regexp.Compile(condition.Message.Text); err != nil {
+			return nil, fmt.Errorf("invalid websocket message text pattern: %w", err)
+		}
+	}
+	if condition.Message.Binary != "" {
+		if compiled.binary, err = base64.StdEncoding.DecodeString(condition.Message.Binary); err != nil {
+			return nil, fmt.Errorf("invalid base64 websocket binary condition: %w", err)
+		}
+	}
+	return compiled, nil
+}
+
+// match 匹配请求事件与消息内容，c 为空（非 WebSocket 规则）时总是匹配
+func (c *websocketCondition) match(request *adapter.Request) bool {
+	if c == nil {
+		return true
+	}
+
+	event, _ := request.Metadata["event"].(string)
+	if c.message == nil {
+		return event != "message"
+	}
+	if event != "message" {
+		return false
+	}
+
+	messageType, _ := request.Metadata["message_type"].(string)
+	if c.message.Type != "" && c.message.Type != messageType {
+		return false
+	}
+	if c.text != nil {
+		if messageType != models.WebSocketMessageText || !c.text.Match(request.Body) {
+			return false
+		}
+	} else if c.message.Text != "" {
+		if messageType != models.WebSocketMessageText || string(request.Body) != c.message.Text {
+			return false
+		}
+	}
+	if c.binary != nil {
+		if messageType != models.WebSocketMessageBinary || !bytes.Equal(request.Body, c.binary) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func websocketRule(id string, priority int, matchType models.MatchType, condition map[string]interface{}) *models.Rule {
+	rule := grpcRule(id, priority, matchType, condition)
+	rule.Protocol = models.ProtocolWebSocket
+	return rule
+}
+
+func websocketRequest(event, messageType, body string, headers map[string]string) *adapter.Request {
+	return &adapter.Request{
+		Protocol: models.ProtocolWebSocket,
+		Path:     "/ws/chat",
+		Headers:  headers,
+		Body:     []byte(body),
+		Metadata: map[string]interface{}{
+			"event":        event,
+			"message_type": messageType,
+			"query":        map[string]string{"room": "1"},
+		},
+	}
+}
+
+func TestMatch_WebSocketRules(t *testing.T) {
+	rules := []*models.Rule{
+		websocketRule("connect-admin", 50, models.MatchTypeSimple, map[string]interface{}{
+			"path":    "/ws/chat",
+			"headers": map[string]interface{}{"X-Role": "admin"},
+		}),
+		websocketRule("connect", 40, models.MatchTypeSimple, map[string]interface{}{
+			"path":  "/ws/chat",
+			"query": map[string]interface{}{"room": "1"},
+		}),
+		websocketRule("ping", 30, models.MatchTypeSimple, map[string]interface{}{
+			"path":    "/ws/chat",
+			"message": map[string]interface{}{"text": "ping"},
+		}),
+		websocketRule("subscribe", 20, models.MatchTypeSimple, map[string]interface{}{
+			"path": "/ws/chat",
+			"message": map[string]interface{}{
+				"body": map[string]interface{}{"action": "subscribe"},
+			},
+		}),
+		websocketRule("binary", 15, models.MatchTypeSimple, map[string]interface{}{
+			"message": map[string]interface{}{"type": "binary", "binary": "AAEC"},
+		}),
+		websocketRule("regex-text", 10, models.MatchTypeRegex, map[string]interface{}{
+			"path":    "^/ws/.*$",
+			"message": map[string]interface{}{"type": "text", "text": "^hello .+$"},
+		}),
+		websocketRule("invalid-binary", 100, models.MatchTypeSimple, map[string]interface{}{
+			"message": map[string]interface{}{"binary": "not base64!"},
+		}),
+	}
+	repo := new(MockRuleRepository)
+	repo.On("FindEnabledByEnvironment", context.Background(), "p1", "e1").Return(rules, nil)
+	e := NewMatchEngine(repo)
+
+	tests := []struct {
+		name    string
+		request *adapter.Request
+		ruleID  string
+	}{
+		{"连接请求头", websocketRequest("connect", "", "", map[string]string{"X-Role": "admin"}), "connect-admin"},
+		{"连接查询参数", websocketRequest("connect", "", "", nil), "connect"},
+		{"文本消息", websocketRequest("message", "text", "ping", nil), "ping"},
+		{"JSON 字段", websocketRequest("message", "text", `{"action":"subscribe","topic":"a"}`, nil), "subscribe"},
+		{"二进制帧", websocketRequest("message", "binary", "\x00\x01\x02", nil), "binary"},
+		{"文本内容不能匹配二进制条件", websocketRequest("message", "text", "\x00\x01\x02", nil), ""},
+		{"正则文本", websocketRequest("message", "text", "hello world", nil), "regex-text"},
+		{"未匹配的消息", websocketRequest("message", "text", "bye", nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := e.Match(context.Background(), tt.request, "p1", "e1")
+			require.NoError(t, err)
+			if tt.ruleID == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			assert.Equal(t, tt.ruleID, rule.ID)
+		})
+	}
+}
+
+func TestSimpleMatch_WebSocketEvent(t *testing.T) {
+	e := NewMatchEngine(new(MockRuleRepository))
+	rule := websocketRule("r1", 0, models.MatchTypeSimple, map[string]interface{}{"path": "/ws/chat"})
+
+	matched, err := e.simpleMatch(websocketRequest("connect", "", "", nil), rule)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	// 连接规则不匹配连接上的消息
+	matched, err = e.simpleMatch(websocketRequest("message", "text", "ping", nil), rule)
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	rule.MatchCondition["message"] = map[string]interface{}{"type": "text"}
+	matched, err = e.simpleMatch(websocketRequest("message", "text", "ping", nil), rule)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	rule.MatchCondition["message"] = map[string]interface{}{"type": "frame"}
+	_, err = e.simpleMatch(websocketRequest("message", "text", "ping", nil), rule)
+	assert.Error(t, err)
+}
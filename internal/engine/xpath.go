@@ -0,0 +1,434 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// xmlNode XML 元素节点
+type xmlNode struct {
+	name     string // 本地名称（忽略命名空间前缀）
+	attrs    map[string]string
+	children []*xmlNode
+	text     strings.Builder // 直接子文本
+}
+
+// stringValue 获取节点的字符串值（所有后代文本拼接）
+func (n *xmlNode) stringValue() string {
+	var sb strings.Builder
+	n.collectText(&sb)
+	return strings.TrimSpace(sb.String())
+}
+
+func (n *xmlNode) collectText(sb *strings.Builder) {
+	sb.WriteString(n.text.String())
+	for _, child := range n.children {
+		child.collectText(sb)
+	}
+}
+
+// parseXMLDocument 解析 XML 文档，返回虚拟文档节点
+func parseXMLDocument(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	document := &xmlNode{}
+	stack := []*xmlNode{document}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(t)
+		}
+	}
+
+	if len(document.children) == 0 {
+		return nil, fmt.Errorf("failed to parse xml: no root element")
+	}
+	return document, nil
+}
+
+// xpathStepKind XPath 步骤类型
+type xpathStepKind int
+
+const (
+	stepElement xpathStepKind = iota
+	stepAttribute
+	stepText
+)
+
+// xpathPredicate XPath 谓词
+type xpathPredicate struct {
+	index    int    // 位置谓词（从1开始），-1 表示 last()
+	attr     string // [@attr] / [@attr='v']
+	child    string // [child] / [child='v']
+	text     bool   // [text()='v']
+	hasValue bool
+	value    string
+}
+
+// xpathStep XPath 步骤
+type xpathStep struct {
+	descendant bool
+	kind       xpathStepKind
+	name       string // 本地名称，* 表示任意
+	predicates []xpathPredicate
+}
+
+// XPath 已解析的 XPath 表达式
+//
+// 支持的语法子集：/a/b、//b、*、@attr、@*、text()、[n]、[last()]、
+// [@attr]、[@attr='v']、[child]、[child='v']、[text()='v']。
+// 名称比较忽略命名空间前缀，如 soap:Body 与 Body 等价（不做命名空间 URI 绑定）。
+// 不支持轴（如 parent::）、函数（如 count()、contains()）、.、..、| 以及
+// 比较运算 !=、<、> 等，解析时返回错误。
+type XPath struct {
+	expr  string
+	steps []xpathStep
+}
+
+// ParseXPath 解析 XPath 表达式
+func ParseXPath(expr string) (*XPath, error) {
+	path := strings.TrimSpace(expr)
+	if path == "" {
+		return nil, fmt.Errorf("empty xpath")
+	}
+
+	var steps []xpathStep
+	for i := 0; i < len(path); {
+		descendant := false
+		if strings.HasPrefix(path[i:], "//") {
+			descendant = true
+			i += 2
+		} else if path[i] == '/' {
+			i++
+		}
+
+		// 读取步骤（方括号内的 / 不作为分隔符）
+		start := i
+		depth := 0
+		quote := byte(0)
+		for i < len(path) {
+			c := path[i]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+			} else if c == '\'' || c == '"' {
+				quote = c
+			} else if c == '[' {
+				depth++
+			} else if c == ']' {
+				depth--
+			} else if c == '/' && depth == 0 {
+				break
+			}
+			i++
+		}
+		if depth != 0 || quote != 0 {
+			return nil, fmt.Errorf("invalid xpath %q: unbalanced brackets or quotes", expr)
+		}
+
+		step, err := parseXPathStep(path[start:i], descendant)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpath %q: %w", expr, err)
+		}
+		steps = append(steps, step)
+	}
+
+	return &XPath{expr: expr, steps: steps}, nil
+}
+
+// parseXPathStep 解析单个步骤
+func parseXPathStep(raw string, descendant bool) (xpathStep, error) {
+	step := xpathStep{descendant: descendant, kind: stepElement}
+
+	nameEnd := strings.IndexByte(raw, '[')
+	if nameEnd < 0 {
+		nameEnd = len(raw)
+	}
+	name := strings.TrimSpace(raw[:nameEnd])
+
+	switch {
+	case name == "":
+		return step, fmt.Errorf("empty step")
+	case name == "text()":
+		step.kind = stepText
+	case strings.HasPrefix(name, "@"):
+		step.kind = stepAttribute
+		if name != "@*" && !validXPathName(name[1:]) {
+			return step, fmt.Errorf("unsupported step %q", name)
+		}
+		step.name = localName(name[1:])
+	default:
+		if name != "*" && !validXPathName(name) {
+			return step, fmt.Errorf("unsupported step %q", name)
+		}
+		step.name = localName(name)
+	}
+
+	rest := raw[nameEnd:]
+	for len(rest) > 0 {
+		if rest[0] != '[' {
+			return step, fmt.Errorf("unexpected %q", rest)
+		}
+		end := closingBracket(rest)
+		if end < 0 {
+			return step, fmt.Errorf("unclosed predicate")
+		}
+		predicate, err := parseXPathPredicate(strings.TrimSpace(rest[1:end]))
+		if err != nil {
+			return step, err
+		}
+		step.predicates = append(step.predicates, predicate)
+		rest = rest[end+1:]
+	}
+
+	if step.kind != stepElement && len(step.predicates) > 0 {
+		return step, fmt.Errorf("predicates are only supported on element steps")
+	}
+	return step, nil
+}
+
+// closingBracket 查找与开头 [ 匹配的 ]
+func closingBracket(s string) int {
+	quote := byte(0)
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+		} else if c == ']' {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseXPathPredicate 解析谓词
+func parseXPathPredicate(raw string) (xpathPredicate, error) {
+	var predicate xpathPredicate
+
+	if raw == "last()" {
+		predicate.index = -1
+		return predicate, nil
+	}
+	if index, err := strconv.Atoi(raw); err == nil {
+		if index < 1 {
+			return predicate, fmt.Errorf("position predicate must be >= 1")
+		}
+		predicate.index = index
+		return predicate, nil
+	}
+
+	left := raw
+	if eq := strings.IndexByte(raw, '='); eq >= 0 {
+		left = strings.TrimSpace(raw[:eq])
+		value := strings.TrimSpace(raw[eq+1:])
+		if len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+			return predicate, fmt.Errorf("predicate value must be quoted: %s", raw)
+		}
+		predicate.hasValue = true
+		predicate.value = value[1 : len(value)-1]
+	}
+
+	switch {
+	case left == "text()":
+		if !predicate.hasValue {
+			return predicate, fmt.Errorf("text() predicate requires a value")
+		}
+		predicate.text = true
+	case strings.HasPrefix(left, "@") && validXPathName(left[1:]):
+		predicate.attr = localName(left[1:])
+	case validXPathName(left):
+		predicate.child = localName(left)
+	default:
+		return predicate, fmt.Errorf("unsupported predicate: %s", raw)
+	}
+	return predicate, nil
+}
+
+// validXPathName 检查是否为合法的（可带前缀的）元素或属性名
+func validXPathName(name string) bool {
+	if name == "" {
+		return false
+	}
+	parts := strings.Split(name, ":")
+	if len(parts) > 2 {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" {
+			return false
+		}
+		for i, r := range part {
+			switch {
+			case r == '_' || unicode.IsLetter(r):
+			case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// localName 去除命名空间前缀
+func localName(name string) string {
+	if idx := strings.LastIndexByte(name, ':'); idx >= 0 {
+		return name[idx+1:]
+	}
+	return name
+}
+
+// String 返回原始表达式
+func (p *XPath) String() string {
+	return p.expr
+}
+
+// Find 在 XML 文档中查找所有匹配节点的字符串值
+func (p *XPath) Find(document *xmlNode) []interface{} {
+	nodes := []*xmlNode{document}
+
+	for i, step := range p.steps {
+		switch step.kind {
+		case stepAttribute:
+			var values []interface{}
+			for _, node := range expandAxis(nodes, step.descendant) {
+				if step.name == "*" {
+					for _, v := range node.attrs {
+						values = append(values, v)
+					}
+				} else if v, ok := node.attrs[step.name]; ok {
+					values = append(values, v)
+				}
+			}
+			if i != len(p.steps)-1 {
+				return nil
+			}
+			return values
+		case stepText:
+			var values []interface{}
+			for _, node := range expandAxis(nodes, step.descendant) {
+				if node == document {
+					continue
+				}
+				values = append(values, strings.TrimSpace(node.text.String()))
+			}
+			if i != len(p.steps)-1 {
+				return nil
+			}
+			return values
+		default:
+			var next []*xmlNode
+			for _, context := range expandAxis(nodes, step.descendant) {
+				var candidates []*xmlNode
+				for _, child := range context.children {
+					if step.name == "*" || child.name == step.name {
+						candidates = append(candidates, child)
+					}
+				}
+				for _, predicate := range step.predicates {
+					candidates = applyXPathPredicate(predicate, candidates)
+				}
+				next = append(next, candidates...)
+			}
+			nodes = next
+		}
+
+		if len(nodes) == 0 {
+			return nil
+		}
+	}
+
+	values := make([]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, node.stringValue())
+	}
+	return values
+}
+
+// expandAxis 展开轴：descendant 为 true 时返回自身及所有后代
+func expandAxis(nodes []*xmlNode, descendant bool) []*xmlNode {
+	if !descendant {
+		return nodes
+	}
+	var result []*xmlNode
+	var walk func(n *xmlNode)
+	walk = func(n *xmlNode) {
+		result = append(result, n)
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+	return result
+}
+
+// applyXPathPredicate 过滤候选节点
+func applyXPathPredicate(predicate xpathPredicate, candidates []*xmlNode) []*xmlNode {
+	if predicate.index != 0 {
+		index := predicate.index
+		if index == -1 {
+			index = len(candidates)
+		}
+		if index >= 1 && index <= len(candidates) {
+			return []*xmlNode{candidates[index-1]}
+		}
+		return nil
+	}
+
+	var result []*xmlNode
+	for _, node := range candidates {
+		switch {
+		case predicate.text:
+			if strings.TrimSpace(node.text.String()) == predicate.value {
+				result = append(result, node)
+			}
+		case predicate.attr != "":
+			if v, ok := node.attrs[predicate.attr]; ok && (!predicate.hasValue || v == predicate.value) {
+				result = append(result, node)
+			}
+		default:
+			for _, child := range node.children {
+				if child.name == predicate.child && (!predicate.hasValue || child.stringValue() == predicate.value) {
+					result = append(result, node)
+					break
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const soapEnvelope = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Header><auth token="abc"/></soap:Header>
+  <soap:Body>
+    <GetUser>
+      <id>42</id>
+      <role type="admin">owner</role>
+      <role type="user">member</role>
+    </GetUser>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestXPath_Find(t *testing.T) {
+	document, err := parseXMLDocument([]byte(soapEnvelope))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []interface{}
+	}{
+		{"绝对路径", "/Envelope/Body/GetUser/id", []interface{}{"42"}},
+		{"带命名空间前缀", "/soap:Envelope/soap:Body/GetUser/id", []interface{}{"42"}},
+		{"后代路径", "//GetUser/id", []interface{}{"42"}},
+		{"属性", "//auth/@token", []interface{}{"abc"}},
+		{"位置谓词", "//role[2]", []interface{}{"member"}},
+		{"last谓词", "//role[last()]", []interface{}{"member"}},
+		{"属性值谓词", "//role[@type='admin']", []interface{}{"owner"}},
+		{"子元素谓词", "//GetUser[id='42']/role[1]/@type", []interface{}{"admin"}},
+		{"text()", "//role/text()", []interface{}{"owner", "member"}},
+		{"通配符", "//GetUser/*[1]", []interface{}{"42"}},
+		{"不存在", "//missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xpath, err := ParseXPath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, xpath.Find(document))
+		})
+	}
+}
+
+func TestXPath_ParseErrors(t *testing.T) {
+	invalid := []string{
+		"", "//a[", "//a[@b=c]", "//a[0]", "//@b[1]",
+		"//a/..", "//a/parent::b", "count(//a)", "//a[contains(@b,'c')]", "//a[@b!='c']", "//a|//b",
+	}
+	for _, expr := range invalid {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseXPath(expr)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// selectFault 按顺序以各自的概率判断故障，返回第一个触发的故障，都未触发时返回 nil
+func selectFault(faults []models.FaultConfig) (*models.FaultConfig, error) {
+	for i := range faults {
+		if err := validateFault(&faults[i]); err != nil {
+			return nil, fmt.Errorf("invalid faults[%d]: %w", i, err)
+		}
+	}
+	for i := range faults {
+		if rand.Float64() < faults[i].Probability {
+			fault := faults[i]
+			return &fault, nil
+		}
+	}
+	return nil, nil
+}
+
+// validateFault 校验故障类型与参数
+func validateFault(fault *models.FaultConfig) error {
+	if fault.Probability <= 0 || fault.Probability > 1 {
+		return fmt.Errorf("probability must be in (0, 1], got %v", fault.Probability)
+	}
+	if fault.Bytes < 0 || fault.Duration < 0 {
+		return fmt.Errorf("bytes and duration must not be negative")
+	}
+	switch fault.Type {
+	case models.FaultEmptyResponse, models.FaultConnectionReset, models.FaultMalformedResponse, models.FaultHang:
+	case models.FaultCloseAfterBytes:
+		if fault.Bytes == 0 {
+			return fmt.Errorf("close_after_bytes requires bytes")
+		}
+	default:
+		return fmt.Errorf("unsupported fault type: %s", fault.Type)
+	}
+	return nil
+}
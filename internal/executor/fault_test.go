@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func faultRule(protocol models.ProtocolType, faults ...models.FaultConfig) *models.Rule {
+	return &models.Rule{
+		ID:       "fault",
+		Protocol: protocol,
+		Response: models.Response{
+			Type:    models.ResponseTypeStatic,
+			Content: map[string]interface{}{"status_code": 200, "body": map[string]interface{}{"ok": true}},
+			Faults:  faults,
+		},
+	}
+}
+
+func TestExecute_Faults(t *testing.T) {
+	executor := NewMockExecutor()
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+
+	// 概率为 1 的故障总是触发，按顺序选择第一个
+	response, err := executor.Execute(request, faultRule(models.ProtocolHTTP,
+		models.FaultConfig{Type: models.FaultConnectionReset, Probability: 1, Bytes: 10},
+		models.FaultConfig{Type: models.FaultHang, Probability: 1},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, &models.FaultConfig{Type: models.FaultConnectionReset, Probability: 1, Bytes: 10}, response.Metadata["fault"])
+	assert.JSONEq(t, `{"ok":true}`, string(response.Body))
+
+	// 概率很小时绝大多数请求正常返回
+	triggered := 0
+	for i := 0; i < 100; i++ {
+		response, err = executor.Execute(request, faultRule(models.ProtocolHTTP,
+			models.FaultConfig{Type: models.FaultEmptyResponse, Probability: 0.0001},
+		))
+		require.NoError(t, err)
+		if response.Metadata["fault"] != nil {
+			triggered++
+		}
+	}
+	assert.Less(t, triggered, 5)
+}
+
+func TestExecute_FaultsInvalid(t *testing.T) {
+	executor := NewMockExecutor()
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+
+	for _, fault := range []models.FaultConfig{
+		{Type: models.FaultEmptyResponse},
+		{Type: models.FaultEmptyResponse, Probability: 1.5},
+		{Type: models.FaultCloseAfterBytes, Probability: 1},
+		{Type: models.FaultHang, Probability: 1, Duration: -1},
+		{Type: "explode", Probability: 1},
+	} {
+		_, err := executor.Execute(request, faultRule(models.ProtocolHTTP, fault))
+		assert.Error(t, err, "%+v", fault)
+	}
+}
+
+func TestExecute_FaultsIgnoredForNonHTTP(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := faultRule(models.ProtocolGRPC, models.FaultConfig{Type: models.FaultEmptyResponse, Probability: 1})
+	rule.Response.Content = map[string]interface{}{"body": map[string]interface{}{"message": "hi"}}
+
+	response, err := executor.Execute(&adapter.Request{Protocol: models.ProtocolGRPC}, rule)
+	require.NoError(t, err)
+	assert.Nil(t, response.Metadata["fault"])
+}
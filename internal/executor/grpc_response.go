@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// grpcResponse 生成 gRPC 响应，render 为 true 时按模板渲染响应消息（Dynamic 响应）
+//
+// 统一响应模型中 StatusCode 为 gRPC 状态码，Body 为 JSON 形式的响应消息，
+// 由 gRPC 服务按方法的输出类型转换为 protobuf 消息。
+func (e *MockExecutor) grpcResponse(request *adapter.Request, rule *models.Rule, env *models.Environment, render bool) (*adapter.Response, error) {
+	contentBytes, err := json.Marshal(rule.Response.Content)
+	if err != nil {
+		logger.Error("failed to marshal response content", zap.Error(err))
+		return nil, err
+	}
+
+	var grpcResp models.GRPCResponse
+	if err := json.Unmarshal(contentBytes, &grpcResp); err != nil {
+		logger.Error("failed to unmarshal grpc response", zap.Error(err))
+		return nil, err
+	}
+
+	code, err := adapter.ParseGRPCCode(grpcResp.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if grpcResp.Body != nil && len(grpcResp.Stream) > 0 {
+		return nil, fmt.Errorf("grpc response body and stream cannot both be set")
+	}
+
+	var ctx *TemplateContext
+	message := grpcResp.Message
+	if render {
+		ctx = e.templateEngine.BuildContext(request, rule, env)
+		if message, err = e.templateEngine.Render(grpcResp.Message, ctx); err != nil {
+			return nil, fmt.Errorf("failed to render status message: %w", err)
+		}
+	}
+
+	// 流式响应消息依次渲染，body 等价于只有一条消息的流
+	var bodyBytes []byte
+	var stream []adapter.GRPCStreamMessage
+	for _, item := range grpcResp.Stream {
+		data, err := e.renderGRPCBody(item.Body, ctx)
+		if err != nil {
+			return nil, err
+		}
+		stream = append(stream, adapter.GRPCStreamMessage{
+			Body:  data,
+			Delay: time.Duration(item.Delay) * time.Millisecond,
+		})
+	}
+	if grpcResp.Body != nil {
+		if bodyBytes, err = e.renderGRPCBody(grpcResp.Body, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	headers := grpcResp.Headers
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	trailers := grpcResp.Trailers
+	if trailers == nil {
+		trailers = make(map[string]string)
+	}
+
+	return &adapter.Response{
+		StatusCode: int(code),
+		Headers:    headers,
+		Body:       bodyBytes,
+		Metadata: map[string]interface{}{
+			"grpc_message":  message,
+			"grpc_trailers": trailers,
+			"grpc_stream":   stream,
+		},
+	}, nil
+}
+
+// renderGRPCBody 序列化响应消息，ctx 不为空时先按模板渲染
+func (e *MockExecutor) renderGRPCBody(body interface{}, ctx *TemplateContext) ([]byte, error) {
+	if ctx != nil {
+		rendered, err := e.templateEngine.RenderJSON(body, ctx)
+		if err != nil {
+			logger.Error("failed to render json template", zap.Error(err))
+			return nil, fmt.Errorf("failed to render json template: %w", err)
+		}
+		body = rendered
+	}
+	return json.Marshal(body)
+}
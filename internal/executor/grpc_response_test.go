@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestGRPCStaticResponse(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := &models.Rule{
+		Protocol: models.ProtocolGRPC,
+		Response: models.Response{
+			Type: models.ResponseTypeStatic,
+			Content: map[string]interface{}{
+				"code":     "not_found",
+				"message":  "user not found",
+				"headers":  map[string]interface{}{"x-request-id": "abc"},
+				"trailers": map[string]interface{}{"x-retry-after": "5"},
+			},
+		},
+	}
+
+	response, err := executor.Execute(&adapter.Request{Protocol: models.ProtocolGRPC}, rule)
+	require.NoError(t, err)
+	assert.Equal(t, int(codes.NotFound), response.StatusCode)
+	assert.Equal(t, "abc", response.Headers["x-request-id"])
+	assert.Nil(t, response.Body)
+	assert.Equal(t, "user not found", response.Metadata["grpc_message"])
+	assert.Equal(t, map[string]string{"x-retry-after": "5"}, response.Metadata["grpc_trailers"])
+	_, hasContentType := response.Headers["Content-Type"]
+	assert.False(t, hasContentType)
+}
+
+func TestGRPCDynamicResponse(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := &models.Rule{
+		Protocol: models.ProtocolGRPC,
+		Response: models.Response{
+			Type: models.ResponseTypeDynamic,
+			Content: map[string]interface{}{
+				"body": map[string]interface{}{"id": "{{.Request.Body.id}}", "name": "alice"},
+			},
+		},
+	}
+
+	request := &adapter.Request{
+		Protocol: models.ProtocolGRPC,
+		Path:     "/acme.user.v1.UserService/GetUser",
+		Body:     []byte(`{"id":"42"}`),
+	}
+	response, err := executor.Execute(request, rule)
+	require.NoError(t, err)
+	assert.Equal(t, int(codes.OK), response.StatusCode)
+	assert.JSONEq(t, `{"id":"42","name":"alice"}`, string(response.Body))
+}
+
+func TestGRPCResponse_InvalidCode(t *testing.T) {
+	executor := NewMockExecutor()
+	for _, code := range []interface{}{"NOPE", 17, -1, 2.5} {
+		rule := &models.Rule{
+			Protocol: models.ProtocolGRPC,
+			Response: models.Response{
+				Type:    models.ResponseTypeStatic,
+				Content: map[string]interface{}{"code": code},
+			},
+		}
+		_, err := executor.Execute(&adapter.Request{Protocol: models.ProtocolGRPC}, rule)
+		assert.Error(t, err, "code %v", code)
+	}
+}
+
+func TestGRPCStreamResponse(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := &models.Rule{
+		Protocol: models.ProtocolGRPC,
+		Response: models.Response{
+			Type: models.ResponseTypeDynamic,
+			Content: map[string]interface{}{
+				"stream": []interface{}{
+					map[string]interface{}{"body": map[string]interface{}{"id": "{{.Request.Body.id}}", "seq": 1}},
+					map[string]interface{}{"body": map[string]interface{}{"id": "{{.Request.Body.id}}", "seq": 2}, "delay": 50},
+				},
+				"code":    "UNAVAILABLE",
+				"message": "feed interrupted",
+			},
+		},
+	}
+
+	request := &adapter.Request{Protocol: models.ProtocolGRPC, Body: []byte(`{"id":"42"}`)}
+	response, err := executor.Execute(request, rule)
+	require.NoError(t, err)
+	assert.Equal(t, int(codes.Unavailable), response.StatusCode)
+	assert.Nil(t, response.Body)
+
+	stream, ok := response.Metadata["grpc_stream"].([]adapter.GRPCStreamMessage)
+	require.True(t, ok)
+	require.Len(t, stream, 2)
+	assert.JSONEq(t, `{"id":"42","seq":1}`, string(stream[0].Body))
+	assert.Zero(t, stream[0].Delay)
+	assert.JSONEq(t, `{"id":"42","seq":2}`, string(stream[1].Body))
+	assert.Equal(t, 50*time.Millisecond, stream[1].Delay)
+
+	// body 与 stream 互斥
+	rule.Response.Content["body"] = map[string]interface{}{"id": "1"}
+	_, err = executor.Execute(request, rule)
+	assert.Error(t, err)
+}
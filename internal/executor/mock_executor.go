@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -13,7 +14,9 @@ import (
 	"time"
 
 	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/engine"
 	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
 	"github.com/gomockserver/mockserver/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -29,43 +32,90 @@ type MockExecutor struct {
 	stepCounters   map[string]int64
 	stepCountersMu sync.RWMutex
 
+	// 响应序列调用计数器
+	sequenceCounters   map[string]int64
+	sequenceCountersMu sync.RWMutex
+
 	// 模板引擎
 	templateEngine *TemplateEngine
 
 	// 代理执行器
 	proxyExecutor *ProxyExecutor
+
+	// 脚本响应执行器
+	scriptExecutor *ScriptExecutor
+	envVars        *engine.EnvVariablesCache
 }
 
 // NewMockExecutor 创建 Mock 执行器
 func NewMockExecutor() *MockExecutor {
 	return &MockExecutor{
-		stepCounters:   make(map[string]int64),
-		templateEngine: NewTemplateEngine(),
-		proxyExecutor:  NewProxyExecutor(),
+		stepCounters:     make(map[string]int64),
+		sequenceCounters: make(map[string]int64),
+		templateEngine:   NewTemplateEngine(),
+		proxyExecutor:    NewProxyExecutor(),
+		scriptExecutor:   NewScriptExecutor(),
 	}
 }
 
+// SetEnvironmentRepository 设置环境仓库，用于向响应脚本注入环境变量
+func (e *MockExecutor) SetEnvironmentRepository(envRepo repository.EnvironmentRepository) {
+	e.envVars = engine.NewEnvVariablesCache(envRepo, engine.DefaultEnvVariablesTTL)
+}
+
+// EnvironmentChanged 环境变更后清除环境变量缓存（实现 repository.EnvironmentChangeListener）
+func (e *MockExecutor) EnvironmentChanged(ctx context.Context, environmentID string) {
+	e.envVars.Invalidate(environmentID)
+}
+
 // Execute 执行 Mock 响应生成
 func (e *MockExecutor) Execute(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
 	// 应用延迟
 	if rule.Response.Delay != nil {
-		delay := e.calculateDelay(rule.Response.Delay)
+		delay := e.calculateRuleDelay(rule.Response.Delay, rule.ID)
 		if delay > 0 {
 			time.Sleep(time.Duration(delay) * time.Millisecond)
 		}
 	}
 
-	// 根据响应类型生成响应
+	response, err := e.generateResponse(request, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按概率选择传输层故障，由 HTTPAdapter.WriteResponse 劫持连接注入
+	if rule.Protocol == models.ProtocolHTTP && len(rule.Response.Faults) > 0 {
+		fault, err := selectFault(rule.Response.Faults)
+		if err != nil {
+			return nil, err
+		}
+		if fault != nil {
+			if response.Metadata == nil {
+				response.Metadata = make(map[string]interface{})
+			}
+			response.Metadata["fault"] = fault
+		}
+	}
+	return response, nil
+}
+
+// generateResponse 根据响应类型生成响应
+func (e *MockExecutor) generateResponse(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
 	switch rule.Response.Type {
 	case models.ResponseTypeStatic:
 		return e.staticResponse(request, rule)
 	case models.ResponseTypeDynamic:
 		return e.dynamicResponse(request, rule, nil)
 	case models.ResponseTypeScript:
-		// TODO: v0.4.0 实现
-		return nil, fmt.Errorf("script response not implemented yet")
+		return e.scriptResponse(request, rule)
 	case models.ResponseTypeProxy:
 		return e.proxyResponse(request, rule)
+	case models.ResponseTypeSequence:
+		return e.sequenceResponse(request, rule)
+	case models.ResponseTypeSSE:
+		return e.sseResponse(request, rule)
+	case models.ResponseTypeNDJSON:
+		return e.ndjsonResponse(request, rule)
 	default:
 		return nil, fmt.Errorf("unsupported response type: %s", rule.Response.Type)
 	}
@@ -73,8 +123,14 @@ func (e *MockExecutor) Execute(request *adapter.Request, rule *models.Rule) (*ad
 
 // staticResponse 生成静态响应
 func (e *MockExecutor) staticResponse(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
+	if rule.Protocol == models.ProtocolGRPC {
+		return e.grpcResponse(request, rule, nil, false)
+	}
+	if rule.Protocol == models.ProtocolWebSocket {
+		return e.websocketResponse(request, rule, nil, false)
+	}
 	if rule.Protocol != models.ProtocolHTTP {
-		return nil, fmt.Errorf("only HTTP protocol is supported in static response")
+		return nil, fmt.Errorf("only HTTP, gRPC and WebSocket protocols are supported in static response")
 	}
 
 	// 解析 HTTP 响应配置
@@ -174,13 +230,26 @@ func (e *MockExecutor) staticResponse(request *adapter.Request, rule *models.Rul
 		Metadata:   make(map[string]interface{}),
 	}
 
+	// 分块或限速发送响应体
+	if httpResp.Stream != nil {
+		if err := applyHTTPStream(response, httpResp.Stream); err != nil {
+			return nil, err
+		}
+	}
+
 	return response, nil
 }
 
 // dynamicResponse 生成动态响应
 func (e *MockExecutor) dynamicResponse(request *adapter.Request, rule *models.Rule, env *models.Environment) (*adapter.Response, error) {
+	if rule.Protocol == models.ProtocolGRPC {
+		return e.grpcResponse(request, rule, env, true)
+	}
+	if rule.Protocol == models.ProtocolWebSocket {
+		return e.websocketResponse(request, rule, env, true)
+	}
 	if rule.Protocol != models.ProtocolHTTP {
-		return nil, fmt.Errorf("only HTTP protocol is supported in dynamic response")
+		return nil, fmt.Errorf("only HTTP, gRPC and WebSocket protocols are supported in dynamic response")
 	}
 
 	// 解析 HTTP 响应配置
@@ -267,9 +336,115 @@ func (e *MockExecutor) dynamicResponse(request *adapter.Request, rule *models.Ru
 		Metadata:   make(map[string]interface{}),
 	}
 
+	// 分块或限速发送响应体
+	if httpResp.Stream != nil {
+		if err := applyHTTPStream(response, httpResp.Stream); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// scriptResponse 生成脚本响应
+func (e *MockExecutor) scriptResponse(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
+	if rule.Protocol != models.ProtocolHTTP {
+		return nil, fmt.Errorf("only HTTP protocol is supported in script response")
+	}
+
+	result, err := e.scriptExecutor.Execute(request, rule, e.envVars.Get(rule.EnvironmentID))
+	if err != nil {
+		logger.Error("failed to execute response script", zap.String("rule_id", rule.ID), zap.Error(err))
+		return nil, err
+	}
+
+	// 设置默认 Content-Type
+	if _, ok := result.Headers["Content-Type"]; !ok {
+		result.Headers["Content-Type"] = e.getDefaultContentType(result.ContentType)
+	}
+
+	return &adapter.Response{
+		StatusCode: result.StatusCode,
+		Headers:    result.Headers,
+		Body:       result.Body,
+		Metadata:   make(map[string]interface{}),
+	}, nil
+}
+
+// sequenceResponse 按调用次数从响应序列中选择响应并执行
+func (e *MockExecutor) sequenceResponse(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
+	config, err := parseSequenceConfig(rule.Response.Content)
+	if err != nil {
+		logger.Error("failed to parse sequence config", zap.String("rule_id", rule.ID), zap.Error(err))
+		return nil, err
+	}
+
+	call := e.nextSequenceCall(rule.ID)
+	selected, err := selectSequenceResponse(config, call)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以选中的响应替换规则响应，复用常规响应流程（包括各自的延迟）
+	derived := *rule
+	derived.Response = *selected
+	response, err := e.Execute(request, &derived)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata["sequence_call"] = call
 	return response, nil
 }
 
+// nextSequenceCall 增加并返回规则的序列调用计数
+func (e *MockExecutor) nextSequenceCall(ruleID string) int64 {
+	e.sequenceCountersMu.Lock()
+	defer e.sequenceCountersMu.Unlock()
+
+	e.sequenceCounters[ruleID]++
+	return e.sequenceCounters[ruleID]
+}
+
+// GetSequenceCounter 获取响应序列调用计数
+func (e *MockExecutor) GetSequenceCounter(ruleID string) int64 {
+	e.sequenceCountersMu.RLock()
+	defer e.sequenceCountersMu.RUnlock()
+
+	return e.sequenceCounters[ruleID]
+}
+
+// ResetSequenceCounter 重置响应序列计数器，ruleID 为空时重置所有
+func (e *MockExecutor) ResetSequenceCounter(ruleID string) {
+	e.sequenceCountersMu.Lock()
+	defer e.sequenceCountersMu.Unlock()
+
+	if ruleID == "" {
+		e.sequenceCounters = make(map[string]int64)
+	} else {
+		delete(e.sequenceCounters, ruleID)
+	}
+
+	logger.Info("reset sequence counter", zap.String("rule_id", ruleID))
+}
+
+// GetRuleCounters 获取规则的所有调用计数
+func (e *MockExecutor) GetRuleCounters(ruleID string) map[string]int64 {
+	return map[string]int64{
+		"sequence": e.GetSequenceCounter(ruleID),
+		"step":     e.GetStepCounter(ruleID),
+	}
+}
+
+// ResetRuleCounters 重置规则的所有调用计数，ruleID 为空时重置所有规则
+func (e *MockExecutor) ResetRuleCounters(ruleID string) {
+	e.ResetSequenceCounter(ruleID)
+	e.ResetStepCounter(ruleID)
+}
+
 // proxyResponse 生成代理响应
 func (e *MockExecutor) proxyResponse(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
 	// 解析代理配置
@@ -329,6 +504,14 @@ func (e *MockExecutor) calculateDelay(config *models.DelayConfig) int {
 	}
 }
 
+// calculateRuleDelay 计算规则延迟，阶梯延迟按规则独立计数
+func (e *MockExecutor) calculateRuleDelay(config *models.DelayConfig, ruleID string) int {
+	if strings.EqualFold(config.Type, "step") {
+		return e.calculateStepDelay(config, ruleID)
+	}
+	return e.calculateDelay(config)
+}
+
 // calculateStepDelay 计算阶梯延迟
 func (e *MockExecutor) calculateStepDelay(config *models.DelayConfig, ruleID string) int {
 	// 使用规则ID作为计数器键，实现计数器隔离
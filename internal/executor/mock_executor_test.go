@@ -11,6 +11,7 @@ import (
 	"github.com/gomockserver/mockserver/internal/adapter"
 	"github.com/gomockserver/mockserver/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestCalculateDelay 测试延迟计算
@@ -204,11 +205,11 @@ func TestGetDefaultResponse(t *testing.T) {
 	assert.Contains(t, string(response.Body), "No matching rule found")
 }
 
-// TestUnsupportedResponseType 测试不支持的响应类型（仅Script响应）
+// TestUnsupportedResponseType 测试无法执行的响应配置（缺少脚本的Script响应）
 func TestUnsupportedResponseType(t *testing.T) {
 	executor := NewMockExecutor()
 
-	// Script响应类型尚未实现
+	// Script响应缺少脚本内容
 	t.Run("Script响应", func(t *testing.T) {
 		rule := &models.Rule{
 			Protocol: models.ProtocolHTTP,
@@ -315,7 +316,7 @@ func TestHTMLResponse(t *testing.T) {
 	assert.Contains(t, response.Headers["Content-Type"], "html")
 }
 
-// TestNonHTTPProtocol 测试非HTTP协议的错误处理
+// TestNonHTTPProtocol 测试不支持静态响应的协议的错误处理
 func TestNonHTTPProtocol(t *testing.T) {
 	executor := NewMockExecutor()
 
@@ -323,8 +324,6 @@ func TestNonHTTPProtocol(t *testing.T) {
 		name     string
 		protocol models.ProtocolType
 	}{
-		{"gRPC协议", models.ProtocolGRPC},
-		{"WebSocket协议", models.ProtocolWebSocket},
 		{"TCP协议", models.ProtocolTCP},
 	}
 
@@ -350,7 +349,7 @@ func TestNonHTTPProtocol(t *testing.T) {
 
 			assert.Error(t, err, "非HTTP协议应该返回错误")
 			assert.Nil(t, response)
-			assert.Contains(t, err.Error(), "only HTTP protocol is supported")
+			assert.Contains(t, err.Error(), "only HTTP, gRPC and WebSocket protocols are supported")
 		})
 	}
 }
@@ -1146,3 +1145,36 @@ func TestProxyResponse(t *testing.T) {
 		assert.Nil(t, response)
 	})
 }
+
+func TestExecute_StepDelayCountsPerRule(t *testing.T) {
+	executor := NewMockExecutor()
+	delay := &models.DelayConfig{Type: "step", Fixed: 0, Step: 1, Limit: 10}
+	newRule := func(id string) *models.Rule {
+		return &models.Rule{
+			ID:       id,
+			Protocol: models.ProtocolHTTP,
+			Response: models.Response{
+				Type:    models.ResponseTypeStatic,
+				Delay:   delay,
+				Content: map[string]interface{}{"status_code": 200, "content_type": "TEXT", "body": "ok"},
+			},
+		}
+	}
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+
+	for i := 0; i < 3; i++ {
+		_, err := executor.Execute(request, newRule("rule-a"))
+		require.NoError(t, err)
+	}
+	_, err := executor.Execute(request, newRule("rule-b"))
+	require.NoError(t, err)
+
+	// 每条规则的阶梯延迟从第一次调用开始独立递增
+	assert.Equal(t, int64(3), executor.GetStepCounter("rule-a"))
+	assert.Equal(t, int64(1), executor.GetStepCounter("rule-b"))
+	assert.Equal(t, map[string]int64{"step": 3, "sequence": 0}, executor.GetRuleCounters("rule-a"))
+
+	executor.ResetRuleCounters("rule-a")
+	assert.Equal(t, int64(0), executor.GetStepCounter("rule-a"))
+	assert.Equal(t, int64(1), executor.GetStepCounter("rule-b"))
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gomockserver/mockserver/internal/adapter"
@@ -89,6 +90,13 @@ func (p *ProxyExecutor) Execute(request *adapter.Request, config *ProxyConfig) (
 	if request.Path != "" {
 		targetURL = targetURL + request.Path
 	}
+	if rawQuery, ok := request.Metadata["raw_query"].(string); ok && rawQuery != "" {
+		separator := "?"
+		if strings.Contains(targetURL, "?") {
+			separator = "&"
+		}
+		targetURL = targetURL + separator + rawQuery
+	}
 
 	// 提取HTTP方法
 	method := "GET"
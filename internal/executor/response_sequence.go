@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// SequenceMode 响应序列模式
+type SequenceMode string
+
+const (
+	SequenceModeSequential SequenceMode = "sequential" // 按顺序返回，结束后停留在最后一个
+	SequenceModeCycle      SequenceMode = "cycle"      // 循环返回
+	SequenceModeRandom     SequenceMode = "random"     // 按权重随机返回
+	SequenceModeFailNth    SequenceMode = "fail_nth"   // 第 N 次调用返回失败响应
+)
+
+// SequenceConfig 响应序列配置
+type SequenceConfig struct {
+	Mode      SequenceMode      `json:"mode"`
+	Responses []models.Response `json:"responses"`
+	Weights   []int             `json:"weights,omitempty"`    // random 模式权重，未配置的响应权重为 1
+	FailOn    []int             `json:"fail_on,omitempty"`    // fail_nth 模式下失败的调用序号（从 1 开始）
+	FailEvery int               `json:"fail_every,omitempty"` // fail_nth 模式下每 N 次调用失败一次
+	Failure   *models.Response  `json:"failure,omitempty"`    // fail_nth 模式的失败响应，缺省为 500
+}
+
+// defaultFailureResponse fail_nth 模式的默认失败响应
+var defaultFailureResponse = models.Response{
+	Type: models.ResponseTypeStatic,
+	Content: map[string]interface{}{
+		"status_code":  500,
+		"content_type": string(models.ContentTypeJSON),
+		"body":         map[string]interface{}{"error": "Injected failure"},
+	},
+}
+
+// parseSequenceConfig 从响应内容解析序列配置
+func parseSequenceConfig(content map[string]interface{}) (*SequenceConfig, error) {
+	contentBytes, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var config SequenceConfig
+	if err := json.Unmarshal(contentBytes, &config); err != nil {
+		return nil, fmt.Errorf("invalid sequence config: %w", err)
+	}
+
+	if config.Mode == "" {
+		config.Mode = SequenceModeSequential
+	}
+	if len(config.Responses) == 0 {
+		return nil, fmt.Errorf("sequence responses are empty")
+	}
+	for i, item := range config.Responses {
+		if item.Type == models.ResponseTypeSequence {
+			return nil, fmt.Errorf("nested sequence at responses[%d] is not supported", i)
+		}
+	}
+	if config.Failure != nil && config.Failure.Type == models.ResponseTypeSequence {
+		return nil, fmt.Errorf("nested sequence in failure response is not supported")
+	}
+
+	return &config, nil
+}
+
+// selectSequenceResponse 根据调用序号（从 1 开始）选择响应
+func selectSequenceResponse(config *SequenceConfig, call int64) (*models.Response, error) {
+	responses := config.Responses
+	last := int64(len(responses) - 1)
+
+	switch config.Mode {
+	case SequenceModeSequential:
+		return &responses[min(call-1, last)], nil
+	case SequenceModeCycle:
+		return &responses[(call-1)%int64(len(responses))], nil
+	case SequenceModeRandom:
+		return &responses[weightedIndex(config.Weights, len(responses))], nil
+	case SequenceModeFailNth:
+		if shouldFail(config, call) {
+			if config.Failure != nil {
+				return config.Failure, nil
+			}
+			return &defaultFailureResponse, nil
+		}
+		return &responses[min(call-1, last)], nil
+	default:
+		return nil, fmt.Errorf("unsupported sequence mode: %s", config.Mode)
+	}
+}
+
+// shouldFail 判断本次调用是否应返回失败响应
+func shouldFail(config *SequenceConfig, call int64) bool {
+	if config.FailEvery > 0 && call%int64(config.FailEvery) == 0 {
+		return true
+	}
+	for _, n := range config.FailOn {
+		if int64(n) == call {
+			return true
+		}
+	}
+	return false
+}
+
+// weightedIndex 按权重随机选择下标，权重全为 0 时等概率选择
+func weightedIndex(weights []int, n int) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		total += sequenceWeight(weights, i)
+	}
+	if total == 0 {
+		return rand.Intn(n)
+	}
+
+	r := rand.Intn(total)
+	for i := 0; i < n; i++ {
+		r -= sequenceWeight(weights, i)
+		if r < 0 {
+			return i
+		}
+	}
+	return n - 1
+}
+
+// sequenceWeight 获取第 i 个响应的权重，未配置时为 1，负数按 0 处理
+func sequenceWeight(weights []int, i int) int {
+	if i >= len(weights) {
+		return 1
+	}
+	return max(weights[i], 0)
+}
@@ -0,0 +1,169 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textResponse 构造返回指定文本的静态响应配置
+func textResponse(statusCode int, body string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "Static",
+		"content": map[string]interface{}{
+			"status_code":  statusCode,
+			"content_type": "Text",
+			"body":         body,
+		},
+	}
+}
+
+func sequenceRule(id string, content map[string]interface{}) *models.Rule {
+	return &models.Rule{
+		ID:       id,
+		Protocol: models.ProtocolHTTP,
+		Response: models.Response{
+			Type:    models.ResponseTypeSequence,
+			Content: content,
+		},
+	}
+}
+
+func executeBodies(t *testing.T, executor *MockExecutor, rule *models.Rule, n int) []string {
+	t.Helper()
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+	bodies := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		response, err := executor.Execute(request, rule)
+		require.NoError(t, err)
+		bodies = append(bodies, string(response.Body))
+	}
+	return bodies
+}
+
+func TestSequenceResponse_Sequential(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := sequenceRule("seq", map[string]interface{}{
+		"responses": []interface{}{textResponse(200, "a"), textResponse(200, "b")},
+	})
+
+	assert.Equal(t, []string{"a", "b", "b", "b"}, executeBodies(t, executor, rule, 4))
+	assert.Equal(t, int64(4), executor.GetSequenceCounter("seq"))
+}
+
+func TestSequenceResponse_Cycle(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := sequenceRule("cycle", map[string]interface{}{
+		"mode":      "cycle",
+		"responses": []interface{}{textResponse(200, "a"), textResponse(200, "b"), textResponse(200, "c")},
+	})
+
+	assert.Equal(t, []string{"a", "b", "c", "a", "b"}, executeBodies(t, executor, rule, 5))
+}
+
+func TestSequenceResponse_RandomWeighted(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := sequenceRule("random", map[string]interface{}{
+		"mode":      "random",
+		"responses": []interface{}{textResponse(200, "never"), textResponse(200, "always")},
+		"weights":   []interface{}{0, 5},
+	})
+
+	for _, body := range executeBodies(t, executor, rule, 20) {
+		assert.Equal(t, "always", body)
+	}
+}
+
+func TestSequenceResponse_FailNth(t *testing.T) {
+	executor := NewMockExecutor()
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+	rule := sequenceRule("flaky", map[string]interface{}{
+		"mode":       "fail_nth",
+		"responses":  []interface{}{textResponse(200, "ok")},
+		"fail_every": 3,
+		"fail_on":    []interface{}{1},
+	})
+
+	var statuses []int
+	for i := 0; i < 6; i++ {
+		response, err := executor.Execute(request, rule)
+		require.NoError(t, err)
+		statuses = append(statuses, response.StatusCode)
+	}
+	assert.Equal(t, []int{500, 200, 500, 200, 200, 500}, statuses)
+
+	// 自定义失败响应
+	rule = sequenceRule("flaky-custom", map[string]interface{}{
+		"mode":      "fail_nth",
+		"responses": []interface{}{textResponse(200, "ok")},
+		"fail_on":   []interface{}{2},
+		"failure":   textResponse(503, "unavailable"),
+	})
+	assert.Equal(t, []string{"ok", "unavailable", "ok"}, executeBodies(t, executor, rule, 3))
+}
+
+func TestSequenceResponse_ResetCounters(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := sequenceRule("retry", map[string]interface{}{
+		"responses": []interface{}{textResponse(503, "retry"), textResponse(200, "done")},
+	})
+
+	assert.Equal(t, []string{"retry", "done"}, executeBodies(t, executor, rule, 2))
+	assert.Equal(t, int64(2), executor.GetRuleCounters("retry")["sequence"])
+
+	executor.ResetRuleCounters("retry")
+	assert.Equal(t, int64(0), executor.GetSequenceCounter("retry"))
+	assert.Equal(t, []string{"retry"}, executeBodies(t, executor, rule, 1))
+
+	executor.ResetRuleCounters("")
+	assert.Equal(t, int64(0), executor.GetSequenceCounter("retry"))
+}
+
+func TestSequenceResponse_StepDelayPerRule(t *testing.T) {
+	executor := NewMockExecutor()
+	delay := &models.DelayConfig{Type: "step", Fixed: 0, Step: 1}
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+
+	ruleA := &models.Rule{ID: "a", Protocol: models.ProtocolHTTP, Response: models.Response{
+		Type: models.ResponseTypeStatic, Delay: delay, Content: map[string]interface{}{"body": "a"},
+	}}
+	ruleB := &models.Rule{ID: "b", Protocol: models.ProtocolHTTP, Response: models.Response{
+		Type: models.ResponseTypeStatic, Delay: delay, Content: map[string]interface{}{"body": "b"},
+	}}
+
+	for i := 0; i < 2; i++ {
+		_, err := executor.Execute(request, ruleA)
+		require.NoError(t, err)
+	}
+	_, err := executor.Execute(request, ruleB)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), executor.GetStepCounter("a"))
+	assert.Equal(t, int64(1), executor.GetStepCounter("b"))
+}
+
+func TestSequenceResponse_InvalidConfig(t *testing.T) {
+	executor := NewMockExecutor()
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+
+	tests := []struct {
+		name    string
+		content map[string]interface{}
+		errMsg  string
+	}{
+		{"空序列", map[string]interface{}{"mode": "cycle"}, "sequence responses are empty"},
+		{"未知模式", map[string]interface{}{"mode": "shuffle", "responses": []interface{}{textResponse(200, "a")}}, "unsupported sequence mode"},
+		{"嵌套序列", map[string]interface{}{"responses": []interface{}{map[string]interface{}{"type": "Sequence"}}}, "nested sequence"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executor.Execute(request, sequenceRule("invalid", tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package executor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/engine"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ScriptResponseConfig 脚本响应配置
+type ScriptResponseConfig struct {
+	Script  string `json:"script"`            // JavaScript 脚本代码，最后一个表达式的值作为响应
+	Timeout int    `json:"timeout,omitempty"` // 超时时间（毫秒），为 0 时使用默认值
+}
+
+// ScriptResult 脚本执行结果
+type ScriptResult struct {
+	StatusCode  int
+	Headers     map[string]string
+	Body        []byte
+	ContentType models.ContentType
+}
+
+// ScriptExecutor 脚本响应执行器
+//
+// 脚本可使用 request、rule、env 对象以及 respond 辅助函数，
+// 最后一个表达式需返回响应对象：
+//
+//	{ status: 200, headers: {...}, body: ..., content_type: "JSON" }
+//
+// body 为字符串时按文本处理，为对象/数组时按 JSON 处理，
+// 为 Uint8Array/ArrayBuffer 时按二进制处理；content_type 可显式覆盖推断结果。
+type ScriptExecutor struct {
+	maxExecutionTime time.Duration
+	vmPool           *engine.VMPool
+	programs         *engine.ProgramCache
+}
+
+// NewScriptExecutor 创建脚本响应执行器
+func NewScriptExecutor() *ScriptExecutor {
+	return &ScriptExecutor{
+		maxExecutionTime: 5 * time.Second, // 默认最大执行时间 5 秒
+		vmPool:           engine.NewVMPool(),
+		programs:         engine.NewProgramCache(500), // 默认缓存500个规则的已编译脚本
+	}
+}
+
+// Execute 执行响应脚本
+func (s *ScriptExecutor) Execute(request *adapter.Request, rule *models.Rule, env map[string]interface{}) (*ScriptResult, error) {
+	// 解析脚本配置
+	contentBytes, err := json.Marshal(rule.Response.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ScriptResponseConfig
+	if err := json.Unmarshal(contentBytes, &config); err != nil {
+		return nil, err
+	}
+	if config.Script == "" {
+		return nil, errors.New("script not found in response content")
+	}
+
+	// 按规则缓存已编译脚本，脚本变更时自动重新编译
+	cacheKey := rule.ID
+	if cacheKey == "" {
+		cacheKey = config.Script
+	}
+	program, err := s.programs.CompileFor(cacheKey, config.Script)
+	if err != nil {
+		logger.Error("response script compile error",
+			zap.String("rule_id", rule.ID),
+			zap.Error(err))
+		return nil, fmt.Errorf("script compile failed: %w", err)
+	}
+
+	timeout := s.maxExecutionTime
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Millisecond
+	}
+
+	if env == nil {
+		env = make(map[string]interface{})
+	}
+
+	// 从池中获取运行时，归还时清除脚本新增的全局属性
+	vm := s.vmPool.Get(program)
+	defer s.vmPool.Put(vm)
+
+	engine.InjectSecureAPI(vm.Runtime, &engine.ScriptContext{
+		Request: request,
+		Rule:    rule,
+		Env:     env,
+	})
+	injectRespondAPI(vm.Runtime)
+
+	startTime := time.Now()
+	value, err := engine.RunProgram(vm.Runtime, program.Program, timeout)
+	if err != nil {
+		logger.Error("response script execution error",
+			zap.String("rule_id", rule.ID),
+			zap.Duration("duration", time.Since(startTime)),
+			zap.Error(err))
+		return nil, err
+	}
+
+	return convertScriptValue(value)
+}
+
+// injectRespondAPI 注入响应构建辅助函数
+func injectRespondAPI(vm *goja.Runtime) {
+	build := func(contentType models.ContentType) func(body goja.Value, status goja.Value, headers goja.Value) map[string]interface{} {
+		return func(body goja.Value, status goja.Value, headers goja.Value) map[string]interface{} {
+			result := map[string]interface{}{
+				"content_type": string(contentType),
+			}
+			if body != nil && !goja.IsUndefined(body) {
+				result["body"] = body.Export()
+			}
+			if status != nil && !goja.IsUndefined(status) && !goja.IsNull(status) {
+				result["status"] = status.Export()
+			}
+			if headers != nil && !goja.IsUndefined(headers) && !goja.IsNull(headers) {
+				result["headers"] = headers.Export()
+			}
+			return result
+		}
+	}
+
+	vm.Set("respond", map[string]interface{}{
+		"json":  build(models.ContentTypeJSON),
+		"text":  build(models.ContentTypeText),
+		"html":  build(models.ContentTypeHTML),
+		"xml":   build(models.ContentTypeXML),
+		"bytes": build(models.ContentTypeBinary),
+	})
+}
+
+// convertScriptValue 将脚本返回值转换为响应结果
+func convertScriptValue(value goja.Value) (*ScriptResult, error) {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil, errors.New("script must return a response object")
+	}
+
+	obj, ok := value.Export().(map[string]interface{})
+	if !ok {
+		return nil, errors.New("script must return a response object")
+	}
+
+	result := &ScriptResult{
+		StatusCode: 200,
+		Headers:    make(map[string]string),
+	}
+
+	// 状态码
+	status, exists := obj["status"]
+	if !exists {
+		status, exists = obj["status_code"]
+	}
+	if exists {
+		code, ok := toStatusCode(status)
+		if !ok {
+			return nil, fmt.Errorf("invalid status code: %v", status)
+		}
+		result.StatusCode = code
+	}
+
+	// 响应头
+	if headers, ok := obj["headers"].(map[string]interface{}); ok {
+		for key, val := range headers {
+			result.Headers[key] = fmt.Sprint(val)
+		}
+	}
+
+	// 响应体
+	if ct, ok := obj["content_type"].(string); ok && ct != "" {
+		result.ContentType = models.ContentType(ct)
+	}
+
+	body, err := encodeScriptBody(obj["body"], &result.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	result.Body = body
+
+	return result, nil
+}
+
+// encodeScriptBody 编码脚本返回的响应体，并在未指定时推断内容类型
+func encodeScriptBody(body interface{}, contentType *models.ContentType) ([]byte, error) {
+	switch v := body.(type) {
+	case nil:
+		if *contentType == "" {
+			*contentType = models.ContentTypeJSON
+		}
+		return []byte{}, nil
+	case []byte:
+		if *contentType == "" {
+			*contentType = models.ContentTypeBinary
+		}
+		return v, nil
+	case goja.ArrayBuffer:
+		if *contentType == "" {
+			*contentType = models.ContentTypeBinary
+		}
+		return v.Bytes(), nil
+	case string:
+		switch *contentType {
+		case "":
+			*contentType = models.ContentTypeText
+			return []byte(v), nil
+		case models.ContentTypeBinary:
+			// 二进制内容支持 Base64 编码的字符串
+			decoded, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode base64 binary body: %w", err)
+			}
+			return decoded, nil
+		case models.ContentTypeJSON:
+			// 已是 JSON 文本时直接使用，否则编码为 JSON 字符串
+			if json.Valid([]byte(v)) {
+				return []byte(v), nil
+			}
+			return json.Marshal(v)
+		default:
+			return []byte(v), nil
+		}
+	default:
+		if *contentType == "" {
+			*contentType = models.ContentTypeJSON
+		}
+		if *contentType == models.ContentTypeBinary {
+			if data, ok := toByteSlice(v); ok {
+				return data, nil
+			}
+		}
+		return json.Marshal(v)
+	}
+}
+
+// toStatusCode 转换状态码
+func toStatusCode(v interface{}) (int, bool) {
+	var code int
+	switch n := v.(type) {
+	case int64:
+		code = int(n)
+	case int:
+		code = n
+	case float64:
+		code = int(n)
+	case string:
+		if _, err := fmt.Sscanf(strings.TrimSpace(n), "%d", &code); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if code < 100 || code > 999 {
+		return 0, false
+	}
+	return code, true
+}
+
+// toByteSlice 将数字数组转换为字节切片
+func toByteSlice(v interface{}) ([]byte, bool) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	data := make([]byte, len(items))
+	for i, item := range items {
+		switch n := item.(type) {
+		case int64:
+			data[i] = byte(n)
+		case float64:
+			data[i] = byte(n)
+		default:
+			return nil, false
+		}
+	}
+	return data, true
+}
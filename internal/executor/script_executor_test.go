@@ -0,0 +1,176 @@
+package executor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScriptRule(id, script string) *models.Rule {
+	return &models.Rule{
+		ID:       id,
+		Name:     "script rule",
+		Protocol: models.ProtocolHTTP,
+		Response: models.Response{
+			Type: models.ResponseTypeScript,
+			Content: map[string]interface{}{
+				"script": script,
+			},
+		},
+	}
+}
+
+// TestScriptResponse_CartTotal 测试根据请求体计算购物车总价
+func TestScriptResponse_CartTotal(t *testing.T) {
+	executor := NewMockExecutor()
+
+	rule := newScriptRule("rule-cart", `
+		var items = request.json.items;
+		var total = 0;
+		for (var i = 0; i < items.length; i++) {
+			total += items[i].price * items[i].qty;
+		}
+		({
+			status: total > 100 ? 201 : 200,
+			headers: { "X-Cart-Items": items.length },
+			body: { total: total, free_shipping: total > 100 }
+		})
+	`)
+
+	request := &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/cart",
+		Body:     []byte(`{"items":[{"price":30,"qty":2},{"price":50,"qty":1}]}`),
+		Metadata: map[string]interface{}{"method": "POST"},
+	}
+
+	response, err := executor.Execute(request, rule)
+	require.NoError(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+	assert.Equal(t, "2", response.Headers["X-Cart-Items"])
+	assert.Equal(t, "application/json", response.Headers["Content-Type"])
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(response.Body, &body))
+	assert.Equal(t, float64(110), body["total"])
+	assert.Equal(t, true, body["free_shipping"])
+}
+
+// TestScriptResponse_BodyTypes 测试不同响应体类型
+func TestScriptResponse_BodyTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		statusCode  int
+		contentType string
+		body        []byte
+	}{
+		{
+			name:        "文本响应",
+			script:      `({ body: "hello " + request.path })`,
+			statusCode:  200,
+			contentType: "text/plain",
+			body:        []byte("hello /api/test"),
+		},
+		{
+			name:        "respond.json 辅助函数",
+			script:      `respond.json({ ok: true }, 202)`,
+			statusCode:  202,
+			contentType: "application/json",
+			body:        []byte(`{"ok":true}`),
+		},
+		{
+			name:        "respond.xml 辅助函数",
+			script:      `respond.xml("<ok/>", 200, { "Content-Type": "text/xml" })`,
+			statusCode:  200,
+			contentType: "text/xml",
+			body:        []byte("<ok/>"),
+		},
+		{
+			name:        "Base64 二进制响应",
+			script:      `respond.bytes("AQID")`,
+			statusCode:  200,
+			contentType: "application/octet-stream",
+			body:        []byte{1, 2, 3},
+		},
+		{
+			name:        "Uint8Array 二进制响应",
+			script:      `({ status: "206", body: new Uint8Array([4, 5, 6]) })`,
+			statusCode:  206,
+			contentType: "application/octet-stream",
+			body:        []byte{4, 5, 6},
+		},
+	}
+
+	executor := NewMockExecutor()
+	request := &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/test",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := executor.Execute(request, newScriptRule("", tt.script))
+			require.NoError(t, err)
+			assert.Equal(t, tt.statusCode, response.StatusCode)
+			assert.Equal(t, tt.contentType, response.Headers["Content-Type"])
+			assert.Equal(t, tt.body, response.Body)
+		})
+	}
+}
+
+// TestScriptExecutor_Env 测试环境变量注入
+func TestScriptExecutor_Env(t *testing.T) {
+	executor := NewScriptExecutor()
+
+	rule := newScriptRule("rule-env", `respond.text(env.region + ":" + rule.name)`)
+	result, err := executor.Execute(&adapter.Request{}, rule, map[string]interface{}{"region": "cn-east"})
+	require.NoError(t, err)
+	assert.Equal(t, "cn-east:script rule", string(result.Body))
+}
+
+// TestScriptExecutor_Errors 测试错误场景
+func TestScriptExecutor_Errors(t *testing.T) {
+	executor := NewScriptExecutor()
+	executor.maxExecutionTime = 100 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		script string
+		errMsg string
+	}{
+		{"语法错误", `this is not javascript {{{`, "compile failed"},
+		{"未返回对象", `1 + 1`, "must return a response object"},
+		{"无效状态码", `({ status: 42 })`, "invalid status code"},
+		{"执行超时", `while(true) {}`, "execution failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executor.Execute(&adapter.Request{}, newScriptRule("", tt.script), nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+// TestScriptExecutor_ProgramCachePerRule 测试按规则缓存已编译脚本
+func TestScriptExecutor_ProgramCachePerRule(t *testing.T) {
+	executor := NewScriptExecutor()
+	request := &adapter.Request{}
+
+	result, err := executor.Execute(request, newScriptRule("rule-1", `respond.text("v1")`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(result.Body))
+
+	// 规则脚本更新后重新编译，缓存项数量不变
+	result, err = executor.Execute(request, newScriptRule("rule-1", `respond.text("v2")`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(result.Body))
+	assert.Equal(t, 1, executor.programs.Size())
+}
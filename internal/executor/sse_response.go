@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// sseResponse 生成 Server-Sent Events 响应，事件的 data 按模板渲染
+//
+// 统一响应模型中 Metadata 的 sse_stream 为待发送的事件流，由 HTTPAdapter.WriteResponse 逐个刷新；
+// Body 为事件流第一轮的完整内容，供请求日志和契约校验使用。
+func (e *MockExecutor) sseResponse(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
+	if rule.Protocol != models.ProtocolHTTP {
+		return nil, fmt.Errorf("only HTTP protocol is supported in SSE response")
+	}
+
+	contentBytes, err := json.Marshal(rule.Response.Content)
+	if err != nil {
+		logger.Error("failed to marshal response content", zap.Error(err))
+		return nil, err
+	}
+
+	var sseResp models.SSEResponse
+	if err := json.Unmarshal(contentBytes, &sseResp); err != nil {
+		logger.Error("failed to unmarshal sse response", zap.Error(err))
+		return nil, err
+	}
+	if err := validateSSEResponse(&sseResp); err != nil {
+		return nil, err
+	}
+
+	ctx := e.templateEngine.BuildContext(request, rule, nil)
+	stream := &adapter.SSEStream{
+		Events:   make([]adapter.SSEEvent, 0, len(sseResp.Events)),
+		Loop:     sseResp.Loop,
+		KeepOpen: sseResp.KeepOpen,
+	}
+	var body []byte
+	for _, event := range sseResp.Events {
+		data, err := e.renderSSEData(event.Data, ctx)
+		if err != nil {
+			return nil, err
+		}
+		built := adapter.SSEEvent{
+			ID:    event.ID,
+			Event: event.Event,
+			Data:  data,
+			Retry: event.Retry,
+			Delay: time.Duration(event.Delay) * time.Millisecond,
+		}
+		stream.Events = append(stream.Events, built)
+		body = append(body, adapter.FormatSSEEvent(built)...)
+	}
+
+	headers := sseResp.Headers
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if _, ok := headers["Content-Type"]; !ok {
+		headers["Content-Type"] = "text/event-stream"
+	}
+	if _, ok := headers["Cache-Control"]; !ok {
+		headers["Cache-Control"] = "no-cache"
+	}
+	// 避免反向代理缓冲事件
+	if _, ok := headers["X-Accel-Buffering"]; !ok {
+		headers["X-Accel-Buffering"] = "no"
+	}
+
+	statusCode := sseResp.StatusCode
+	if statusCode == 0 {
+		statusCode = 200
+	}
+
+	return &adapter.Response{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       body,
+		Metadata: map[string]interface{}{
+			"sse_stream": stream,
+		},
+	}, nil
+}
+
+// validateSSEResponse 校验事件字段和流配置，id、event 不能包含换行，循环发送要求事件之间有间隔
+func validateSSEResponse(sseResp *models.SSEResponse) error {
+	if len(sseResp.Events) == 0 && !sseResp.KeepOpen {
+		return fmt.Errorf("sse events are empty")
+	}
+
+	hasDelay := false
+	for i, event := range sseResp.Events {
+		if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Event, "\r\n") {
+			return fmt.Errorf("sse events[%d] id and event must not contain line breaks", i)
+		}
+		if event.Delay < 0 || event.Retry < 0 {
+			return fmt.Errorf("sse events[%d] delay and retry must not be negative", i)
+		}
+		if event.Delay > 0 {
+			hasDelay = true
+		}
+	}
+	if sseResp.Loop && !hasDelay {
+		return fmt.Errorf("looping sse events require at least one event with a delay")
+	}
+	return nil
+}
+
+// renderSSEData 渲染事件数据：字符串按文本模板渲染，其他 JSON 值按 JSON 模板渲染后序列化
+func (e *MockExecutor) renderSSEData(data interface{}, ctx *TemplateContext) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		rendered, err := e.templateEngine.Render(v, ctx)
+		if err != nil {
+			logger.Error("failed to render sse data template", zap.Error(err))
+			return "", fmt.Errorf("failed to render sse data template: %w", err)
+		}
+		return rendered, nil
+	default:
+		rendered, err := e.templateEngine.RenderJSON(v, ctx)
+		if err != nil {
+			logger.Error("failed to render sse json template", zap.Error(err))
+			return "", fmt.Errorf("failed to render sse json template: %w", err)
+		}
+		encoded, err := json.Marshal(rendered)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sseRule(content map[string]interface{}) *models.Rule {
+	return &models.Rule{
+		ID:       "sse",
+		Protocol: models.ProtocolHTTP,
+		Response: models.Response{Type: models.ResponseTypeSSE, Content: content},
+	}
+}
+
+func TestSSEResponse(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := sseRule(map[string]interface{}{
+		"headers": map[string]interface{}{"X-Stream": "chat"},
+		"events": []interface{}{
+			map[string]interface{}{"id": "1", "event": "delta", "data": map[string]interface{}{"content": "{{.Request.Body.prompt}}"}},
+			map[string]interface{}{"id": "2", "data": "line1\nline2", "delay": 20, "retry": 3000},
+			map[string]interface{}{"data": "[DONE]"},
+		},
+		"keep_open": true,
+	})
+
+	request := &adapter.Request{Protocol: models.ProtocolHTTP, Path: "/chat", Body: []byte(`{"prompt":"hi"}`)}
+	response, err := executor.Execute(request, rule)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Headers["Content-Type"])
+	assert.Equal(t, "no-cache", response.Headers["Cache-Control"])
+	assert.Equal(t, "chat", response.Headers["X-Stream"])
+
+	stream, ok := response.Metadata["sse_stream"].(*adapter.SSEStream)
+	require.True(t, ok)
+	assert.True(t, stream.KeepOpen)
+	assert.False(t, stream.Loop)
+	require.Len(t, stream.Events, 3)
+	assert.Equal(t, adapter.SSEEvent{ID: "1", Event: "delta", Data: `{"content":"hi"}`}, stream.Events[0])
+	assert.Equal(t, adapter.SSEEvent{ID: "2", Data: "line1\nline2", Retry: 3000, Delay: 20 * time.Millisecond}, stream.Events[1])
+
+	assert.Equal(t, "id: 1\nevent: delta\ndata: {\"content\":\"hi\"}\n\n"+
+		"id: 2\nretry: 3000\ndata: line1\ndata: line2\n\n"+
+		"data: [DONE]\n\n", string(response.Body))
+}
+
+func TestSSEResponse_Invalid(t *testing.T) {
+	executor := NewMockExecutor()
+	request := &adapter.Request{Protocol: models.ProtocolHTTP}
+
+	tests := []struct {
+		name    string
+		content map[string]interface{}
+	}{
+		{"没有事件", map[string]interface{}{}},
+		{"id 包含换行", map[string]interface{}{"events": []interface{}{map[string]interface{}{"id": "1\n2", "data": "x"}}}},
+		{"负数延迟", map[string]interface{}{"events": []interface{}{map[string]interface{}{"data": "x", "delay": -1}}}},
+		{"循环没有间隔", map[string]interface{}{"loop": true, "events": []interface{}{map[string]interface{}{"data": "x"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executor.Execute(request, sseRule(tt.content))
+			assert.Error(t, err)
+		})
+	}
+
+	rule := sseRule(map[string]interface{}{"events": []interface{}{map[string]interface{}{"data": "x"}}})
+	rule.Protocol = models.ProtocolGRPC
+	_, err := executor.Execute(request, rule)
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// applyHTTPStream 按分块与限速配置拆分响应体，设置 Metadata 的 body_stream 由 HTTPAdapter.WriteResponse 逐段刷新
+//
+// Body 保留完整响应体，供请求日志、契约校验和录制使用。
+func applyHTTPStream(response *adapter.Response, stream *models.HTTPStream) error {
+	if stream.ChunkSize < 0 || stream.ChunkDelay < 0 || stream.BytesPerSecond < 0 {
+		return fmt.Errorf("stream chunk_size, chunk_delay and bytes_per_second must not be negative")
+	}
+	if stream.ChunkSize == 0 && stream.ChunkDelay > 0 {
+		return fmt.Errorf("stream chunk_delay requires chunk_size")
+	}
+	if stream.ChunkSize == 0 && stream.BytesPerSecond == 0 {
+		return nil
+	}
+
+	bodyStream := &adapter.BodyStream{
+		Chunked:        stream.ChunkSize > 0,
+		BytesPerSecond: stream.BytesPerSecond,
+	}
+	if stream.ChunkSize == 0 {
+		bodyStream.Chunks = []adapter.BodyChunk{{Data: response.Body}}
+	} else {
+		delay := time.Duration(stream.ChunkDelay) * time.Millisecond
+		for offset := 0; offset < len(response.Body); offset += stream.ChunkSize {
+			chunk := adapter.BodyChunk{Data: response.Body[offset:min(offset+stream.ChunkSize, len(response.Body))]}
+			if offset > 0 {
+				chunk.Delay = delay
+			}
+			bodyStream.Chunks = append(bodyStream.Chunks, chunk)
+		}
+	}
+
+	response.Metadata["body_stream"] = bodyStream
+	return nil
+}
+
+// ndjsonResponse 生成 NDJSON 流式响应，每行按 JSON 模板渲染
+func (e *MockExecutor) ndjsonResponse(request *adapter.Request, rule *models.Rule) (*adapter.Response, error) {
+	if rule.Protocol != models.ProtocolHTTP {
+		return nil, fmt.Errorf("only HTTP protocol is supported in NDJSON response")
+	}
+
+	contentBytes, err := json.Marshal(rule.Response.Content)
+	if err != nil {
+		logger.Error("failed to marshal response content", zap.Error(err))
+		return nil, err
+	}
+
+	var ndjsonResp models.NDJSONResponse
+	if err := json.Unmarshal(contentBytes, &ndjsonResp); err != nil {
+		logger.Error("failed to unmarshal ndjson response", zap.Error(err))
+		return nil, err
+	}
+	if len(ndjsonResp.Lines) == 0 {
+		return nil, fmt.Errorf("ndjson lines are empty")
+	}
+	if ndjsonResp.BytesPerSecond < 0 {
+		return nil, fmt.Errorf("ndjson bytes_per_second must not be negative")
+	}
+
+	ctx := e.templateEngine.BuildContext(request, rule, nil)
+	stream := &adapter.BodyStream{
+		Chunks:         make([]adapter.BodyChunk, 0, len(ndjsonResp.Lines)),
+		Chunked:        true,
+		BytesPerSecond: ndjsonResp.BytesPerSecond,
+	}
+	var body []byte
+	for i, line := range ndjsonResp.Lines {
+		if line.Delay < 0 {
+			return nil, fmt.Errorf("ndjson lines[%d] delay must not be negative", i)
+		}
+		rendered, err := e.templateEngine.RenderJSON(line.Data, ctx)
+		if err != nil {
+			logger.Error("failed to render ndjson template", zap.Error(err))
+			return nil, fmt.Errorf("failed to render ndjson template: %w", err)
+		}
+		data, err := json.Marshal(rendered)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, '\n')
+		stream.Chunks = append(stream.Chunks, adapter.BodyChunk{
+			Data:  data,
+			Delay: time.Duration(line.Delay) * time.Millisecond,
+		})
+		body = append(body, data...)
+	}
+
+	headers := ndjsonResp.Headers
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if _, ok := headers["Content-Type"]; !ok {
+		headers["Content-Type"] = "application/x-ndjson"
+	}
+
+	statusCode := ndjsonResp.StatusCode
+	if statusCode == 0 {
+		statusCode = 200
+	}
+
+	return &adapter.Response{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       body,
+		Metadata: map[string]interface{}{
+			"body_stream": stream,
+		},
+	}, nil
+}
@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPStream_Chunks(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := &models.Rule{
+		Protocol: models.ProtocolHTTP,
+		Response: models.Response{
+			Type: models.ResponseTypeStatic,
+			Content: map[string]interface{}{
+				"content_type": "Text",
+				"body":         "abcdefghij",
+				"stream":       map[string]interface{}{"chunk_size": 4, "chunk_delay": 50},
+			},
+		},
+	}
+
+	response, err := executor.Execute(&adapter.Request{Protocol: models.ProtocolHTTP}, rule)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdefghij", string(response.Body))
+
+	stream, ok := response.Metadata["body_stream"].(*adapter.BodyStream)
+	require.True(t, ok)
+	assert.True(t, stream.Chunked)
+	assert.Equal(t, []adapter.BodyChunk{
+		{Data: []byte("abcd")},
+		{Data: []byte("efgh"), Delay: 50 * time.Millisecond},
+		{Data: []byte("ij"), Delay: 50 * time.Millisecond},
+	}, stream.Chunks)
+}
+
+func TestHTTPStream_ThrottledFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "large.bin")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", 4096)), 0o644))
+
+	executor := NewMockExecutor()
+	rule := &models.Rule{
+		Protocol: models.ProtocolHTTP,
+		Response: models.Response{
+			Type: models.ResponseTypeStatic,
+			Content: map[string]interface{}{
+				"content_type": "Binary",
+				"body":         map[string]interface{}{"file_path": path},
+				"stream":       map[string]interface{}{"bytes_per_second": 1024},
+			},
+		},
+	}
+
+	response, err := executor.Execute(&adapter.Request{Protocol: models.ProtocolHTTP}, rule)
+	require.NoError(t, err)
+
+	stream, ok := response.Metadata["body_stream"].(*adapter.BodyStream)
+	require.True(t, ok)
+	assert.False(t, stream.Chunked)
+	assert.Equal(t, 1024, stream.BytesPerSecond)
+	require.Len(t, stream.Chunks, 1)
+	assert.Equal(t, 4096, stream.Size())
+}
+
+func TestHTTPStream_Invalid(t *testing.T) {
+	executor := NewMockExecutor()
+	for _, stream := range []map[string]interface{}{
+		{"chunk_size": -1},
+		{"bytes_per_second": -1},
+		{"chunk_delay": 100},
+	} {
+		rule := &models.Rule{
+			Protocol: models.ProtocolHTTP,
+			Response: models.Response{
+				Type:    models.ResponseTypeDynamic,
+				Content: map[string]interface{}{"body": map[string]interface{}{"ok": true}, "stream": stream},
+			},
+		}
+		_, err := executor.Execute(&adapter.Request{Protocol: models.ProtocolHTTP}, rule)
+		assert.Error(t, err, "%v", stream)
+	}
+}
+
+func TestNDJSONResponse(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := &models.Rule{
+		Protocol: models.ProtocolHTTP,
+		Response: models.Response{
+			Type: models.ResponseTypeNDJSON,
+			Content: map[string]interface{}{
+				"lines": []interface{}{
+					map[string]interface{}{"data": map[string]interface{}{"status": "pulling", "model": "{{.Request.Body.model}}"}},
+					map[string]interface{}{"data": map[string]interface{}{"status": "done"}, "delay": 30},
+				},
+			},
+		},
+	}
+
+	request := &adapter.Request{Protocol: models.ProtocolHTTP, Body: []byte(`{"model":"llama"}`)}
+	response, err := executor.Execute(request, rule)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "application/x-ndjson", response.Headers["Content-Type"])
+	assert.Equal(t, "{\"model\":\"llama\",\"status\":\"pulling\"}\n{\"status\":\"done\"}\n", string(response.Body))
+
+	stream, ok := response.Metadata["body_stream"].(*adapter.BodyStream)
+	require.True(t, ok)
+	assert.True(t, stream.Chunked)
+	require.Len(t, stream.Chunks, 2)
+	assert.Equal(t, 30*time.Millisecond, stream.Chunks[1].Delay)
+
+	rule.Response.Content = map[string]interface{}{"lines": []interface{}{}}
+	_, err = executor.Execute(request, rule)
+	assert.Error(t, err)
+}
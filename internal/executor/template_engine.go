@@ -148,12 +148,16 @@ type TemplateContext struct {
 
 // RequestContext 请求上下文
 type RequestContext struct {
-	Method  string            `json:"method"`
-	Path    string            `json:"path"`
-	Headers map[string]string `json:"headers"`
-	Query   map[string]string `json:"query"`
-	Body    interface{}       `json:"body"`
-	IP      string            `json:"ip"`
+	Method     string                     `json:"method"`
+	Path       string                     `json:"path"`
+	Headers    map[string]string          `json:"headers"`
+	Query      map[string]string          `json:"query"`
+	Body       interface{}                `json:"body"`
+	IP         string                     `json:"ip"`
+	Form       map[string]string          `json:"form,omitempty"`        // 表单字段（取第一个值）
+	FormValues map[string][]string        `json:"form_values,omitempty"` // 表单字段（全部值）
+	Files      []adapter.FormFile         `json:"files,omitempty"`       // multipart 文件部分
+	ClientCert *adapter.ClientCertificate `json:"client_cert,omitempty"` // 客户端证书（mTLS），未出示时为 nil
 }
 
 // RuleContext 规则上下文
@@ -184,11 +188,12 @@ func (e *TemplateEngine) BuildContext(request *adapter.Request, rule *models.Rul
 
 	ctx := &TemplateContext{
 		Request: &RequestContext{
-			Method:  method,
-			Path:    request.Path,
-			Headers: request.Headers,
-			Query:   query,
-			IP:      request.SourceIP,
+			Method:     method,
+			Path:       request.Path,
+			Headers:    request.Headers,
+			Query:      query,
+			IP:         request.SourceIP,
+			ClientCert: adapter.GetClientCertificate(request),
 		},
 		Rule: &RuleContext{
 			ID:       rule.ID,
@@ -210,6 +215,13 @@ func (e *TemplateEngine) BuildContext(request *adapter.Request, rule *models.Rul
 		}
 	}
 
+	// 解析表单请求体
+	if form, err := adapter.GetForm(request); err == nil && form != nil {
+		ctx.Request.Form = form.FirstValues()
+		ctx.Request.FormValues = form.Fields
+		ctx.Request.Files = form.Files
+	}
+
 	// 设置环境变量
 	if env != nil && env.Variables != nil {
 		ctx.Environment.Variables = env.Variables
@@ -433,6 +433,28 @@ func TestTemplateEngine_BuildContext(t *testing.T) {
 	// Check environment context
 	assert.Equal(t, "http://localhost:9090", ctx.Environment.Variables["base_url"])
 	assert.Equal(t, "v1", ctx.Environment.Variables["version"])
+	assert.Nil(t, ctx.Request.ClientCert)
+}
+
+func TestTemplateEngine_ClientCert(t *testing.T) {
+	engine := NewTemplateEngine()
+	request := &adapter.Request{
+		Path: "/api/orders",
+		Metadata: map[string]interface{}{
+			"method":      "GET",
+			"client_cert": &adapter.ClientCertificate{SubjectCN: "partner-a", SANs: []string{"partner-a.example.test"}},
+		},
+	}
+	template := `{{with .Request.ClientCert}}{{.SubjectCN}} {{index .SANs 0}}{{else}}anonymous{{end}}`
+
+	result, err := engine.Render(template, engine.BuildContext(request, &models.Rule{}, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "partner-a partner-a.example.test", result)
+
+	delete(request.Metadata, "client_cert")
+	result, err = engine.Render(template, engine.BuildContext(request, &models.Rule{}, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "anonymous", result)
 }
 
 func TestTemplateEngine_BuiltInFunctions(t *testing.T) {
@@ -483,3 +505,26 @@ func TestTemplateEngine_BuiltInFunctions(t *testing.T) {
 		assert.Len(t, result, 10)
 	})
 }
+
+func TestTemplateEngine_BuildContext_FormBody(t *testing.T) {
+	engine := NewTemplateEngine()
+
+	request := &adapter.Request{
+		Path:    "/api/login",
+		Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Body:    []byte("username=alice&roles=admin&roles=dev"),
+		Metadata: map[string]interface{}{
+			"method": "POST",
+		},
+	}
+	rule := &models.Rule{ID: "rule-form", Name: "form rule"}
+
+	ctx := engine.BuildContext(request, rule, nil)
+	require.NotNil(t, ctx.Request.Form)
+	assert.Equal(t, "alice", ctx.Request.Form["username"])
+	assert.Equal(t, []string{"admin", "dev"}, ctx.Request.FormValues["roles"])
+
+	rendered, err := engine.Render(`{"user":"{{.Request.Form.username}}","roles":{{len (index .Request.FormValues "roles")}}}`, ctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":"alice","roles":2}`, rendered)
+}
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// websocketResponse 生成 WebSocket 响应，render 为 true 时按模板渲染 text、json 消息（Dynamic 响应）
+//
+// 统一响应模型中 Metadata 的 websocket_frames 为依次发送的消息帧，websocket_close 为关闭帧配置，
+// websocket_periodic 为周期推送配置，由 WebSocketAdapter.Build 组装为待发送的帧。
+func (e *MockExecutor) websocketResponse(request *adapter.Request, rule *models.Rule, env *models.Environment, render bool) (*adapter.Response, error) {
+	contentBytes, err := json.Marshal(rule.Response.Content)
+	if err != nil {
+		logger.Error("failed to marshal response content", zap.Error(err))
+		return nil, err
+	}
+
+	var wsResp models.WebSocketResponse
+	if err := json.Unmarshal(contentBytes, &wsResp); err != nil {
+		logger.Error("failed to unmarshal websocket response", zap.Error(err))
+		return nil, err
+	}
+
+	var ctx *TemplateContext
+	if render {
+		ctx = e.templateEngine.BuildContext(request, rule, env)
+	}
+
+	frames := make([]adapter.WebSocketFrame, 0, len(wsResp.Messages))
+	for _, message := range wsResp.Messages {
+		frame, err := e.websocketFrame(message, ctx)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+
+	// 周期推送的消息在每次推送时渲染，这里只校验格式
+	if wsResp.Periodic != nil {
+		if wsResp.Periodic.Interval <= 0 || len(wsResp.Periodic.Messages) == 0 {
+			return nil, fmt.Errorf("websocket periodic requires a positive interval and messages")
+		}
+		for _, message := range wsResp.Periodic.Messages {
+			if _, err := adapter.NewWebSocketMessageFrame(message); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &adapter.Response{
+		Headers: make(map[string]string),
+		Metadata: map[string]interface{}{
+			"websocket_frames":   frames,
+			"websocket_close":    wsResp.Close,
+			"websocket_periodic": wsResp.Periodic,
+		},
+	}, nil
+}
+
+// websocketFrame 将回复消息转换为消息帧，ctx 不为空时先按模板渲染 text、json 消息
+func (e *MockExecutor) websocketFrame(message models.WebSocketReplyMessage, ctx *TemplateContext) (adapter.WebSocketFrame, error) {
+	if ctx != nil {
+		switch message.Type {
+		case "", models.WebSocketMessageText:
+			if text, ok := message.Data.(string); ok {
+				rendered, err := e.templateEngine.Render(text, ctx)
+				if err != nil {
+					return adapter.WebSocketFrame{}, fmt.Errorf("failed to render websocket message: %w", err)
+				}
+				message.Data = rendered
+			}
+		case models.WebSocketMessageJSON:
+			rendered, err := e.templateEngine.RenderJSON(message.Data, ctx)
+			if err != nil {
+				logger.Error("failed to render json template", zap.Error(err))
+				return adapter.WebSocketFrame{}, fmt.Errorf("failed to render json template: %w", err)
+			}
+			message.Data = rendered
+		}
+	}
+	return adapter.NewWebSocketMessageFrame(message)
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketDynamicResponse(t *testing.T) {
+	executor := NewMockExecutor()
+	rule := &models.Rule{
+		Protocol: models.ProtocolWebSocket,
+		Response: models.Response{
+			Type: models.ResponseTypeDynamic,
+			Content: map[string]interface{}{
+				"messages": []interface{}{
+					map[string]interface{}{"data": "echo {{.Request.Body.text}}"},
+					map[string]interface{}{"type": "json", "data": map[string]interface{}{"echo": "{{.Request.Body.text}}"}, "delay": 10},
+					map[string]interface{}{"type": "binary", "data": "AAEC"},
+				},
+				"close": map[string]interface{}{"code": 1000, "reason": "bye"},
+			},
+		},
+	}
+
+	request := &adapter.Request{Protocol: models.ProtocolWebSocket, Body: []byte(`{"text":"hi"}`)}
+	response, err := executor.Execute(request, rule)
+	require.NoError(t, err)
+
+	frames, ok := response.Metadata["websocket_frames"].([]adapter.WebSocketFrame)
+	require.True(t, ok)
+	require.Len(t, frames, 3)
+	assert.Equal(t, adapter.WebSocketFrame{Type: websocket.TextMessage, Data: []byte("echo hi")}, frames[0])
+	assert.Equal(t, websocket.TextMessage, frames[1].Type)
+	assert.JSONEq(t, `{"echo":"hi"}`, string(frames[1].Data))
+	assert.Equal(t, 10*time.Millisecond, frames[1].Delay)
+	assert.Equal(t, adapter.WebSocketFrame{Type: websocket.BinaryMessage, Data: []byte{0, 1, 2}}, frames[2])
+	assert.Equal(t, &models.WebSocketClose{Code: 1000, Reason: "bye"}, response.Metadata["websocket_close"])
+}
+
+func TestWebSocketResponse_InvalidMessages(t *testing.T) {
+	executor := NewMockExecutor()
+	for _, message := range []map[string]interface{}{
+		{"data": map[string]interface{}{"not": "text"}},
+		{"type": "binary", "data": "not base64!"},
+		{"type": "frame", "data": "x"},
+	} {
+		rule := &models.Rule{
+			Protocol: models.ProtocolWebSocket,
+			Response: models.Response{
+				Type:    models.ResponseTypeStatic,
+				Content: map[string]interface{}{"messages": []interface{}{message}},
+			},
+		}
+		_, err := executor.Execute(&adapter.Request{Protocol: models.ProtocolWebSocket}, rule)
+		assert.Error(t, err, "%v", message)
+	}
+}
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,6 +20,11 @@ import (
 type RequestLoggerMiddleware struct {
 	repo    repository.RequestLogRepository
 	enabled bool
+
+	// 正在异步保存的日志，Flush 等待它们写入完成
+	pending   map[uint64]chan struct{}
+	nextID    uint64
+	pendingMu sync.Mutex
 }
 
 // NewRequestLoggerMiddleware 创建请求日志中间件
@@ -25,6 +32,7 @@ func NewRequestLoggerMiddleware(repo repository.RequestLogRepository) *RequestLo
 	return &RequestLoggerMiddleware{
 		repo:    repo,
 		enabled: true,
+		pending: make(map[uint64]chan struct{}),
 	}
 }
 
@@ -39,16 +47,30 @@ type responseWriter struct {
 	body *bytes.Buffer
 }
 
+// maxStreamCaptureSize 事件流响应最多捕获的字节数，持续推送的响应不会无限占用内存
+const maxStreamCaptureSize = 64 * 1024
+
 func (w *responseWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	w.capture(b)
 	return w.ResponseWriter.Write(b)
 }
 
 func (w *responseWriter) WriteString(s string) (int, error) {
-	w.body.WriteString(s)
+	w.capture([]byte(s))
 	return w.ResponseWriter.WriteString(s)
 }
 
+// capture 捕获响应体，事件流响应只保留开头部分（非 JSON 响应体本就截断存储）
+func (w *responseWriter) capture(b []byte) {
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		remaining := max(maxStreamCaptureSize-w.body.Len(), 0)
+		if len(b) > remaining {
+			b = b[:remaining]
+		}
+	}
+	w.body.Write(b)
+}
+
 // Handler 日志记录处理器
 func (m *RequestLoggerMiddleware) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -95,7 +117,7 @@ func (m *RequestLoggerMiddleware) Handler() gin.HandlerFunc {
 			RuleID:        getStringValue(ruleID),
 			Protocol:      m.getProtocol(c),
 			Method:        c.Request.Method,
-			Path:          c.Request.URL.Path,
+			Path:          requestPath(c),
 			StatusCode:    c.Writer.Status(),
 			Duration:      duration,
 			SourceIP:      c.ClientIP(),
@@ -104,18 +126,80 @@ func (m *RequestLoggerMiddleware) Handler() gin.HandlerFunc {
 			Response:      m.buildResponseData(c, blw.body.Bytes()),
 		}
 
-		// 异步保存日志（避免阻塞请求）
-		go func() {
-			ctx := c.Request.Context()
-			if err := m.repo.Create(ctx, requestLog); err != nil {
-				logger.Error("failed to save request log",
-					zap.String("request_id", requestLog.RequestID),
-					zap.Error(err))
-			}
-		}()
+		// 契约校验违规项
+		if violations, ok := c.Get("contract_violations"); ok {
+			requestLog.ContractViolations, _ = violations.([]models.ContractViolation)
+		}
+		if fault, ok := c.Get("fault"); ok {
+			requestLog.Fault, _ = fault.(models.FaultType)
+		}
+		if fallback, ok := c.Get("fallback"); ok {
+			requestLog.Fallback, _ = fallback.(models.FallbackMode)
+		}
+
+		// 在处理器返回前登记，响应到达客户端后发起的 Flush 一定会等待这条日志
+		m.Log(requestLog)
+	}
+}
+
+// Log 异步保存一条请求日志，供不经过 Gin 的协议（如 gRPC）复用
+//
+// 日志在调用返回前登记，之后发起的 Flush 一定会等待它写入完成；
+// 请求结束后其上下文会被取消，不能复用，因此保存使用独立的上下文。
+func (m *RequestLoggerMiddleware) Log(requestLog *models.RequestLog) {
+	if !m.enabled {
+		return
+	}
+
+	done := m.track()
+	go func() {
+		defer done()
+		ctx := context.Background()
+		if err := m.repo.Create(ctx, requestLog); err != nil {
+			logger.Error("failed to save request log",
+				zap.String("request_id", requestLog.RequestID),
+				zap.Error(err))
+		}
+	}()
+}
+
+// track 登记一条正在保存的日志，返回保存结束时调用的函数
+func (m *RequestLoggerMiddleware) track() func() {
+	ch := make(chan struct{})
+
+	m.pendingMu.Lock()
+	id := m.nextID
+	m.nextID++
+	m.pending[id] = ch
+	m.pendingMu.Unlock()
+
+	return func() {
+		m.pendingMu.Lock()
+		delete(m.pending, id)
+		m.pendingMu.Unlock()
+		close(ch)
 	}
 }
 
+// Flush 等待调用前已登记的日志全部保存完成，用于请求校验前的同步屏障
+func (m *RequestLoggerMiddleware) Flush(ctx context.Context) error {
+	m.pendingMu.Lock()
+	waiting := make([]chan struct{}, 0, len(m.pending))
+	for _, ch := range m.pending {
+		waiting = append(waiting, ch)
+	}
+	m.pendingMu.Unlock()
+
+	for _, ch := range waiting {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 // getProtocol 获取协议类型
 func (m *RequestLoggerMiddleware) getProtocol(c *gin.Context) models.ProtocolType {
 	// 检查是否是 WebSocket 升级请求
@@ -131,7 +215,7 @@ func (m *RequestLoggerMiddleware) buildRequestData(c *gin.Context, body []byte)
 
 	// 基本信息
 	data["method"] = c.Request.Method
-	data["path"] = c.Request.URL.Path
+	data["path"] = requestPath(c)
 	data["query"] = c.Request.URL.RawQuery
 	data["headers"] = m.sanitizeHeaders(c.Request.Header)
 
@@ -182,6 +266,11 @@ func (m *RequestLoggerMiddleware) buildResponseData(c *gin.Context, body []byte)
 
 // sanitizeHeaders 清理敏感头信息
 func (m *RequestLoggerMiddleware) sanitizeHeaders(headers map[string][]string) map[string]string {
+	return SanitizeHeaders(headers)
+}
+
+// SanitizeHeaders 清理敏感头信息，每个头只保留第一个值，供其他协议记录请求日志时复用
+func SanitizeHeaders(headers map[string][]string) map[string]string {
 	result := make(map[string]string)
 	sensitiveHeaders := map[string]bool{
 		"authorization": true,
@@ -202,6 +291,14 @@ func (m *RequestLoggerMiddleware) sanitizeHeaders(headers map[string][]string) m
 	return result
 }
 
+// requestPath 获取请求路径，Mock 请求使用去除项目/环境前缀后的实际路径
+func requestPath(c *gin.Context) string {
+	if path := c.GetString("request_path"); path != "" {
+		return path
+	}
+	return c.Request.URL.Path
+}
+
 // getStringValue 安全获取字符串值
 func getStringValue(v interface{}) string {
 	if v == nil {
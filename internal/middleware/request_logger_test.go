@@ -5,6 +5,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -289,6 +290,29 @@ func TestMultipleWrites(t *testing.T) {
 	assert.Equal(t, "Part1 Part2 Part3", rw.body.String())
 }
 
+func TestResponseWriter_EventStreamCaptureLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Header("Content-Type", "text/event-stream")
+
+	rw := &responseWriter{
+		ResponseWriter: c.Writer,
+		body:           bytes.NewBufferString(""),
+	}
+
+	event := []byte("data: " + strings.Repeat("x", 1000) + "\n\n")
+	for i := 0; i < 2*maxStreamCaptureSize/len(event); i++ {
+		n, err := rw.Write(event)
+		assert.NoError(t, err)
+		assert.Equal(t, len(event), n)
+	}
+
+	// 客户端收到完整事件流，日志只捕获开头部分
+	assert.Greater(t, w.Body.Len(), maxStreamCaptureSize)
+	assert.Equal(t, maxStreamCaptureSize, rw.body.Len())
+}
+
 // Mock RequestLogRepository
 type MockRequestLogRepo struct {
 	mock.Mock
@@ -440,3 +464,58 @@ func TestRequestLoggerMiddleware_Handler(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestRequestLoggerMiddleware_RecordsFault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockRequestLogRepo)
+	middleware := NewRequestLoggerMiddleware(mockRepo)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(log *models.RequestLog) bool {
+		return log.Fault == models.FaultConnectionReset && log.StatusCode == 503
+	})).Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(middleware.Handler())
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(503)
+		c.Set("fault", models.FaultConnectionReset)
+	})
+
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	assert.NoError(t, middleware.Flush(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequestLoggerMiddleware_Flush(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := new(MockRequestLogRepo)
+	middleware := NewRequestLoggerMiddleware(mockRepo)
+	saved := make(chan struct{})
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.RequestLog")).Return(nil).Run(func(args mock.Arguments) {
+		time.Sleep(50 * time.Millisecond)
+		close(saved)
+	}).Once()
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(middleware.Handler())
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	// 响应返回后 Flush 会等待日志保存完成
+	assert.NoError(t, middleware.Flush(context.Background()))
+	select {
+	case <-saved:
+	default:
+		t.Fatal("Flush returned before the request log was saved")
+	}
+	mockRepo.AssertExpectations(t)
+
+	// 没有待保存日志时立即返回
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, middleware.Flush(ctx))
+}
@@ -0,0 +1,28 @@
+package models
+
+// FallbackMode 环境未匹配规则时的处理方式
+type FallbackMode string
+
+const (
+	// FallbackNotFound 返回默认的 404 响应（默认）
+	FallbackNotFound FallbackMode = "not_found"
+	// FallbackResponse 返回配置的自定义响应
+	FallbackResponse FallbackMode = "response"
+	// FallbackProxy 转发到上游，只 Mock 配置了规则的接口
+	FallbackProxy FallbackMode = "proxy"
+)
+
+// FallbackConfig 环境未匹配规则时的回退策略
+//
+//	"fallback": {
+//	  "mode": "proxy",
+//	  "proxy": {"target_url": "https://api.example.com", "modify_request": {"headers": {"X-Env": "mock"}}}
+//	}
+//
+// response 与 proxy 的格式分别与 Static、Proxy 规则的 response.content 相同，
+// 按回退策略应答的请求在请求日志的 fallback 字段中标记。
+type FallbackConfig struct {
+	Mode     FallbackMode           `bson:"mode" json:"mode"`
+	Response map[string]interface{} `bson:"response,omitempty" json:"response,omitempty"` // response 模式的静态响应内容
+	Proxy    map[string]interface{} `bson:"proxy,omitempty" json:"proxy,omitempty"`       // proxy 模式的代理配置
+}
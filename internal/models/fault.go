@@ -0,0 +1,29 @@
+package models
+
+// FaultType 传输层故障类型
+type FaultType string
+
+const (
+	FaultEmptyResponse     FaultType = "empty_response"     // 不发送任何数据直接关闭连接
+	FaultConnectionReset   FaultType = "connection_reset"   // 发送部分响应后以 RST 重置 TCP 连接
+	FaultMalformedResponse FaultType = "malformed_response" // 发送随机字节而不是 HTTP 响应
+	FaultHang              FaultType = "hang"               // 发送响应头后挂起，不发送响应体
+	FaultCloseAfterBytes   FaultType = "close_after_bytes"  // 发送 bytes 字节响应后正常关闭连接
+)
+
+// FaultConfig 规则的传输层故障配置（HTTP 规则 response.faults 的元素）
+//
+//	"faults": [
+//	  {"type": "connection_reset", "probability": 0.1},
+//	  {"type": "hang", "probability": 0.05, "duration": 30000},
+//	  {"type": "close_after_bytes", "probability": 0.05, "bytes": 128}
+//	]
+//
+// 每次请求按顺序以各自的概率判断，触发第一个命中的故障；故障在 Mock 服务劫持连接后注入，
+// 并记录在请求日志的 fault 字段。
+type FaultConfig struct {
+	Type        FaultType `bson:"type" json:"type"`
+	Probability float64   `bson:"probability" json:"probability"`               // 触发概率（0-1]
+	Bytes       int       `bson:"bytes,omitempty" json:"bytes,omitempty"`       // connection_reset、close_after_bytes 发送的响应字节数，malformed_response 的随机字节数
+	Duration    int       `bson:"duration,omitempty" json:"duration,omitempty"` // hang 挂起的毫秒数，0 表示直到客户端断开
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// ProtoFileKind 上传的 protobuf 描述文件类型
+type ProtoFileKind string
+
+const (
+	ProtoFileKindSource        ProtoFileKind = "proto"          // .proto 源文件
+	ProtoFileKindDescriptorSet ProtoFileKind = "descriptor_set" // protoc --descriptor_set_out 生成的 FileDescriptorSet
+)
+
+// ProtoFile 项目上传的 protobuf 描述文件，gRPC Mock 服务据此解析请求与响应消息
+//
+// 同一项目的 .proto 文件一起编译，Name 即 import 路径（如 "acme/user/v1/user.proto"），
+// 可以互相 import，也可以 import google/protobuf 下的标准文件和已上传描述集中的文件。
+type ProtoFile struct {
+	ID        string        `bson:"_id,omitempty" json:"id"`
+	ProjectID string        `bson:"project_id" json:"project_id"`
+	Name      string        `bson:"name" json:"name"`
+	Kind      ProtoFileKind `bson:"kind" json:"kind"`
+	Content   []byte        `bson:"content" json:"-"`
+	Services  []string      `bson:"services,omitempty" json:"services,omitempty"` // 文件中定义的服务全名
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// GRPCMatchCondition gRPC 匹配条件
+//
+//	"match_condition": {
+//	  "service": "acme.user.v1.UserService",
+//	  "method": "GetUser",
+//	  "metadata": {"x-tenant": "a"},
+//	  "body": {"json_path": [{"path": "$.id", "value": "42"}]}
+//	}
+//
+// body 与 HTTP 请求体条件相同，请求消息按 protobuf JSON 映射（字段名为 lowerCamelCase）转换后匹配。
+// 正则匹配规则中 service、method 与 metadata 的值均为正则表达式。
+type GRPCMatchCondition struct {
+	Service  string                 `json:"service"`
+	Method   string                 `json:"method,omitempty"` // 为空时匹配服务的所有方法
+	Metadata map[string]string      `json:"metadata,omitempty"`
+	Body     map[string]interface{} `json:"body,omitempty"`
+}
+
+// HTTPCondition 转换为等价的 HTTP 匹配条件：gRPC 请求路径为 /<service>/<method>，元数据作为请求头
+func (c *GRPCMatchCondition) HTTPCondition(regex bool) *HTTPMatchCondition {
+	condition := &HTTPMatchCondition{
+		Headers: c.Metadata,
+		Body:    c.Body,
+	}
+	if regex {
+		method := c.Method
+		if method == "" {
+			method = "[^/]+"
+		}
+		condition.PathRegex = "^/(?:" + c.Service + ")/(?:" + method + ")$"
+		return condition
+	}
+
+	method := c.Method
+	if method == "" {
+		method = ":method"
+	}
+	condition.Path = "/" + c.Service + "/" + method
+	return condition
+}
+
+// GRPCResponse gRPC 响应配置（Static、Dynamic 响应的 content）
+//
+//	"content": {
+//	  "code": "NOT_FOUND",
+//	  "message": "user not found",
+//	  "body": {"id": "42", "name": "alice"},
+//	  "headers": {"x-request-id": "abc"},
+//	  "trailers": {"x-retry-after": "5"}
+//	}
+//
+// 服务端流、双向流方法使用 stream 描述依次发送的响应消息，发送完毕后以 code 结束流，
+// 非 OK 的 code 即在发送这些消息后提前终止流：
+//
+//	"content": {
+//	  "stream": [
+//	    {"body": {"price": 1}},
+//	    {"body": {"price": 2}, "delay": 500}
+//	  ],
+//	  "code": "UNAVAILABLE",
+//	  "message": "feed interrupted"
+//	}
+//
+// 客户端流、双向流方法的每条请求消息分别匹配规则；双向流按匹配到的规则逐条回复，
+// 客户端流在请求结束后返回最后一条匹配规则的响应。匹配到非 OK 响应时立即结束流。
+type GRPCResponse struct {
+	Code     interface{}         `json:"code,omitempty"`     // 状态码名称（如 NOT_FOUND）或数值，默认 OK
+	Message  string              `json:"message,omitempty"`  // 非 OK 状态的描述
+	Body     interface{}         `json:"body,omitempty"`     // 响应消息，按 protobuf JSON 映射转换
+	Stream   []GRPCStreamMessage `json:"stream,omitempty"`   // 流式响应消息，与 body 互斥
+	Headers  map[string]string   `json:"headers,omitempty"`  // 响应头元数据
+	Trailers map[string]string   `json:"trailers,omitempty"` // 尾部元数据
+}
+
+// GRPCStreamMessage 流式响应中的一条消息
+type GRPCStreamMessage struct {
+	Body  interface{} `json:"body"`
+	Delay int         `json:"delay,omitempty"` // 发送前等待的毫秒数
+}
+
+// grpcNamePattern 服务全名或方法名中允许的字符
+var grpcNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// ValidGRPCName 检查服务全名或方法名是否合法
+func ValidGRPCName(name string) bool {
+	return grpcNamePattern.MatchString(name)
+}
@@ -101,6 +101,7 @@ type ImportResult struct {
 	Created        int               `json:"created"` // 新建的规则数
 	Updated        int               `json:"updated"` // 更新的规则数
 	Errors         []ImportError     `json:"errors,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"` // 转换警告（如 OpenAPI 导入）
 }
 
 // ImportError 导入错误
@@ -109,6 +110,25 @@ type ImportError struct {
 	Error    string `json:"error"`
 }
 
+// OpenAPIImportRequest OpenAPI/Swagger 导入请求（文档内容来自请求体，其余参数来自查询字符串）
+type OpenAPIImportRequest struct {
+	Spec            []byte         `json:"-" form:"-"`                                         // 文档内容（JSON 或 YAML）
+	TargetProjectID string         `json:"target_project_id" form:"target_project_id"`         // 目标项目ID
+	TargetEnvID     string         `json:"target_environment_id" form:"target_environment_id"` // 目标环境ID
+	Strategy        ImportStrategy `json:"strategy" form:"strategy"`                           // 导入策略
+	DryRun          bool           `json:"dry_run" form:"dry_run"`                             // 仅预览，不写入
+}
+
+// OpenAPIImportPreview OpenAPI/Swagger 导入预览
+type OpenAPIImportPreview struct {
+	Format    string           `json:"format"`             // openapi 或 swagger
+	Version   string           `json:"version"`            // 文档规范版本
+	Title     string           `json:"title,omitempty"`    // 文档标题
+	RuleCount int              `json:"rule_count"`         // 生成的规则数
+	Rules     []RuleExportData `json:"rules"`              // 生成的规则
+	Warnings  []string         `json:"warnings,omitempty"` // 转换警告
+}
+
 // CloneRuleRequest 克隆规则请求
 type CloneRuleRequest struct {
 	TargetProjectID     string `json:"target_project_id,omitempty"` // 目标项目ID（可选）
@@ -28,10 +28,13 @@ const (
 type ResponseType string
 
 const (
-	ResponseTypeStatic  ResponseType = "Static"
-	ResponseTypeDynamic ResponseType = "Dynamic"
-	ResponseTypeProxy   ResponseType = "Proxy"
-	ResponseTypeScript  ResponseType = "Script"
+	ResponseTypeStatic   ResponseType = "Static"
+	ResponseTypeDynamic  ResponseType = "Dynamic"
+	ResponseTypeProxy    ResponseType = "Proxy"
+	ResponseTypeScript   ResponseType = "Script"
+	ResponseTypeSequence ResponseType = "Sequence"
+	ResponseTypeSSE      ResponseType = "SSE"
+	ResponseTypeNDJSON   ResponseType = "NDJSON"
 )
 
 // ContentType 内容类型
@@ -57,12 +60,39 @@ type Rule struct {
 	Enabled        bool                   `bson:"enabled" json:"enabled"`
 	MatchCondition map[string]interface{} `bson:"match_condition" json:"match_condition"`
 	Response       Response               `bson:"response" json:"response"`
+	Scenario       *RuleScenario          `bson:"scenario,omitempty" json:"scenario,omitempty"`
 	Tags           []string               `bson:"tags,omitempty" json:"tags,omitempty"`
 	Creator        string                 `bson:"creator,omitempty" json:"creator,omitempty"`
 	CreatedAt      time.Time              `bson:"created_at" json:"created_at"`
 	UpdatedAt      time.Time              `bson:"updated_at" json:"updated_at"`
 }
 
+// ScenarioStateStarted 场景初始状态
+const ScenarioStateStarted = "Started"
+
+// RuleScenario 规则场景配置
+//
+// 规则仅在场景处于 RequiredState 时参与匹配，命中后将场景切换到 NewState。
+// 同一场景的规则应使用一致的客户端标识配置，未配置时状态在项目/环境内共享。
+type RuleScenario struct {
+	Name          string `bson:"name" json:"name"`
+	RequiredState string `bson:"required_state,omitempty" json:"required_state,omitempty"`
+	NewState      string `bson:"new_state,omitempty" json:"new_state,omitempty"`
+	ClientHeader  string `bson:"client_header,omitempty" json:"client_header,omitempty"` // 按请求头区分客户端
+	ClientCookie  string `bson:"client_cookie,omitempty" json:"client_cookie,omitempty"` // 按 Cookie 区分客户端
+}
+
+// ScenarioState 场景状态
+type ScenarioState struct {
+	ID            string    `bson:"_id,omitempty" json:"id"`
+	ProjectID     string    `bson:"project_id" json:"project_id"`
+	EnvironmentID string    `bson:"environment_id" json:"environment_id"`
+	Scenario      string    `bson:"scenario" json:"scenario"`
+	ClientID      string    `bson:"client_id" json:"client_id"`
+	State         string    `bson:"state" json:"state"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
 // HTTPMatchCondition HTTP 匹配条件
 type HTTPMatchCondition struct {
 	Method      interface{}            `json:"method"` // string 或 []string
@@ -72,6 +102,7 @@ type HTTPMatchCondition struct {
 	Headers     map[string]string      `json:"headers,omitempty"`
 	Body        map[string]interface{} `json:"body,omitempty"`
 	IPWhitelist []string               `json:"ip_whitelist,omitempty"`
+	ClientCert  *ClientCertCondition   `json:"client_cert,omitempty"` // 客户端证书（mTLS）条件
 }
 
 // Response 响应配置
@@ -79,9 +110,13 @@ type Response struct {
 	Type    ResponseType           `bson:"type" json:"type"`
 	Delay   *DelayConfig           `bson:"delay,omitempty" json:"delay,omitempty"`
 	Content map[string]interface{} `bson:"content" json:"content"`
+	Faults  []FaultConfig          `bson:"faults,omitempty" json:"faults,omitempty"` // 传输层故障，仅 HTTP 规则
 }
 
 // DelayConfig 延迟配置
+//
+// step 延迟按规则独立计数（第 n 次调用延迟 Fixed + (n-1)*Step，不超过 Limit），
+// 可通过 DELETE /api/v1/rules/:id/counters 重置
 type DelayConfig struct {
 	Type   string `bson:"type" json:"type"` // fixed, random, normal, step
 	Min    int    `bson:"min,omitempty" json:"min,omitempty"`
@@ -99,6 +134,19 @@ type HTTPResponse struct {
 	Headers     map[string]string `json:"headers,omitempty"`
 	Body        interface{}       `json:"body"`
 	ContentType ContentType       `json:"content_type"`
+	Stream      *HTTPStream       `json:"stream,omitempty"`
+}
+
+// HTTPStream 响应体分块与限速配置，用于模拟慢速下载和流式响应
+//
+//	"stream": {"chunk_size": 1024, "chunk_delay": 100, "bytes_per_second": 65536}
+//
+// 配置 chunk_size 时响应体按块以 chunked 编码发送，第一块之后每块发送前等待 chunk_delay 毫秒；
+// 只配置 bytes_per_second 时保留 Content-Length，按带宽限制逐步写出响应体。
+type HTTPStream struct {
+	ChunkSize      int `json:"chunk_size,omitempty"`       // 每块字节数
+	ChunkDelay     int `json:"chunk_delay,omitempty"`      // 块之间等待的毫秒数
+	BytesPerSecond int `json:"bytes_per_second,omitempty"` // 带宽限制，0 表示不限速
 }
 
 // Project 项目模型
@@ -113,13 +161,33 @@ type Project struct {
 
 // Environment 环境模型
 type Environment struct {
-	ID        string                 `bson:"_id,omitempty" json:"id"`
-	Name      string                 `bson:"name" json:"name"`
-	ProjectID string                 `bson:"project_id" json:"project_id"`
-	BaseURL   string                 `bson:"base_url,omitempty" json:"base_url,omitempty"`
-	Variables map[string]interface{} `bson:"variables,omitempty" json:"variables,omitempty"`
-	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time              `bson:"updated_at" json:"updated_at"`
+	ID         string                 `bson:"_id,omitempty" json:"id"`
+	Name       string                 `bson:"name" json:"name"`
+	ProjectID  string                 `bson:"project_id" json:"project_id"`
+	BaseURL    string                 `bson:"base_url,omitempty" json:"base_url,omitempty"`
+	Variables  map[string]interface{} `bson:"variables,omitempty" json:"variables,omitempty"`
+	Recording  *RecordingConfig       `bson:"recording,omitempty" json:"recording,omitempty"`
+	Contract   *ContractConfig        `bson:"contract,omitempty" json:"contract,omitempty"`
+	ClientAuth ClientAuthMode         `bson:"client_auth,omitempty" json:"client_auth,omitempty"` // 客户端证书模式，为空时按 request 处理
+	Fallback   *FallbackConfig        `bson:"fallback,omitempty" json:"fallback,omitempty"`       // 未匹配规则时的回退策略，为空时返回 404
+	CreatedAt  time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+// RecordedRuleTag 录制生成的规则标签
+const RecordedRuleTag = "recorded"
+
+// RecordingConfig 环境录制配置
+//
+// 启用后，代理规则转发的请求/响应会被保存为静态规则；配置 TargetURL 时，
+// 未匹配任何规则的请求也会转发到该地址并录制。
+type RecordingConfig struct {
+	Enabled   bool     `bson:"enabled" json:"enabled"`
+	TargetURL string   `bson:"target_url,omitempty" json:"target_url,omitempty"` // 未匹配请求的上游地址
+	Headers   []string `bson:"headers,omitempty" json:"headers,omitempty"`       // 参与匹配的请求头
+	QueryKeys []string `bson:"query_keys,omitempty" json:"query_keys,omitempty"` // 参与匹配的查询参数，* 表示全部
+	MatchBody bool     `bson:"match_body,omitempty" json:"match_body,omitempty"` // 是否按 JSON 请求体匹配
+	Priority  int      `bson:"priority,omitempty" json:"priority,omitempty"`     // 录制规则优先级，默认 100
 }
 
 // Workspace 工作空间模型
@@ -133,6 +201,20 @@ type Workspace struct {
 	UpdatedAt   time.Time              `bson:"updated_at" json:"updated_at"`
 }
 
+// ContractConfig 环境绑定的 OpenAPI 契约校验配置
+type ContractConfig struct {
+	Spec             string `bson:"spec" json:"spec"`                           // OpenAPI 3.x / Swagger 2.0 文档（JSON 或 YAML）
+	ValidateRequest  bool   `bson:"validate_request" json:"validate_request"`   // 校验请求，不符合契约时返回 400
+	ValidateResponse bool   `bson:"validate_response" json:"validate_response"` // 校验 Mock 响应，不符合契约时记录到请求日志
+}
+
+// ContractViolation 契约校验违规项
+type ContractViolation struct {
+	Location string `bson:"location" json:"location"`             // path/query/header/body/response
+	Name     string `bson:"name,omitempty" json:"name,omitempty"` // 参数名或 JSONPath
+	Message  string `bson:"message" json:"message"`               // 违规描述
+}
+
 // RequestLog 请求日志模型
 type RequestLog struct {
 	ID            string                 `bson:"_id,omitempty" json:"id"`
@@ -149,6 +231,12 @@ type RequestLog struct {
 	Duration      int64                  `bson:"duration" json:"duration"` // 毫秒
 	SourceIP      string                 `bson:"source_ip" json:"source_ip"`
 	Timestamp     time.Time              `bson:"timestamp" json:"timestamp"`
+	// ContractViolations 契约校验违规项（环境绑定 OpenAPI 文档时）
+	ContractViolations []ContractViolation `bson:"contract_violations,omitempty" json:"contract_violations,omitempty"`
+	// Fault 注入的传输层故障类型，此时 StatusCode 为规则原本返回的状态码
+	Fault FaultType `bson:"fault,omitempty" json:"fault,omitempty"`
+	// Fallback 未匹配规则时的回退处理方式，proxy 包括录制和正向代理转发到上游的请求
+	Fallback FallbackMode `bson:"fallback,omitempty" json:"fallback,omitempty"`
 }
 
 // Version 版本记录模型
@@ -0,0 +1,26 @@
+package models
+
+// NDJSONResponse NDJSON 流式响应配置（NDJSON 响应的 content）
+//
+//	"content": {
+//	  "lines": [
+//	    {"data": {"status": "pulling", "id": "{{uuid}}"}},
+//	    {"data": {"status": "downloading", "progress": 50}, "delay": 500},
+//	    {"data": {"status": "done"}, "delay": 500}
+//	  ]
+//	}
+//
+// 每行 data 按 JSON 模板渲染后序列化为一行 JSON，发送前等待 delay 毫秒，
+// 以 chunked 编码逐行刷新，Content-Type 默认为 application/x-ndjson。
+type NDJSONResponse struct {
+	StatusCode     int               `json:"status_code,omitempty"` // 默认 200
+	Headers        map[string]string `json:"headers,omitempty"`
+	Lines          []NDJSONLine      `json:"lines"`
+	BytesPerSecond int               `json:"bytes_per_second,omitempty"` // 带宽限制，0 表示不限速
+}
+
+// NDJSONLine NDJSON 响应中的一行
+type NDJSONLine struct {
+	Data  interface{} `json:"data"`
+	Delay int         `json:"delay,omitempty"` // 发送前等待的毫秒数
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// HostBinding 主机名路由绑定
+//
+// Mock 服务按请求的 Host 将请求路由到绑定的项目和环境，请求路径无需携带 /:projectID/:environmentID 前缀。
+type HostBinding struct {
+	ID            string    `bson:"_id,omitempty" json:"id"`
+	Host          string    `bson:"host" json:"host"` // 主机名（不含端口、小写），支持 *.mock.local 通配符
+	ProjectID     string    `bson:"project_id" json:"project_id"`
+	EnvironmentID string    `bson:"environment_id" json:"environment_id"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// SSEResponse Server-Sent Events 响应配置（SSE 响应的 content）
+//
+//	"content": {
+//	  "headers": {"X-Stream": "prices"},
+//	  "events": [
+//	    {"id": "1", "event": "price", "data": {"symbol": "{{.Request.Query.symbol}}", "price": 100}},
+//	    {"id": "2", "event": "price", "data": "tick {{counter}}", "delay": 500},
+//	    {"data": "[DONE]", "retry": 3000}
+//	  ],
+//	  "loop": false,
+//	  "keep_open": false
+//	}
+//
+// 事件依次发送，每个事件发送前等待 delay 毫秒。data 为字符串时按文本模板渲染，
+// 其他 JSON 值按 JSON 模板渲染后序列化为一行，多行文本拆分为多个 data 字段。
+// loop 为 true 时循环发送事件直到客户端断开，要求至少一个事件配置 delay；
+// keep_open 为 true 时事件发送完后保持连接直到客户端断开。
+type SSEResponse struct {
+	StatusCode int               `json:"status_code,omitempty"` // 默认 200
+	Headers    map[string]string `json:"headers,omitempty"`
+	Events     []SSEEvent        `json:"events"`
+	Loop       bool              `json:"loop,omitempty"`
+	KeepOpen   bool              `json:"keep_open,omitempty"`
+}
+
+// SSEEvent Server-Sent Events 事件
+type SSEEvent struct {
+	ID    string      `json:"id,omitempty"`
+	Event string      `json:"event,omitempty"` // 事件类型，为空时客户端按 message 处理
+	Data  interface{} `json:"data"`
+	Retry int         `json:"retry,omitempty"` // 客户端重连间隔毫秒数
+	Delay int         `json:"delay,omitempty"` // 发送前等待的毫秒数
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// TLSCertificate 环境上传的 HTTPS Mock 服务证书
+//
+// HTTPS Mock 服务按 SNI 主机名选择证书，匹配上传证书的主机名时优先使用上传的证书，
+// 否则由本地根 CA 签发。私钥不通过 API 返回。
+type TLSCertificate struct {
+	ID            string    `bson:"_id,omitempty" json:"id"`
+	ProjectID     string    `bson:"project_id" json:"project_id"`
+	EnvironmentID string    `bson:"environment_id" json:"environment_id"`
+	Hostnames     []string  `bson:"hostnames" json:"hostnames"`     // 证书的 DNS 名称与 IP 地址
+	Certificate   string    `bson:"certificate" json:"certificate"` // PEM 编码的证书链
+	PrivateKey    string    `bson:"private_key" json:"-"`           // PEM 编码的私钥
+	NotAfter      time.Time `bson:"not_after" json:"not_after"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ClientAuthMode 环境的客户端证书（mTLS）模式
+type ClientAuthMode string
+
+const (
+	// ClientAuthRequest 接受客户端出示的证书用于匹配，不出示也允许访问（默认）
+	ClientAuthRequest ClientAuthMode = "request"
+	// ClientAuthRequire 未出示客户端证书的请求返回 403
+	ClientAuthRequire ClientAuthMode = "require"
+	// ClientAuthIgnore 忽略客户端证书，规则和模板中视为未出示
+	ClientAuthIgnore ClientAuthMode = "ignore"
+)
+
+// ClientCertCondition 客户端证书匹配条件，简单匹配与正则匹配规则均按精确值匹配
+//
+// 配置 subject_cn、sans、issuer 或 fingerprint 时要求客户端出示证书。
+type ClientCertCondition struct {
+	Present     *bool    `json:"present,omitempty"`     // 是否出示证书
+	SubjectCN   string   `json:"subject_cn,omitempty"`  // 主题 CN
+	SANs        []string `json:"sans,omitempty"`        // 证书须包含全部 SAN（DNS 名称、IP、邮箱或 URI，不区分大小写）
+	Issuer      string   `json:"issuer,omitempty"`      // 颁发者 CN 或完整 DN（如 CN=Partner CA,O=Acme）
+	Fingerprint string   `json:"fingerprint,omitempty"` // SHA-256 指纹，十六进制，不区分大小写，可包含冒号
+}
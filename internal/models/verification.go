@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// RequestMatcher 请求校验匹配条件
+type RequestMatcher struct {
+	Method    string            `json:"method,omitempty"`     // 请求方法，不区分大小写
+	Path      string            `json:"path,omitempty"`       // 精确路径，支持 :param 路径参数
+	PathRegex string            `json:"path_regex,omitempty"` // 路径正则
+	Headers   map[string]string `json:"headers,omitempty"`    // 请求头（名称不区分大小写，值为正则）
+	Query     map[string]string `json:"query,omitempty"`      // 查询参数（值为正则）
+	BodyPaths []BodyPathMatcher `json:"body_json_path,omitempty"`
+	RuleID    string            `json:"rule_id,omitempty"` // 命中的规则ID
+}
+
+// BodyPathMatcher 请求体 JSONPath 条件
+type BodyPathMatcher struct {
+	Path  string      `json:"path"`
+	Op    string      `json:"op,omitempty"` // eq, ne, gt, gte, lt, lte, contains, regex, exists
+	Value interface{} `json:"value,omitempty"`
+}
+
+// CountConstraint 调用次数约束，均未设置时要求至少调用一次
+type CountConstraint struct {
+	Exactly *int `json:"exactly,omitempty"`
+	AtLeast *int `json:"at_least,omitempty"`
+	AtMost  *int `json:"at_most,omitempty"`
+	Never   bool `json:"never,omitempty"`
+}
+
+// VerificationRequest 请求校验
+type VerificationRequest struct {
+	ProjectID     string           `json:"project_id" binding:"required"`
+	EnvironmentID string           `json:"environment_id" binding:"required"`
+	Request       RequestMatcher   `json:"request"`
+	Count         *CountConstraint `json:"count,omitempty"`
+	StartTime     *time.Time       `json:"start_time,omitempty"`
+	EndTime       *time.Time       `json:"end_time,omitempty"`
+}
+
+// SequenceVerificationRequest 请求顺序校验，要求按给定顺序依次出现
+type SequenceVerificationRequest struct {
+	ProjectID     string           `json:"project_id" binding:"required"`
+	EnvironmentID string           `json:"environment_id" binding:"required"`
+	Requests      []RequestMatcher `json:"requests" binding:"required,min=1"`
+	StartTime     *time.Time       `json:"start_time,omitempty"`
+	EndTime       *time.Time       `json:"end_time,omitempty"`
+}
+
+// NearMiss 最接近但未匹配的请求
+type NearMiss struct {
+	Request    *RequestLog `json:"request"`
+	Mismatches []string    `json:"mismatches"`
+}
+
+// VerificationResult 请求校验结果
+type VerificationResult struct {
+	Passed          bool          `json:"passed"`
+	Message         string        `json:"message"`
+	MatchedCount    int           `json:"matched_count"`
+	MatchedRequests []*RequestLog `json:"matched_requests,omitempty"`
+	FailedIndex     *int          `json:"failed_index,omitempty"` // 顺序校验失败的步骤下标
+	NearMisses      []NearMiss    `json:"near_misses,omitempty"`
+}
@@ -0,0 +1,108 @@
+package models
+
+// WebSocket 消息类型
+const (
+	WebSocketMessageText   = "text"   // 文本帧
+	WebSocketMessageBinary = "binary" // 二进制帧，内容为 base64 编码
+	WebSocketMessageJSON   = "json"   // 文本帧，内容为 JSON 值
+)
+
+// WebSocketMatchCondition WebSocket 匹配条件
+//
+//	"match_condition": {
+//	  "path": "/ws/chat",
+//	  "query": {"room": "1"},
+//	  "headers": {"Authorization": "Bearer token"},
+//	  "message": {"type": "text", "text": "ping"}
+//	}
+//
+// 未配置 message 的规则在连接建立时匹配（握手请求的路径、查询参数和请求头），
+// 配置 message 的规则在连接上每收到一条消息时匹配，同时要求连接的路径、查询参数和请求头满足条件。
+// 正则匹配规则中 path、query、headers 与 message.text 的值均为正则表达式。
+type WebSocketMatchCondition struct {
+	Path    string                     `json:"path,omitempty"`
+	Query   map[string]string          `json:"query,omitempty"`
+	Headers map[string]string          `json:"headers,omitempty"`
+	Message *WebSocketMessageCondition `json:"message,omitempty"`
+}
+
+// WebSocketMessageCondition WebSocket 消息匹配条件
+//
+//	"message": {"type": "text", "text": "ping"}
+//	"message": {"body": {"json_path": [{"path": "$.action", "value": "subscribe"}]}}
+//	"message": {"type": "binary", "binary": "AAEC"}
+//
+// body 与 HTTP 请求体条件相同，按 JSON 解析文本帧后匹配。
+type WebSocketMessageCondition struct {
+	Type   string                 `json:"type,omitempty"`   // text、binary，为空时匹配任意类型
+	Text   string                 `json:"text,omitempty"`   // 文本帧内容，简单匹配要求完全相等
+	Binary string                 `json:"binary,omitempty"` // base64 编码的二进制帧内容，要求完全相等
+	Body   map[string]interface{} `json:"body,omitempty"`
+}
+
+// HTTPCondition 转换为等价的 HTTP 匹配条件：握手请求的路径、查询参数、请求头，以及消息内容的 body 条件
+func (c *WebSocketMatchCondition) HTTPCondition(regex bool) *HTTPMatchCondition {
+	condition := &HTTPMatchCondition{
+		Query:   c.Query,
+		Headers: c.Headers,
+	}
+	if regex {
+		condition.PathRegex = c.Path
+	} else {
+		condition.Path = c.Path
+	}
+	if c.Message != nil {
+		condition.Body = c.Message.Body
+	}
+	return condition
+}
+
+// WebSocketResponse WebSocket 响应配置（Static、Dynamic 响应的 content）
+//
+//	"content": {
+//	  "messages": [
+//	    {"type": "text", "data": "pong"},
+//	    {"type": "json", "data": {"event": "subscribed"}, "delay": 100},
+//	    {"type": "binary", "data": "AAEC"}
+//	  ],
+//	  "close": {"code": 1008, "reason": "unauthorized"}
+//	}
+//
+// messages 依次发送，配置 close 时发送完消息后以该关闭码关闭连接。
+// Dynamic 响应按模板渲染 text、json 消息，模板中 .Request.Body 为收到的消息。
+//
+// periodic 配置匹配后的周期推送（如行情、通知），每次推送前重新渲染模板，连接关闭时停止：
+//
+//	"content": {
+//	  "messages": [{"type": "json", "data": {"event": "subscribed"}}],
+//	  "periodic": {
+//	    "interval": 1000,
+//	    "count": 10,
+//	    "messages": [{"type": "json", "data": {"price": "{{random 90 110}}", "seq": "{{counter}}"}}]
+//	  }
+//	}
+type WebSocketResponse struct {
+	Messages []WebSocketReplyMessage `json:"messages,omitempty"`
+	Close    *WebSocketClose         `json:"close,omitempty"`
+	Periodic *WebSocketPeriodic      `json:"periodic,omitempty"`
+}
+
+// WebSocketPeriodic WebSocket 周期推送配置
+type WebSocketPeriodic struct {
+	Interval int                     `json:"interval"`        // 推送间隔毫秒数，第一次推送在一个间隔之后
+	Count    int                     `json:"count,omitempty"` // 推送次数，0 表示直到连接关闭
+	Messages []WebSocketReplyMessage `json:"messages"`
+}
+
+// WebSocketReplyMessage WebSocket 回复消息
+type WebSocketReplyMessage struct {
+	Type  string      `json:"type,omitempty"` // text（默认）、json、binary
+	Data  interface{} `json:"data"`
+	Delay int         `json:"delay,omitempty"` // 发送前等待的毫秒数
+}
+
+// WebSocketClose WebSocket 关闭帧
+type WebSocketClose struct {
+	Code   int    `json:"code"` // RFC 6455 关闭码，如 1000、1008
+	Reason string `json:"reason,omitempty"`
+}
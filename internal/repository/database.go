@@ -131,6 +131,66 @@ func createIndexes(ctx context.Context) error {
 		return err
 	}
 
+	// Scenario States 集合索引
+	scenarioStatesCollection := database.Collection("scenario_states")
+	uniqueState := true
+	scenarioStatesIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "project_id", Value: 1},
+				{Key: "environment_id", Value: 1},
+				{Key: "scenario", Value: 1},
+				{Key: "client_id", Value: 1},
+			},
+			Options: &options.IndexOptions{Unique: &uniqueState},
+		},
+	}
+	if _, err := scenarioStatesCollection.Indexes().CreateMany(ctx, scenarioStatesIndexes); err != nil {
+		return err
+	}
+
+	// Proto Files 集合索引
+	protoFilesCollection := database.Collection("proto_files")
+	uniqueProto := true
+	protoFilesIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "project_id", Value: 1},
+				{Key: "name", Value: 1},
+			},
+			Options: &options.IndexOptions{Unique: &uniqueProto},
+		},
+	}
+	if _, err := protoFilesCollection.Indexes().CreateMany(ctx, protoFilesIndexes); err != nil {
+		return err
+	}
+
+	// TLS Certificates 集合索引
+	tlsCertificatesCollection := database.Collection("tls_certificates")
+	uniqueTLSCertificate := true
+	tlsCertificatesIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "environment_id", Value: 1}},
+			Options: &options.IndexOptions{Unique: &uniqueTLSCertificate},
+		},
+	}
+	if _, err := tlsCertificatesCollection.Indexes().CreateMany(ctx, tlsCertificatesIndexes); err != nil {
+		return err
+	}
+
+	// Host Bindings 集合索引
+	hostBindingsCollection := database.Collection("host_bindings")
+	uniqueHostBinding := true
+	hostBindingsIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "host", Value: 1}},
+			Options: &options.IndexOptions{Unique: &uniqueHostBinding},
+		},
+	}
+	if _, err := hostBindingsCollection.Indexes().CreateMany(ctx, hostBindingsIndexes); err != nil {
+		return err
+	}
+
 	// Versions 集合索引
 	versionsCollection := database.Collection("versions")
 	versionsIndexes := []mongo.IndexModel{
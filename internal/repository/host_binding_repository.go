@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HostBindingRepository 主机名路由绑定仓库接口
+type HostBindingRepository interface {
+	Save(ctx context.Context, binding *models.HostBinding) error
+	Delete(ctx context.Context, host string) (bool, error)
+	List(ctx context.Context) ([]*models.HostBinding, error)
+}
+
+type hostBindingRepository struct {
+	collection *mongo.Collection
+}
+
+// NewHostBindingRepository 创建主机名路由绑定仓库
+func NewHostBindingRepository() HostBindingRepository {
+	return &hostBindingRepository{
+		collection: GetCollection("host_bindings"),
+	}
+}
+
+// Save 保存绑定，同一主机名只保留一个绑定
+func (r *hostBindingRepository) Save(ctx context.Context, binding *models.HostBinding) error {
+	now := time.Now()
+	binding.UpdatedAt = now
+
+	filter := bson.M{"host": binding.Host}
+	update := bson.M{
+		"$set": bson.M{
+			"project_id":     binding.ProjectID,
+			"environment_id": binding.EnvironmentID,
+			"updated_at":     binding.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		binding.ID = oid.Hex()
+		binding.CreatedAt = now
+	}
+
+	return nil
+}
+
+// Delete 删除主机名的绑定，返回绑定是否存在
+func (r *hostBindingRepository) Delete(ctx context.Context, host string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"host": host})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// List 查询所有绑定
+func (r *hostBindingRepository) List(ctx context.Context) ([]*models.HostBinding, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "host", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bindings []*models.HostBinding
+	if err = cursor.All(ctx, &bindings); err != nil {
+		return nil, err
+	}
+
+	return bindings, nil
+}
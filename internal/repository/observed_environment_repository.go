@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// EnvironmentChangeListener 环境变更监听器
+type EnvironmentChangeListener interface {
+	// EnvironmentChanged 环境创建、更新或删除成功后调用，environmentID 为空表示所有环境
+	EnvironmentChanged(ctx context.Context, environmentID string)
+}
+
+// observedEnvironmentRepository 写入成功后通知监听器的环境仓库
+type observedEnvironmentRepository struct {
+	EnvironmentRepository
+	listeners []EnvironmentChangeListener
+}
+
+// NewObservedEnvironmentRepository 创建环境仓库包装，环境写入成功后按顺序通知监听器（如环境变量、契约缓存）
+func NewObservedEnvironmentRepository(repo EnvironmentRepository, listeners ...EnvironmentChangeListener) EnvironmentRepository {
+	return &observedEnvironmentRepository{
+		EnvironmentRepository: repo,
+		listeners:             listeners,
+	}
+}
+
+// Create 创建环境
+func (r *observedEnvironmentRepository) Create(ctx context.Context, environment *models.Environment) error {
+	if err := r.EnvironmentRepository.Create(ctx, environment); err != nil {
+		return err
+	}
+	r.notify(ctx, environment.ID)
+	return nil
+}
+
+// Update 更新环境
+func (r *observedEnvironmentRepository) Update(ctx context.Context, environment *models.Environment) error {
+	if err := r.EnvironmentRepository.Update(ctx, environment); err != nil {
+		return err
+	}
+	r.notify(ctx, environment.ID)
+	return nil
+}
+
+// Delete 删除环境
+func (r *observedEnvironmentRepository) Delete(ctx context.Context, id string) error {
+	if err := r.EnvironmentRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.notify(ctx, id)
+	return nil
+}
+
+// notify 通知环境已变更
+func (r *observedEnvironmentRepository) notify(ctx context.Context, environmentID string) {
+	for _, listener := range r.listeners {
+		listener.EnvironmentChanged(ctx, environmentID)
+	}
+}
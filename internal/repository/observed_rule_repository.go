@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gomockserver/mockserver/internal/models"
+)
+
+// RuleChangeListener 规则变更监听器
+type RuleChangeListener interface {
+	// RuleSaved 规则创建或更新成功后调用
+	RuleSaved(ctx context.Context, rule *models.Rule)
+	// RuleDeleted 规则删除成功后调用，rule 为删除前的规则（查询失败时只有 ID）；
+	// 规则被移动到其他项目或环境时，也会以更新前的规则调用
+	RuleDeleted(ctx context.Context, rule *models.Rule)
+}
+
+// observedRuleRepository 写入成功后通知监听器的规则仓库
+type observedRuleRepository struct {
+	RuleRepository
+	listeners []RuleChangeListener
+}
+
+// NewObservedRuleRepository 创建规则仓库包装，规则写入成功后按顺序通知监听器（如规则缓存、匹配引擎的规则索引）
+func NewObservedRuleRepository(repo RuleRepository, listeners ...RuleChangeListener) RuleRepository {
+	return &observedRuleRepository{
+		RuleRepository: repo,
+		listeners:      listeners,
+	}
+}
+
+// Create 创建规则
+func (r *observedRuleRepository) Create(ctx context.Context, rule *models.Rule) error {
+	if err := r.RuleRepository.Create(ctx, rule); err != nil {
+		return err
+	}
+	r.notifySaved(ctx, rule)
+	return nil
+}
+
+// Update 更新规则
+func (r *observedRuleRepository) Update(ctx context.Context, rule *models.Rule) error {
+	previous := r.previous(ctx, rule.ID)
+	if err := r.RuleRepository.Update(ctx, rule); err != nil {
+		return err
+	}
+	if previous.ProjectID != "" && (previous.ProjectID != rule.ProjectID || previous.EnvironmentID != rule.EnvironmentID) {
+		r.notifyDeleted(ctx, previous)
+	}
+	r.notifySaved(ctx, rule)
+	return nil
+}
+
+// Delete 删除规则
+func (r *observedRuleRepository) Delete(ctx context.Context, id string) error {
+	previous := r.previous(ctx, id)
+	if err := r.RuleRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.notifyDeleted(ctx, previous)
+	return nil
+}
+
+// previous 查询写入前的规则，查询失败时返回只有 ID 的规则
+func (r *observedRuleRepository) previous(ctx context.Context, id string) *models.Rule {
+	rule, err := r.RuleRepository.FindByID(ctx, id)
+	if err != nil || rule == nil {
+		return &models.Rule{ID: id}
+	}
+	return rule
+}
+
+// notifySaved 通知规则已保存
+func (r *observedRuleRepository) notifySaved(ctx context.Context, rule *models.Rule) {
+	for _, listener := range r.listeners {
+		listener.RuleSaved(ctx, rule)
+	}
+}
+
+// notifyDeleted 通知规则已删除
+func (r *observedRuleRepository) notifyDeleted(ctx context.Context, rule *models.Rule) {
+	for _, listener := range r.listeners {
+		listener.RuleDeleted(ctx, rule)
+	}
+}
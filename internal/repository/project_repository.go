@@ -201,11 +201,15 @@ func (r *environmentRepository) Update(ctx context.Context, environment *models.
 	filter := bson.M{"_id": objectID}
 	// 排除 _id 字段，避免更新不可变字段
 	update := bson.M{"$set": bson.M{
-		"name":       environment.Name,
-		"project_id": environment.ProjectID,
-		"base_url":   environment.BaseURL,
-		"variables":  environment.Variables,
-		"updated_at": environment.UpdatedAt,
+		"name":        environment.Name,
+		"project_id":  environment.ProjectID,
+		"base_url":    environment.BaseURL,
+		"variables":   environment.Variables,
+		"recording":   environment.Recording,
+		"contract":    environment.Contract,
+		"client_auth": environment.ClientAuth,
+		"fallback":    environment.Fallback,
+		"updated_at":  environment.UpdatedAt,
 	}}
 
 	_, err = r.collection.UpdateOne(ctx, filter, update)
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProtoRepository protobuf 描述文件仓库接口
+type ProtoRepository interface {
+	Save(ctx context.Context, file *models.ProtoFile) error
+	Delete(ctx context.Context, projectID, name string) (bool, error)
+	FindByProject(ctx context.Context, projectID string) ([]*models.ProtoFile, error)
+}
+
+type protoRepository struct {
+	collection *mongo.Collection
+}
+
+// NewProtoRepository 创建 protobuf 描述文件仓库
+func NewProtoRepository() ProtoRepository {
+	return &protoRepository{
+		collection: GetCollection("proto_files"),
+	}
+}
+
+// Save 保存描述文件，同一项目下同名文件覆盖
+func (r *protoRepository) Save(ctx context.Context, file *models.ProtoFile) error {
+	now := time.Now()
+	file.UpdatedAt = now
+
+	filter := bson.M{
+		"project_id": file.ProjectID,
+		"name":       file.Name,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"kind":       file.Kind,
+			"content":    file.Content,
+			"services":   file.Services,
+			"updated_at": file.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		file.ID = oid.Hex()
+		file.CreatedAt = now
+	}
+
+	return nil
+}
+
+// Delete 删除描述文件，返回文件是否存在
+func (r *protoRepository) Delete(ctx context.Context, projectID, name string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"project_id": projectID, "name": name})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// FindByProject 查询项目的所有描述文件，按名称排序
+func (r *protoRepository) FindByProject(ctx context.Context, projectID string) ([]*models.ProtoFile, error) {
+	opts := options.Find().SetSort(bson.D{primitive.E{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"project_id": projectID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var files []*models.ProtoFile
+	if err = cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
@@ -32,12 +32,14 @@ type RequestLogFilter struct {
 	Path          string
 	StatusCode    int
 	SourceIP      string
-	StartTime     time.Time
-	EndTime       time.Time
-	Page          int
-	PageSize      int
-	SortBy        string
-	SortOrder     int // 1: asc, -1: desc
+	// ContractViolated 仅查询存在契约违规的日志
+	ContractViolated bool
+	StartTime        time.Time
+	EndTime          time.Time
+	Page             int
+	PageSize         int
+	SortBy           string
+	SortOrder        int // 1: asc, -1: desc
 }
 
 // RequestLogStatistics 请求日志统计信息
@@ -175,6 +177,9 @@ func (r *mongoRequestLogRepository) List(ctx context.Context, filter RequestLogF
 	if filter.SourceIP != "" {
 		query["source_ip"] = filter.SourceIP
 	}
+	if filter.ContractViolated {
+		query["contract_violations.0"] = bson.M{"$exists": true}
+	}
 	if !filter.StartTime.IsZero() || !filter.EndTime.IsZero() {
 		timeQuery := bson.M{}
 		if !filter.StartTime.IsZero() {
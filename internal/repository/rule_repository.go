@@ -71,6 +71,7 @@ func (r *ruleRepository) Update(ctx context.Context, rule *models.Rule) error {
 		"enabled":         rule.Enabled,
 		"match_condition": rule.MatchCondition,
 		"response":        rule.Response,
+		"scenario":        rule.Scenario,
 		"tags":            rule.Tags,
 		"creator":         rule.Creator,
 		"updated_at":      rule.UpdatedAt,
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScenarioRepository 场景状态仓库接口
+type ScenarioRepository interface {
+	GetState(ctx context.Context, projectID, environmentID, scenario, clientID string) (*models.ScenarioState, error)
+	SetState(ctx context.Context, state *models.ScenarioState) error
+	ListStates(ctx context.Context, projectID, environmentID string) ([]*models.ScenarioState, error)
+	DeleteStates(ctx context.Context, projectID, environmentID, scenario string) (int64, error)
+}
+
+type scenarioRepository struct {
+	collection *mongo.Collection
+}
+
+// NewScenarioRepository 创建场景状态仓库
+func NewScenarioRepository() ScenarioRepository {
+	return &scenarioRepository{
+		collection: GetCollection("scenario_states"),
+	}
+}
+
+// GetState 获取场景状态，不存在时返回 nil
+func (r *scenarioRepository) GetState(ctx context.Context, projectID, environmentID, scenario, clientID string) (*models.ScenarioState, error) {
+	filter := bson.M{
+		"project_id":     projectID,
+		"environment_id": environmentID,
+		"scenario":       scenario,
+		"client_id":      clientID,
+	}
+
+	var state models.ScenarioState
+	err := r.collection.FindOne(ctx, filter).Decode(&state)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// SetState 设置场景状态（不存在时创建）
+func (r *scenarioRepository) SetState(ctx context.Context, state *models.ScenarioState) error {
+	state.UpdatedAt = time.Now()
+
+	filter := bson.M{
+		"project_id":     state.ProjectID,
+		"environment_id": state.EnvironmentID,
+		"scenario":       state.Scenario,
+		"client_id":      state.ClientID,
+	}
+	update := bson.M{"$set": bson.M{
+		"state":      state.State,
+		"updated_at": state.UpdatedAt,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		state.ID = oid.Hex()
+	}
+
+	return nil
+}
+
+// ListStates 列出环境下所有场景状态
+func (r *scenarioRepository) ListStates(ctx context.Context, projectID, environmentID string) ([]*models.ScenarioState, error) {
+	filter := bson.M{
+		"project_id":     projectID,
+		"environment_id": environmentID,
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		primitive.E{Key: "scenario", Value: 1},
+		primitive.E{Key: "client_id", Value: 1},
+	})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var states []*models.ScenarioState
+	if err = cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// DeleteStates 删除场景状态，scenario 为空时删除环境下所有场景
+func (r *scenarioRepository) DeleteStates(ctx context.Context, projectID, environmentID, scenario string) (int64, error) {
+	filter := bson.M{
+		"project_id":     projectID,
+		"environment_id": environmentID,
+	}
+	if scenario != "" {
+		filter["scenario"] = scenario
+	}
+
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TLSCertificateRepository 环境 HTTPS 证书仓库接口
+type TLSCertificateRepository interface {
+	Save(ctx context.Context, cert *models.TLSCertificate) error
+	Delete(ctx context.Context, environmentID string) (bool, error)
+	FindByEnvironment(ctx context.Context, environmentID string) (*models.TLSCertificate, error)
+	List(ctx context.Context) ([]*models.TLSCertificate, error)
+}
+
+type tlsCertificateRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTLSCertificateRepository 创建环境 HTTPS 证书仓库
+func NewTLSCertificateRepository() TLSCertificateRepository {
+	return &tlsCertificateRepository{
+		collection: GetCollection("tls_certificates"),
+	}
+}
+
+// Save 保存证书，每个环境只保留一个证书
+func (r *tlsCertificateRepository) Save(ctx context.Context, cert *models.TLSCertificate) error {
+	now := time.Now()
+	cert.UpdatedAt = now
+
+	filter := bson.M{"environment_id": cert.EnvironmentID}
+	update := bson.M{
+		"$set": bson.M{
+			"project_id":  cert.ProjectID,
+			"hostnames":   cert.Hostnames,
+			"certificate": cert.Certificate,
+			"private_key": cert.PrivateKey,
+			"not_after":   cert.NotAfter,
+			"updated_at":  cert.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		cert.ID = oid.Hex()
+		cert.CreatedAt = now
+	}
+
+	return nil
+}
+
+// Delete 删除环境的证书，返回证书是否存在
+func (r *tlsCertificateRepository) Delete(ctx context.Context, environmentID string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"environment_id": environmentID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// FindByEnvironment 查询环境的证书，不存在时返回 nil
+func (r *tlsCertificateRepository) FindByEnvironment(ctx context.Context, environmentID string) (*models.TLSCertificate, error) {
+	var cert models.TLSCertificate
+	err := r.collection.FindOne(ctx, bson.M{"environment_id": environmentID}).Decode(&cert)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// List 查询所有环境的证书
+func (r *tlsCertificateRepository) List(ctx context.Context) ([]*models.TLSCertificate, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var certs []*models.TLSCertificate
+	if err = cursor.All(ctx, &certs); err != nil {
+		return nil, err
+	}
+
+	return certs, nil
+}
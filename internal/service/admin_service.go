@@ -1,6 +1,8 @@
 package service
 
 import (
+	"errors"
+	"io"
 	"runtime"
 	"time"
 
@@ -19,6 +21,14 @@ type AdminService struct {
 	statisticsHandler   *api.StatisticsHandler
 	mockHandler         *api.MockHandler
 	importExportService ImportExportService
+	scenarioHandler     *api.ScenarioHandler
+	counterHandler      *api.CounterHandler
+	verificationService VerificationService
+	cacheStatsService   *CacheStatsService
+	protoHandler        *api.ProtoHandler
+	websocketHandler    *api.WebSocketHandler
+	tlsHandler          *api.TLSHandler
+	routingHandler      *api.RoutingHandler
 }
 
 // NewAdminService 创建管理服务
@@ -37,6 +47,46 @@ func NewAdminService(
 	}
 }
 
+// SetScenarioHandler 设置场景状态处理器
+func (s *AdminService) SetScenarioHandler(handler *api.ScenarioHandler) {
+	s.scenarioHandler = handler
+}
+
+// SetCounterHandler 设置规则调用计数器处理器
+func (s *AdminService) SetCounterHandler(handler *api.CounterHandler) {
+	s.counterHandler = handler
+}
+
+// SetVerificationService 设置请求校验服务
+func (s *AdminService) SetVerificationService(verificationService VerificationService) {
+	s.verificationService = verificationService
+}
+
+// SetCacheStatsService 设置缓存统计服务
+func (s *AdminService) SetCacheStatsService(cacheStatsService *CacheStatsService) {
+	s.cacheStatsService = cacheStatsService
+}
+
+// SetProtoHandler 设置 protobuf 描述文件处理器
+func (s *AdminService) SetProtoHandler(handler *api.ProtoHandler) {
+	s.protoHandler = handler
+}
+
+// SetWebSocketHandler 设置 Mock 服务 WebSocket 连接管理处理器
+func (s *AdminService) SetWebSocketHandler(handler *api.WebSocketHandler) {
+	s.websocketHandler = handler
+}
+
+// SetTLSHandler 设置 HTTPS 证书处理器
+func (s *AdminService) SetTLSHandler(handler *api.TLSHandler) {
+	s.tlsHandler = handler
+}
+
+// SetRoutingHandler 设置主机名路由绑定处理器
+func (s *AdminService) SetRoutingHandler(handler *api.RoutingHandler) {
+	s.routingHandler = handler
+}
+
 // StartAdminServer 启动管理服务器
 func StartAdminServer(addr string, service *AdminService) error {
 	gin.SetMode(gin.ReleaseMode)
@@ -58,6 +108,16 @@ func StartAdminServer(addr string, service *AdminService) error {
 			rules.DELETE("/:id", service.ruleHandler.DeleteRule)
 			rules.POST("/:id/enable", service.ruleHandler.EnableRule)
 			rules.POST("/:id/disable", service.ruleHandler.DisableRule)
+
+			// 规则调用计数器 API
+			if service.counterHandler != nil {
+				rules.GET("/:id/counters", service.counterHandler.GetRuleCounters)
+				rules.DELETE("/:id/counters", service.counterHandler.ResetRuleCounters)
+			}
+		}
+
+		if service.counterHandler != nil {
+			v1.DELETE("/counters", service.counterHandler.ResetAllCounters)
 		}
 
 		// 项目管理 API
@@ -77,6 +137,38 @@ func StartAdminServer(addr string, service *AdminService) error {
 				environments.GET("/:env_id", service.projectHandler.GetEnvironment)
 				environments.PUT("/:env_id", service.projectHandler.UpdateEnvironment)
 				environments.DELETE("/:env_id", service.projectHandler.DeleteEnvironment)
+
+				// 场景状态 API
+				if service.scenarioHandler != nil {
+					scenarios := environments.Group("/:env_id/scenarios")
+					{
+						scenarios.GET("", service.scenarioHandler.ListScenarios)
+						scenarios.DELETE("", service.scenarioHandler.ResetScenarios)
+						scenarios.PUT("/:name", service.scenarioHandler.SetScenarioState)
+						scenarios.DELETE("/:name", service.scenarioHandler.ResetScenarios)
+					}
+				}
+
+				// 环境 HTTPS 证书 API
+				if service.tlsHandler != nil {
+					certificates := environments.Group("/:env_id/tls")
+					{
+						certificates.GET("", service.tlsHandler.GetCertificate)
+						certificates.PUT("", service.tlsHandler.UploadCertificate)
+						certificates.DELETE("", service.tlsHandler.DeleteCertificate)
+					}
+				}
+			}
+
+			// protobuf 描述文件 API（gRPC Mock）
+			if service.protoHandler != nil {
+				protos := projects.Group("/:id/protos")
+				{
+					protos.GET("", service.protoHandler.ListProtos)
+					protos.POST("", service.protoHandler.UploadProtos)
+					protos.GET("/*name", service.protoHandler.GetProto)
+					protos.DELETE("/*name", service.protoHandler.DeleteProto)
+				}
 			}
 		}
 
@@ -86,6 +178,9 @@ func StartAdminServer(addr string, service *AdminService) error {
 			system.GET("/health", HealthCheck)
 			system.GET("/version", GetVersion)
 			system.GET("/info", GetSystemInfo)
+			if service.cacheStatsService != nil {
+				system.GET("/cache", service.getCacheStats)
+			}
 		}
 
 		// 统计 API
@@ -107,6 +202,43 @@ func StartAdminServer(addr string, service *AdminService) error {
 			mock.DELETE("/history/:id", service.mockHandler.DeleteMockHistoryItem)
 		}
 
+		// HTTPS 根 CA 证书下载
+		if service.tlsHandler != nil {
+			v1.GET("/tls/ca.crt", service.tlsHandler.DownloadCA)
+		}
+
+		// 主机名路由绑定 API
+		if service.routingHandler != nil {
+			hosts := v1.Group("/routing/hosts")
+			{
+				hosts.GET("", service.routingHandler.ListHostBindings)
+				hosts.PUT("/:host", service.routingHandler.SaveHostBinding)
+				hosts.DELETE("/:host", service.routingHandler.DeleteHostBinding)
+			}
+		}
+
+		// WebSocket 连接管理 API
+		if service.websocketHandler != nil {
+			websocket := v1.Group("/websocket")
+			{
+				websocket.GET("/stats", service.websocketHandler.GetStats)
+				websocket.GET("/connections", service.websocketHandler.ListConnections)
+				websocket.GET("/connections/:id", service.websocketHandler.GetConnection)
+				websocket.POST("/connections/:id/messages", service.websocketHandler.SendMessage)
+				websocket.DELETE("/connections/:id", service.websocketHandler.CloseConnection)
+				websocket.POST("/broadcast", service.websocketHandler.Broadcast)
+			}
+		}
+
+		// 请求校验 API
+		if service.verificationService != nil {
+			verify := v1.Group("/verify")
+			{
+				verify.POST("", service.VerifyRequests)
+				verify.POST("/sequence", service.VerifyRequestSequence)
+			}
+		}
+
 		// 导入导出 API
 		if service.importExportService != nil {
 			importExport := v1.Group("/import-export")
@@ -115,6 +247,7 @@ func StartAdminServer(addr string, service *AdminService) error {
 				importExport.POST("/rules/export", service.ExportRules)
 				importExport.POST("/import", service.ImportData)
 				importExport.POST("/validate", service.ValidateImportData)
+				importExport.POST("/openapi", service.ImportOpenAPI)
 			}
 		}
 	}
@@ -249,6 +382,113 @@ func (s *AdminService) ValidateImportData(c *gin.Context) {
 	})
 }
 
+// ImportOpenAPI 导入 OpenAPI/Swagger 文档（请求体为 JSON 或 YAML 文档），dry_run=true 时仅预览
+func (s *AdminService) ImportOpenAPI(c *gin.Context) {
+	var req models.OpenAPIImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	spec, err := io.ReadAll(c.Request.Body)
+	if err != nil || len(spec) == 0 {
+		c.JSON(400, gin.H{"error": "openapi document is required"})
+		return
+	}
+	req.Spec = spec
+
+	if req.DryRun {
+		preview, err := s.importExportService.PreviewOpenAPI(c.Request.Context(), spec)
+		if err != nil {
+			s.handleOpenAPIError(c, err)
+			return
+		}
+		c.JSON(200, preview)
+		return
+	}
+
+	if req.TargetProjectID == "" || req.TargetEnvID == "" {
+		c.JSON(400, gin.H{"error": "target_project_id and target_environment_id are required"})
+		return
+	}
+
+	result, err := s.importExportService.ImportOpenAPI(c.Request.Context(), &req)
+	if err != nil {
+		s.handleOpenAPIError(c, err)
+		return
+	}
+
+	statusCode := 200
+	if !result.Success {
+		statusCode = 207 // 部分成功
+	}
+	c.JSON(statusCode, result)
+}
+
+// handleOpenAPIError 文档无效返回 400，其余返回 500
+func (s *AdminService) handleOpenAPIError(c *gin.Context, err error) {
+	if errors.Is(err, ErrInvalidOpenAPI) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Error("failed to import openapi document", zap.Error(err))
+	c.JSON(500, gin.H{"error": "failed to import openapi document: " + err.Error()})
+}
+
+// VerifyRequests 校验请求调用次数
+func (s *AdminService) VerifyRequests(c *gin.Context) {
+	var req models.VerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	result, err := s.verificationService.Verify(c.Request.Context(), &req)
+	if err != nil {
+		s.handleVerificationError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// VerifyRequestSequence 校验请求顺序
+func (s *AdminService) VerifyRequestSequence(c *gin.Context) {
+	var req models.SequenceVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	result, err := s.verificationService.VerifySequence(c.Request.Context(), &req)
+	if err != nil {
+		s.handleVerificationError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// handleVerificationError 处理请求校验错误
+func (s *AdminService) handleVerificationError(c *gin.Context, err error) {
+	if errors.Is(err, ErrInvalidMatcher) || errors.Is(err, ErrTooManyLogs) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Error("failed to verify requests", zap.Error(err))
+	c.JSON(500, gin.H{"error": "failed to verify requests: " + err.Error()})
+}
+
+// getCacheStats 获取规则缓存与跨实例失效传播统计
+func (s *AdminService) getCacheStats(c *gin.Context) {
+	stats, err := s.cacheStatsService.GetCacheStats(c.Request.Context())
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, stats)
+}
+
 // getDashboardStatistics 获取仪表盘统计数据
 func (s *AdminService) getDashboardStatistics(c *gin.Context) {
 	// 这里返回模拟数据，实际应该从数据库查询
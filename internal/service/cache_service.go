@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gomockserver/mockserver/internal/cache"
@@ -23,6 +25,38 @@ func NewCacheService(
 	cacheStrategy *cache.CacheStrategy,
 	logger *zap.Logger,
 ) (*CacheService, error) {
+	// 创建L2 Redis缓存
+	l2Cache, err := cache.NewRedisL2Cache(redisConfig, logger.Named("l2_redis"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create L2 cache: %w", err)
+	}
+
+	return newCacheService(l2Cache, cacheStrategy, logger), nil
+}
+
+// NewCacheServiceWithL2 使用已创建的L2缓存创建缓存服务（如与失效传播共用同一个 Redis 连接）
+func NewCacheServiceWithL2(
+	l2Cache cache.L2Cache,
+	cacheStrategy *cache.CacheStrategy,
+	logger *zap.Logger,
+) *CacheService {
+	return newCacheService(l2Cache, cacheStrategy, logger)
+}
+
+// NewMemoryCacheService 创建只使用L1内存缓存的缓存服务（未启用 Redis 时使用）
+func NewMemoryCacheService(
+	cacheStrategy *cache.CacheStrategy,
+	logger *zap.Logger,
+) *CacheService {
+	return newCacheService(cache.NewNoopL2Cache(), cacheStrategy, logger)
+}
+
+// newCacheService 使用给定的L2缓存创建缓存服务
+func newCacheService(
+	l2Cache cache.L2Cache,
+	cacheStrategy *cache.CacheStrategy,
+	logger *zap.Logger,
+) *CacheService {
 	if cacheStrategy == nil {
 		cacheStrategy = cache.DefaultCacheStrategy()
 	}
@@ -30,17 +64,11 @@ func NewCacheService(
 	// 创建L1内存缓存
 	l1Cache := cache.NewMemoryL1Cache(
 		cacheStrategy.L1MaxEntries,
-		100, // 100MB内存限制
+		cacheStrategy.L1MaxMemoryMB,
 		cacheStrategy.L1CleanupInterval,
 		logger.Named("l1_cache"),
 	)
 
-	// 创建L2 Redis缓存
-	l2Cache, err := cache.NewRedisL2Cache(redisConfig, logger.Named("l2_redis"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create L2 cache: %w", err)
-	}
-
 	// 创建频率跟踪器
 	tracker := cache.NewSimpleFrequencyTracker(cacheStrategy.AccessFreqWindow)
 
@@ -59,7 +87,7 @@ func NewCacheService(
 	}
 
 	logger.Info("Cache service initialized successfully")
-	return service, nil
+	return service
 }
 
 // Start 启动缓存服务
@@ -79,11 +107,21 @@ func (s *CacheService) GetManager() cache.Manager {
 	return s.manager
 }
 
+// DefaultEnvironmentRulesTTL 环境启用规则默认缓存时间
+const DefaultEnvironmentRulesTTL = 3 * time.Minute
+
 // CacheRuleService 规则缓存服务
 type CacheRuleService struct {
 	cacheService *CacheService
 	ruleRepo     repository.RuleRepository
+	rulesTTL     time.Duration
 	logger       *zap.Logger
+
+	// 缓存键的失效版本：从数据库加载期间键被清除时不写入缓存，避免旧数据覆盖失效；
+	// epoch 在全量失效时推进，对所有键生效
+	versions   map[string]uint64
+	epoch      uint64
+	versionsMu sync.Mutex
 }
 
 // NewCacheRuleService 创建规则缓存服务
@@ -95,7 +133,41 @@ func NewCacheRuleService(
 	return &CacheRuleService{
 		cacheService: cacheService,
 		ruleRepo:     ruleRepo,
+		rulesTTL:     DefaultEnvironmentRulesTTL,
 		logger:       logger.Named("cache_rule_service"),
+		versions:     make(map[string]uint64),
+	}
+}
+
+// version 获取缓存键的失效版本
+func (s *CacheRuleService) version(key string) uint64 {
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	return s.versions[key] + s.epoch
+}
+
+// setIfUnchanged 键在加载期间未被清除时写入缓存
+func (s *CacheRuleService) setIfUnchanged(ctx context.Context, key string, value interface{}, ttl time.Duration, version uint64) error {
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	if s.versions[key]+s.epoch != version {
+		return nil
+	}
+	return s.cacheService.GetManager().Set(ctx, key, value, ttl)
+}
+
+// deleteKey 推进键的失效版本并清除缓存
+func (s *CacheRuleService) deleteKey(ctx context.Context, key string) error {
+	s.versionsMu.Lock()
+	s.versions[key]++
+	s.versionsMu.Unlock()
+	return s.cacheService.GetManager().Delete(ctx, key)
+}
+
+// SetRulesTTL 设置环境启用规则的缓存时间
+func (s *CacheRuleService) SetRulesTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.rulesTTL = ttl
 	}
 }
 
@@ -106,19 +178,20 @@ func (s *CacheRuleService) GetRulesByProject(ctx context.Context, projectID stri
 	// 尝试从缓存获取
 	if cached, err := s.cacheService.GetManager().Get(ctx, cacheKey); err == nil && cached != nil {
 		s.logger.Debug("Cache hit for project rules", zap.String("project_id", projectID))
-		if rules, ok := cached.([]*models.Rule); ok {
+		if rules, ok := decodeCachedRules(cached); ok {
 			return rules, nil
 		}
 	}
 
 	// 缓存未命中，从数据库获取
+	version := s.version(cacheKey)
 	rules, err := s.ruleRepo.FindByEnvironment(ctx, projectID, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rules from database: %w", err)
 	}
 
 	// 存入缓存
-	if err := s.cacheService.GetManager().Set(ctx, cacheKey, rules, 5*time.Minute); err != nil {
+	if err := s.setIfUnchanged(ctx, cacheKey, rules, 5*time.Minute, version); err != nil {
 		s.logger.Warn("Failed to cache project rules",
 			zap.String("project_id", projectID),
 			zap.Error(err),
@@ -142,19 +215,20 @@ func (s *CacheRuleService) GetEnabledRulesByEnvironment(ctx context.Context, pro
 			zap.String("project_id", projectID),
 			zap.String("environment_id", environmentID),
 		)
-		if rules, ok := cached.([]*models.Rule); ok {
+		if rules, ok := decodeCachedRules(cached); ok {
 			return rules, nil
 		}
 	}
 
 	// 缓存未命中，从数据库获取
+	version := s.version(cacheKey)
 	rules, err := s.ruleRepo.FindEnabledByEnvironment(ctx, projectID, environmentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get enabled rules from database: %w", err)
 	}
 
 	// 存入缓存
-	if err := s.cacheService.GetManager().Set(ctx, cacheKey, rules, 3*time.Minute); err != nil {
+	if err := s.setIfUnchanged(ctx, cacheKey, rules, s.rulesTTL, version); err != nil {
 		s.logger.Warn("Failed to cache environment rules",
 			zap.String("project_id", projectID),
 			zap.String("environment_id", environmentID),
@@ -177,19 +251,20 @@ func (s *CacheRuleService) GetRuleByID(ctx context.Context, ruleID string) (*mod
 	// 尝试从缓存获取
 	if cached, err := s.cacheService.GetManager().Get(ctx, cacheKey); err == nil && cached != nil {
 		s.logger.Debug("Cache hit for rule", zap.String("rule_id", ruleID))
-		if rule, ok := cached.(*models.Rule); ok {
+		if rule, ok := decodeCachedRule(cached); ok {
 			return rule, nil
 		}
 	}
 
 	// 缓存未命中，从数据库获取
+	version := s.version(cacheKey)
 	rule, err := s.ruleRepo.FindByID(ctx, ruleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rule from database: %w", err)
 	}
 
 	// 存入缓存
-	if err := s.cacheService.GetManager().Set(ctx, cacheKey, rule, 10*time.Minute); err != nil {
+	if err := s.setIfUnchanged(ctx, cacheKey, rule, 10*time.Minute, version); err != nil {
 		s.logger.Warn("Failed to cache rule",
 			zap.String("rule_id", ruleID),
 			zap.Error(err),
@@ -209,7 +284,7 @@ func (s *CacheRuleService) InvalidateProjectRules(ctx context.Context, projectID
 	}
 
 	for _, pattern := range patterns {
-		if err := s.cacheService.GetManager().Delete(ctx, pattern); err != nil {
+		if err := s.deleteKey(ctx, pattern); err != nil {
 			s.logger.Warn("Failed to invalidate cache pattern",
 				zap.String("pattern", pattern),
 				zap.Error(err),
@@ -225,7 +300,7 @@ func (s *CacheRuleService) InvalidateProjectRules(ctx context.Context, projectID
 func (s *CacheRuleService) InvalidateEnvironmentRules(ctx context.Context, projectID, environmentID string) error {
 	pattern := fmt.Sprintf("rules:enabled:%s:%s", projectID, environmentID)
 
-	if err := s.cacheService.GetManager().Delete(ctx, pattern); err != nil {
+	if err := s.deleteKey(ctx, pattern); err != nil {
 		s.logger.Warn("Failed to invalidate environment rules cache",
 			zap.String("project_id", projectID),
 			zap.String("environment_id", environmentID),
@@ -245,7 +320,7 @@ func (s *CacheRuleService) InvalidateEnvironmentRules(ctx context.Context, proje
 func (s *CacheRuleService) InvalidateRule(ctx context.Context, ruleID string) error {
 	pattern := fmt.Sprintf("rule:id:%s", ruleID)
 
-	if err := s.cacheService.GetManager().Delete(ctx, pattern); err != nil {
+	if err := s.deleteKey(ctx, pattern); err != nil {
 		s.logger.Warn("Failed to invalidate rule cache",
 			zap.String("rule_id", ruleID),
 			zap.Error(err),
@@ -257,9 +332,80 @@ func (s *CacheRuleService) InvalidateRule(ctx context.Context, ruleID string) er
 	return nil
 }
 
+// InvalidateLocal 清空本实例L1中的全部规则缓存，用于错过其他实例的失效事件后重新同步（L2 由写入方清除）
+func (s *CacheRuleService) InvalidateLocal(ctx context.Context) error {
+	s.versionsMu.Lock()
+	s.epoch++
+	s.versionsMu.Unlock()
+	return s.cacheService.GetManager().Clear(ctx, cache.L1_HOT)
+}
+
+// RuleSaved 规则创建或更新后清除相关缓存（实现 repository.RuleChangeListener）
+func (s *CacheRuleService) RuleSaved(ctx context.Context, rule *models.Rule) {
+	s.invalidateRuleKeys(ctx, rule)
+}
+
+// RuleDeleted 规则删除或移动后清除原项目/环境的缓存（实现 repository.RuleChangeListener）
+func (s *CacheRuleService) RuleDeleted(ctx context.Context, rule *models.Rule) {
+	s.invalidateRuleKeys(ctx, rule)
+}
+
+// invalidateRuleKeys 清除规则及其所属项目/环境的缓存
+func (s *CacheRuleService) invalidateRuleKeys(ctx context.Context, rule *models.Rule) {
+	if rule == nil {
+		return
+	}
+	if rule.ID != "" {
+		_ = s.InvalidateRule(ctx, rule.ID)
+	}
+	if rule.ProjectID != "" {
+		_ = s.InvalidateProjectRules(ctx, rule.ProjectID)
+		_ = s.InvalidateEnvironmentRules(ctx, rule.ProjectID, rule.EnvironmentID)
+	}
+}
+
+// decodeCachedRules 还原缓存中的规则列表，L2 命中时值为 JSON 解码后的通用结构
+func decodeCachedRules(cached interface{}) ([]*models.Rule, bool) {
+	if rules, ok := cached.([]*models.Rule); ok {
+		return rules, true
+	}
+	var rules []*models.Rule
+	if !remarshal(cached, &rules) {
+		return nil, false
+	}
+	return rules, true
+}
+
+// decodeCachedRule 还原缓存中的单个规则
+func decodeCachedRule(cached interface{}) (*models.Rule, bool) {
+	if rule, ok := cached.(*models.Rule); ok {
+		return rule, true
+	}
+	var rule models.Rule
+	if !remarshal(cached, &rule) || rule.ID == "" {
+		return nil, false
+	}
+	return &rule, true
+}
+
+// remarshal 通过 JSON 将通用结构转换为目标类型
+func remarshal(value interface{}, target interface{}) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, target) == nil
+}
+
+// PropagationStatsProvider 跨实例失效传播统计来源（InvalidationPropagator）
+type PropagationStatsProvider interface {
+	PropagationStats() *cache.PropagationStats
+}
+
 // CacheStatsService 缓存统计服务
 type CacheStatsService struct {
 	cacheService *CacheService
+	propagation  PropagationStatsProvider
 	logger       *zap.Logger
 }
 
@@ -274,9 +420,26 @@ func NewCacheStatsService(
 	}
 }
 
-// GetCacheStats 获取缓存统计信息
+// SetPropagation 设置跨实例失效传播统计来源
+func (s *CacheStatsService) SetPropagation(provider PropagationStatsProvider) {
+	s.propagation = provider
+}
+
+// GetCacheStats 获取缓存统计信息，未启用规则缓存时只包含传播统计
 func (s *CacheStatsService) GetCacheStats(ctx context.Context) (*cache.CacheStats, error) {
-	return s.cacheService.GetManager().GetStats(ctx)
+	stats := &cache.CacheStats{}
+	if s.cacheService != nil {
+		managerStats, err := s.cacheService.GetManager().GetStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// 复制一份，避免修改管理器内部的统计
+		*stats = *managerStats
+	}
+	if s.propagation != nil {
+		stats.Propagation = s.propagation.PropagationStats()
+	}
+	return stats, nil
 }
 
 // GetCacheStrategy 获取当前缓存策略
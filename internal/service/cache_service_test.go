@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCacheRuleService_GetEnabledRulesByEnvironment(t *testing.T) {
+	ruleRepo := new(MockBatchRuleRepository)
+	rules := []*models.Rule{{ID: "r1", ProjectID: "p1", EnvironmentID: "e1", Enabled: true}}
+	ruleRepo.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").Return(rules, nil)
+
+	cacheService := NewMemoryCacheService(nil, zap.NewNop())
+	svc := NewCacheRuleService(cacheService, ruleRepo, zap.NewNop())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		got, err := svc.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+		require.NoError(t, err)
+		assert.Equal(t, rules, got)
+	}
+	ruleRepo.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 1)
+
+	// 规则写入后清除所在环境的缓存
+	svc.RuleSaved(ctx, rules[0])
+	_, err := svc.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	require.NoError(t, err)
+	ruleRepo.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 2)
+
+	svc.RuleDeleted(ctx, &models.Rule{ID: "r1", ProjectID: "p1", EnvironmentID: "e1"})
+	_, err = svc.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	require.NoError(t, err)
+	ruleRepo.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 3)
+}
+
+func TestCacheRuleService_ObservedRepositoryInvalidates(t *testing.T) {
+	store := new(MockBatchRuleRepository)
+	cacheService := NewMemoryCacheService(nil, zap.NewNop())
+	svc := NewCacheRuleService(cacheService, store, zap.NewNop())
+	ruleRepo := repository.NewObservedRuleRepository(store, svc)
+	ctx := context.Background()
+
+	store.On("FindEnabledByEnvironment", mock.Anything, "p1", mock.Anything).Return([]*models.Rule{}, nil)
+	_, _ = svc.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	_, _ = svc.GetEnabledRulesByEnvironment(ctx, "p1", "e2")
+	store.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 2)
+
+	// 规则从 e1 移动到 e2，两个环境的缓存都失效
+	moved := &models.Rule{ID: "r1", ProjectID: "p1", EnvironmentID: "e2"}
+	store.On("FindByID", mock.Anything, "r1").Return(&models.Rule{ID: "r1", ProjectID: "p1", EnvironmentID: "e1"}, nil)
+	store.On("Update", mock.Anything, moved).Return(nil)
+	require.NoError(t, ruleRepo.Update(ctx, moved))
+
+	_, _ = svc.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	_, _ = svc.GetEnabledRulesByEnvironment(ctx, "p1", "e2")
+	store.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 4)
+}
+
+func TestDecodeCachedRules(t *testing.T) {
+	rules := []*models.Rule{{
+		ID:             "r1",
+		Priority:       10,
+		MatchCondition: map[string]interface{}{"method": "GET", "path": "/users"},
+	}}
+
+	// 模拟 L2 Redis 命中：值为 JSON 解码后的通用结构
+	data, err := json.Marshal(rules)
+	require.NoError(t, err)
+	var generic interface{}
+	require.NoError(t, json.Unmarshal(data, &generic))
+
+	decoded, ok := decodeCachedRules(generic)
+	require.True(t, ok)
+	require.Len(t, decoded, 1)
+	assert.Equal(t, "r1", decoded[0].ID)
+	assert.Equal(t, 10, decoded[0].Priority)
+	assert.Equal(t, "/users", decoded[0].MatchCondition["path"])
+
+	_, ok = decodeCachedRules("not rules")
+	assert.False(t, ok)
+}
+
+func TestCacheRuleService_InvalidationDuringLoadIsNotOverwritten(t *testing.T) {
+	ruleRepo := new(MockBatchRuleRepository)
+	cacheService := NewMemoryCacheService(nil, zap.NewNop())
+	svc := NewCacheRuleService(cacheService, ruleRepo, zap.NewNop())
+	ctx := context.Background()
+
+	stale := []*models.Rule{{ID: "r1", ProjectID: "p1", EnvironmentID: "e1", Enabled: true}}
+	// 从数据库读到旧规则后、写入缓存前，规则被修改
+	ruleRepo.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").Return(stale, nil).Once().Run(func(args mock.Arguments) {
+		svc.RuleSaved(ctx, stale[0])
+	})
+	ruleRepo.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").Return([]*models.Rule{}, nil)
+
+	_, err := svc.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	require.NoError(t, err)
+
+	got, err := svc.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	ruleRepo.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 2)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// clientAuthConfigTTL 客户端证书模式缓存有效期
+const clientAuthConfigTTL = 2 * time.Second
+
+// clientAuthEntry 客户端证书模式缓存项
+type clientAuthEntry struct {
+	mode      models.ClientAuthMode
+	expiresAt time.Time
+}
+
+// ClientAuthService 按环境提供客户端证书（mTLS）模式
+type ClientAuthService struct {
+	envRepo repository.EnvironmentRepository
+
+	mu      sync.RWMutex
+	entries map[string]clientAuthEntry
+}
+
+// NewClientAuthService 创建客户端证书模式服务
+func NewClientAuthService(envRepo repository.EnvironmentRepository) *ClientAuthService {
+	return &ClientAuthService{
+		envRepo: envRepo,
+		entries: make(map[string]clientAuthEntry),
+	}
+}
+
+// ClientAuthMode 获取环境的客户端证书模式，未配置或加载失败时返回 request
+func (s *ClientAuthService) ClientAuthMode(ctx context.Context, environmentID string) models.ClientAuthMode {
+	s.mu.RLock()
+	entry, exists := s.entries[environmentID]
+	s.mu.RUnlock()
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.mode
+	}
+
+	mode := models.ClientAuthRequest
+	env, err := s.envRepo.FindByID(ctx, environmentID)
+	if err != nil {
+		logger.Warn("failed to load client auth mode",
+			zap.String("environment_id", environmentID),
+			zap.Error(err))
+	} else if env != nil && env.ClientAuth != "" {
+		mode = env.ClientAuth
+	}
+
+	s.mu.Lock()
+	s.entries[environmentID] = clientAuthEntry{mode: mode, expiresAt: time.Now().Add(clientAuthConfigTTL)}
+	s.mu.Unlock()
+
+	return mode
+}
+
+// EnvironmentChanged 环境变更后清除缓存（实现 repository.EnvironmentChangeListener）
+func (s *ClientAuthService) EnvironmentChanged(ctx context.Context, environmentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if environmentID == "" {
+		s.entries = make(map[string]clientAuthEntry)
+		return
+	}
+	delete(s.entries, environmentID)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClientAuthService_ClientAuthMode(t *testing.T) {
+	envRepo := new(MockImportEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", ClientAuth: models.ClientAuthRequire}, nil).Twice()
+	envRepo.On("FindByID", mock.Anything, "e2").Return(&models.Environment{ID: "e2"}, nil).Once()
+	envRepo.On("FindByID", mock.Anything, "e3").Return(nil, errors.New("database error")).Once()
+
+	svc := NewClientAuthService(envRepo)
+	ctx := context.Background()
+
+	assert.Equal(t, models.ClientAuthRequire, svc.ClientAuthMode(ctx, "e1"))
+	assert.Equal(t, models.ClientAuthRequire, svc.ClientAuthMode(ctx, "e1"))
+	assert.Equal(t, models.ClientAuthRequest, svc.ClientAuthMode(ctx, "e2"))
+	assert.Equal(t, models.ClientAuthRequest, svc.ClientAuthMode(ctx, "e3"))
+
+	// 环境变更后重新加载
+	svc.EnvironmentChanged(ctx, "e1")
+	assert.Equal(t, models.ClientAuthRequire, svc.ClientAuthMode(ctx, "e1"))
+	envRepo.AssertExpectations(t)
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/engine"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// contractConfigTTL 契约配置缓存有效期
+const contractConfigTTL = 2 * time.Second
+
+// ContractValidator 根据 OpenAPI 3.x / Swagger 2.0 文档校验请求与 Mock 响应
+type ContractValidator struct {
+	doc        *openAPIDocument
+	operations []*contractOperation
+}
+
+// contractOperation 文档中的单个操作
+type contractOperation struct {
+	method     string
+	path       string         // 含基础路径的路径模板
+	pattern    *regexp.Regexp // 路径匹配正则
+	paramNames []string       // 路径参数名（与正则分组顺序一致）
+	parameters []map[string]interface{}
+	body       map[string]interface{} // 请求体定义（OpenAPI 3 requestBody，Swagger body 参数会被转换为相同结构）
+	responses  map[string]interface{}
+}
+
+// NewContractValidator 解析文档并创建契约校验器
+func NewContractValidator(spec []byte) (*ContractValidator, error) {
+	doc, err := parseOpenAPIDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &ContractValidator{doc: doc}
+	basePath := doc.basePath()
+	paths := doc.root["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		item := doc.resolve(paths[path])
+		if item == nil {
+			continue
+		}
+		pathParams, _ := item["parameters"].([]interface{})
+		for _, method := range openAPIMethods {
+			operation, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op, err := v.compileOperation(strings.ToUpper(method), basePath+path, pathParams, operation)
+			if err != nil {
+				return nil, err
+			}
+			v.operations = append(v.operations, op)
+		}
+	}
+
+	// 路径参数少的操作优先，保证 /users/me 先于 /users/{id} 匹配
+	sort.SliceStable(v.operations, func(i, j int) bool {
+		return len(v.operations[i].paramNames) < len(v.operations[j].paramNames)
+	})
+	return v, nil
+}
+
+// compileOperation 编译操作的路径模板、参数与请求体定义
+func (v *ContractValidator) compileOperation(method, path string, pathParams []interface{}, operation map[string]interface{}) (*contractOperation, error) {
+	op := &contractOperation{method: method, path: path}
+
+	// 路径模板 /users/{id} 转换为正则
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	rest := path
+	for {
+		start := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if start < 0 || end < start {
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		pattern.WriteString(regexp.QuoteMeta(rest[:start]))
+		pattern.WriteString("([^/]+)")
+		op.paramNames = append(op.paramNames, rest[start+1:end])
+		rest = rest[end+1:]
+	}
+	pattern.WriteString("/?$")
+	compiled, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid path %s", ErrInvalidOpenAPI, path)
+	}
+	op.pattern = compiled
+
+	// 操作级参数覆盖路径级同名参数
+	opParams, _ := operation["parameters"].([]interface{})
+	merged := make(map[string]map[string]interface{})
+	var order []string
+	for _, raw := range append(append([]interface{}{}, pathParams...), opParams...) {
+		param := v.doc.resolve(raw)
+		if param == nil {
+			continue
+		}
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		key := in + ":" + name
+		if _, exists := merged[key]; !exists {
+			order = append(order, key)
+		}
+		merged[key] = param
+	}
+	for _, key := range order {
+		param := merged[key]
+		if param["in"] == "body" {
+			// Swagger 2.0 body 参数
+			required, _ := param["required"].(bool)
+			content := make(map[string]interface{})
+			for _, mediaType := range v.swaggerConsumes(operation) {
+				content[mediaType] = map[string]interface{}{"schema": param["schema"]}
+			}
+			op.body = map[string]interface{}{"required": required, "content": content}
+			continue
+		}
+		op.parameters = append(op.parameters, param)
+	}
+
+	if body := v.doc.resolve(operation["requestBody"]); body != nil {
+		op.body = body
+	}
+	op.responses, _ = operation["responses"].(map[string]interface{})
+	return op, nil
+}
+
+// swaggerConsumes Swagger 2.0 操作接受的媒体类型，未声明时为 application/json
+func (v *ContractValidator) swaggerConsumes(operation map[string]interface{}) []string {
+	consumes, ok := operation["consumes"].([]interface{})
+	if !ok {
+		consumes, _ = v.doc.root["consumes"].([]interface{})
+	}
+	var mediaTypes []string
+	for _, mediaType := range consumes {
+		if mediaType, ok := mediaType.(string); ok {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+	return mediaTypes
+}
+
+// findOperation 按方法和路径查找操作，返回路径参数值
+func (v *ContractValidator) findOperation(method, path string) (*contractOperation, map[string]string) {
+	for _, op := range v.operations {
+		if op.method != method {
+			continue
+		}
+		matches := op.pattern.FindStringSubmatch(path)
+		if matches == nil {
+			continue
+		}
+		values := make(map[string]string, len(op.paramNames))
+		for i, name := range op.paramNames {
+			values[name] = matches[i+1]
+		}
+		return op, values
+	}
+	return nil, nil
+}
+
+// ValidateRequest 校验请求的路径参数、查询参数、请求头与请求体
+func (v *ContractValidator) ValidateRequest(request *adapter.Request) []models.ContractViolation {
+	method, _ := request.Metadata["method"].(string)
+	op, pathValues := v.findOperation(strings.ToUpper(method), request.Path)
+	if op == nil {
+		return []models.ContractViolation{{
+			Location: "path",
+			Name:     request.Path,
+			Message:  fmt.Sprintf("operation %s %s is not defined in contract", method, request.Path),
+		}}
+	}
+
+	query, _ := request.Metadata["query"].(map[string]string)
+	var violations []models.ContractViolation
+	for _, param := range op.parameters {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+
+		var raw string
+		var present bool
+		switch in {
+		case "path":
+			raw, present = pathValues[name]
+		case "query":
+			raw, present = query[name]
+		case "header":
+			raw = adapter.HeaderValue(request.Headers, name)
+			present = raw != ""
+		default:
+			continue
+		}
+
+		if !present {
+			if required, _ := param["required"].(bool); required {
+				violations = append(violations, models.ContractViolation{Location: in, Name: name, Message: "required parameter is missing"})
+			}
+			continue
+		}
+
+		schema := v.parameterSchema(param)
+		if schema == nil {
+			continue
+		}
+		for _, violation := range engine.NewJSONSchemaWithRoot(schema, v.doc.root).Validate(v.coerceParameter(raw, schema)) {
+			violations = append(violations, models.ContractViolation{Location: in, Name: name, Message: violation.Message})
+		}
+	}
+
+	return append(violations, v.validateRequestBody(op, request)...)
+}
+
+// validateRequestBody 校验请求体
+func (v *ContractValidator) validateRequestBody(op *contractOperation, request *adapter.Request) []models.ContractViolation {
+	if op.body == nil {
+		return nil
+	}
+	if len(request.Body) == 0 {
+		if required, _ := op.body["required"].(bool); required {
+			return []models.ContractViolation{{Location: "body", Message: "request body is required"}}
+		}
+		return nil
+	}
+
+	content, _ := op.body["content"].(map[string]interface{})
+	return v.validateContent("body", content, adapter.HeaderValue(request.Headers, "Content-Type"), request.Body)
+}
+
+// ValidateResponse 校验 Mock 响应的状态码与响应体
+func (v *ContractValidator) ValidateResponse(request *adapter.Request, response *adapter.Response) []models.ContractViolation {
+	method, _ := request.Metadata["method"].(string)
+	op, _ := v.findOperation(strings.ToUpper(method), request.Path)
+	if op == nil {
+		return nil
+	}
+
+	code := strconv.Itoa(response.StatusCode)
+	definition, ok := op.responses[code]
+	if !ok {
+		definition, ok = op.responses[code[:1]+"XX"]
+	}
+	if !ok {
+		definition, ok = op.responses["default"]
+	}
+	if !ok {
+		return []models.ContractViolation{{
+			Location: "response",
+			Name:     "status_code",
+			Message:  fmt.Sprintf("status %d is not declared for %s %s", response.StatusCode, op.method, op.path),
+		}}
+	}
+
+	resolved := v.doc.resolve(definition)
+	if resolved == nil || len(response.Body) == 0 {
+		return nil
+	}
+
+	content, _ := resolved["content"].(map[string]interface{})
+	if schema, exists := resolved["schema"]; exists && content == nil {
+		// Swagger 2.0 响应直接声明 schema
+		content = map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+	}
+	return v.validateContent("response", content, adapter.HeaderValue(response.Headers, "Content-Type"), response.Body)
+}
+
+// validateContent 按媒体类型校验内容，仅 JSON 内容做 Schema 校验
+func (v *ContractValidator) validateContent(location string, content map[string]interface{}, contentType string, body []byte) []models.ContractViolation {
+	if len(content) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := matchMediaType(content, mediaType)
+	if !ok {
+		return []models.ContractViolation{{
+			Location: location,
+			Name:     "Content-Type",
+			Message:  fmt.Sprintf("content type %q is not declared", contentType),
+		}}
+	}
+
+	schema, _ := v.doc.resolve(media)["schema"].(map[string]interface{})
+	if schema == nil || !strings.Contains(mediaType, "json") {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []models.ContractViolation{{Location: location, Message: "invalid JSON: " + err.Error()}}
+	}
+
+	var violations []models.ContractViolation
+	for _, violation := range engine.NewJSONSchemaWithRoot(schema, v.doc.root).Validate(value) {
+		violations = append(violations, models.ContractViolation{Location: location, Name: violation.Path, Message: violation.Message})
+	}
+	return violations
+}
+
+// matchMediaType 查找媒体类型定义，支持 application/* 与 */* 通配
+func matchMediaType(content map[string]interface{}, mediaType string) (interface{}, bool) {
+	if media, ok := content[mediaType]; ok {
+		return media, true
+	}
+	if slash := strings.Index(mediaType, "/"); slash > 0 {
+		if media, ok := content[mediaType[:slash]+"/*"]; ok {
+			return media, true
+		}
+	}
+	media, ok := content["*/*"]
+	return media, ok
+}
+
+// parameterSchema 获取参数 Schema（OpenAPI 3 使用 schema 字段，Swagger 2.0 参数自身即 Schema）
+func (v *ContractValidator) parameterSchema(param map[string]interface{}) map[string]interface{} {
+	if schema, ok := param["schema"]; ok {
+		return v.doc.resolve(schema)
+	}
+	if _, ok := param["type"]; ok {
+		schema := make(map[string]interface{}, len(param))
+		for key, value := range param {
+			if key != "required" && key != "name" && key != "in" {
+				schema[key] = value
+			}
+		}
+		return schema
+	}
+	return nil
+}
+
+// coerceParameter 将字符串参数按 Schema 类型转换后再校验
+func (v *ContractValidator) coerceParameter(raw string, schema map[string]interface{}) interface{} {
+	switch schemaType(schema) {
+	case "integer", "number":
+		if number, err := strconv.ParseFloat(raw, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	case "array":
+		items := v.doc.resolve(schema["items"])
+		var values []interface{}
+		for _, item := range strings.Split(raw, ",") {
+			if items != nil {
+				values = append(values, v.coerceParameter(item, items))
+			} else {
+				values = append(values, item)
+			}
+		}
+		return values
+	}
+	return raw
+}
+
+// contractEntry 契约缓存项
+type contractEntry struct {
+	hash      [sha256.Size]byte
+	validator *ContractValidator
+	config    *models.ContractConfig
+	expiresAt time.Time
+}
+
+// ContractService 按环境提供契约校验器，文档未变化时复用已编译的校验器
+type ContractService struct {
+	envRepo repository.EnvironmentRepository
+
+	mu      sync.RWMutex
+	entries map[string]*contractEntry
+}
+
+// NewContractService 创建契约服务
+func NewContractService(envRepo repository.EnvironmentRepository) *ContractService {
+	return &ContractService{
+		envRepo: envRepo,
+		entries: make(map[string]*contractEntry),
+	}
+}
+
+// Contract 获取环境绑定的契约校验器，未绑定或文档无效时返回 nil
+func (s *ContractService) Contract(ctx context.Context, environmentID string) (*ContractValidator, *models.ContractConfig) {
+	s.mu.RLock()
+	entry, exists := s.entries[environmentID]
+	s.mu.RUnlock()
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.validator, entry.config
+	}
+
+	next := &contractEntry{expiresAt: time.Now().Add(contractConfigTTL)}
+	env, err := s.envRepo.FindByID(ctx, environmentID)
+	if err != nil {
+		logger.Warn("failed to load contract config",
+			zap.String("environment_id", environmentID),
+			zap.Error(err))
+	} else if env != nil && env.Contract != nil && env.Contract.Spec != "" &&
+		(env.Contract.ValidateRequest || env.Contract.ValidateResponse) {
+		next.config = env.Contract
+		next.hash = sha256.Sum256([]byte(env.Contract.Spec))
+		if exists && entry.validator != nil && entry.hash == next.hash {
+			next.validator = entry.validator
+		} else if next.validator, err = NewContractValidator([]byte(env.Contract.Spec)); err != nil {
+			logger.Warn("invalid contract document",
+				zap.String("environment_id", environmentID),
+				zap.Error(err))
+		}
+	}
+	if next.validator == nil {
+		next.config = nil
+	}
+
+	s.mu.Lock()
+	s.entries[environmentID] = next
+	s.mu.Unlock()
+
+	return next.validator, next.config
+}
+
+// EnvironmentChanged 环境变更后清除契约缓存（实现 repository.EnvironmentChangeListener）
+func (s *ContractService) EnvironmentChanged(ctx context.Context, environmentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if environmentID == "" {
+		s.entries = make(map[string]*contractEntry)
+		return
+	}
+	delete(s.entries, environmentID)
+}
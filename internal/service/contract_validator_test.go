@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const contractSpec = `
+openapi: 3.0.0
+info: {title: Orders, version: "1"}
+paths:
+  /orders:
+    post:
+      parameters:
+        - {name: X-Tenant, in: header, required: true, schema: {type: string}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Order'}
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Order'}
+  /orders/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: integer, minimum: 1}}
+    get:
+      parameters:
+        - {name: expand, in: query, schema: {type: boolean}}
+        - {name: fields, in: query, schema: {type: array, items: {type: string, enum: [id, qty]}}}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Order'}
+        4XX:
+          description: error
+  /orders/latest:
+    get:
+      responses:
+        '200': {description: ok}
+components:
+  schemas:
+    Order:
+      type: object
+      required: [item, qty]
+      properties:
+        item: {type: string}
+        qty: {type: integer, minimum: 1}
+`
+
+func contractRequest(method, path string, query map[string]string, headers map[string]string, body string) *adapter.Request {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	if query == nil {
+		query = map[string]string{}
+	}
+	return &adapter.Request{
+		Path:     path,
+		Headers:  headers,
+		Body:     []byte(body),
+		Metadata: map[string]interface{}{"method": method, "query": query},
+	}
+}
+
+func TestContractValidator_ValidateRequest(t *testing.T) {
+	validator, err := NewContractValidator([]byte(contractSpec))
+	require.NoError(t, err)
+
+	jsonHeaders := map[string]string{"Content-Type": "application/json", "X-Tenant": "acme"}
+	tests := []struct {
+		name       string
+		request    *adapter.Request
+		violations []models.ContractViolation
+	}{
+		{"合法 POST", contractRequest("POST", "/orders", nil, jsonHeaders, `{"item":"book","qty":2}`), nil},
+		{"合法 GET", contractRequest("get", "/orders/42", map[string]string{"expand": "true", "fields": "id,qty"}, nil, ""), nil},
+		{"静态路径优先", contractRequest("GET", "/orders/latest", nil, nil, ""), nil},
+		{
+			"未定义操作", contractRequest("DELETE", "/orders/1", nil, nil, ""),
+			[]models.ContractViolation{{Location: "path", Name: "/orders/1", Message: "operation DELETE /orders/1 is not defined in contract"}},
+		},
+		{
+			"路径参数类型错误", contractRequest("GET", "/orders/abc", nil, nil, ""),
+			[]models.ContractViolation{{Location: "path", Name: "id", Message: "expected type integer, got string"}},
+		},
+		{
+			"查询参数枚举错误", contractRequest("GET", "/orders/1", map[string]string{"fields": "id,price"}, nil, ""),
+			[]models.ContractViolation{{Location: "query", Name: "fields", Message: "value price is not one of [id qty]"}},
+		},
+		{
+			"缺少请求头和请求体", contractRequest("POST", "/orders", nil, nil, ""),
+			[]models.ContractViolation{
+				{Location: "header", Name: "X-Tenant", Message: "required parameter is missing"},
+				{Location: "body", Message: "request body is required"},
+			},
+		},
+		{
+			"请求体不符合 Schema", contractRequest("POST", "/orders", nil, jsonHeaders, `{"qty":0}`),
+			[]models.ContractViolation{
+				{Location: "body", Name: "$.item", Message: "required property is missing"},
+				{Location: "body", Name: "$.qty", Message: "value must be at least 1"},
+			},
+		},
+		{
+			"不支持的媒体类型", contractRequest("POST", "/orders", nil, map[string]string{"Content-Type": "text/plain", "X-Tenant": "a"}, "x"),
+			[]models.ContractViolation{{Location: "body", Name: "Content-Type", Message: "content type \"text/plain\" is not declared"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.violations, validator.ValidateRequest(tt.request))
+		})
+	}
+}
+
+func TestContractValidator_ValidateResponse(t *testing.T) {
+	validator, err := NewContractValidator([]byte(contractSpec))
+	require.NoError(t, err)
+
+	request := contractRequest("GET", "/orders/1", nil, nil, "")
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	assert.Empty(t, validator.ValidateResponse(request, &adapter.Response{StatusCode: 200, Headers: jsonHeaders, Body: []byte(`{"item":"a","qty":1}`)}))
+	// 4XX 范围响应未声明内容
+	assert.Empty(t, validator.ValidateResponse(request, &adapter.Response{StatusCode: 404, Headers: jsonHeaders, Body: []byte(`{}`)}))
+
+	violations := validator.ValidateResponse(request, &adapter.Response{StatusCode: 200, Headers: jsonHeaders, Body: []byte(`{"item":"a","qty":"1"}`)})
+	assert.Equal(t, []models.ContractViolation{{Location: "response", Name: "$.qty", Message: "expected type integer, got string"}}, violations)
+
+	violations = validator.ValidateResponse(request, &adapter.Response{StatusCode: 500})
+	require.Len(t, violations, 1)
+	assert.Equal(t, "status_code", violations[0].Name)
+}
+
+func TestContractValidator_Swagger(t *testing.T) {
+	validator, err := NewContractValidator([]byte(`{
+		"swagger": "2.0",
+		"basePath": "/v1",
+		"paths": {
+			"/users": {
+				"post": {
+					"parameters": [
+						{"name": "page", "in": "query", "type": "integer", "maximum": 10},
+						{"name": "body", "in": "body", "required": true, "schema": {"type": "object", "required": ["name"]}}
+					],
+					"responses": {"200": {"description": "ok", "schema": {"type": "array"}}}
+				}
+			}
+		}
+	}`))
+	require.NoError(t, err)
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	assert.Empty(t, validator.ValidateRequest(contractRequest("POST", "/v1/users", map[string]string{"page": "2"}, headers, `{"name":"a"}`)))
+
+	violations := validator.ValidateRequest(contractRequest("POST", "/v1/users", map[string]string{"page": "20"}, headers, `{}`))
+	require.Len(t, violations, 2)
+	assert.Equal(t, "query", violations[0].Location)
+	assert.Equal(t, "body", violations[1].Location)
+
+	violations = validator.ValidateResponse(contractRequest("POST", "/v1/users", nil, nil, ""), &adapter.Response{StatusCode: 200, Headers: headers, Body: []byte(`{}`)})
+	assert.Equal(t, []models.ContractViolation{{Location: "response", Name: "$", Message: "expected type array, got object"}}, violations)
+}
+
+func TestContractService_Contract(t *testing.T) {
+	envRepo := new(MockImportEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{
+		ID:       "e1",
+		Contract: &models.ContractConfig{Spec: contractSpec, ValidateRequest: true},
+	}, nil).Once()
+	envRepo.On("FindByID", mock.Anything, "e2").Return(&models.Environment{
+		ID:       "e2",
+		Contract: &models.ContractConfig{Spec: "not a spec", ValidateResponse: true},
+	}, nil).Once()
+	envRepo.On("FindByID", mock.Anything, "e3").Return(&models.Environment{ID: "e3"}, nil).Once()
+
+	svc := NewContractService(envRepo)
+	ctx := context.Background()
+
+	validator, config := svc.Contract(ctx, "e1")
+	require.NotNil(t, validator)
+	assert.True(t, config.ValidateRequest)
+	cached, _ := svc.Contract(ctx, "e1")
+	assert.Same(t, validator, cached)
+
+	validator, config = svc.Contract(ctx, "e2")
+	assert.Nil(t, validator)
+	assert.Nil(t, config)
+
+	validator, _ = svc.Contract(ctx, "e3")
+	assert.Nil(t, validator)
+	envRepo.AssertExpectations(t)
+}
+
+// staticContractProvider 固定返回同一契约的提供者
+type staticContractProvider struct {
+	validator *ContractValidator
+	config    *models.ContractConfig
+}
+
+func (p *staticContractProvider) Contract(ctx context.Context, environmentID string) (*ContractValidator, *models.ContractConfig) {
+	return p.validator, p.config
+}
+
+func TestMockService_HandleMockRequest_Contract(t *testing.T) {
+	validator, err := NewContractValidator([]byte(contractSpec))
+	require.NoError(t, err)
+
+	mockEngine := new(MockMatchEngine)
+	mockExecutor := new(MockMockExecutor)
+	service := NewMockService(mockEngine, mockExecutor)
+	service.SetContractProvider(&staticContractProvider{
+		validator: validator,
+		config:    &models.ContractConfig{ValidateRequest: true, ValidateResponse: true},
+	})
+
+	rule := &models.Rule{ID: "rule-001"}
+	mockEngine.On("Match", mock.Anything, mock.Anything, "project-001", "env-001").Return(rule, nil)
+	mockExecutor.On("Execute", mock.Anything, rule).Return(&adapter.Response{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       []byte(`{"item":"a"}`),
+	}, nil)
+
+	var violations interface{}
+	router := setupTestRouter()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		violations, _ = c.Get("contract_violations")
+	})
+	router.Any("/:projectID/:environmentID/*path", service.HandleMockRequest)
+
+	// 不符合契约的请求返回 400
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/project-001/env-001/orders/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Request does not match OpenAPI contract")
+	assert.Contains(t, w.Body.String(), `"location":"path"`)
+	mockEngine.AssertNotCalled(t, "Match", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// 偏离契约的响应照常返回，违规项记录到上下文供请求日志使用
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/project-001/env-001/orders/1", bytes.NewReader(nil)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []models.ContractViolation{{Location: "response", Name: "$.qty", Message: "required property is missing"}}, violations)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// fallbackConfigTTL 回退策略缓存有效期
+const fallbackConfigTTL = 2 * time.Second
+
+// fallbackEntry 回退策略缓存项
+type fallbackEntry struct {
+	config    *models.FallbackConfig
+	expiresAt time.Time
+}
+
+// FallbackService 按环境提供未匹配规则时的回退策略
+type FallbackService struct {
+	envRepo repository.EnvironmentRepository
+
+	mu      sync.RWMutex
+	entries map[string]fallbackEntry
+}
+
+// NewFallbackService 创建回退策略服务
+func NewFallbackService(envRepo repository.EnvironmentRepository) *FallbackService {
+	return &FallbackService{
+		envRepo: envRepo,
+		entries: make(map[string]fallbackEntry),
+	}
+}
+
+// Fallback 获取环境的回退策略，未配置或加载失败时返回 nil
+func (s *FallbackService) Fallback(ctx context.Context, environmentID string) *models.FallbackConfig {
+	s.mu.RLock()
+	entry, exists := s.entries[environmentID]
+	s.mu.RUnlock()
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.config
+	}
+
+	var config *models.FallbackConfig
+	env, err := s.envRepo.FindByID(ctx, environmentID)
+	if err != nil {
+		logger.Warn("failed to load fallback config",
+			zap.String("environment_id", environmentID),
+			zap.Error(err))
+	} else if env != nil && env.Fallback != nil && env.Fallback.Mode != "" {
+		config = env.Fallback
+	}
+
+	s.mu.Lock()
+	s.entries[environmentID] = fallbackEntry{config: config, expiresAt: time.Now().Add(fallbackConfigTTL)}
+	s.mu.Unlock()
+
+	return config
+}
+
+// EnvironmentChanged 环境变更后清除缓存（实现 repository.EnvironmentChangeListener）
+func (s *FallbackService) EnvironmentChanged(ctx context.Context, environmentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if environmentID == "" {
+		s.entries = make(map[string]fallbackEntry)
+		return
+	}
+	delete(s.entries, environmentID)
+}
+
+// fallbackRule 将 response、proxy 模式的回退策略转换为规则，由 MockExecutor 生成响应
+func fallbackRule(projectID, environmentID string, config *models.FallbackConfig) *models.Rule {
+	rule := &models.Rule{
+		ProjectID:     projectID,
+		EnvironmentID: environmentID,
+		Protocol:      models.ProtocolHTTP,
+		Response:      models.Response{Type: models.ResponseTypeStatic, Content: config.Response},
+	}
+	if config.Mode == models.FallbackProxy {
+		rule.Response = models.Response{Type: models.ResponseTypeProxy, Content: config.Proxy}
+	}
+	return rule
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/executor"
+	"github.com/gomockserver/mockserver/internal/middleware"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// startFallbackMockServer 启动只匹配 /v1/charges 的 Mock 服务，环境 e1 使用 fallback 回退策略，返回服务地址与请求日志
+func startFallbackMockServer(t *testing.T, fallback *models.FallbackConfig) (string, func() []*models.RequestLog) {
+	rule := &models.Rule{
+		ID:       "charges",
+		Protocol: models.ProtocolHTTP,
+		Response: models.Response{Type: models.ResponseTypeStatic, Content: map[string]interface{}{
+			"status_code":  200,
+			"content_type": "JSON",
+			"body":         map[string]interface{}{"mocked": true},
+		}},
+	}
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, mock.MatchedBy(func(request *adapter.Request) bool {
+		return request.Path == "/v1/charges"
+	}), "p1", "e1").Return(rule, nil)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(nil, nil)
+
+	envRepo := new(MockImportEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", ProjectID: "p1", Fallback: fallback}, nil)
+
+	logRepo := new(MockRequestLogRepositoryForCleanup)
+	var mu sync.Mutex
+	var logs []*models.RequestLog
+	logRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		logs = append(logs, args.Get(1).(*models.RequestLog))
+	}).Return(nil)
+	requestLogger := middleware.NewRequestLoggerMiddleware(logRepo)
+
+	service := NewMockService(matchEngine, executor.NewMockExecutor())
+	service.SetRequestLogger(requestLogger)
+	service.SetFallbackProvider(NewFallbackService(envRepo))
+	server := httptest.NewServer(newMockRouter(service).Handler())
+	t.Cleanup(server.Close)
+
+	return server.URL, func() []*models.RequestLog {
+		require.NoError(t, requestLogger.Flush(context.Background()))
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*models.RequestLog(nil), logs...)
+	}
+}
+
+// TestMockServer_FallbackProxy 测试未匹配的请求经代理修改器转发到上游，并在请求日志中标记
+func TestMockServer_FallbackProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "real")
+		io.WriteString(w, "upstream "+r.URL.RequestURI()+" "+r.Header.Get("X-Env"))
+	}))
+	t.Cleanup(upstream.Close)
+
+	addr, requestLogs := startFallbackMockServer(t, &models.FallbackConfig{
+		Mode: models.FallbackProxy,
+		Proxy: map[string]interface{}{
+			"target_url":      upstream.URL,
+			"modify_request":  map[string]interface{}{"headers": map[string]interface{}{"X-Env": "mock"}},
+			"modify_response": map[string]interface{}{"headers": map[string]interface{}{"X-Fallback": "proxy"}},
+		},
+	})
+
+	resp, body := proxyGet(t, http.DefaultClient, addr+"/p1/e1/v1/charges")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"mocked":true}`, body)
+
+	resp, body = proxyGet(t, http.DefaultClient, addr+"/p1/e1/v1/customers?limit=2")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "real", resp.Header.Get("X-Upstream"))
+	assert.Equal(t, "proxy", resp.Header.Get("X-Fallback"))
+	assert.Equal(t, "upstream /v1/customers?limit=2 mock", body)
+
+	logs := requestLogs()
+	require.Len(t, logs, 2)
+	assert.Equal(t, "charges", logs[0].RuleID)
+	assert.Empty(t, logs[0].Fallback)
+	assert.Empty(t, logs[1].RuleID)
+	assert.Equal(t, models.FallbackProxy, logs[1].Fallback)
+}
+
+// TestMockServer_FallbackResponse 测试未匹配的请求返回自定义响应
+func TestMockServer_FallbackResponse(t *testing.T) {
+	addr, requestLogs := startFallbackMockServer(t, &models.FallbackConfig{
+		Mode: models.FallbackResponse,
+		Response: map[string]interface{}{
+			"status_code":  501,
+			"content_type": "JSON",
+			"body":         map[string]interface{}{"error": "not mocked yet"},
+		},
+	})
+
+	resp, body := proxyGet(t, http.DefaultClient, addr+"/p1/e1/v1/customers")
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"not mocked yet"}`, body)
+
+	logs := requestLogs()
+	require.Len(t, logs, 1)
+	assert.Equal(t, models.FallbackResponse, logs[0].Fallback)
+}
+
+// TestMockServer_FallbackNotFound 测试未配置回退策略或 not_found 模式返回默认 404
+func TestMockServer_FallbackNotFound(t *testing.T) {
+	for _, fallback := range []*models.FallbackConfig{nil, {Mode: models.FallbackNotFound}} {
+		addr, requestLogs := startFallbackMockServer(t, fallback)
+
+		resp, _ := proxyGet(t, http.DefaultClient, addr+"/p1/e1/v1/customers")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		logs := requestLogs()
+		require.Len(t, logs, 1)
+		assert.Empty(t, logs[0].Fallback)
+	}
+}
+
+// TestMockServer_FallbackProxyUnavailable 测试上游不可用时返回 502
+func TestMockServer_FallbackProxyUnavailable(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+
+	addr, _ := startFallbackMockServer(t, &models.FallbackConfig{
+		Mode:  models.FallbackProxy,
+		Proxy: map[string]interface{}{"target_url": upstream.URL},
+	})
+
+	resp, _ := proxyGet(t, http.DefaultClient, addr+"/p1/e1/v1/customers")
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+// TestFallbackService_EnvironmentChanged 测试环境变更后重新加载回退策略
+func TestFallbackService_EnvironmentChanged(t *testing.T) {
+	envRepo := new(MockImportEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1"}, nil).Once()
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", Fallback: &models.FallbackConfig{Mode: models.FallbackProxy}}, nil).Once()
+	fallbacks := NewFallbackService(envRepo)
+
+	assert.Nil(t, fallbacks.Fallback(context.Background(), "e1"))
+	assert.Nil(t, fallbacks.Fallback(context.Background(), "e1"))
+
+	fallbacks.EnvironmentChanged(context.Background(), "e1")
+	config := fallbacks.Fallback(context.Background(), "e1")
+	require.NotNil(t, config)
+	assert.Equal(t, models.FallbackProxy, config.Mode)
+	envRepo.AssertExpectations(t)
+}
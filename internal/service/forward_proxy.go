@@ -0,0 +1,276 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/executor"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// proxyDialTimeout CONNECT 隧道连接上游的超时时间
+const proxyDialTimeout = 10 * time.Second
+
+// proxyHopHeaders 转发前移除的代理专用请求头
+var proxyHopHeaders = []string{"Proxy-Connection", "Proxy-Authorization", "Proxy-Authenticate"}
+
+// ProxyHost 正向代理拦截的主机
+type ProxyHost struct {
+	Host          string // 主机名，不含端口，支持 *.example.com 通配符
+	ProjectID     string
+	EnvironmentID string
+}
+
+// upstreamContextKey 请求上下文中正向代理的原始主机地址
+type upstreamContextKey struct{}
+
+// ForwardProxy HTTP(S) 正向代理
+//
+// 拦截主机的请求改写为 /:projectID/:environmentID/*path 后交给 Mock 路由处理，
+// 未匹配规则时 MockService 通过 Passthrough 转发到原始主机；HTTPS 请求通过 CONNECT
+// 由根 CA 签发证书解密（MITM）。其他主机的 HTTP 请求原样转发，CONNECT 请求建立透明隧道。
+type ForwardProxy struct {
+	hosts   []ProxyHost
+	certs   *TLSService
+	proxy   *executor.ProxyExecutor
+	forward *httputil.ReverseProxy
+}
+
+// NewForwardProxy 创建正向代理，certs 为空时不解密 HTTPS 请求
+func NewForwardProxy(hosts []ProxyHost, certs *TLSService) *ForwardProxy {
+	normalized := make([]ProxyHost, 0, len(hosts))
+	for _, host := range hosts {
+		host.Host = normalizeHostname(host.Host)
+		normalized = append(normalized, host)
+	}
+	return &ForwardProxy{
+		hosts: normalized,
+		certs: certs,
+		proxy: executor.NewProxyExecutor(),
+		forward: &httputil.ReverseProxy{
+			// 绝对形式的请求 URL 即为上游地址
+			Rewrite: func(r *httputil.ProxyRequest) {},
+		},
+	}
+}
+
+// Handler 包装 Mock 路由，CONNECT 与绝对 URL 的请求按正向代理处理，其他请求交给 next
+func (p *ForwardProxy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodConnect:
+			p.handleConnect(w, r, next)
+		case r.URL.IsAbs():
+			p.handleHTTP(w, r, next)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Passthrough 将未匹配规则的请求转发到正向代理的原始主机
+func (p *ForwardProxy) Passthrough(request *adapter.Request, upstream string) (*adapter.Response, error) {
+	return p.proxy.Execute(request, &executor.ProxyConfig{TargetURL: upstream})
+}
+
+// match 查找拦截主机的配置，host 可包含端口
+func (p *ForwardProxy) match(host string) *ProxyHost {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = normalizeHostname(host)
+	for i := range p.hosts {
+		if wildcardMatch(p.hosts[i].Host, host) {
+			return &p.hosts[i]
+		}
+	}
+	return nil
+}
+
+// handleHTTP 处理明文 HTTP 代理请求
+func (p *ForwardProxy) handleHTTP(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	target := p.match(r.URL.Host)
+	if target == nil {
+		p.forward.ServeHTTP(w, r)
+		return
+	}
+	p.dispatch(w, r, next, target, r.URL.Scheme+"://"+r.URL.Host)
+}
+
+// dispatch 将拦截主机的请求改写为 Mock 路由路径，并在上下文中记录原始主机地址
+func (p *ForwardProxy) dispatch(w http.ResponseWriter, r *http.Request, next http.Handler, target *ProxyHost, upstream string) {
+	for _, header := range proxyHopHeaders {
+		r.Header.Del(header)
+	}
+	if r.Host == "" {
+		r.Host = r.URL.Host
+	}
+
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	r.URL.Scheme = ""
+	r.URL.Host = ""
+	r.URL.Path = "/" + target.ProjectID + "/" + target.EnvironmentID + path
+	r.URL.RawPath = ""
+	r.RequestURI = r.URL.RequestURI()
+
+	ctx := context.WithValue(r.Context(), upstreamContextKey{}, upstream)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// handleConnect 处理 CONNECT 请求：拦截主机解密后交给 Mock 路由，其他主机建立透明隧道
+func (p *ForwardProxy) handleConnect(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	target := p.match(r.Host)
+	if target != nil && p.certs == nil {
+		logger.Warn("https interception requires tls certificates, tunneling instead", zap.String("host", r.Host))
+		target = nil
+	}
+
+	var upstream net.Conn
+	if target == nil {
+		var err error
+		upstream, err = net.DialTimeout("tcp", r.Host, proxyDialTimeout)
+		if err != nil {
+			logger.Warn("failed to connect proxy upstream", zap.String("host", r.Host), zap.Error(err))
+			http.Error(w, "Failed to connect upstream", http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "CONNECT requires HTTP/1.1", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		logger.Error("failed to hijack connect request", zap.Error(err))
+		return
+	}
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		conn.Close()
+		return
+	}
+	client := &bufferedConn{Conn: conn, reader: buf.Reader}
+
+	if target == nil {
+		tunnel(client, upstream)
+		return
+	}
+	// 连接由 http.Server 关闭，或由劫持连接的故障注入关闭
+	p.intercept(client, r.Host, next, target)
+}
+
+// intercept 以根 CA 签发的证书终止 TLS，在连接上处理解密后的 HTTP/1.1 请求
+func (p *ForwardProxy) intercept(conn net.Conn, host string, next http.Handler, target *ProxyHost) {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	tlsConn := tls.Server(conn, &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return p.certs.certificateFor(hello.ServerName)
+			}
+			return p.certs.certificateFor(hostname)
+		},
+	})
+
+	upstream := "https://" + host
+	listener := newSingleConnListener(tlsConn)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p.dispatch(w, r, next, target, upstream)
+		}),
+		ReadHeaderTimeout: proxyDialTimeout,
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateClosed || state == http.StateHijacked {
+				listener.Close()
+			}
+		},
+	}
+	server.Serve(listener)
+}
+
+// tunnel 在客户端与上游之间双向复制数据，任一方向结束后关闭两端
+func tunnel(client, upstream net.Conn) {
+	var once sync.Once
+	closeBoth := func() {
+		client.Close()
+		upstream.Close()
+	}
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, client)
+		once.Do(closeBoth)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream)
+		once.Do(closeBoth)
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+}
+
+// upstreamFromContext 获取正向代理请求的原始主机地址，非代理请求返回空
+func upstreamFromContext(ctx context.Context) string {
+	upstream, _ := ctx.Value(upstreamContextKey{}).(string)
+	return upstream
+}
+
+// bufferedConn 先读取劫持时已缓冲的数据
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// singleConnListener 只返回一个连接的监听器，Close 后 Accept 返回错误
+type singleConnListener struct {
+	conn     net.Conn
+	accepted sync.Once
+	closed   chan struct{}
+	close    sync.Once
+}
+
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	return &singleConnListener{conn: conn, closed: make(chan struct{})}
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.accepted.Do(func() { conn = l.conn })
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *singleConnListener) Close() error {
+	l.close.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
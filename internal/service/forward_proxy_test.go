@@ -0,0 +1,134 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newForwardProxyMockService 创建只匹配 /v1/charges 的 Mock 服务
+func newForwardProxyMockService() *MockService {
+	rule := &models.Rule{ID: "charges", Protocol: models.ProtocolHTTP}
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, mock.MatchedBy(func(request *adapter.Request) bool {
+		return request.Path == "/v1/charges"
+	}), "p1", "e1").Return(rule, nil)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(nil, nil)
+	mockExecutor := new(MockMockExecutor)
+	mockExecutor.On("Execute", mock.Anything, rule).Return(&adapter.Response{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       []byte(`{"mocked":true}`),
+	}, nil)
+	return NewMockService(matchEngine, mockExecutor)
+}
+
+// startForwardProxy 启动正向代理，返回代理地址
+func startForwardProxy(t *testing.T, hosts []ProxyHost, certs *TLSService) *url.URL {
+	service := newForwardProxyMockService()
+	service.SetForwardProxy(NewForwardProxy(hosts, certs))
+	server := httptest.NewServer(service.forwardProxy.Handler(newMockRouter(service).Handler()))
+	t.Cleanup(server.Close)
+	proxyURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return proxyURL
+}
+
+// newUpstream 启动记录请求路径的上游服务
+func newUpstream(t *testing.T, tlsEnabled bool) *httptest.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "real")
+		io.WriteString(w, "upstream "+r.Method+" "+r.URL.RequestURI())
+	})
+	var server *httptest.Server
+	if tlsEnabled {
+		server = httptest.NewTLSServer(handler)
+	} else {
+		server = httptest.NewServer(handler)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func proxyGet(t *testing.T, client *http.Client, target string) (*http.Response, string) {
+	resp, err := client.Get(target)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+// TestForwardProxy_HTTP 测试明文 HTTP 代理：拦截主机按规则应答，未匹配与其他主机转发到上游
+func TestForwardProxy_HTTP(t *testing.T) {
+	upstream := newUpstream(t, false)
+	upstreamURL, _ := url.Parse(upstream.URL)
+
+	proxyURL := startForwardProxy(t, []ProxyHost{{Host: "127.0.0.1", ProjectID: "p1", EnvironmentID: "e1"}}, nil)
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, body := proxyGet(t, client, upstream.URL+"/v1/charges")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"mocked":true}`, body)
+
+	resp, body = proxyGet(t, client, upstream.URL+"/v1/customers?limit=2")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "real", resp.Header.Get("X-Upstream"))
+	assert.Equal(t, "upstream GET /v1/customers?limit=2", body)
+
+	// 未配置的主机原样转发
+	resp, body = proxyGet(t, client, "http://localhost:"+upstreamURL.Port()+"/v1/charges")
+	assert.Equal(t, "real", resp.Header.Get("X-Upstream"))
+	assert.Equal(t, "upstream GET /v1/charges", body)
+}
+
+// TestForwardProxy_ConnectIntercept 测试 HTTPS 拦截主机通过 CONNECT 解密后按规则应答
+func TestForwardProxy_ConnectIntercept(t *testing.T) {
+	certs, err := NewTLSService("", nil, newMemoryTLSCertificateRepository())
+	require.NoError(t, err)
+	upstream := newUpstream(t, true)
+
+	proxyURL := startForwardProxy(t, []ProxyHost{{Host: "api.example.test", ProjectID: "p1", EnvironmentID: "e1"}}, certs)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certs.CACertificatePEM()))
+	roots.AddCert(upstream.Certificate())
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+
+	// 拦截主机不需要真实存在，证书由根 CA 签发
+	for i := 0; i < 2; i++ {
+		resp, body := proxyGet(t, client, "https://api.example.test/v1/charges")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"mocked":true}`, body)
+		require.NotNil(t, resp.TLS)
+		assert.Equal(t, "api.example.test", resp.TLS.PeerCertificates[0].DNSNames[0])
+	}
+
+	// 未配置的主机建立透明隧道，客户端直接与上游握手
+	resp, body := proxyGet(t, client, upstream.URL+"/v1/charges")
+	assert.Equal(t, "real", resp.Header.Get("X-Upstream"))
+	assert.Equal(t, "upstream GET /v1/charges", body)
+	assert.Equal(t, upstream.Certificate().Raw, resp.TLS.PeerCertificates[0].Raw)
+}
+
+// TestForwardProxy_NonProxyRequests 测试普通 Mock 请求不受正向代理影响
+func TestForwardProxy_NonProxyRequests(t *testing.T) {
+	proxyURL := startForwardProxy(t, nil, nil)
+
+	resp, body := proxyGet(t, http.DefaultClient, proxyURL.String()+"/p1/e1/v1/charges")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"mocked":true}`, body)
+}
@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/middleware"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// gRPC 请求选择项目和环境的元数据
+const (
+	GRPCProjectMetadata     = "x-mock-project"
+	GRPCEnvironmentMetadata = "x-mock-environment"
+)
+
+// defaultProtoRegistryTTL 项目描述符缓存时间，其他实例上传的文件最迟在此时间后生效
+const defaultProtoRegistryTTL = 30 * time.Second
+
+// ProtoSource protobuf 描述文件来源（repository.ProtoRepository）
+type ProtoSource interface {
+	FindByProject(ctx context.Context, projectID string) ([]*models.ProtoFile, error)
+}
+
+// cachedRegistry 已编译的项目描述符
+type cachedRegistry struct {
+	registry *adapter.ProtoRegistry
+	loadedAt time.Time
+}
+
+// GRPCService gRPC Mock 服务
+//
+// 所有调用由未知服务处理器统一处理：按请求元数据 x-mock-project / x-mock-environment
+// （缺省为配置的默认项目和环境）选择项目，用项目上传的描述文件解析请求消息，
+// 再按规则匹配并生成响应。服务反射同样按项目返回描述符。
+type GRPCService struct {
+	grpcAdapter   *adapter.GRPCAdapter
+	matchEngine   MatchEngineInterface
+	mockExecutor  MockExecutorInterface
+	protos        ProtoSource
+	requestLogger *middleware.RequestLoggerMiddleware
+
+	defaultProject     string
+	defaultEnvironment string
+
+	registryTTL  time.Duration
+	registriesMu sync.Mutex
+	registries   map[string]*cachedRegistry
+}
+
+// NewGRPCService 创建 gRPC Mock 服务
+func NewGRPCService(matchEngine MatchEngineInterface, mockExecutor MockExecutorInterface, protos ProtoSource) *GRPCService {
+	return &GRPCService{
+		grpcAdapter:  adapter.NewGRPCAdapter(),
+		matchEngine:  matchEngine,
+		mockExecutor: mockExecutor,
+		protos:       protos,
+		registryTTL:  defaultProtoRegistryTTL,
+		registries:   make(map[string]*cachedRegistry),
+	}
+}
+
+// SetRequestLogger 设置请求日志中间件，每次调用记录一条请求日志
+func (s *GRPCService) SetRequestLogger(requestLogger *middleware.RequestLoggerMiddleware) {
+	s.requestLogger = requestLogger
+}
+
+// SetDefaultTarget 设置请求未携带项目、环境元数据时使用的项目和环境
+func (s *GRPCService) SetDefaultTarget(projectID, environmentID string) {
+	s.defaultProject = projectID
+	s.defaultEnvironment = environmentID
+}
+
+// ProtosChanged 项目描述文件变更后清除已编译的描述符
+func (s *GRPCService) ProtosChanged(projectID string) {
+	s.registriesMu.Lock()
+	defer s.registriesMu.Unlock()
+	delete(s.registries, projectID)
+}
+
+// Registry 获取项目已编译的描述符，过期或不存在时重新加载
+func (s *GRPCService) Registry(ctx context.Context, projectID string) (*adapter.ProtoRegistry, error) {
+	s.registriesMu.Lock()
+	cached, ok := s.registries[projectID]
+	s.registriesMu.Unlock()
+	if ok && time.Since(cached.loadedAt) < s.registryTTL {
+		return cached.registry, nil
+	}
+
+	files, err := s.protos.FindByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := adapter.CompileProtoFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	s.registriesMu.Lock()
+	s.registries[projectID] = &cachedRegistry{registry: registry, loadedAt: time.Now()}
+	s.registriesMu.Unlock()
+	return registry, nil
+}
+
+// HandleStream 处理所有 gRPC 调用（grpc.UnknownServiceHandler）
+func (s *GRPCService) HandleStream(srv interface{}, stream grpc.ServerStream) error {
+	fullMethod, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "method name not found in stream")
+	}
+	ctx := stream.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+	projectID := metadataValue(md, GRPCProjectMetadata, s.defaultProject)
+	environmentID := metadataValue(md, GRPCEnvironmentMetadata, s.defaultEnvironment)
+	if projectID == "" {
+		return status.Errorf(codes.InvalidArgument, "%s metadata is required", GRPCProjectMetadata)
+	}
+
+	registry, err := s.Registry(ctx, projectID)
+	if err != nil {
+		logger.Error("failed to load protos", zap.String("project_id", projectID), zap.Error(err))
+		return status.Errorf(codes.Internal, "failed to load protos for project %s: %v", projectID, err)
+	}
+
+	serviceName, _, _ := adapter.SplitGRPCMethod(fullMethod)
+	switch serviceName {
+	case reflectionv1.ServerReflection_ServiceDesc.ServiceName:
+		return reflectionv1.ServerReflection_ServiceDesc.Streams[0].Handler(reflection.NewServerV1(s.reflectionOptions(registry)), stream)
+	case reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName:
+		return reflectionv1alpha.ServerReflection_ServiceDesc.Streams[0].Handler(reflection.NewServer(s.reflectionOptions(registry)), stream)
+	}
+
+	method, err := registry.FindMethod(fullMethod)
+	if err != nil {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	if environmentID == "" {
+		return status.Errorf(codes.InvalidArgument, "%s metadata is required", GRPCEnvironmentMetadata)
+	}
+
+	call := &grpcCallContext{
+		fullMethod:    fullMethod,
+		method:        method,
+		metadata:      md,
+		registry:      registry,
+		projectID:     projectID,
+		environmentID: environmentID,
+	}
+	if method.IsStreamingClient() {
+		return s.handleClientStream(stream, call)
+	}
+	return s.handleUnary(stream, call)
+}
+
+// grpcCallContext 一次 gRPC 调用的上下文
+type grpcCallContext struct {
+	fullMethod    string
+	method        protoreflect.MethodDescriptor
+	metadata      metadata.MD
+	registry      *adapter.ProtoRegistry
+	projectID     string
+	environmentID string
+}
+
+// handleUnary 处理一元调用和服务端流：接收一条请求消息，按匹配规则的响应依次发送响应消息
+func (s *GRPCService) handleUnary(stream grpc.ServerStream, call *grpcCallContext) error {
+	exchange := newGRPCExchange()
+
+	request, err := s.recv(stream, call, exchange)
+	if err != nil {
+		return err
+	}
+
+	reply, err := s.reply(stream.Context(), request, call, exchange)
+	if err == nil && reply == nil {
+		err = status.Errorf(codes.Unimplemented, "no mock rule matched %s", call.fullMethod)
+	}
+	if err == nil {
+		err = s.send(stream, call, reply, exchange)
+	}
+	if err == nil && reply.Code != codes.OK {
+		err = status.Error(reply.Code, reply.Message)
+	}
+
+	s.log(call, exchange, err)
+	return err
+}
+
+// handleClientStream 处理客户端流和双向流
+//
+// 与 WebSocketAdapter.readPump 处理消息帧相同，每条请求消息分别匹配规则：
+// 双向流立即发送匹配规则的响应消息，客户端流在请求结束后发送最后一条匹配规则的响应。
+// 未匹配的消息被忽略，匹配到非 OK 响应时发送其响应消息后立即以该状态结束流。
+func (s *GRPCService) handleClientStream(stream grpc.ServerStream, call *grpcCallContext) error {
+	exchange := newGRPCExchange()
+	bidi := call.method.IsStreamingServer()
+
+	var last *adapter.GRPCReply
+	err := func() error {
+		for {
+			request, err := s.recv(stream, call, exchange)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			reply, err := s.reply(stream.Context(), request, call, exchange)
+			if err != nil {
+				return err
+			}
+			if reply == nil {
+				exchange.unmatched++
+				continue
+			}
+
+			last = reply
+			if bidi || reply.Code != codes.OK {
+				if err := s.send(stream, call, reply, exchange); err != nil {
+					return err
+				}
+			}
+			if reply.Code != codes.OK {
+				return status.Error(reply.Code, reply.Message)
+			}
+		}
+	}()
+
+	if err == nil && !bidi {
+		if last == nil {
+			err = status.Errorf(codes.Unimplemented, "no mock rule matched %s", call.fullMethod)
+		} else {
+			err = s.send(stream, call, last, exchange)
+		}
+	}
+
+	s.log(call, exchange, err)
+	return err
+}
+
+// recv 接收一条请求消息并转换为统一请求模型，流结束时返回 io.EOF
+func (s *GRPCService) recv(stream grpc.ServerStream, call *grpcCallContext, exchange *grpcExchange) (*adapter.Request, error) {
+	in := dynamicpb.NewMessage(call.method.Input())
+	if err := stream.RecvMsg(in); err != nil {
+		return nil, err
+	}
+
+	grpcCall := &adapter.GRPCCall{
+		FullMethod: call.fullMethod,
+		Message:    in,
+		Metadata:   call.metadata,
+		Registry:   call.registry,
+	}
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		grpcCall.Peer = p.Addr
+	}
+	request, err := s.grpcAdapter.Parse(grpcCall)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse request: %v", err)
+	}
+	request.Metadata["grpc_stream_index"] = exchange.received
+
+	exchange.receive(request)
+	return request, nil
+}
+
+// reply 匹配规则并生成响应，未匹配规则时返回 nil
+func (s *GRPCService) reply(ctx context.Context, request *adapter.Request, call *grpcCallContext, exchange *grpcExchange) (*adapter.GRPCReply, error) {
+	rule, err := s.matchEngine.Match(ctx, request, call.projectID, call.environmentID)
+	if err != nil {
+		logger.Error("failed to match rule", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to match rule")
+	}
+	if rule == nil {
+		logger.Info("no rule matched",
+			zap.String("method", call.fullMethod),
+			zap.String("project_id", call.projectID),
+			zap.String("environment_id", call.environmentID))
+		return nil, nil
+	}
+	exchange.ruleID = rule.ID
+
+	response, err := s.mockExecutor.Execute(request, rule)
+	if err != nil {
+		logger.Error("failed to execute mock", zap.String("rule_id", rule.ID), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to execute mock: %v", err)
+	}
+	built, err := s.grpcAdapter.Build(response)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid mock response: %v", err)
+	}
+	return built.(*adapter.GRPCReply), nil
+}
+
+// send 发送响应头、响应消息和尾部元数据，不返回响应中的状态
+//
+// 一元调用只发送第一条响应消息；非 OK 的一元响应不发送响应消息。
+// 每条消息发送前等待其延迟，等待期间客户端取消调用时返回。
+func (s *GRPCService) send(stream grpc.ServerStream, call *grpcCallContext, reply *adapter.GRPCReply, exchange *grpcExchange) error {
+	messages := reply.Messages
+	if !call.method.IsStreamingServer() {
+		if reply.Code != codes.OK {
+			messages = nil
+		} else if len(messages) > 1 {
+			messages = messages[:1]
+		} else if len(messages) == 0 {
+			messages = []adapter.GRPCStreamMessage{{}}
+		}
+	}
+
+	// 响应头只能发送一次，流中后续响应的响应头被忽略
+	if len(reply.Header) > 0 && !exchange.headerSent {
+		if err := stream.SetHeader(reply.Header); err != nil {
+			return err
+		}
+		exchange.header = metadata.Join(exchange.header, reply.Header)
+	}
+	if len(messages) == 0 && len(reply.Header) > 0 && !exchange.headerSent {
+		if err := stream.SendHeader(nil); err != nil {
+			return err
+		}
+		exchange.headerSent = true
+	}
+
+	for _, message := range messages {
+		if message.Delay > 0 {
+			timer := time.NewTimer(message.Delay)
+			select {
+			case <-timer.C:
+			case <-stream.Context().Done():
+				timer.Stop()
+				return status.FromContextError(stream.Context().Err()).Err()
+			}
+		}
+		out, err := call.registry.UnmarshalMessage(call.method.Output(), message.Body)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.SendMsg(out); err != nil {
+			return err
+		}
+		exchange.headerSent = true
+		exchange.send(message.Body)
+	}
+
+	stream.SetTrailer(reply.Trailer)
+	exchange.trailer = metadata.Join(exchange.trailer, reply.Trailer)
+	return nil
+}
+
+// maxLoggedStreamMessages 请求日志中每个方向最多记录的消息数，超出部分只计数
+const maxLoggedStreamMessages = 100
+
+// grpcExchange 一次调用（一元调用或一个流）收发的消息，结束后记录为一条请求日志
+type grpcExchange struct {
+	startTime  time.Time
+	requestID  string
+	sourceIP   string
+	ruleID     string
+	received   int
+	sent       int
+	unmatched  int
+	requests   []interface{}
+	responses  []interface{}
+	header     metadata.MD
+	trailer    metadata.MD
+	headerSent bool
+}
+
+func newGRPCExchange() *grpcExchange {
+	return &grpcExchange{
+		startTime: time.Now(),
+		requestID: uuid.New().String(),
+	}
+}
+
+// receive 记录收到的请求消息，流的请求 ID 为第一条消息的请求 ID
+func (e *grpcExchange) receive(request *adapter.Request) {
+	if e.received == 0 {
+		e.requestID = request.ID
+		e.sourceIP = request.SourceIP
+	}
+	e.received++
+	if len(e.requests) < maxLoggedStreamMessages {
+		e.requests = append(e.requests, jsonValue(request.Body))
+	}
+}
+
+// send 记录已发送的响应消息
+func (e *grpcExchange) send(body []byte) {
+	e.sent++
+	if len(e.responses) < maxLoggedStreamMessages {
+		e.responses = append(e.responses, jsonValue(body))
+	}
+}
+
+// log 记录请求日志，StatusCode 为调用结束时的 gRPC 状态码
+//
+// 一元调用记录请求、响应消息 body；流式调用记录 messages 列表和 message_count。
+func (s *GRPCService) log(call *grpcCallContext, exchange *grpcExchange, err error) {
+	if s.requestLogger == nil {
+		return
+	}
+	st := status.Convert(err)
+
+	requestData := map[string]interface{}{
+		"path":    call.fullMethod,
+		"headers": middleware.SanitizeHeaders(call.metadata),
+	}
+	responseData := map[string]interface{}{
+		"status_code": int(st.Code()),
+		"status":      st.Code().String(),
+	}
+	if st.Message() != "" {
+		responseData["message"] = st.Message()
+	}
+	if len(exchange.header) > 0 {
+		responseData["headers"] = middleware.SanitizeHeaders(exchange.header)
+	}
+	if len(exchange.trailer) > 0 {
+		responseData["trailers"] = middleware.SanitizeHeaders(exchange.trailer)
+	}
+
+	if call.method.IsStreamingClient() {
+		requestData["messages"] = exchange.requests
+		requestData["message_count"] = exchange.received
+		requestData["unmatched_count"] = exchange.unmatched
+	} else if len(exchange.requests) > 0 {
+		requestData["body"] = exchange.requests[0]
+	}
+	if call.method.IsStreamingServer() {
+		responseData["messages"] = exchange.responses
+		responseData["message_count"] = exchange.sent
+	} else if len(exchange.responses) > 0 {
+		responseData["body"] = exchange.responses[0]
+	}
+
+	s.requestLogger.Log(&models.RequestLog{
+		RequestID:     exchange.requestID,
+		ProjectID:     call.projectID,
+		EnvironmentID: call.environmentID,
+		RuleID:        exchange.ruleID,
+		Protocol:      models.ProtocolGRPC,
+		Method:        "POST",
+		Path:          call.fullMethod,
+		StatusCode:    int(st.Code()),
+		Duration:      time.Since(exchange.startTime).Milliseconds(),
+		SourceIP:      exchange.sourceIP,
+		Timestamp:     exchange.startTime,
+		Request:       requestData,
+		Response:      responseData,
+	})
+}
+
+// reflectionOptions 按项目描述符构建服务反射配置
+func (s *GRPCService) reflectionOptions(registry *adapter.ProtoRegistry) reflection.ServerOptions {
+	return reflection.ServerOptions{
+		Services:           reflectionServices(registry.Services()),
+		DescriptorResolver: registry.Files(),
+		ExtensionResolver:  registry.Types(),
+	}
+}
+
+// reflectionServices 服务反射列出的服务：项目上传的服务和反射服务本身
+type reflectionServices []string
+
+// GetServiceInfo 实现 reflection.ServiceInfoProvider
+func (r reflectionServices) GetServiceInfo() map[string]grpc.ServiceInfo {
+	info := map[string]grpc.ServiceInfo{
+		reflectionv1.ServerReflection_ServiceDesc.ServiceName:      {},
+		reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName: {},
+	}
+	for _, name := range r {
+		info[name] = grpc.ServiceInfo{}
+	}
+	return info
+}
+
+// jsonValue 解析 JSON 形式的消息用于记录日志，解析失败时返回原始字符串
+func jsonValue(data []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return string(data)
+	}
+	return value
+}
+
+// metadataValue 获取元数据的第一个值，不存在时返回默认值
+func metadataValue(md metadata.MD, key, fallback string) string {
+	if values := md.Get(key); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	return fallback
+}
+
+// NewGRPCServer 创建 gRPC Mock 服务器，所有方法由 service 处理
+func NewGRPCServer(service *GRPCService) *grpc.Server {
+	return grpc.NewServer(grpc.UnknownServiceHandler(service.HandleStream))
+}
+
+// StartGRPCServer 启动 gRPC Mock 服务器
+func StartGRPCServer(addr string, service *GRPCService) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	logger.Info("starting grpc mock server", zap.String("address", addr))
+	return NewGRPCServer(service).Serve(listener)
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/executor"
+	"github.com/gomockserver/mockserver/internal/middleware"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testGreeterProto = `syntax = "proto3";
+package acme.greeter.v1;
+
+service Greeter {
+  rpc SayHello(HelloRequest) returns (HelloReply);
+  rpc Chat(stream HelloRequest) returns (stream HelloReply);
+  rpc Watch(HelloRequest) returns (stream HelloReply);
+  rpc Collect(stream HelloRequest) returns (HelloReply);
+}
+
+message HelloRequest {
+  string name = 1;
+}
+
+message HelloReply {
+  string message = 1;
+}
+`
+
+// memoryProtoSource 内存描述文件来源
+type memoryProtoSource struct {
+	mu    sync.Mutex
+	files map[string][]*models.ProtoFile
+	loads int
+}
+
+func (s *memoryProtoSource) FindByProject(ctx context.Context, projectID string) ([]*models.ProtoFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	return s.files[projectID], nil
+}
+
+// startGRPCTestServer 在内存连接上启动 gRPC Mock 服务，返回客户端连接
+func startGRPCTestServer(t *testing.T, grpcService *GRPCService) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(grpcService)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func newGreeterService(t *testing.T, matchEngine MatchEngineInterface) (*GRPCService, *memoryProtoSource) {
+	protos := &memoryProtoSource{files: map[string][]*models.ProtoFile{
+		"p1": {{ProjectID: "p1", Name: "acme/greeter/v1/greeter.proto", Kind: models.ProtoFileKindSource, Content: []byte(testGreeterProto)}},
+	}}
+	return NewGRPCService(matchEngine, executor.NewMockExecutor(), protos), protos
+}
+
+// greeterMessages 获取 SayHello 的请求、响应消息描述符
+func greeterMessages(t *testing.T, grpcService *GRPCService) (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor) {
+	registry, err := grpcService.Registry(context.Background(), "p1")
+	require.NoError(t, err)
+	method, err := registry.FindMethod("/acme.greeter.v1.Greeter/SayHello")
+	require.NoError(t, err)
+	return method.Input(), method.Output()
+}
+
+func TestGRPCService_UnaryCall(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	rule := &models.Rule{
+		ID:        "r1",
+		Protocol:  models.ProtocolGRPC,
+		MatchType: models.MatchTypeSimple,
+		Response: models.Response{
+			Type: models.ResponseTypeDynamic,
+			Content: map[string]interface{}{
+				"body":     map[string]interface{}{"message": "hello {{.Request.Body.name}}"},
+				"headers":  map[string]interface{}{"x-request-id": "abc"},
+				"trailers": map[string]interface{}{"x-served-by": "mock"},
+			},
+		},
+	}
+	matchEngine.On("Match", mock.Anything, mock.MatchedBy(func(r *adapter.Request) bool {
+		return r.Path == "/acme.greeter.v1.Greeter/SayHello" && string(r.Body) == `{"name":"alice"}` && r.Headers["x-tenant"] == "a"
+	}), "p1", "e1").Return(rule, nil)
+
+	logRepo := new(MockRequestLogRepositoryForCleanup)
+	var logged *models.RequestLog
+	logRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		logged = args.Get(1).(*models.RequestLog)
+	}).Return(nil)
+	requestLogger := middleware.NewRequestLoggerMiddleware(logRepo)
+
+	grpcService, _ := newGreeterService(t, matchEngine)
+	grpcService.SetRequestLogger(requestLogger)
+	grpcService.SetDefaultTarget("p1", "")
+	conn := startGRPCTestServer(t, grpcService)
+	input, output := greeterMessages(t, grpcService)
+
+	in := dynamicpb.NewMessage(input)
+	in.Set(input.Fields().ByName("name"), protoreflect.ValueOfString("alice"))
+	out := dynamicpb.NewMessage(output)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), GRPCEnvironmentMetadata, "e1", "x-tenant", "a")
+	var header, trailer metadata.MD
+	err := conn.Invoke(ctx, "/acme.greeter.v1.Greeter/SayHello", in, out, grpc.Header(&header), grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello alice", out.Get(output.Fields().ByName("message")).String())
+	assert.Equal(t, []string{"abc"}, header.Get("x-request-id"))
+	assert.Equal(t, []string{"mock"}, trailer.Get("x-served-by"))
+
+	require.NoError(t, requestLogger.Flush(context.Background()))
+	require.NotNil(t, logged)
+	assert.Equal(t, models.ProtocolGRPC, logged.Protocol)
+	assert.Equal(t, "r1", logged.RuleID)
+	assert.Equal(t, "p1", logged.ProjectID)
+	assert.Equal(t, "e1", logged.EnvironmentID)
+	assert.Equal(t, "/acme.greeter.v1.Greeter/SayHello", logged.Path)
+	assert.Equal(t, 0, logged.StatusCode)
+	assert.Equal(t, map[string]interface{}{"name": "alice"}, logged.Request["body"])
+}
+
+func TestGRPCService_ErrorStatus(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	rule := &models.Rule{
+		ID:       "r1",
+		Protocol: models.ProtocolGRPC,
+		Response: models.Response{
+			Type: models.ResponseTypeStatic,
+			Content: map[string]interface{}{
+				"code":     "RESOURCE_EXHAUSTED",
+				"message":  "slow down",
+				"trailers": map[string]interface{}{"x-retry-after": "5"},
+			},
+		},
+	}
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(rule, nil).Once()
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(nil, nil)
+
+	grpcService, _ := newGreeterService(t, matchEngine)
+	conn := startGRPCTestServer(t, grpcService)
+	input, output := greeterMessages(t, grpcService)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), GRPCProjectMetadata, "p1", GRPCEnvironmentMetadata, "e1")
+
+	var trailer metadata.MD
+	err := conn.Invoke(ctx, "/acme.greeter.v1.Greeter/SayHello", dynamicpb.NewMessage(input), dynamicpb.NewMessage(output), grpc.Trailer(&trailer))
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Equal(t, "slow down", st.Message())
+	assert.Equal(t, []string{"5"}, trailer.Get("x-retry-after"))
+
+	// 未匹配规则
+	err = conn.Invoke(ctx, "/acme.greeter.v1.Greeter/SayHello", dynamicpb.NewMessage(input), dynamicpb.NewMessage(output))
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// 描述文件中不存在的方法
+	err = conn.Invoke(ctx, "/acme.greeter.v1.Greeter/Missing", dynamicpb.NewMessage(input), dynamicpb.NewMessage(output))
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// 缺少项目元数据且未配置默认项目
+	err = conn.Invoke(context.Background(), "/acme.greeter.v1.Greeter/SayHello", dynamicpb.NewMessage(input), dynamicpb.NewMessage(output))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCService_Reflection(t *testing.T) {
+	grpcService, protos := newGreeterService(t, new(MockMatchEngine))
+	grpcService.SetDefaultTarget("p1", "e1")
+	conn := startGRPCTestServer(t, grpcService)
+
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, "acme.greeter.v1.Greeter")
+	assert.Contains(t, services, "grpc.reflection.v1.ServerReflection")
+
+	require.NoError(t, stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "acme.greeter.v1.Greeter"},
+	}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	files := resp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	require.NotEmpty(t, files)
+	var fd descriptorpb.FileDescriptorProto
+	require.NoError(t, proto.Unmarshal(files[0], &fd))
+	assert.Equal(t, "acme/greeter/v1/greeter.proto", fd.GetName())
+	require.NoError(t, stream.CloseSend())
+
+	// 描述文件变更后重新编译
+	loads := protos.loads
+	grpcService.ProtosChanged("p1")
+	_, err = grpcService.Registry(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, loads+1, protos.loads)
+}
+
+// captureRequestLogs 记录写入的请求日志
+func captureRequestLogs(grpcService *GRPCService) (*middleware.RequestLoggerMiddleware, *[]*models.RequestLog) {
+	logRepo := new(MockRequestLogRepositoryForCleanup)
+	var mu sync.Mutex
+	logs := &[]*models.RequestLog{}
+	logRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		*logs = append(*logs, args.Get(1).(*models.RequestLog))
+	}).Return(nil)
+	requestLogger := middleware.NewRequestLoggerMiddleware(logRepo)
+	grpcService.SetRequestLogger(requestLogger)
+	return requestLogger, logs
+}
+
+// helloRequest 构造 HelloRequest 消息
+func helloRequest(input protoreflect.MessageDescriptor, name string) *dynamicpb.Message {
+	in := dynamicpb.NewMessage(input)
+	in.Set(input.Fields().ByName("name"), protoreflect.ValueOfString(name))
+	return in
+}
+
+// replyMessage 获取 HelloReply 的 message 字段
+func replyMessage(output protoreflect.MessageDescriptor, out *dynamicpb.Message) string {
+	return out.Get(output.Fields().ByName("message")).String()
+}
+
+func grpcRule(id string, content map[string]interface{}) *models.Rule {
+	return &models.Rule{
+		ID:       id,
+		Protocol: models.ProtocolGRPC,
+		Response: models.Response{Type: models.ResponseTypeDynamic, Content: content},
+	}
+}
+
+func bodyName(name string) interface{} {
+	return mock.MatchedBy(func(r *adapter.Request) bool {
+		return string(r.Body) == `{"name":"`+name+`"}`
+	})
+}
+
+func TestGRPCService_ServerStream(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(grpcRule("r1", map[string]interface{}{
+		"stream": []interface{}{
+			map[string]interface{}{"body": map[string]interface{}{"message": "1 {{.Request.Body.name}}"}},
+			map[string]interface{}{"body": map[string]interface{}{"message": "2 {{.Request.Body.name}}"}, "delay": 30},
+			map[string]interface{}{"body": map[string]interface{}{"message": "3 {{.Request.Body.name}}"}},
+		},
+		"code":     "UNAVAILABLE",
+		"message":  "feed interrupted",
+		"headers":  map[string]interface{}{"x-feed": "prices"},
+		"trailers": map[string]interface{}{"x-retry-after": "5"},
+	}), nil)
+
+	grpcService, _ := newGreeterService(t, matchEngine)
+	grpcService.SetDefaultTarget("p1", "e1")
+	requestLogger, logs := captureRequestLogs(grpcService)
+	conn := startGRPCTestServer(t, grpcService)
+	input, output := greeterMessages(t, grpcService)
+
+	stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/acme.greeter.v1.Greeter/Watch")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(helloRequest(input, "alice")))
+	require.NoError(t, stream.CloseSend())
+
+	startTime := time.Now()
+	var messages []string
+	for {
+		out := dynamicpb.NewMessage(output)
+		if err = stream.RecvMsg(out); err != nil {
+			break
+		}
+		messages = append(messages, replyMessage(output, out))
+	}
+	assert.Equal(t, []string{"1 alice", "2 alice", "3 alice"}, messages)
+	assert.GreaterOrEqual(t, time.Since(startTime), 30*time.Millisecond)
+	st := status.Convert(err)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Equal(t, "feed interrupted", st.Message())
+	header, _ := stream.Header()
+	assert.Equal(t, []string{"prices"}, header.Get("x-feed"))
+	assert.Equal(t, []string{"5"}, stream.Trailer().Get("x-retry-after"))
+
+	// 整个流记录为一条请求日志
+	require.NoError(t, requestLogger.Flush(context.Background()))
+	require.Len(t, *logs, 1)
+	logged := (*logs)[0]
+	assert.Equal(t, "r1", logged.RuleID)
+	assert.Equal(t, int(codes.Unavailable), logged.StatusCode)
+	assert.Equal(t, map[string]interface{}{"name": "alice"}, logged.Request["body"])
+	assert.Equal(t, 3, logged.Response["message_count"])
+	assert.Len(t, logged.Response["messages"], 3)
+}
+
+func TestGRPCService_BidiStream(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, bodyName("alice"), "p1", "e1").Return(grpcRule("hello", map[string]interface{}{
+		"body": map[string]interface{}{"message": "hi {{.Request.Body.name}}"},
+	}), nil)
+	matchEngine.On("Match", mock.Anything, bodyName("bob"), "p1", "e1").Return(nil, nil)
+	matchEngine.On("Match", mock.Anything, bodyName("mallory"), "p1", "e1").Return(grpcRule("deny", map[string]interface{}{
+		"code":    "PERMISSION_DENIED",
+		"message": "go away",
+	}), nil)
+
+	grpcService, _ := newGreeterService(t, matchEngine)
+	grpcService.SetDefaultTarget("p1", "e1")
+	requestLogger, logs := captureRequestLogs(grpcService)
+	conn := startGRPCTestServer(t, grpcService)
+	input, output := greeterMessages(t, grpcService)
+
+	stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/acme.greeter.v1.Greeter/Chat")
+	require.NoError(t, err)
+
+	// 每条请求消息分别匹配规则，未匹配的消息被忽略
+	require.NoError(t, stream.SendMsg(helloRequest(input, "alice")))
+	out := dynamicpb.NewMessage(output)
+	require.NoError(t, stream.RecvMsg(out))
+	assert.Equal(t, "hi alice", replyMessage(output, out))
+
+	require.NoError(t, stream.SendMsg(helloRequest(input, "bob")))
+	require.NoError(t, stream.SendMsg(helloRequest(input, "alice")))
+	out = dynamicpb.NewMessage(output)
+	require.NoError(t, stream.RecvMsg(out))
+	assert.Equal(t, "hi alice", replyMessage(output, out))
+
+	// 匹配到非 OK 响应时提前结束流
+	require.NoError(t, stream.SendMsg(helloRequest(input, "mallory")))
+	err = stream.RecvMsg(dynamicpb.NewMessage(output))
+	st := status.Convert(err)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	assert.Equal(t, "go away", st.Message())
+
+	require.NoError(t, requestLogger.Flush(context.Background()))
+	require.Len(t, *logs, 1)
+	logged := (*logs)[0]
+	assert.Equal(t, "deny", logged.RuleID)
+	assert.Equal(t, int(codes.PermissionDenied), logged.StatusCode)
+	assert.Equal(t, 4, logged.Request["message_count"])
+	assert.Equal(t, 1, logged.Request["unmatched_count"])
+	assert.Equal(t, 2, logged.Response["message_count"])
+}
+
+func TestGRPCService_ClientStream(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, bodyName("nobody"), "p1", "e1").Return(nil, nil)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(grpcRule("r1", map[string]interface{}{
+		"body": map[string]interface{}{"message": "last {{.Request.Body.name}}"},
+	}), nil)
+
+	grpcService, _ := newGreeterService(t, matchEngine)
+	grpcService.SetDefaultTarget("p1", "e1")
+	requestLogger, logs := captureRequestLogs(grpcService)
+	conn := startGRPCTestServer(t, grpcService)
+	input, output := greeterMessages(t, grpcService)
+	desc := &grpc.StreamDesc{ClientStreams: true}
+
+	// 请求结束后返回最后一条匹配规则的响应
+	stream, err := conn.NewStream(context.Background(), desc, "/acme.greeter.v1.Greeter/Collect")
+	require.NoError(t, err)
+	for _, name := range []string{"a", "b", "nobody"} {
+		require.NoError(t, stream.SendMsg(helloRequest(input, name)))
+	}
+	require.NoError(t, stream.CloseSend())
+	out := dynamicpb.NewMessage(output)
+	require.NoError(t, stream.RecvMsg(out))
+	assert.Equal(t, "last b", replyMessage(output, out))
+	assert.Equal(t, io.EOF, stream.RecvMsg(dynamicpb.NewMessage(output)))
+
+	// 没有消息匹配规则
+	stream, err = conn.NewStream(context.Background(), desc, "/acme.greeter.v1.Greeter/Collect")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(helloRequest(input, "nobody")))
+	require.NoError(t, stream.CloseSend())
+	err = stream.RecvMsg(dynamicpb.NewMessage(output))
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	require.NoError(t, requestLogger.Flush(context.Background()))
+	require.Len(t, *logs, 2)
+	logged := (*logs)[0]
+	if logged.StatusCode != 0 {
+		logged = (*logs)[1]
+	}
+	assert.Equal(t, 3, logged.Request["message_count"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "a"},
+		map[string]interface{}{"name": "b"},
+		map[string]interface{}{"name": "nobody"},
+	}, logged.Request["messages"])
+	assert.Equal(t, map[string]interface{}{"message": "last b"}, logged.Response["body"])
+}
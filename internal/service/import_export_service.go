@@ -22,6 +22,10 @@ type ImportExportService interface {
 	ImportData(ctx context.Context, req *models.ImportRequest) (*models.ImportResult, error)
 	// ValidateImportData 验证导入数据
 	ValidateImportData(ctx context.Context, data *models.ExportData) error
+	// PreviewOpenAPI 解析 OpenAPI/Swagger 文档并预览生成的规则
+	PreviewOpenAPI(ctx context.Context, spec []byte) (*models.OpenAPIImportPreview, error)
+	// ImportOpenAPI 导入 OpenAPI/Swagger 文档
+	ImportOpenAPI(ctx context.Context, req *models.OpenAPIImportRequest) (*models.ImportResult, error)
 }
 
 type importExportService struct {
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/cache"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultInvalidationChannel 失效事件默认频道
+const DefaultInvalidationChannel = "mockserver:invalidation"
+
+const (
+	// invalidationPublishTimeout 发布失效事件超时时间
+	invalidationPublishTimeout = 2 * time.Second
+	// invalidationRetryInterval 订阅断开后的重试间隔
+	invalidationRetryInterval = time.Second
+)
+
+// 失效事件类型
+const (
+	invalidationRule        = "rule"
+	invalidationEnvironment = "environment"
+)
+
+// InvalidationTransport 失效事件传输（cache.RedisPubSub）
+type InvalidationTransport interface {
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe 订阅直到 ctx 结束或连接失败，每次（重新）建立订阅时调用 onSubscribe
+	Subscribe(ctx context.Context, onSubscribe func(), onMessage func(payload []byte)) error
+}
+
+// RuleIndexInvalidator 按环境清除规则索引（engine.MatchEngine）
+type RuleIndexInvalidator interface {
+	InvalidateRules(projectID, environmentID string)
+	InvalidateAllRules()
+}
+
+// invalidationEvent 跨实例失效事件
+type invalidationEvent struct {
+	Source        string    `json:"source"` // 发布实例ID，实例忽略自己发布的事件
+	Kind          string    `json:"kind"`
+	RuleID        string    `json:"rule_id,omitempty"`
+	ProjectID     string    `json:"project_id,omitempty"`
+	EnvironmentID string    `json:"environment_id,omitempty"`
+	PublishedAt   time.Time `json:"published_at"`
+}
+
+// InvalidationPropagator 跨实例失效传播
+//
+// 作为规则和环境变更监听器挂在仓库写入之后（位于本地缓存监听器之后，此时 L2 已清除），
+// 把失效事件发布给其他实例；其他实例收到后清除本地 L1 规则缓存、匹配引擎规则索引以及环境缓存，
+// 规则索引按数据库重新构建。订阅（重新）建立时做一次全量本地失效，
+// 弥补断线期间丢失的事件，因此失效延迟上限为 Redis 重连时间；索引最长有效期（index_ttl）作为兜底。
+type InvalidationPropagator struct {
+	transport    InvalidationTransport
+	instanceID   string
+	ruleIndex    RuleIndexInvalidator
+	ruleCache    *CacheRuleService
+	envListeners []repository.EnvironmentChangeListener
+	logger       *zap.Logger
+
+	statsMu  sync.Mutex
+	stats    cache.PropagationStats
+	lagTotal float64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewInvalidationPropagator 创建跨实例失效传播器，envListeners 为收到环境失效事件时通知的本地监听器
+func NewInvalidationPropagator(
+	transport InvalidationTransport,
+	ruleIndex RuleIndexInvalidator,
+	logger *zap.Logger,
+	envListeners ...repository.EnvironmentChangeListener,
+) *InvalidationPropagator {
+	instanceID := uuid.NewString()
+	return &InvalidationPropagator{
+		transport:    transport,
+		instanceID:   instanceID,
+		ruleIndex:    ruleIndex,
+		envListeners: envListeners,
+		logger:       logger.Named("invalidation_propagator"),
+		stats:        cache.PropagationStats{InstanceID: instanceID},
+	}
+}
+
+// SetRuleCache 设置规则缓存，收到规则失效事件时清除本地L1
+func (p *InvalidationPropagator) SetRuleCache(ruleCache *CacheRuleService) {
+	p.ruleCache = ruleCache
+}
+
+// Start 在后台订阅其他实例的失效事件，订阅断开后自动重试
+func (p *InvalidationPropagator) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		for {
+			err := p.transport.Subscribe(ctx, func() { p.resync(ctx) }, func(payload []byte) { p.handle(ctx, payload) })
+			if ctx.Err() != nil {
+				return
+			}
+			p.logger.Warn("invalidation subscription lost, retrying", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(invalidationRetryInterval):
+			}
+		}
+	}()
+}
+
+// Stop 停止订阅
+func (p *InvalidationPropagator) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// RuleSaved 发布规则失效事件（实现 repository.RuleChangeListener）
+func (p *InvalidationPropagator) RuleSaved(ctx context.Context, rule *models.Rule) {
+	p.publishRule(ctx, rule)
+}
+
+// RuleDeleted 发布规则失效事件（实现 repository.RuleChangeListener）
+func (p *InvalidationPropagator) RuleDeleted(ctx context.Context, rule *models.Rule) {
+	p.publishRule(ctx, rule)
+}
+
+// EnvironmentChanged 发布环境失效事件（实现 repository.EnvironmentChangeListener）
+func (p *InvalidationPropagator) EnvironmentChanged(ctx context.Context, environmentID string) {
+	p.publish(ctx, &invalidationEvent{Kind: invalidationEnvironment, EnvironmentID: environmentID})
+}
+
+// PropagationStats 获取传播统计
+func (p *InvalidationPropagator) PropagationStats() *cache.PropagationStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	stats := p.stats
+	return &stats
+}
+
+// publishRule 发布规则失效事件
+func (p *InvalidationPropagator) publishRule(ctx context.Context, rule *models.Rule) {
+	if rule == nil {
+		return
+	}
+	p.publish(ctx, &invalidationEvent{
+		Kind:          invalidationRule,
+		RuleID:        rule.ID,
+		ProjectID:     rule.ProjectID,
+		EnvironmentID: rule.EnvironmentID,
+	})
+}
+
+// publish 发布失效事件，失败时只记录日志：其他实例依赖索引有效期兜底
+func (p *InvalidationPropagator) publish(ctx context.Context, event *invalidationEvent) {
+	event.Source = p.instanceID
+	event.PublishedAt = time.Now()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	// 请求结束不应中断发布
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidationPublishTimeout)
+	defer cancel()
+	err = p.transport.Publish(ctx, payload)
+
+	p.statsMu.Lock()
+	if err != nil {
+		p.stats.PublishErrors++
+	} else {
+		p.stats.Published++
+	}
+	p.statsMu.Unlock()
+
+	if err != nil {
+		p.logger.Warn("failed to publish invalidation event",
+			zap.String("kind", event.Kind),
+			zap.String("rule_id", event.RuleID),
+			zap.String("environment_id", event.EnvironmentID),
+			zap.Error(err))
+	}
+}
+
+// handle 处理其他实例发布的失效事件
+func (p *InvalidationPropagator) handle(ctx context.Context, payload []byte) {
+	var event invalidationEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		p.logger.Warn("invalid invalidation event", zap.Error(err))
+		return
+	}
+	if event.Source == p.instanceID {
+		return
+	}
+
+	switch event.Kind {
+	case invalidationRule:
+		if p.ruleCache != nil {
+			// 只清除缓存键：收到的规则可能已被后续写入覆盖，以数据库为准重新加载
+			p.ruleCache.RuleDeleted(ctx, &models.Rule{
+				ID:            event.RuleID,
+				ProjectID:     event.ProjectID,
+				EnvironmentID: event.EnvironmentID,
+			})
+		}
+		if p.ruleIndex != nil {
+			if event.ProjectID == "" {
+				p.ruleIndex.InvalidateAllRules()
+			} else {
+				p.ruleIndex.InvalidateRules(event.ProjectID, event.EnvironmentID)
+			}
+		}
+	case invalidationEnvironment:
+		for _, listener := range p.envListeners {
+			listener.EnvironmentChanged(ctx, event.EnvironmentID)
+		}
+	default:
+		p.logger.Warn("unknown invalidation event", zap.String("kind", event.Kind))
+		return
+	}
+
+	p.recordLag(time.Since(event.PublishedAt))
+}
+
+// resync 订阅（重新）建立后清空本地缓存，弥补断线期间丢失的事件
+func (p *InvalidationPropagator) resync(ctx context.Context) {
+	if p.ruleCache != nil {
+		if err := p.ruleCache.InvalidateLocal(ctx); err != nil {
+			p.logger.Warn("failed to clear local rule cache", zap.Error(err))
+		}
+	}
+	if p.ruleIndex != nil {
+		p.ruleIndex.InvalidateAllRules()
+	}
+	for _, listener := range p.envListeners {
+		listener.EnvironmentChanged(ctx, "")
+	}
+
+	p.statsMu.Lock()
+	p.stats.Resyncs++
+	p.statsMu.Unlock()
+}
+
+// recordLag 记录传播延迟
+func (p *InvalidationPropagator) recordLag(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	lagMs := float64(lag) / float64(time.Millisecond)
+
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	p.stats.Received++
+	p.stats.LastLagMs = lagMs
+	if lagMs > p.stats.MaxLagMs {
+		p.stats.MaxLagMs = lagMs
+	}
+	p.lagTotal += lagMs
+	p.stats.AvgLagMs = p.lagTotal / float64(p.stats.Received)
+	p.stats.LastReceivedAt = time.Now()
+}
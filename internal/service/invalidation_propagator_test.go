@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryBus 进程内消息总线，模拟 Redis pub/sub
+type memoryBus struct {
+	mu          sync.Mutex
+	subscribers []func(payload []byte)
+}
+
+// memoryTransport 连接到 memoryBus 的传输
+type memoryTransport struct {
+	bus        *memoryBus
+	subscribed chan struct{}
+}
+
+func newMemoryTransport(bus *memoryBus) *memoryTransport {
+	return &memoryTransport{bus: bus, subscribed: make(chan struct{})}
+}
+
+func (t *memoryTransport) Publish(ctx context.Context, payload []byte) error {
+	t.bus.mu.Lock()
+	subscribers := append([]func([]byte){}, t.bus.subscribers...)
+	t.bus.mu.Unlock()
+	for _, deliver := range subscribers {
+		deliver(payload)
+	}
+	return nil
+}
+
+func (t *memoryTransport) Subscribe(ctx context.Context, onSubscribe func(), onMessage func(payload []byte)) error {
+	t.bus.mu.Lock()
+	t.bus.subscribers = append(t.bus.subscribers, onMessage)
+	t.bus.mu.Unlock()
+	onSubscribe()
+	close(t.subscribed)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// recordingIndex 记录失效调用的规则索引
+type recordingIndex struct {
+	mu          sync.Mutex
+	invalidated []string
+	all         int
+}
+
+func (r *recordingIndex) InvalidateRules(projectID, environmentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidated = append(r.invalidated, projectID+"/"+environmentID)
+}
+
+func (r *recordingIndex) InvalidateAllRules() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.all++
+}
+
+// recordingEnvListener 记录环境失效通知
+type recordingEnvListener struct {
+	mu      sync.Mutex
+	changed []string
+}
+
+func (r *recordingEnvListener) EnvironmentChanged(ctx context.Context, environmentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changed = append(r.changed, environmentID)
+}
+
+func startPropagator(t *testing.T, bus *memoryBus, index *recordingIndex, env *recordingEnvListener) *InvalidationPropagator {
+	transport := newMemoryTransport(bus)
+	propagator := NewInvalidationPropagator(transport, index, zap.NewNop(), env)
+	propagator.Start(context.Background())
+	t.Cleanup(propagator.Stop)
+	<-transport.subscribed
+	return propagator
+}
+
+func TestInvalidationPropagator_PropagatesChanges(t *testing.T) {
+	bus := &memoryBus{}
+	indexA, indexB := &recordingIndex{}, &recordingIndex{}
+	envA, envB := &recordingEnvListener{}, &recordingEnvListener{}
+	a := startPropagator(t, bus, indexA, envA)
+	b := startPropagator(t, bus, indexB, envB)
+
+	// B 的规则缓存中已有 e1 的规则
+	store := new(MockBatchRuleRepository)
+	store.On("FindEnabledByEnvironment", mock.Anything, "p1", "e1").Return([]*models.Rule{}, nil)
+	ruleCache := NewCacheRuleService(NewMemoryCacheService(nil, zap.NewNop()), store, zap.NewNop())
+	b.SetRuleCache(ruleCache)
+	ctx := context.Background()
+	_, _ = ruleCache.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	_, _ = ruleCache.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	store.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 1)
+
+	a.RuleSaved(ctx, &models.Rule{ID: "r1", ProjectID: "p1", EnvironmentID: "e1"})
+	a.EnvironmentChanged(ctx, "e1")
+
+	// B 清除了规则索引、L1 缓存与环境缓存
+	assert.Equal(t, []string{"p1/e1"}, indexB.invalidated)
+	_, _ = ruleCache.GetEnabledRulesByEnvironment(ctx, "p1", "e1")
+	store.AssertNumberOfCalls(t, "FindEnabledByEnvironment", 2)
+	assert.Equal(t, []string{"", "e1"}, envB.changed)
+
+	// A 忽略自己发布的事件，只有订阅时的全量失效
+	assert.Empty(t, indexA.invalidated)
+	assert.Equal(t, []string{""}, envA.changed)
+
+	statsA, statsB := a.PropagationStats(), b.PropagationStats()
+	assert.Equal(t, int64(2), statsA.Published)
+	assert.Equal(t, int64(0), statsA.Received)
+	assert.Equal(t, int64(2), statsB.Received)
+	assert.Equal(t, int64(1), statsB.Resyncs)
+	assert.GreaterOrEqual(t, statsB.MaxLagMs, statsB.LastLagMs)
+	assert.False(t, statsB.LastReceivedAt.IsZero())
+}
+
+func TestInvalidationPropagator_ResyncOnSubscribe(t *testing.T) {
+	index := &recordingIndex{}
+	env := &recordingEnvListener{}
+	propagator := startPropagator(t, &memoryBus{}, index, env)
+
+	// 订阅建立时清空全部本地缓存，弥补断线期间丢失的事件
+	assert.Equal(t, 1, index.all)
+	assert.Equal(t, []string{""}, env.changed)
+	assert.Equal(t, int64(1), propagator.PropagationStats().Resyncs)
+}
+
+func TestInvalidationPropagator_RuleWithoutProjectInvalidatesAll(t *testing.T) {
+	bus := &memoryBus{}
+	a := startPropagator(t, bus, &recordingIndex{}, &recordingEnvListener{})
+	indexB := &recordingIndex{}
+	startPropagator(t, bus, indexB, &recordingEnvListener{})
+
+	// 删除前查询失败时只有规则 ID，不知道所在环境
+	a.RuleDeleted(context.Background(), &models.Rule{ID: "r1"})
+	assert.Equal(t, 2, indexB.all)
+	assert.Empty(t, indexB.invalidated)
+}
+
+func TestObservedEnvironmentRepository_NotifiesAfterWrite(t *testing.T) {
+	store := new(MockImportEnvironmentRepository)
+	listener := &recordingEnvListener{}
+	envRepo := repository.NewObservedEnvironmentRepository(store, listener)
+	ctx := context.Background()
+
+	env := &models.Environment{ID: "e1"}
+	store.On("Update", mock.Anything, env).Return(nil).Once()
+	require.NoError(t, envRepo.Update(ctx, env))
+
+	store.On("Delete", mock.Anything, "e2").Return(errors.New("boom")).Once()
+	assert.Error(t, envRepo.Delete(ctx, "e2"))
+
+	assert.Equal(t, []string{"e1"}, listener.changed)
+}
+
+func TestCacheStatsService_IncludesPropagation(t *testing.T) {
+	propagator := startPropagator(t, &memoryBus{}, &recordingIndex{}, &recordingEnvListener{})
+
+	// 未启用规则缓存时只返回传播统计
+	statsService := NewCacheStatsService(nil, zap.NewNop())
+	statsService.SetPropagation(propagator)
+	stats, err := statsService.GetCacheStats(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, stats.Propagation)
+	assert.Equal(t, propagator.PropagationStats().InstanceID, stats.Propagation.InstanceID)
+
+	cacheStats := NewCacheStatsService(NewMemoryCacheService(nil, zap.NewNop()), zap.NewNop())
+	stats, err = cacheStats.GetCacheStats(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, stats.Propagation)
+}
@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gomockserver/mockserver/internal/adapter"
 	"github.com/gomockserver/mockserver/internal/middleware"
 	"github.com/gomockserver/mockserver/internal/models"
 	"github.com/gomockserver/mockserver/pkg/logger"
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
@@ -22,20 +24,81 @@ type MockExecutorInterface interface {
 	GetDefaultResponse() *adapter.Response
 }
 
+// ContractProvider 契约提供者，返回环境绑定的 OpenAPI 契约校验器
+type ContractProvider interface {
+	Contract(ctx context.Context, environmentID string) (*ContractValidator, *models.ContractConfig)
+}
+
+// ClientAuthProvider 客户端证书模式提供者，返回环境的 mTLS 模式
+type ClientAuthProvider interface {
+	ClientAuthMode(ctx context.Context, environmentID string) models.ClientAuthMode
+}
+
+// FallbackProvider 回退策略提供者，返回环境未匹配规则时的处理方式
+type FallbackProvider interface {
+	Fallback(ctx context.Context, environmentID string) *models.FallbackConfig
+}
+
 // MockService Mock 服务
 type MockService struct {
-	httpAdapter  *adapter.HTTPAdapter
-	matchEngine  MatchEngineInterface
-	mockExecutor MockExecutorInterface
+	httpAdapter   *adapter.HTTPAdapter
+	wsAdapter     *adapter.WebSocketAdapter
+	matchEngine   MatchEngineInterface
+	mockExecutor  MockExecutorInterface
+	requestLogger *middleware.RequestLoggerMiddleware
+	recorder      Recorder
+	contracts     ContractProvider
+	clientAuth    ClientAuthProvider
+	fallbacks     FallbackProvider
+	forwardProxy  *ForwardProxy
+	routing       *RoutingService
 }
 
 // NewMockService 创建 Mock 服务
 func NewMockService(matchEngine MatchEngineInterface, mockExecutor MockExecutorInterface) *MockService {
-	return &MockService{
+	s := &MockService{
 		httpAdapter:  adapter.NewHTTPAdapter(),
+		wsAdapter:    adapter.NewWebSocketAdapter(),
 		matchEngine:  matchEngine,
 		mockExecutor: mockExecutor,
 	}
+	s.wsAdapter.SetMessageHandler(s.handleWebSocketMessage)
+	return s
+}
+
+// SetRequestLogger 设置请求日志中间件，记录的日志可用于请求校验
+func (s *MockService) SetRequestLogger(requestLogger *middleware.RequestLoggerMiddleware) {
+	s.requestLogger = requestLogger
+}
+
+// SetRecorder 设置录制器，启用录制的环境会将代理流量保存为规则
+func (s *MockService) SetRecorder(recorder Recorder) {
+	s.recorder = recorder
+}
+
+// SetContractProvider 设置契约提供者，绑定 OpenAPI 文档的环境会校验请求与响应
+func (s *MockService) SetContractProvider(contracts ContractProvider) {
+	s.contracts = contracts
+}
+
+// SetClientAuthProvider 设置客户端证书模式提供者，未设置时所有环境按 request 处理
+func (s *MockService) SetClientAuthProvider(clientAuth ClientAuthProvider) {
+	s.clientAuth = clientAuth
+}
+
+// SetFallbackProvider 设置回退策略提供者，未设置时未匹配规则的请求返回默认 404 响应
+func (s *MockService) SetFallbackProvider(fallbacks FallbackProvider) {
+	s.fallbacks = fallbacks
+}
+
+// SetForwardProxy 设置正向代理，Mock 服务端口同时接受 HTTP_PROXY / HTTPS_PROXY 请求
+func (s *MockService) SetForwardProxy(proxy *ForwardProxy) {
+	s.forwardProxy = proxy
+}
+
+// SetRoutingService 设置请求路由，按主机名绑定、路由请求头或默认环境确定项目和环境
+func (s *MockService) SetRoutingService(routing *RoutingService) {
+	s.routing = routing
 }
 
 // HandleMockRequest 处理 Mock 请求
@@ -52,6 +115,26 @@ func (s *MockService) HandleMockRequest(c *gin.Context) {
 		return
 	}
 
+	ctx := context.Background()
+
+	// 客户端证书模式：require 拒绝未出示证书的请求（包括 WebSocket 升级）
+	clientAuth := models.ClientAuthRequest
+	if s.clientAuth != nil {
+		clientAuth = s.clientAuth.ClientAuthMode(ctx, environmentID)
+	}
+	if clientAuth == models.ClientAuthRequire && (c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0) {
+		c.JSON(403, gin.H{
+			"error": "Client certificate required",
+		})
+		return
+	}
+
+	// 携带 Upgrade 头的请求升级为 WebSocket 连接
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		s.HandleWebSocket(c)
+		return
+	}
+
 	// 解析请求为统一模型
 	request, err := s.httpAdapter.Parse(c)
 	if err != nil {
@@ -61,9 +144,34 @@ func (s *MockService) HandleMockRequest(c *gin.Context) {
 		})
 		return
 	}
+	if clientAuth == models.ClientAuthIgnore {
+		delete(request.Metadata, "client_cert")
+	}
+
+	// 供请求日志中间件记录
+	c.Set("project_id", projectID)
+	c.Set("environment_id", environmentID)
+	c.Set("request_id", request.ID)
+	c.Set("request_path", request.Path)
+
+	// 契约校验：不符合 OpenAPI 文档的请求直接返回 400
+	var contract *ContractValidator
+	var contractConfig *models.ContractConfig
+	if s.contracts != nil {
+		contract, contractConfig = s.contracts.Contract(ctx, environmentID)
+	}
+	if contract != nil && contractConfig.ValidateRequest {
+		if violations := contract.ValidateRequest(request); len(violations) > 0 {
+			c.Set("contract_violations", violations)
+			c.JSON(400, gin.H{
+				"error":      "Request does not match OpenAPI contract",
+				"violations": violations,
+			})
+			return
+		}
+	}
 
 	// 匹配规则
-	ctx := context.Background()
 	rule, err := s.matchEngine.Match(ctx, request, projectID, environmentID)
 	if err != nil {
 		logger.Error("failed to match rule", zap.Error(err))
@@ -75,14 +183,76 @@ func (s *MockService) HandleMockRequest(c *gin.Context) {
 
 	var response *adapter.Response
 
-	// 如果没有匹配的规则，返回默认响应
-	if rule == nil {
+	// 录制配置（未启用录制时为 nil）
+	var recording *models.RecordingConfig
+	if s.recorder != nil {
+		recording = s.recorder.RecordingConfig(ctx, environmentID)
+	}
+
+	// 回退策略（未配置时为 nil）
+	var fallback *models.FallbackConfig
+	if rule == nil && s.fallbacks != nil {
+		fallback = s.fallbacks.Fallback(ctx, environmentID)
+	}
+
+	if rule == nil && recording != nil && recording.TargetURL != "" {
+		// 录制模式：未匹配的请求转发到上游并保存为规则
+		response, err = s.recorder.Proxy(request, recording)
+		if err != nil {
+			logger.Error("failed to proxy request for recording", zap.Error(err))
+			c.JSON(502, gin.H{
+				"error": "Failed to proxy request",
+			})
+			return
+		}
+		c.Set("fallback", models.FallbackProxy)
+		s.record(ctx, projectID, environmentID, recording, request, response)
+	} else if rule == nil && fallback != nil && fallback.Mode != models.FallbackNotFound {
+		// 回退策略：返回自定义响应或转发到上游（应用代理配置的请求、响应修改器）
+		response, err = s.mockExecutor.Execute(request, fallbackRule(projectID, environmentID, fallback))
+		if err != nil {
+			logger.Error("failed to execute fallback",
+				zap.String("mode", string(fallback.Mode)),
+				zap.String("environment_id", environmentID),
+				zap.Error(err))
+			if fallback.Mode == models.FallbackProxy {
+				c.JSON(502, gin.H{
+					"error": "Failed to proxy request",
+				})
+			} else {
+				c.JSON(500, gin.H{
+					"error": "Failed to execute fallback response",
+				})
+			}
+			return
+		}
+		c.Set("fallback", fallback.Mode)
+
+		// 录制模式下回退代理的流量同样保存为静态规则
+		if recording != nil && fallback.Mode == models.FallbackProxy {
+			s.record(ctx, projectID, environmentID, recording, request, response)
+		}
+	} else if upstream := upstreamFromContext(c.Request.Context()); rule == nil && fallback == nil && upstream != "" && s.forwardProxy != nil {
+		// 正向代理：环境未配置回退策略时，未匹配的请求转发到原始主机
+		response, err = s.forwardProxy.Passthrough(request, upstream)
+		if err != nil {
+			logger.Error("failed to pass through proxy request", zap.Error(err))
+			c.JSON(502, gin.H{
+				"error": "Failed to proxy request",
+			})
+			return
+		}
+		c.Set("fallback", models.FallbackProxy)
+	} else if rule == nil {
+		// 如果没有匹配的规则，返回默认响应
 		logger.Info("no rule matched, using default response",
 			zap.String("path", request.Path),
 			zap.String("project_id", projectID),
 			zap.String("environment_id", environmentID))
 		response = s.mockExecutor.GetDefaultResponse()
 	} else {
+		c.Set("rule_id", rule.ID)
+
 		// 执行 Mock 响应生成
 		response, err = s.mockExecutor.Execute(request, rule)
 		if err != nil {
@@ -92,24 +262,89 @@ func (s *MockService) HandleMockRequest(c *gin.Context) {
 			})
 			return
 		}
+
+		// 录制模式下代理规则的流量同样保存为静态规则
+		if recording != nil && rule.Response.Type == models.ResponseTypeProxy {
+			s.record(ctx, projectID, environmentID, recording, request, response)
+		}
+	}
+
+	// 响应偏离契约时记录到请求日志，不影响返回
+	if contract != nil && contractConfig.ValidateResponse {
+		if violations := contract.ValidateResponse(request, response); len(violations) > 0 {
+			logger.Warn("mock response does not match OpenAPI contract",
+				zap.String("path", request.Path),
+				zap.String("environment_id", environmentID),
+				zap.Int("violations", len(violations)))
+			c.Set("contract_violations", violations)
+		}
 	}
 
 	// 写入响应
 	s.httpAdapter.WriteResponse(c, response)
 }
 
-// StartMockServer 启动 Mock 服务器
-func StartMockServer(addr string, service *MockService) error {
+// record 保存录制的请求/响应，失败只记录日志，不影响本次响应
+func (s *MockService) record(ctx context.Context, projectID, environmentID string, recording *models.RecordingConfig, request *adapter.Request, response *adapter.Response) {
+	if _, err := s.recorder.Record(ctx, projectID, environmentID, recording, request, response); err != nil {
+		logger.Error("failed to record request",
+			zap.String("path", request.Path),
+			zap.String("environment_id", environmentID),
+			zap.Error(err))
+	}
+}
+
+// newMockRouter 创建 Mock 服务路由，HTTP 与 HTTPS 端口共用
+func newMockRouter(service *MockService) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
 	// 添加 CORS 支持，允许前端直接调用 Mock 服务
 	r.Use(middleware.CORS())
+	if service.requestLogger != nil {
+		r.Use(service.requestLogger.Handler())
+	}
 
 	// Mock 请求处理路由
 	// 格式：/:projectID/:environmentID/*path
 	r.Any("/:projectID/:environmentID/*path", service.HandleMockRequest)
+	return r
+}
+
+// StartMockServer 启动 Mock 服务器，明文端口同时支持 h2c（HTTP/2 prior knowledge 与 Upgrade），设置正向代理时同时处理代理请求
+// 正向代理在请求路由外层，拦截主机的请求不再按主机名绑定路由
+func StartMockServer(addr string, service *MockService) error {
+	r := newMockRouter(service)
+	r.UseH2C = true
+	handler := r.Handler()
+	if service.routing != nil {
+		handler = service.routing.Handler(handler)
+	}
+	if service.forwardProxy != nil {
+		handler = service.forwardProxy.Handler(handler)
+	}
 
 	logger.Info("starting mock server", zap.String("address", addr))
-	return r.Run(addr)
+	return http.ListenAndServe(addr, handler)
+}
+
+// newMockTLSServer 创建 HTTPS Mock 服务器，证书由 certs 按 SNI 主机名提供
+func newMockTLSServer(service *MockService, certs *TLSService) *http.Server {
+	handler := newMockRouter(service).Handler()
+	if service.routing != nil {
+		handler = service.routing.Handler(handler)
+	}
+	return &http.Server{
+		Handler:   handler,
+		TLSConfig: certs.TLSConfig(),
+	}
+}
+
+// StartMockTLSServer 启动 HTTPS Mock 服务器，通过 ALPN 协商 HTTP/2
+func StartMockTLSServer(addr string, service *MockService, certs *TLSService) error {
+	server := newMockTLSServer(service, certs)
+	server.Addr = addr
+
+	logger.Info("starting mock tls server", zap.String("address", addr))
+	return server.ListenAndServeTLS("", "")
 }
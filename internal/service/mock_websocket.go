@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WebSocketAdapter 获取 Mock 服务的 WebSocket 适配器，用于查询连接和主动推送消息
+func (s *MockService) WebSocketAdapter() *adapter.WebSocketAdapter {
+	return s.wsAdapter
+}
+
+// HandleWebSocket 将请求升级为 WebSocket 连接，并按匹配的连接规则发送欢迎消息或关闭连接
+//
+// 连接建立后收到的每条消息由 handleWebSocketMessage 按消息规则回复，未匹配规则的消息被忽略。
+func (s *MockService) HandleWebSocket(c *gin.Context) {
+	request, err := s.wsAdapter.Parse(c)
+	if err != nil || request == nil {
+		// 升级失败时 upgrader 已写入 HTTP 错误响应
+		return
+	}
+
+	// 供请求日志中间件记录握手请求
+	c.Set("project_id", c.Param("projectID"))
+	c.Set("environment_id", c.Param("environmentID"))
+	c.Set("request_id", request.ID)
+	c.Set("request_path", request.Path)
+
+	conn, exists := s.wsAdapter.GetConnection(request.ID)
+	if !exists {
+		return
+	}
+	if ruleID := s.replyWebSocket(request, conn); ruleID != "" {
+		c.Set("rule_id", ruleID)
+	}
+}
+
+// handleWebSocketMessage 处理连接上收到的消息（adapter.MessageHandler）
+func (s *MockService) handleWebSocketMessage(request *adapter.Request, conn *adapter.WebSocketConnection) {
+	s.replyWebSocket(request, conn)
+}
+
+// replyWebSocket 匹配规则并发送回复帧，返回匹配的规则 ID
+func (s *MockService) replyWebSocket(request *adapter.Request, conn *adapter.WebSocketConnection) string {
+	event, _ := request.Metadata["event"].(string)
+	rule, err := s.matchEngine.Match(context.Background(), request, conn.ProjectID, conn.EnvID)
+	if err != nil {
+		logger.Error("failed to match websocket rule", zap.String("connection_id", conn.ID), zap.Error(err))
+		return ""
+	}
+	if rule == nil {
+		logger.Debug("no websocket rule matched",
+			zap.String("connection_id", conn.ID),
+			zap.String("event", event),
+			zap.String("path", request.Path))
+		return ""
+	}
+
+	response, err := s.mockExecutor.Execute(request, rule)
+	if err != nil {
+		logger.Error("failed to execute websocket mock", zap.String("rule_id", rule.ID), zap.Error(err))
+		return rule.ID
+	}
+	built, err := s.wsAdapter.Build(response)
+	if err != nil {
+		logger.Error("invalid websocket mock response", zap.String("rule_id", rule.ID), zap.Error(err))
+		return rule.ID
+	}
+	reply := built.(*adapter.WebSocketReply)
+	if err := s.wsAdapter.SendFrames(conn, reply.Frames); err != nil {
+		logger.Warn("failed to send websocket reply",
+			zap.String("connection_id", conn.ID),
+			zap.String("rule_id", rule.ID),
+			zap.Error(err))
+		return rule.ID
+	}
+	if reply.Periodic != nil {
+		go s.pushWebSocketPeriodic(request, rule, conn, reply.Periodic)
+	}
+	return rule.ID
+}
+
+// pushWebSocketPeriodic 按间隔周期推送消息，直到达到推送次数或连接关闭
+//
+// 每次推送以只包含周期消息的规则副本执行，Dynamic 规则因此每次重新渲染模板，
+// 模板中的请求为触发推送的握手请求或消息。
+func (s *MockService) pushWebSocketPeriodic(request *adapter.Request, rule *models.Rule, conn *adapter.WebSocketConnection, periodic *models.WebSocketPeriodic) {
+	tickRule := *rule
+	tickRule.Response = models.Response{
+		Type:    rule.Response.Type,
+		Content: map[string]interface{}{"messages": periodic.Messages},
+	}
+
+	ticker := time.NewTicker(time.Duration(periodic.Interval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for sent := 0; periodic.Count == 0 || sent < periodic.Count; sent++ {
+		select {
+		case <-ticker.C:
+		case <-conn.Done:
+			return
+		}
+
+		response, err := s.mockExecutor.Execute(request, &tickRule)
+		if err != nil {
+			logger.Error("failed to execute websocket periodic mock", zap.String("rule_id", rule.ID), zap.Error(err))
+			return
+		}
+		built, err := s.wsAdapter.Build(response)
+		if err != nil {
+			logger.Error("invalid websocket periodic response", zap.String("rule_id", rule.ID), zap.Error(err))
+			return
+		}
+		if err := s.wsAdapter.SendFrames(conn, built.(*adapter.WebSocketReply).Frames); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/executor"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func websocketMockRule(id string, content map[string]interface{}) *models.Rule {
+	return &models.Rule{
+		ID:       id,
+		Protocol: models.ProtocolWebSocket,
+		Response: models.Response{Type: models.ResponseTypeDynamic, Content: content},
+	}
+}
+
+// websocketEvent 按事件和消息内容匹配请求
+func websocketEvent(event, body string) interface{} {
+	return mock.MatchedBy(func(r *adapter.Request) bool {
+		return r.Protocol == models.ProtocolWebSocket && r.Metadata["event"] == event && string(r.Body) == body
+	})
+}
+
+// startWebSocketMockServer 启动挂载 Mock 服务的测试服务器，返回 ws:// 地址前缀
+func startWebSocketMockServer(t *testing.T, matchEngine MatchEngineInterface) (*MockService, string) {
+	gin.SetMode(gin.TestMode)
+	service := NewMockService(matchEngine, executor.NewMockExecutor())
+	router := gin.New()
+	router.Any("/:projectID/:environmentID/*path", service.HandleMockRequest)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return service, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func readWebSocketMessage(t *testing.T, conn *websocket.Conn) (int, string) {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	messageType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	return messageType, string(data)
+}
+
+func TestMockService_WebSocketReplies(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, mock.MatchedBy(func(r *adapter.Request) bool {
+		return r.Metadata["event"] == "connect" && r.Path == "/ws/chat" && r.Headers["X-User"] == "alice"
+	}), "p1", "e1").Return(websocketMockRule("welcome", map[string]interface{}{
+		"messages": []interface{}{map[string]interface{}{"data": "welcome {{.Request.Path}}"}},
+	}), nil)
+	matchEngine.On("Match", mock.Anything, websocketEvent("message", "ping"), "p1", "e1").Return(websocketMockRule("pong", map[string]interface{}{
+		"messages": []interface{}{
+			map[string]interface{}{"type": "json", "data": map[string]interface{}{"event": "pong"}},
+			map[string]interface{}{"type": "binary", "data": "AAEC", "delay": 20},
+		},
+	}), nil)
+	matchEngine.On("Match", mock.Anything, websocketEvent("message", "bye"), "p1", "e1").Return(websocketMockRule("bye", map[string]interface{}{
+		"messages": []interface{}{map[string]interface{}{"data": "see you"}},
+		"close":    map[string]interface{}{"code": 4000, "reason": "done"},
+	}), nil)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(nil, nil)
+
+	service, baseURL := startWebSocketMockServer(t, matchEngine)
+	header := http.Header{"X-User": []string{"alice"}}
+	conn, resp, err := websocket.DefaultDialer.Dial(baseURL+"/p1/e1/ws/chat", header)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	// 连接规则的欢迎消息
+	messageType, data := readWebSocketMessage(t, conn)
+	assert.Equal(t, websocket.TextMessage, messageType)
+	assert.Equal(t, "welcome /ws/chat", data)
+	assert.Equal(t, 1, service.WebSocketAdapter().GetConnectionCount())
+
+	// 一条消息回复多条消息，未匹配的消息被忽略
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("unknown")))
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ping")))
+	messageType, data = readWebSocketMessage(t, conn)
+	assert.Equal(t, websocket.TextMessage, messageType)
+	assert.JSONEq(t, `{"event":"pong"}`, data)
+	messageType, data = readWebSocketMessage(t, conn)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	assert.Equal(t, "\x00\x01\x02", data)
+
+	// 回复关闭帧
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("bye")))
+	_, data = readWebSocketMessage(t, conn)
+	assert.Equal(t, "see you", data)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4000, closeErr.Code)
+	assert.Equal(t, "done", closeErr.Text)
+
+	assert.Eventually(t, func() bool {
+		return service.WebSocketAdapter().GetConnectionCount() == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestMockService_WebSocketRejectOnConnect(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, websocketEvent("connect", ""), "p1", "e1").Return(websocketMockRule("reject", map[string]interface{}{
+		"close": map[string]interface{}{"code": 1008, "reason": "unauthorized"},
+	}), nil)
+
+	_, baseURL := startWebSocketMockServer(t, matchEngine)
+	conn, _, err := websocket.DefaultDialer.Dial(baseURL+"/p1/e1/ws/private", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "%v", err)
+}
+
+func TestMockService_WebSocketPeriodicPush(t *testing.T) {
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, websocketEvent("message", "subscribe"), "p1", "e1").Return(websocketMockRule("ticker", map[string]interface{}{
+		"messages": []interface{}{map[string]interface{}{"data": "subscribed"}},
+		"periodic": map[string]interface{}{
+			"interval": 20,
+			"count":    2,
+			"messages": []interface{}{map[string]interface{}{"type": "json", "data": map[string]interface{}{"topic": "{{.Request.Body}}"}}},
+		},
+	}), nil)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(nil, nil)
+
+	_, baseURL := startWebSocketMockServer(t, matchEngine)
+	conn, _, err := websocket.DefaultDialer.Dial(baseURL+"/p1/e1/ws/ticker", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("subscribe")))
+	_, data := readWebSocketMessage(t, conn)
+	assert.Equal(t, "subscribed", data)
+	for i := 0; i < 2; i++ {
+		_, data = readWebSocketMessage(t, conn)
+		assert.JSONEq(t, `{"topic":"subscribe"}`, data)
+	}
+
+	// 达到推送次数后停止
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, err = conn.ReadMessage()
+	var netErr interface{ Timeout() bool }
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
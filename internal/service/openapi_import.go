@@ -0,0 +1,571 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// openAPIRuleTag OpenAPI 导入规则的标签
+	openAPIRuleTag = "openapi"
+	// openAPIBasePriority OpenAPI 导入规则的基础优先级，每个路径参数降低 1，静态路径优先匹配
+	openAPIBasePriority = 100
+	// maxSchemaDepth 根据 Schema 生成示例的最大嵌套深度（同时防止循环引用）
+	maxSchemaDepth = 8
+)
+
+// openAPIMethods 路径项支持的 HTTP 方法，按固定顺序生成规则
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// ErrInvalidOpenAPI OpenAPI/Swagger 文档无效
+var ErrInvalidOpenAPI = errors.New("invalid openapi document")
+
+// openAPIDocument 已解析的 OpenAPI/Swagger 文档
+type openAPIDocument struct {
+	root     map[string]interface{}
+	format   string // openapi 或 swagger
+	version  string
+	warnings []string
+}
+
+// PreviewOpenAPI 解析 OpenAPI/Swagger 文档并预览生成的规则
+func (s *importExportService) PreviewOpenAPI(ctx context.Context, spec []byte) (*models.OpenAPIImportPreview, error) {
+	doc, err := parseOpenAPIDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := doc.rules()
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: no operations defined", ErrInvalidOpenAPI)
+	}
+
+	title := ""
+	if info, ok := doc.root["info"].(map[string]interface{}); ok {
+		title, _ = info["title"].(string)
+	}
+
+	return &models.OpenAPIImportPreview{
+		Format:    doc.format,
+		Version:   doc.version,
+		Title:     title,
+		RuleCount: len(rules),
+		Rules:     rules,
+		Warnings:  doc.warnings,
+	}, nil
+}
+
+// ImportOpenAPI 导入 OpenAPI/Swagger 文档，生成的规则按导入策略写入目标环境
+func (s *importExportService) ImportOpenAPI(ctx context.Context, req *models.OpenAPIImportRequest) (*models.ImportResult, error) {
+	if req.TargetProjectID == "" || req.TargetEnvID == "" {
+		return nil, errors.New("target_project_id and target_environment_id are required")
+	}
+
+	preview, err := s.PreviewOpenAPI(ctx, req.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = models.ImportStrategySkip
+	}
+
+	result, err := s.ImportData(ctx, &models.ImportRequest{
+		Data: models.ExportData{
+			Version:    "1.0",
+			ExportTime: time.Now(),
+			ExportType: models.ExportTypeRules,
+			Data:       models.ExportDataContent{Rules: preview.Rules},
+		},
+		TargetProjectID: req.TargetProjectID,
+		TargetEnvID:     req.TargetEnvID,
+		Strategy:        strategy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Warnings = preview.Warnings
+	return result, nil
+}
+
+// parseOpenAPIDocument 解析 JSON 或 YAML 格式的 OpenAPI 3.x / Swagger 2.0 文档
+func parseOpenAPIDocument(spec []byte) (*openAPIDocument, error) {
+	spec = bytes.TrimSpace(spec)
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("%w: document is empty", ErrInvalidOpenAPI)
+	}
+
+	var raw interface{}
+	if spec[0] == '{' {
+		if err := json.Unmarshal(spec, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOpenAPI, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(spec, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOpenAPI, err)
+		}
+		raw = normalizeYAMLValue(raw)
+	}
+
+	root, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: document must be an object", ErrInvalidOpenAPI)
+	}
+
+	doc := &openAPIDocument{root: root}
+	if version, ok := root["openapi"].(string); ok && strings.HasPrefix(version, "3.") {
+		doc.format, doc.version = "openapi", version
+	} else if version := fmt.Sprint(root["swagger"]); version == "2.0" || version == "2" {
+		doc.format, doc.version = "swagger", "2.0"
+	} else {
+		return nil, fmt.Errorf("%w: expected openapi 3.x or swagger 2.0", ErrInvalidOpenAPI)
+	}
+
+	if paths, ok := root["paths"].(map[string]interface{}); !ok || len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no paths defined", ErrInvalidOpenAPI)
+	}
+
+	return doc, nil
+}
+
+// normalizeYAMLValue 将 YAML 解码出的非字符串键（如响应码 200）统一转换为字符串键
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAMLValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeYAMLValue(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAMLValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// warnf 记录转换警告
+func (d *openAPIDocument) warnf(format string, args ...interface{}) {
+	d.warnings = append(d.warnings, fmt.Sprintf(format, args...))
+}
+
+// rules 将文档中的每个操作转换为一条规则
+func (d *openAPIDocument) rules() []models.RuleExportData {
+	paths := d.root["paths"].(map[string]interface{})
+	basePath := d.basePath()
+
+	rules := []models.RuleExportData{}
+	for _, path := range sortedKeys(paths) {
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, isRef := item["$ref"]; isRef {
+			d.warnf("%s: path item $ref is not supported, skipped", path)
+			continue
+		}
+
+		mockPath, paramCount := d.convertPath(basePath + path)
+		for _, method := range openAPIMethods {
+			operation, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rules = append(rules, d.operationRule(strings.ToUpper(method), path, mockPath, paramCount, operation))
+		}
+	}
+
+	return rules
+}
+
+// basePath 获取文档的基础路径（Swagger 的 basePath 或 OpenAPI 第一个 server 的路径）
+func (d *openAPIDocument) basePath() string {
+	var base string
+	if d.format == "swagger" {
+		base, _ = d.root["basePath"].(string)
+	} else if servers, ok := d.root["servers"].([]interface{}); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			serverURL, _ := server["url"].(string)
+			if strings.Contains(serverURL, "{") {
+				d.warnf("server url %q contains variables, base path ignored", serverURL)
+				return ""
+			}
+			if parsed, err := url.Parse(serverURL); err == nil {
+				base = parsed.Path
+			}
+		}
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// convertPath 将 OpenAPI 路径模板 /users/{id} 转换为匹配引擎支持的 /users/:id，返回路径参数个数
+func (d *openAPIDocument) convertPath(path string) (string, int) {
+	parts := strings.Split(path, "/")
+	paramCount := 0
+	for i, part := range parts {
+		start := strings.Index(part, "{")
+		end := strings.LastIndex(part, "}")
+		if start < 0 || end < start {
+			continue
+		}
+		paramCount++
+		if start != 0 || end != len(part)-1 {
+			d.warnf("%s: partial path parameter %q matches the whole segment", path, part)
+		}
+		parts[i] = ":" + strings.Trim(part[start:end+1], "{}")
+	}
+	return strings.Join(parts, "/"), paramCount
+}
+
+// operationRule 将单个操作转换为规则
+func (d *openAPIDocument) operationRule(method, path, mockPath string, paramCount int, operation map[string]interface{}) models.RuleExportData {
+	name, _ := operation["operationId"].(string)
+	if name == "" {
+		name = method + " " + path
+	}
+	description, _ := operation["summary"].(string)
+	if description == "" {
+		description, _ = operation["description"].(string)
+	}
+
+	tags := []string{openAPIRuleTag}
+	if opTags, ok := operation["tags"].([]interface{}); ok {
+		for _, tag := range opTags {
+			if tag, ok := tag.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return models.RuleExportData{
+		Name:      name,
+		Protocol:  models.ProtocolHTTP,
+		MatchType: models.MatchTypeSimple,
+		Priority:  openAPIBasePriority - paramCount,
+		Enabled:   true,
+		MatchCondition: map[string]interface{}{
+			"method": method,
+			"path":   mockPath,
+		},
+		Response: models.Response{
+			Type:    models.ResponseTypeStatic,
+			Content: d.operationResponse(name, operation),
+		},
+		Tags:        tags,
+		Description: description,
+	}
+}
+
+// operationResponse 选取操作的成功响应（优先 2xx，其次 default）生成静态响应配置
+func (d *openAPIDocument) operationResponse(name string, operation map[string]interface{}) map[string]interface{} {
+	content := map[string]interface{}{
+		"status_code":  200,
+		"content_type": string(models.ContentTypeText),
+		"body":         "",
+	}
+
+	responses, _ := operation["responses"].(map[string]interface{})
+	code := selectResponseCode(responses)
+	if code == "" {
+		d.warnf("%s: no responses defined, using empty 200 response", name)
+		return content
+	}
+	if status, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(code), "X", "0")); err == nil {
+		content["status_code"] = status
+	}
+
+	response := d.resolve(responses[code])
+	var mediaType string
+	var example interface{}
+	if d.format == "swagger" {
+		mediaType, example = d.swaggerExample(operation, response)
+	} else {
+		mediaType, example = d.openAPIExample(response)
+	}
+	if mediaType == "" {
+		return content
+	}
+
+	content["headers"] = map[string]string{"Content-Type": mediaType}
+	contentType, body := exampleBody(mediaType, example)
+	content["content_type"] = string(contentType)
+	content["body"] = body
+	return content
+}
+
+// selectResponseCode 选择响应码：第一个 2xx，其次 default，最后第一个定义的响应
+func selectResponseCode(responses map[string]interface{}) string {
+	codes := sortedKeys(responses)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return code
+		}
+	}
+	if _, ok := responses["default"]; ok {
+		return "default"
+	}
+	if len(codes) > 0 {
+		return codes[0]
+	}
+	return ""
+}
+
+// openAPIExample 从 OpenAPI 3 响应的 content 中获取示例，优先 JSON 媒体类型
+func (d *openAPIDocument) openAPIExample(response map[string]interface{}) (string, interface{}) {
+	content, ok := response["content"].(map[string]interface{})
+	if !ok || len(content) == 0 {
+		return "", nil
+	}
+
+	mediaType := preferredMediaType(sortedKeys(content))
+	media := d.resolve(content[mediaType])
+
+	if example, ok := media["example"]; ok {
+		return mediaType, example
+	}
+	if examples, ok := media["examples"].(map[string]interface{}); ok {
+		for _, key := range sortedKeys(examples) {
+			if value, ok := d.resolve(examples[key])["value"]; ok {
+				return mediaType, value
+			}
+		}
+	}
+	return mediaType, d.exampleFromSchema(media["schema"], 0)
+}
+
+// swaggerExample 从 Swagger 2.0 响应中获取示例，媒体类型来自 examples 或 produces
+func (d *openAPIDocument) swaggerExample(operation, response map[string]interface{}) (string, interface{}) {
+	if examples, ok := response["examples"].(map[string]interface{}); ok && len(examples) > 0 {
+		mediaType := preferredMediaType(sortedKeys(examples))
+		return mediaType, examples[mediaType]
+	}
+
+	schema, hasSchema := response["schema"]
+	if !hasSchema {
+		return "", nil
+	}
+
+	produces, ok := operation["produces"].([]interface{})
+	if !ok {
+		produces, _ = d.root["produces"].([]interface{})
+	}
+	var mediaTypes []string
+	for _, p := range produces {
+		if p, ok := p.(string); ok {
+			mediaTypes = append(mediaTypes, p)
+		}
+	}
+	mediaType := "application/json"
+	if len(mediaTypes) > 0 {
+		mediaType = preferredMediaType(mediaTypes)
+	}
+	return mediaType, d.exampleFromSchema(schema, 0)
+}
+
+// preferredMediaType 优先选择 JSON 媒体类型
+func preferredMediaType(mediaTypes []string) string {
+	for _, mediaType := range mediaTypes {
+		if strings.Contains(mediaType, "json") {
+			return mediaType
+		}
+	}
+	return mediaTypes[0]
+}
+
+// exampleBody 按媒体类型转换示例为响应体
+func exampleBody(mediaType string, example interface{}) (models.ContentType, interface{}) {
+	if strings.Contains(mediaType, "json") {
+		return models.ContentTypeJSON, example
+	}
+
+	text, isString := example.(string)
+	if !isString && example != nil {
+		data, _ := json.Marshal(example)
+		text = string(data)
+	}
+
+	switch {
+	case strings.Contains(mediaType, "xml"):
+		return models.ContentTypeXML, text
+	case mediaType == "text/html":
+		return models.ContentTypeHTML, text
+	default:
+		return models.ContentTypeText, text
+	}
+}
+
+// exampleFromSchema 根据 Schema 生成示例值，优先使用 example/default/enum
+func (d *openAPIDocument) exampleFromSchema(schema interface{}, depth int) interface{} {
+	s, ok := schema.(map[string]interface{})
+	if !ok || depth > maxSchemaDepth {
+		return nil
+	}
+	if _, isRef := s["$ref"]; isRef {
+		return d.exampleFromSchema(d.resolve(s), depth+1)
+	}
+
+	if example, ok := s["example"]; ok {
+		return example
+	}
+	if def, ok := s["default"]; ok {
+		return def
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, sub := range allOf {
+			if obj, ok := d.exampleFromSchema(sub, depth+1).(map[string]interface{}); ok {
+				for key, value := range obj {
+					merged[key] = value
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if list, ok := s[key].([]interface{}); ok && len(list) > 0 {
+			return d.exampleFromSchema(list[0], depth+1)
+		}
+	}
+
+	switch schemaType(s) {
+	case "object":
+		obj := make(map[string]interface{})
+		properties, _ := s["properties"].(map[string]interface{})
+		for name, property := range properties {
+			if value := d.exampleFromSchema(property, depth+1); value != nil {
+				obj[name] = value
+			}
+		}
+		if len(properties) == 0 {
+			if value := d.exampleFromSchema(s["additionalProperties"], depth+1); value != nil {
+				obj["key"] = value
+			}
+		}
+		return obj
+	case "array":
+		if item := d.exampleFromSchema(s["items"], depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "string":
+		format, _ := s["format"].(string)
+		return stringExample(format)
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// schemaType 获取 Schema 类型，兼容 OpenAPI 3.1 的类型数组，未声明类型但有属性时视为对象
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if item, ok := item.(string); ok && item != "null" {
+				return item
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// stringExample 按字符串格式生成示例
+func stringExample(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "byte":
+		return "c3RyaW5n"
+	default:
+		return "string"
+	}
+}
+
+// resolve 解析本地 $ref 引用（如 #/components/schemas/User），非引用直接返回
+func (d *openAPIDocument) resolve(value interface{}) map[string]interface{} {
+	for depth := 0; depth <= maxSchemaDepth; depth++ {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		ref, isRef := obj["$ref"].(string)
+		if !isRef {
+			return obj
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			d.warnf("external reference %q is not supported", ref)
+			return nil
+		}
+
+		var current interface{} = d.root
+		for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				current = nil
+				break
+			}
+			current = m[token]
+		}
+		if current == nil {
+			d.warnf("reference %q not found", ref)
+			return nil
+		}
+		value = current
+	}
+	return nil
+}
+
+// sortedKeys 返回排序后的键，保证生成结果稳定
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const petstoreOpenAPI = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      tags: [pets]
+      responses:
+        200:
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      responses:
+        '201':
+          description: created
+          content:
+            application/xml:
+              example: <pet/>
+            application/json:
+              examples:
+                dog:
+                  $ref: '#/components/examples/Dog'
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      responses:
+        default:
+          description: error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+    delete:
+      operationId: deletePet
+      responses:
+        '204':
+          description: deleted
+components:
+  examples:
+    Dog:
+      value: {id: 1, name: Rex}
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id: {type: integer, format: int64}
+        name: {type: string, example: Tom}
+        status: {type: string, enum: [available, sold]}
+        born: {type: string, format: date}
+        owner:
+          $ref: '#/components/schemas/Pet'
+    Error:
+      allOf:
+        - type: object
+          properties:
+            code: {type: integer, default: 404}
+        - type: object
+          properties:
+            message: {type: string}
+`
+
+const petstoreSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy", "version": "1"},
+  "basePath": "/api/",
+  "produces": ["application/json"],
+  "paths": {
+    "/users/{id}/avatar.{ext}": {
+      "get": {
+        "produces": ["image/png"],
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/users": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "ok",
+            "schema": {"type": "array", "items": {"$ref": "#/definitions/User"}}
+          }
+        }
+      },
+      "post": {
+        "responses": {
+          "201": {
+            "description": "created",
+            "examples": {"application/json": {"id": "u1"}}
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "User": {"properties": {"email": {"type": "string", "format": "email"}, "active": {"type": "boolean"}}}
+  }
+}`
+
+func findRule(t *testing.T, rules []models.RuleExportData, name string) models.RuleExportData {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	t.Fatalf("rule %s not found", name)
+	return models.RuleExportData{}
+}
+
+func TestPreviewOpenAPI_OpenAPI3(t *testing.T) {
+	service, _, _, _ := setupImportExportService()
+
+	preview, err := service.PreviewOpenAPI(context.Background(), []byte(petstoreOpenAPI))
+	require.NoError(t, err)
+	assert.Equal(t, "openapi", preview.Format)
+	assert.Equal(t, "3.0.3", preview.Version)
+	assert.Equal(t, "Petstore", preview.Title)
+	assert.Equal(t, 4, preview.RuleCount)
+
+	list := findRule(t, preview.Rules, "listPets")
+	assert.Equal(t, map[string]interface{}{"method": "GET", "path": "/v1/pets"}, list.MatchCondition)
+	assert.Equal(t, 100, list.Priority)
+	assert.Equal(t, []string{"openapi", "pets"}, list.Tags)
+	assert.Equal(t, "List pets", list.Description)
+	assert.Equal(t, models.ResponseTypeStatic, list.Response.Type)
+	assert.Equal(t, 200, list.Response.Content["status_code"])
+	assert.Equal(t, "JSON", list.Response.Content["content_type"])
+	pets := list.Response.Content["body"].([]interface{})
+	require.Len(t, pets, 1)
+	pet := pets[0].(map[string]interface{})
+	assert.Equal(t, 0, pet["id"])
+	assert.Equal(t, "Tom", pet["name"])
+	assert.Equal(t, "available", pet["status"])
+	assert.Equal(t, "2024-01-01", pet["born"])
+	assert.Contains(t, pet, "owner")
+
+	create := findRule(t, preview.Rules, "POST /pets")
+	assert.Equal(t, 201, create.Response.Content["status_code"])
+	assert.Equal(t, map[string]string{"Content-Type": "application/json"}, create.Response.Content["headers"])
+	assert.Equal(t, map[string]interface{}{"id": 1, "name": "Rex"}, create.Response.Content["body"])
+
+	get := findRule(t, preview.Rules, "getPet")
+	assert.Equal(t, "/v1/pets/:petId", get.MatchCondition["path"])
+	assert.Equal(t, 99, get.Priority)
+	assert.Equal(t, 200, get.Response.Content["status_code"])
+	assert.Equal(t, map[string]interface{}{"code": 404, "message": "string"}, get.Response.Content["body"])
+
+	del := findRule(t, preview.Rules, "deletePet")
+	assert.Equal(t, 204, del.Response.Content["status_code"])
+	assert.Equal(t, "", del.Response.Content["body"])
+	assert.NotContains(t, del.Response.Content, "headers")
+
+	// 生成的规则可以通过导入校验
+	data := &models.ExportData{Version: "1.0", ExportType: models.ExportTypeRules, Data: models.ExportDataContent{Rules: preview.Rules}}
+	assert.NoError(t, service.ValidateImportData(context.Background(), data))
+}
+
+func TestPreviewOpenAPI_Swagger2(t *testing.T) {
+	service, _, _, _ := setupImportExportService()
+
+	preview, err := service.PreviewOpenAPI(context.Background(), []byte(petstoreSwagger))
+	require.NoError(t, err)
+	assert.Equal(t, "swagger", preview.Format)
+	assert.Equal(t, 3, preview.RuleCount)
+
+	list := findRule(t, preview.Rules, "GET /users")
+	assert.Equal(t, "/api/users", list.MatchCondition["path"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"email": "user@example.com", "active": true}}, list.Response.Content["body"])
+
+	create := findRule(t, preview.Rules, "POST /users")
+	assert.Equal(t, 201, create.Response.Content["status_code"])
+	assert.Equal(t, map[string]interface{}{"id": "u1"}, create.Response.Content["body"])
+
+	avatar := findRule(t, preview.Rules, "GET /users/{id}/avatar.{ext}")
+	assert.Equal(t, "/api/users/:id/:ext", avatar.MatchCondition["path"])
+	assert.Equal(t, 98, avatar.Priority)
+	assert.Equal(t, "Text", avatar.Response.Content["content_type"])
+	require.Len(t, preview.Warnings, 1)
+	assert.Contains(t, preview.Warnings[0], "partial path parameter")
+}
+
+func TestPreviewOpenAPI_Invalid(t *testing.T) {
+	service, _, _, _ := setupImportExportService()
+
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"空文档", "  "},
+		{"语法错误", "{invalid"},
+		{"非对象", "- a\n- b"},
+		{"未知版本", "openapi: 2.5\npaths: {/a: {get: {}}}"},
+		{"没有路径", "openapi: 3.0.0\npaths: {}"},
+		{"没有操作", "openapi: 3.0.0\npaths: {/a: {summary: x}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.PreviewOpenAPI(context.Background(), []byte(tt.spec))
+			assert.ErrorIs(t, err, ErrInvalidOpenAPI)
+		})
+	}
+}
+
+func TestImportOpenAPI(t *testing.T) {
+	service, mockRuleRepo, _, mockEnvRepo := setupImportExportService()
+	ctx := context.Background()
+
+	mockEnvRepo.On("FindByID", ctx, "env-1").Return(&models.Environment{ID: "env-1"}, nil)
+	mockRuleRepo.On("FindByEnvironment", ctx, "proj-1", "env-1").Return([]*models.Rule{{ID: "r0", Name: "listPets"}}, nil)
+	mockRuleRepo.On("Create", ctx, mock.MatchedBy(func(rule *models.Rule) bool {
+		return rule.ProjectID == "proj-1" && rule.EnvironmentID == "env-1"
+	})).Return(nil).Times(3)
+
+	result, err := service.ImportOpenAPI(ctx, &models.OpenAPIImportRequest{
+		Spec:            []byte(petstoreOpenAPI),
+		TargetProjectID: "proj-1",
+		TargetEnvID:     "env-1",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, result.Created)
+	assert.Equal(t, 1, result.Skipped)
+	mockRuleRepo.AssertExpectations(t)
+
+	_, err = service.ImportOpenAPI(ctx, &models.OpenAPIImportRequest{Spec: []byte(petstoreOpenAPI)})
+	assert.Error(t, err)
+}
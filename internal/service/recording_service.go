@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/executor"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// recordingConfigTTL 录制配置缓存有效期，较短以便开关录制后尽快生效
+	recordingConfigTTL = 2 * time.Second
+	// defaultRecordedPriority 录制规则默认优先级
+	defaultRecordedPriority = 100
+)
+
+// recordedHeaderBlacklist 录制响应时丢弃的响应头
+var recordedHeaderBlacklist = map[string]bool{
+	"connection":        true,
+	"content-length":    true,
+	"date":              true,
+	"keep-alive":        true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+}
+
+// Recorder 录制接口
+type Recorder interface {
+	// RecordingConfig 获取环境的录制配置，未启用时返回 nil
+	RecordingConfig(ctx context.Context, environmentID string) *models.RecordingConfig
+	// Proxy 将未匹配的请求转发到录制配置的上游地址
+	Proxy(request *adapter.Request, config *models.RecordingConfig) (*adapter.Response, error)
+	// Record 将请求/响应保存为静态规则，重复的请求返回 nil
+	Record(ctx context.Context, projectID, environmentID string, config *models.RecordingConfig, request *adapter.Request, response *adapter.Response) (*models.Rule, error)
+}
+
+// recordingConfigEntry 录制配置缓存项
+type recordingConfigEntry struct {
+	config    *models.RecordingConfig
+	expiresAt time.Time
+}
+
+// RecordingService 录制服务，将代理流量转换为静态规则
+type RecordingService struct {
+	ruleRepo repository.RuleRepository
+	envRepo  repository.EnvironmentRepository
+	proxy    *executor.ProxyExecutor
+
+	configMu sync.RWMutex
+	configs  map[string]recordingConfigEntry
+
+	// 按环境串行化录制，避免并发请求重复保存同一规则
+	recordLocksMu sync.Mutex
+	recordLocks   map[string]*sync.Mutex
+}
+
+// NewRecordingService 创建录制服务
+func NewRecordingService(ruleRepo repository.RuleRepository, envRepo repository.EnvironmentRepository) *RecordingService {
+	return &RecordingService{
+		ruleRepo:    ruleRepo,
+		envRepo:     envRepo,
+		proxy:       executor.NewProxyExecutor(),
+		configs:     make(map[string]recordingConfigEntry),
+		recordLocks: make(map[string]*sync.Mutex),
+	}
+}
+
+// RecordingConfig 获取环境的录制配置，未启用时返回 nil
+func (s *RecordingService) RecordingConfig(ctx context.Context, environmentID string) *models.RecordingConfig {
+	s.configMu.RLock()
+	entry, exists := s.configs[environmentID]
+	s.configMu.RUnlock()
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.config
+	}
+
+	var config *models.RecordingConfig
+	env, err := s.envRepo.FindByID(ctx, environmentID)
+	if err != nil {
+		logger.Warn("failed to load recording config",
+			zap.String("environment_id", environmentID),
+			zap.Error(err))
+	} else if env != nil && env.Recording != nil && env.Recording.Enabled {
+		config = env.Recording
+	}
+
+	s.configMu.Lock()
+	s.configs[environmentID] = recordingConfigEntry{config: config, expiresAt: time.Now().Add(recordingConfigTTL)}
+	s.configMu.Unlock()
+
+	return config
+}
+
+// Proxy 将未匹配的请求转发到录制配置的上游地址
+func (s *RecordingService) Proxy(request *adapter.Request, config *models.RecordingConfig) (*adapter.Response, error) {
+	return s.proxy.Execute(request, &executor.ProxyConfig{
+		TargetURL:      strings.TrimSuffix(config.TargetURL, "/"),
+		FollowRedirect: false,
+	})
+}
+
+// Record 将请求/响应保存为静态规则，重复的请求返回 nil
+func (s *RecordingService) Record(ctx context.Context, projectID, environmentID string, config *models.RecordingConfig, request *adapter.Request, response *adapter.Response) (*models.Rule, error) {
+	rule := buildRecordedRule(config, request, response)
+	rule.ProjectID = projectID
+	rule.EnvironmentID = environmentID
+
+	fingerprint, err := conditionFingerprint(rule.MatchCondition)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := s.recordLock(environmentID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// 每次从仓库加载指纹，已录制规则被删除后可以重新录制
+	recorded, err := s.loadFingerprints(ctx, projectID, environmentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := recorded[fingerprint]; exists {
+		return nil, nil
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save recorded rule: %w", err)
+	}
+
+	logger.Info("recorded rule",
+		zap.String("rule_id", rule.ID),
+		zap.String("environment_id", environmentID),
+		zap.String("name", rule.Name))
+	return rule, nil
+}
+
+// recordLock 获取环境的录制锁
+func (s *RecordingService) recordLock(environmentID string) *sync.Mutex {
+	s.recordLocksMu.Lock()
+	defer s.recordLocksMu.Unlock()
+	lock, exists := s.recordLocks[environmentID]
+	if !exists {
+		lock = &sync.Mutex{}
+		s.recordLocks[environmentID] = lock
+	}
+	return lock
+}
+
+// loadFingerprints 加载环境已录制规则的指纹
+func (s *RecordingService) loadFingerprints(ctx context.Context, projectID, environmentID string) (map[string]struct{}, error) {
+	rules, err := s.ruleRepo.FindByEnvironment(ctx, projectID, environmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recorded rules: %w", err)
+	}
+
+	recorded := make(map[string]struct{})
+	for _, rule := range rules {
+		if !hasTag(rule.Tags, models.RecordedRuleTag) {
+			continue
+		}
+		if fingerprint, err := conditionFingerprint(rule.MatchCondition); err == nil {
+			recorded[fingerprint] = struct{}{}
+		}
+	}
+
+	return recorded, nil
+}
+
+// buildRecordedRule 根据请求/响应生成静态规则
+func buildRecordedRule(config *models.RecordingConfig, request *adapter.Request, response *adapter.Response) *models.Rule {
+	method, _ := request.Metadata["method"].(string)
+
+	condition := map[string]interface{}{
+		"method": method,
+		"path":   request.Path,
+	}
+
+	// 参与匹配的查询参数
+	query, _ := request.Metadata["query"].(map[string]string)
+	selectedQuery := make(map[string]string)
+	for _, key := range config.QueryKeys {
+		if key == "*" {
+			for k, v := range query {
+				selectedQuery[k] = v
+			}
+			break
+		}
+		if value, exists := query[key]; exists {
+			selectedQuery[key] = value
+		}
+	}
+	if len(selectedQuery) > 0 {
+		condition["query"] = selectedQuery
+	}
+
+	// 参与匹配的请求头
+	selectedHeaders := make(map[string]string)
+	for _, name := range config.Headers {
+		if value := adapter.HeaderValue(request.Headers, name); value != "" {
+			selectedHeaders[name] = value
+		}
+	}
+	if len(selectedHeaders) > 0 {
+		condition["headers"] = selectedHeaders
+	}
+
+	// JSON 请求体
+	if config.MatchBody && len(request.Body) > 0 {
+		var body interface{}
+		if err := json.Unmarshal(request.Body, &body); err == nil {
+			condition["body"] = map[string]interface{}{"json": body}
+		}
+	}
+
+	priority := config.Priority
+	if priority == 0 {
+		priority = defaultRecordedPriority
+	}
+
+	return &models.Rule{
+		Name:           fmt.Sprintf("Recorded %s %s", method, request.Path),
+		Protocol:       models.ProtocolHTTP,
+		MatchType:      models.MatchTypeSimple,
+		Priority:       priority,
+		Enabled:        true,
+		MatchCondition: condition,
+		Response: models.Response{
+			Type:    models.ResponseTypeStatic,
+			Content: recordedResponseContent(response),
+		},
+		Tags: []string{models.RecordedRuleTag},
+	}
+}
+
+// recordedResponseContent 将响应转换为静态响应配置
+func recordedResponseContent(response *adapter.Response) map[string]interface{} {
+	headers := make(map[string]string, len(response.Headers))
+	for key, value := range response.Headers {
+		if !recordedHeaderBlacklist[strings.ToLower(key)] {
+			headers[key] = value
+		}
+	}
+
+	contentType, body := recordedBody(response)
+	return map[string]interface{}{
+		"status_code":  response.StatusCode,
+		"headers":      headers,
+		"content_type": string(contentType),
+		"body":         body,
+	}
+}
+
+// recordedBody 按响应 Content-Type 推断内容类型，无法以文本保存的内容使用 Base64
+func recordedBody(response *adapter.Response) (models.ContentType, interface{}) {
+	body := response.Body
+	if adapter.HeaderValue(response.Headers, "Content-Encoding") == "" && utf8.Valid(body) {
+		mediaType, _, _ := mime.ParseMediaType(adapter.HeaderValue(response.Headers, "Content-Type"))
+		if mediaType == "" && len(body) > 0 {
+			mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+		}
+
+		switch {
+		case strings.Contains(mediaType, "json"):
+			var parsed interface{}
+			if err := json.Unmarshal(body, &parsed); err == nil {
+				return models.ContentTypeJSON, parsed
+			}
+			return models.ContentTypeText, string(body)
+		case mediaType == "text/html":
+			return models.ContentTypeHTML, string(body)
+		case strings.Contains(mediaType, "xml"):
+			return models.ContentTypeXML, string(body)
+		case strings.HasPrefix(mediaType, "text/"), len(body) == 0:
+			return models.ContentTypeText, string(body)
+		}
+	}
+
+	return models.ContentTypeBinary, base64.StdEncoding.EncodeToString(body)
+}
+
+// conditionFingerprint 计算匹配条件指纹（JSON 序列化按键排序，结果稳定）
+func conditionFingerprint(condition map[string]interface{}) (string, error) {
+	data, err := json.Marshal(condition)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// hasTag 检查标签是否存在
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func recordingRequest(body string) *adapter.Request {
+	return &adapter.Request{
+		Protocol: models.ProtocolHTTP,
+		Path:     "/api/users",
+		Headers:  map[string]string{"X-Tenant": "acme", "User-Agent": "curl"},
+		Body:     []byte(body),
+		Metadata: map[string]interface{}{
+			"method": "POST",
+			"query":  map[string]string{"page": "1", "ts": "123"},
+		},
+	}
+}
+
+func TestRecordingService_RecordingConfig(t *testing.T) {
+	ruleRepo := new(MockBatchRuleRepository)
+	envRepo := new(MockImportEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{
+		ID:        "e1",
+		Recording: &models.RecordingConfig{Enabled: true, TargetURL: "http://backend"},
+	}, nil).Once()
+	envRepo.On("FindByID", mock.Anything, "e2").Return(&models.Environment{
+		ID:        "e2",
+		Recording: &models.RecordingConfig{Enabled: false, TargetURL: "http://backend"},
+	}, nil).Once()
+
+	svc := NewRecordingService(ruleRepo, envRepo)
+	ctx := context.Background()
+
+	config := svc.RecordingConfig(ctx, "e1")
+	require.NotNil(t, config)
+	assert.Equal(t, "http://backend", config.TargetURL)
+	// 第二次读取命中缓存
+	assert.Same(t, config, svc.RecordingConfig(ctx, "e1"))
+
+	assert.Nil(t, svc.RecordingConfig(ctx, "e2"))
+	envRepo.AssertExpectations(t)
+}
+
+func TestRecordingService_Record(t *testing.T) {
+	ruleRepo := new(MockBatchRuleRepository)
+	envRepo := new(MockImportEnvironmentRepository)
+	var saved []*models.Rule
+	ruleRepo.On("FindByEnvironment", mock.Anything, "p1", "e1").Return([]*models.Rule{}, nil).Once()
+	ruleRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Rule")).Return(nil).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(*models.Rule))
+	}).Twice()
+
+	svc := NewRecordingService(ruleRepo, envRepo)
+	config := &models.RecordingConfig{
+		Enabled:   true,
+		Headers:   []string{"x-tenant"},
+		QueryKeys: []string{"page"},
+		MatchBody: true,
+	}
+	response := &adapter.Response{
+		StatusCode: 201,
+		Headers:    map[string]string{"Content-Type": "application/json", "Content-Length": "12", "X-Request-Id": "r1"},
+		Body:       []byte(`{"id":"u1"}`),
+	}
+
+	rule, err := svc.Record(context.Background(), "p1", "e1", config, recordingRequest(`{"name":"alice"}`), response)
+	require.NoError(t, err)
+	require.NotNil(t, rule)
+
+	assert.Equal(t, "Recorded POST /api/users", rule.Name)
+	assert.Equal(t, "p1", rule.ProjectID)
+	assert.Equal(t, "e1", rule.EnvironmentID)
+	assert.Equal(t, 100, rule.Priority)
+	assert.True(t, rule.Enabled)
+	assert.Equal(t, []string{models.RecordedRuleTag}, rule.Tags)
+	assert.Equal(t, models.ResponseTypeStatic, rule.Response.Type)
+
+	assert.Equal(t, map[string]interface{}{
+		"method":  "POST",
+		"path":    "/api/users",
+		"query":   map[string]string{"page": "1"},
+		"headers": map[string]string{"x-tenant": "acme"},
+		"body":    map[string]interface{}{"json": map[string]interface{}{"name": "alice"}},
+	}, rule.MatchCondition)
+
+	content := rule.Response.Content
+	assert.Equal(t, 201, content["status_code"])
+	assert.Equal(t, string(models.ContentTypeJSON), content["content_type"])
+	assert.Equal(t, map[string]interface{}{"id": "u1"}, content["body"])
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Request-Id": "r1"}, content["headers"])
+
+	// 相同请求不会重复录制
+	ruleRepo.On("FindByEnvironment", mock.Anything, "p1", "e1").Return(saved, nil).Once()
+	rule, err = svc.Record(context.Background(), "p1", "e1", config, recordingRequest(`{"name":"alice"}`), response)
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+
+	// 录制的规则被删除后可以重新录制
+	ruleRepo.On("FindByEnvironment", mock.Anything, "p1", "e1").Return([]*models.Rule{}, nil).Once()
+	rule, err = svc.Record(context.Background(), "p1", "e1", config, recordingRequest(`{"name":"alice"}`), response)
+	require.NoError(t, err)
+	assert.NotNil(t, rule)
+	ruleRepo.AssertExpectations(t)
+}
+
+func TestRecordingService_Record_ExistingRecordedRules(t *testing.T) {
+	config := &models.RecordingConfig{Enabled: true}
+	existing := buildRecordedRule(config, recordingRequest(""), &adapter.Response{StatusCode: 200})
+
+	ruleRepo := new(MockBatchRuleRepository)
+	ruleRepo.On("FindByEnvironment", mock.Anything, "p1", "e1").Return([]*models.Rule{existing}, nil).Once()
+
+	svc := NewRecordingService(ruleRepo, new(MockImportEnvironmentRepository))
+	rule, err := svc.Record(context.Background(), "p1", "e1", config, recordingRequest(`{"ignored":true}`), &adapter.Response{StatusCode: 500})
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+	ruleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRecordedBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		body        []byte
+		contentType models.ContentType
+		expected    interface{}
+	}{
+		{"JSON", map[string]string{"Content-Type": "application/json; charset=utf-8"}, []byte(`[1,2]`), models.ContentTypeJSON, []interface{}{float64(1), float64(2)}},
+		{"HTML", map[string]string{"Content-Type": "text/html"}, []byte("<p>hi</p>"), models.ContentTypeHTML, "<p>hi</p>"},
+		{"XML", map[string]string{"Content-Type": "application/xml"}, []byte("<a/>"), models.ContentTypeXML, "<a/>"},
+		{"纯文本", map[string]string{"Content-Type": "text/plain"}, []byte("ok"), models.ContentTypeText, "ok"},
+		{"二进制", map[string]string{"Content-Type": "image/png"}, []byte{0x89, 0x50}, models.ContentTypeBinary, "iVA="},
+		{"压缩内容", map[string]string{"Content-Type": "application/json", "Content-Encoding": "gzip"}, []byte("{}"), models.ContentTypeBinary, "e30="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, body := recordedBody(&adapter.Response{Headers: tt.headers, Body: tt.body})
+			assert.Equal(t, tt.contentType, contentType)
+			assert.Equal(t, tt.expected, body)
+		})
+	}
+}
+
+func TestRecordingService_Proxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/users", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	svc := NewRecordingService(new(MockBatchRuleRepository), new(MockImportEnvironmentRepository))
+	request := recordingRequest("")
+	request.Metadata["method"] = "GET"
+	request.Metadata["raw_query"] = "page=1"
+
+	response, err := svc.Proxy(request, &models.RecordingConfig{TargetURL: backend.URL + "/"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.JSONEq(t, `{"ok":true}`, string(response.Body))
+}
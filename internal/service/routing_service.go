@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 默认的路由请求头
+const (
+	DefaultProjectHeader     = "X-Mock-Project"
+	DefaultEnvironmentHeader = "X-Mock-Environment"
+)
+
+// routingEnvironmentTTL 路由使用的环境缓存有效期
+const routingEnvironmentTTL = 2 * time.Second
+
+// routingEnvironmentEntry 环境缓存项，环境不存在时 env 为空
+type routingEnvironmentEntry struct {
+	env       *models.Environment
+	expiresAt time.Time
+}
+
+// RoutingService Mock 请求路由
+//
+// 请求依次按主机名绑定、路由请求头（X-Mock-Environment，可选 X-Mock-Project）和配置的默认环境
+// 确定项目与环境，去掉环境 BaseURL 的路径前缀后改写为 /:projectID/:environmentID/*path 交给 Mock 路由。
+// 未命中绑定和请求头、且路径以已存在的 /:projectID/:environmentID 开头的请求保持原样。
+type RoutingService struct {
+	repo    repository.HostBindingRepository
+	envRepo repository.EnvironmentRepository
+
+	projectHeader      string
+	environmentHeader  string
+	defaultProject     string
+	defaultEnvironment string
+
+	mu           sync.RWMutex
+	bindings     map[string]*models.HostBinding
+	environments map[string]routingEnvironmentEntry
+}
+
+// NewRoutingService 创建 Mock 请求路由
+func NewRoutingService(repo repository.HostBindingRepository, envRepo repository.EnvironmentRepository) *RoutingService {
+	return &RoutingService{
+		repo:              repo,
+		envRepo:           envRepo,
+		projectHeader:     DefaultProjectHeader,
+		environmentHeader: DefaultEnvironmentHeader,
+		bindings:          make(map[string]*models.HostBinding),
+		environments:      make(map[string]routingEnvironmentEntry),
+	}
+}
+
+// SetHeaders 设置指定项目和环境的请求头，为空时保持默认值
+func (s *RoutingService) SetHeaders(projectHeader, environmentHeader string) {
+	if projectHeader != "" {
+		s.projectHeader = projectHeader
+	}
+	if environmentHeader != "" {
+		s.environmentHeader = environmentHeader
+	}
+}
+
+// SetDefaultTarget 设置未命中主机名绑定和路由请求头时使用的项目和环境
+func (s *RoutingService) SetDefaultTarget(projectID, environmentID string) {
+	s.defaultProject = projectID
+	s.defaultEnvironment = environmentID
+}
+
+// Reload 重新加载主机名绑定
+func (s *RoutingService) Reload(ctx context.Context) error {
+	records, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	bindings := make(map[string]*models.HostBinding, len(records))
+	for _, record := range records {
+		bindings[normalizeHostname(record.Host)] = record
+	}
+
+	s.mu.Lock()
+	s.bindings = bindings
+	s.mu.Unlock()
+
+	logger.Info("host bindings loaded", zap.Int("count", len(bindings)))
+	return nil
+}
+
+// BindingsChanged 主机名绑定变更后重新加载（实现 api.HostBindingListener）
+func (s *RoutingService) BindingsChanged(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		logger.Error("failed to reload host bindings", zap.Error(err))
+	}
+}
+
+// EnvironmentChanged 环境变更后清除缓存（实现 repository.EnvironmentChangeListener）
+func (s *RoutingService) EnvironmentChanged(ctx context.Context, environmentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if environmentID == "" {
+		s.environments = make(map[string]routingEnvironmentEntry)
+		return
+	}
+	delete(s.environments, environmentID)
+}
+
+// Handler 包装 Mock 路由，将命中路由的请求改写为 /:projectID/:environmentID/*path
+func (s *RoutingService) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 正向代理已按拦截主机改写的请求
+		if upstreamFromContext(r.Context()) == "" {
+			s.route(r)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// route 解析请求的项目和环境并改写路径，未命中时保持原样
+func (s *RoutingService) route(r *http.Request) {
+	ctx := r.Context()
+	projectID, environmentID := s.resolve(r)
+	if environmentID == "" {
+		return
+	}
+
+	env := s.environment(ctx, environmentID)
+	if projectID == "" {
+		if env == nil {
+			return
+		}
+		projectID = env.ProjectID
+	}
+
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	if env != nil {
+		path = stripBasePath(path, env.BaseURL)
+	}
+	r.URL.Path = "/" + projectID + "/" + environmentID + path
+	r.URL.RawPath = ""
+	r.RequestURI = r.URL.RequestURI()
+}
+
+// resolve 按主机名绑定、路由请求头和默认环境确定项目与环境，项目为空时取环境所属项目
+func (s *RoutingService) resolve(r *http.Request) (string, string) {
+	if binding := s.binding(r.Host); binding != nil {
+		return binding.ProjectID, binding.EnvironmentID
+	}
+	if environmentID := r.Header.Get(s.environmentHeader); environmentID != "" {
+		return r.Header.Get(s.projectHeader), environmentID
+	}
+	if s.defaultEnvironment == "" || s.isPrefixRoute(r) {
+		return "", ""
+	}
+	return s.defaultProject, s.defaultEnvironment
+}
+
+// binding 查找主机名的绑定，精确名称优先于通配符
+func (s *RoutingService) binding(host string) *models.HostBinding {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = normalizeHostname(host)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if binding, ok := s.bindings[host]; ok {
+		return binding
+	}
+	if _, parent, ok := strings.Cut(host, "."); ok {
+		return s.bindings["*."+parent]
+	}
+	return nil
+}
+
+// isPrefixRoute 判断请求路径是否以已存在环境的 /:projectID/:environmentID 开头
+func (s *RoutingService) isPrefixRoute(r *http.Request) bool {
+	segments := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if len(segments) < 2 || segments[0] == "" || segments[1] == "" {
+		return false
+	}
+	env := s.environment(r.Context(), segments[1])
+	return env != nil && env.ProjectID == segments[0]
+}
+
+// environment 获取环境，不存在或加载失败时返回 nil
+func (s *RoutingService) environment(ctx context.Context, environmentID string) *models.Environment {
+	s.mu.RLock()
+	entry, exists := s.environments[environmentID]
+	s.mu.RUnlock()
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.env
+	}
+
+	env, err := s.envRepo.FindByID(ctx, environmentID)
+	if err != nil {
+		// 路径段不一定是环境 ID（如无效的 ObjectID），只记录调试日志且不缓存
+		logger.Debug("failed to load routing environment",
+			zap.String("environment_id", environmentID),
+			zap.Error(err))
+		return nil
+	}
+
+	s.mu.Lock()
+	s.environments[environmentID] = routingEnvironmentEntry{env: env, expiresAt: time.Now().Add(routingEnvironmentTTL)}
+	s.mu.Unlock()
+
+	return env
+}
+
+// stripBasePath 去掉 BaseURL 的路径前缀，路径不以该前缀开头时保持原样
+func stripBasePath(path, baseURL string) string {
+	if baseURL == "" {
+		return path
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return path
+	}
+	prefix := strings.TrimSuffix(base.Path, "/")
+	if prefix == "" {
+		return path
+	}
+	if path == prefix {
+		return "/"
+	}
+	if strings.HasPrefix(path, prefix+"/") {
+		return strings.TrimPrefix(path, prefix)
+	}
+	return path
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHostBindingRepository 内存主机名绑定仓库
+type memoryHostBindingRepository struct {
+	bindings []*models.HostBinding
+}
+
+func (r *memoryHostBindingRepository) Save(ctx context.Context, binding *models.HostBinding) error {
+	r.bindings = append(r.bindings, binding)
+	return nil
+}
+
+func (r *memoryHostBindingRepository) Delete(ctx context.Context, host string) (bool, error) {
+	return false, nil
+}
+
+func (r *memoryHostBindingRepository) List(ctx context.Context) ([]*models.HostBinding, error) {
+	return r.bindings, nil
+}
+
+// newTestRoutingService 创建绑定 payments.mock.local 与 *.sandbox.local 的路由，环境 e1 的 BaseURL 带 /v1 前缀
+func newTestRoutingService(t *testing.T) *RoutingService {
+	repo := &memoryHostBindingRepository{bindings: []*models.HostBinding{
+		{Host: "payments.mock.local", ProjectID: "p1", EnvironmentID: "e1"},
+		{Host: "*.sandbox.local", ProjectID: "p1", EnvironmentID: "e2"},
+		{Host: "api.sandbox.local", ProjectID: "p2", EnvironmentID: "e3"},
+	}}
+	envRepo := new(MockImportEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", ProjectID: "p1", BaseURL: "https://payments.example.com/v1/"}, nil)
+	envRepo.On("FindByID", mock.Anything, "e2").Return(&models.Environment{ID: "e2", ProjectID: "p1"}, nil)
+	envRepo.On("FindByID", mock.Anything, "e3").Return(&models.Environment{ID: "e3", ProjectID: "p2"}, nil)
+	envRepo.On("FindByID", mock.Anything, mock.Anything).Return(nil, nil)
+
+	routing := NewRoutingService(repo, envRepo)
+	require.NoError(t, routing.Reload(context.Background()))
+	return routing
+}
+
+// TestRoutingService_Route 测试按主机名、请求头和默认环境改写请求路径
+func TestRoutingService_Route(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		target     string
+		headers    map[string]string
+		withTarget bool
+		expected   string
+	}{
+		{"主机名绑定去掉 BaseURL 前缀", "payments.mock.local", "/v1/charges?limit=2", nil, false, "/p1/e1/charges?limit=2"},
+		{"主机名带端口且大写", "Payments.Mock.Local:9090", "/v1", nil, false, "/p1/e1/"},
+		{"不以 BaseURL 前缀开头的路径保持原样", "payments.mock.local", "/v10/charges", nil, false, "/p1/e1/v10/charges"},
+		{"精确主机名优先于通配符", "api.sandbox.local", "/orders", nil, false, "/p2/e3/orders"},
+		{"通配符主机名", "shop.sandbox.local", "/orders", nil, false, "/p1/e2/orders"},
+		{"主机名绑定优先于请求头", "payments.mock.local", "/v1/charges", map[string]string{"X-Mock-Environment": "e2"}, false, "/p1/e1/charges"},
+		{"请求头指定环境", "localhost:9090", "/orders", map[string]string{"X-Mock-Environment": "e2"}, false, "/p1/e2/orders"},
+		{"请求头指定项目和环境", "localhost:9090", "/orders", map[string]string{"X-Mock-Project": "p9", "X-Mock-Environment": "e9"}, false, "/p9/e9/orders"},
+		{"请求头环境不存在", "localhost:9090", "/p1/e1/orders", map[string]string{"X-Mock-Environment": "e9"}, false, "/p1/e1/orders"},
+		{"未命中保持前缀路由", "localhost:9090", "/p1/e1/orders", nil, false, "/p1/e1/orders"},
+		{"默认环境", "localhost:9090", "/orders", nil, true, "/p1/e2/orders"},
+		{"默认环境不覆盖已存在环境的前缀路由", "localhost:9090", "/p2/e3/orders", nil, true, "/p2/e3/orders"},
+		{"默认环境处理不存在环境的前缀", "localhost:9090", "/p2/e9/orders", nil, true, "/p1/e2/p2/e9/orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routing := newTestRoutingService(t)
+			if tt.withTarget {
+				routing.SetDefaultTarget("p1", "e2")
+			}
+			var routed string
+			handler := routing.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				routed = r.URL.RequestURI()
+				assert.Equal(t, routed, r.RequestURI)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Host = tt.host
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.expected, routed)
+		})
+	}
+}
+
+// TestRoutingService_CustomHeaders 测试自定义路由请求头
+func TestRoutingService_CustomHeaders(t *testing.T) {
+	routing := newTestRoutingService(t)
+	routing.SetHeaders("", "X-Env")
+	var routed string
+	handler := routing.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routed = r.URL.Path
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Env", "e3")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "/p2/e3/orders", routed)
+	assert.Equal(t, DefaultProjectHeader, routing.projectHeader)
+}
+
+// TestMockServer_HostRouting 测试 Mock 服务按主机名绑定应答，前缀路由仍然可用
+func TestMockServer_HostRouting(t *testing.T) {
+	service := newForwardProxyMockService()
+	service.SetRoutingService(newTestRoutingService(t))
+	server := httptest.NewServer(service.routing.Handler(newMockRouter(service).Handler()))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/v1/charges", nil)
+	require.NoError(t, err)
+	req.Host = "payments.mock.local"
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, body := proxyGet(t, http.DefaultClient, server.URL+"/p1/e1/v1/charges")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"mocked":true}`, body)
+}
+
+// TestRoutingService_EnvironmentChanged 测试环境变更后重新加载 BaseURL
+func TestRoutingService_EnvironmentChanged(t *testing.T) {
+	envRepo := new(MockImportEnvironmentRepository)
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", ProjectID: "p1", BaseURL: "http://localhost/v1"}, nil).Once()
+	envRepo.On("FindByID", mock.Anything, "e1").Return(&models.Environment{ID: "e1", ProjectID: "p1", BaseURL: "http://localhost/v2"}, nil).Once()
+	envRepo.On("FindByID", mock.Anything, "orders").Return(nil, nil)
+	routing := NewRoutingService(&memoryHostBindingRepository{}, envRepo)
+	routing.SetDefaultTarget("p1", "e1")
+
+	route := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/v2/orders", nil)
+		routing.route(req)
+		return req.URL.Path
+	}
+	assert.Equal(t, "/p1/e1/v2/orders", route())
+	assert.Equal(t, "/p1/e1/v2/orders", route())
+
+	routing.EnvironmentChanged(context.Background(), "e1")
+	assert.Equal(t, "/p1/e1/orders", route())
+	envRepo.AssertExpectations(t)
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/gomockserver/mockserver/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// caCertFile 根 CA 证书文件名
+	caCertFile = "ca.crt"
+	// caKeyFile 根 CA 私钥文件名
+	caKeyFile = "ca.key"
+	// caValidity 根 CA 有效期
+	caValidity = 10 * 365 * 24 * time.Hour
+	// leafValidity 签发证书的有效期，不超过客户端对私有 CA 证书的有效期限制
+	leafValidity = 365 * 24 * time.Hour
+)
+
+// TLSService HTTPS Mock 服务证书管理
+//
+// 按 SNI 主机名选择证书：优先使用环境上传的证书（支持通配符证书），
+// 其次由本地根 CA 为 localhost、IP 地址和配置的主机名签发证书并缓存。
+type TLSService struct {
+	repo      repository.TLSCertificateRepository
+	ca        *x509.Certificate
+	caKey     crypto.Signer
+	caPEM     []byte
+	hostnames []string // 小写，可包含 *.example.test 通配符
+
+	mu       sync.RWMutex
+	issued   map[string]*tls.Certificate // 主机名 -> 签发的证书
+	uploaded map[string]*tls.Certificate // 证书的 DNS 名称或 IP -> 上传的证书
+}
+
+// NewTLSService 创建 HTTPS 证书管理服务，caDir 中不存在根 CA 时生成并保存，caDir 为空时只在内存中生成
+func NewTLSService(caDir string, hostnames []string, repo repository.TLSCertificateRepository) (*TLSService, error) {
+	ca, caKey, caPEM, err := loadOrCreateCA(caDir)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make([]string, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		normalized = append(normalized, normalizeHostname(hostname))
+	}
+
+	return &TLSService{
+		repo:      repo,
+		ca:        ca,
+		caKey:     caKey,
+		caPEM:     caPEM,
+		hostnames: normalized,
+		issued:    make(map[string]*tls.Certificate),
+		uploaded:  make(map[string]*tls.Certificate),
+	}, nil
+}
+
+// CACertificatePEM 获取 PEM 编码的根 CA 证书，供客户端加入信任列表
+func (s *TLSService) CACertificatePEM() []byte {
+	return s.caPEM
+}
+
+// TLSConfig 创建 HTTPS Mock 服务的 TLS 配置，通过 ALPN 协商 HTTP/2
+//
+// 握手时总是请求但不校验客户端证书，由环境的 client_auth 模式决定是否要求出示证书。
+func (s *TLSService) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: s.GetCertificate,
+		ClientAuth:     tls.RequestClientCert,
+	}
+}
+
+// GetCertificate 按 SNI 主机名选择证书（tls.Config.GetCertificate），客户端未发送 SNI 时按连接的本地地址签发
+func (s *TLSService) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := normalizeHostname(hello.ServerName)
+	if name == "" && hello.Conn != nil {
+		if addr, ok := hello.Conn.LocalAddr().(*net.TCPAddr); ok {
+			name = addr.IP.String()
+		}
+	}
+
+	if cert := s.uploadedCertificate(name); cert != nil {
+		return cert, nil
+	}
+	if !s.allowed(name) {
+		logger.Warn("no certificate configured for tls server name", zap.String("server_name", name))
+		return nil, fmt.Errorf("no certificate for server name %q", name)
+	}
+	return s.issue(name)
+}
+
+// certificateFor 获取主机名的证书，不检查配置的主机名，供正向代理解密 CONNECT 请求
+func (s *TLSService) certificateFor(name string) (*tls.Certificate, error) {
+	name = normalizeHostname(name)
+	if cert := s.uploadedCertificate(name); cert != nil {
+		return cert, nil
+	}
+	return s.issue(name)
+}
+
+// Reload 重新加载所有环境上传的证书，无效的证书跳过并记录日志
+func (s *TLSService) Reload(ctx context.Context) error {
+	records, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	uploaded := make(map[string]*tls.Certificate)
+	for _, record := range records {
+		cert, err := tls.X509KeyPair([]byte(record.Certificate), []byte(record.PrivateKey))
+		if err != nil {
+			logger.Warn("invalid uploaded tls certificate",
+				zap.String("environment_id", record.EnvironmentID),
+				zap.Error(err))
+			continue
+		}
+		for _, hostname := range record.Hostnames {
+			uploaded[normalizeHostname(hostname)] = &cert
+		}
+	}
+
+	s.mu.Lock()
+	s.uploaded = uploaded
+	s.mu.Unlock()
+	return nil
+}
+
+// CertificatesChanged 环境证书变更后重新加载（实现 api.TLSCertificateManager）
+func (s *TLSService) CertificatesChanged(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		logger.Error("failed to reload uploaded tls certificates", zap.Error(err))
+	}
+}
+
+// uploadedCertificate 查找主机名对应的上传证书，精确名称优先于通配符
+func (s *TLSService) uploadedCertificate(name string) *tls.Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cert, ok := s.uploaded[name]; ok {
+		return cert
+	}
+	if _, parent, ok := strings.Cut(name, "."); ok {
+		return s.uploaded["*."+parent]
+	}
+	return nil
+}
+
+// allowed 判断是否为主机名签发证书：localhost、IP 地址和配置的主机名
+func (s *TLSService) allowed(name string) bool {
+	if name == "" {
+		return false
+	}
+	if name == "localhost" || net.ParseIP(name) != nil {
+		return true
+	}
+	for _, hostname := range s.hostnames {
+		if wildcardMatch(hostname, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// issue 签发并缓存主机名的证书
+func (s *TLSService) issue(name string) (*tls.Certificate, error) {
+	s.mu.RLock()
+	cert, ok := s.issued[name]
+	s.mu.RUnlock()
+	if ok {
+		return cert, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cert, ok := s.issued[name]; ok {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(leafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{name}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, s.ca, &key.PublicKey, s.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate for %s: %w", name, err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	cert = &tls.Certificate{
+		Certificate: [][]byte{der, s.ca.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	s.issued[name] = cert
+	logger.Info("issued tls certificate", zap.String("hostname", name))
+	return cert, nil
+}
+
+// loadOrCreateCA 从目录加载根 CA，不存在时生成并保存
+func loadOrCreateCA(dir string) (*x509.Certificate, crypto.Signer, []byte, error) {
+	if dir != "" {
+		certPEM, certErr := os.ReadFile(filepath.Join(dir, caCertFile))
+		keyPEM, keyErr := os.ReadFile(filepath.Join(dir, caKeyFile))
+		if certErr == nil && keyErr == nil {
+			return parseCA(certPEM, keyPEM)
+		}
+		if !errors.Is(certErr, os.ErrNotExist) && certErr != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read ca certificate: %w", certErr)
+		}
+		if !errors.Is(keyErr, os.ErrNotExist) && keyErr != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read ca private key: %w", keyErr)
+		}
+	}
+
+	certPEM, keyPEM, err := generateCA()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to create ca directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, caKeyFile), keyPEM, 0o600); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to write ca private key: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, caCertFile), certPEM, 0o644); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to write ca certificate: %w", err)
+		}
+		logger.Info("generated tls root ca", zap.String("dir", dir))
+	}
+	return parseCA(certPEM, keyPEM)
+}
+
+// generateCA 生成 PEM 编码的根 CA 证书和私钥
+func generateCA() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "GoMockServer Local CA", Organization: []string{"GoMockServer"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// parseCA 解析 PEM 编码的根 CA 证书和私钥
+func parseCA(certPEM, keyPEM []byte) (*x509.Certificate, crypto.Signer, []byte, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid ca certificate or private key: %w", err)
+	}
+	ca, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid ca certificate: %w", err)
+	}
+	if !ca.IsCA {
+		return nil, nil, nil, fmt.Errorf("ca certificate is not a certificate authority")
+	}
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unsupported ca private key type %T", pair.PrivateKey)
+	}
+	return ca, signer, certPEM, nil
+}
+
+// randomSerial 生成 128 位随机证书序列号
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// normalizeHostname 主机名转小写并去掉末尾的点
+func normalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+}
+
+// wildcardMatch 判断主机名是否匹配模式，*.example.test 只匹配一级子域名
+func wildcardMatch(pattern, name string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		label, parent, found := strings.Cut(name, ".")
+		return found && label != "" && parent == suffix
+	}
+	return pattern == name
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/adapter"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTLSCertificateRepository 内存环境证书仓库
+type memoryTLSCertificateRepository struct {
+	certs map[string]*models.TLSCertificate
+}
+
+func newMemoryTLSCertificateRepository() *memoryTLSCertificateRepository {
+	return &memoryTLSCertificateRepository{certs: make(map[string]*models.TLSCertificate)}
+}
+
+func (r *memoryTLSCertificateRepository) Save(ctx context.Context, cert *models.TLSCertificate) error {
+	r.certs[cert.EnvironmentID] = cert
+	return nil
+}
+
+func (r *memoryTLSCertificateRepository) Delete(ctx context.Context, environmentID string) (bool, error) {
+	_, exists := r.certs[environmentID]
+	delete(r.certs, environmentID)
+	return exists, nil
+}
+
+func (r *memoryTLSCertificateRepository) FindByEnvironment(ctx context.Context, environmentID string) (*models.TLSCertificate, error) {
+	return r.certs[environmentID], nil
+}
+
+func (r *memoryTLSCertificateRepository) List(ctx context.Context) ([]*models.TLSCertificate, error) {
+	certs := make([]*models.TLSCertificate, 0, len(r.certs))
+	for _, cert := range r.certs {
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// selfSignedCertificate 生成 PEM 编码的自签名证书和私钥
+func selfSignedCertificate(t *testing.T, hostnames ...string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hostnames[0]},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     hostnames,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+// newDefaultResponseMockService 创建未匹配规则时返回默认响应的 Mock 服务
+func newDefaultResponseMockService() *MockService {
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", "e1").Return(nil, nil)
+	mockExecutor := new(MockMockExecutor)
+	mockExecutor.On("GetDefaultResponse").Return(&adapter.Response{
+		StatusCode: http.StatusNotFound,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       []byte(`{"error":"No matching rule found"}`),
+	})
+	return NewMockService(matchEngine, mockExecutor)
+}
+
+// startMockTLSServer 在随机端口启动 HTTPS Mock 服务器，返回监听地址
+func startMockTLSServer(t *testing.T, service *MockService, certs *TLSService) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := newMockTLSServer(service, certs)
+	go server.ServeTLS(ln, "", "")
+	t.Cleanup(func() { server.Close() })
+	return ln.Addr().String()
+}
+
+// newTLSClient 创建信任 roots 的客户端，所有请求都连接到 addr，clientCerts 为出示的客户端证书
+func newTLSClient(roots *x509.CertPool, addr string, clientCerts ...tls.Certificate) *http.Client {
+	dialer := &net.Dialer{Timeout: 2 * time.Second}
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: clientCerts},
+			ForceAttemptHTTP2: true,
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
+
+// TestTLSService_IssuedCertificateHTTP2 测试根 CA 为配置的主机名签发证书并通过 ALPN 协商 HTTP/2
+func TestTLSService_IssuedCertificateHTTP2(t *testing.T) {
+	certs, err := NewTLSService("", []string{"api.example.test", "*.mock.test"}, newMemoryTLSCertificateRepository())
+	require.NoError(t, err)
+	addr := startMockTLSServer(t, newDefaultResponseMockService(), certs)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certs.CACertificatePEM()))
+	client := newTLSClient(roots, addr)
+
+	for _, host := range []string{"api.example.test", "orders.mock.test", "localhost"} {
+		resp, err := client.Get("https://" + host + "/p1/e1/api/users")
+		require.NoError(t, err, host)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		assert.Equal(t, 2, resp.ProtoMajor, host)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, host)
+		assert.JSONEq(t, `{"error":"No matching rule found"}`, string(body), host)
+	}
+
+	// 未配置的主机名不签发证书，通配符只匹配一级子域名
+	for _, host := range []string{"unknown.test", "a.b.mock.test"} {
+		_, err := client.Get("https://" + host + "/p1/e1/api/users")
+		assert.Error(t, err, host)
+	}
+}
+
+// TestTLSService_UploadedCertificate 测试优先使用环境上传的证书，删除后回退到根 CA 签发的证书
+func TestTLSService_UploadedCertificate(t *testing.T) {
+	repo := newMemoryTLSCertificateRepository()
+	certs, err := NewTLSService("", []string{"api.example.test"}, repo)
+	require.NoError(t, err)
+
+	certPEM, keyPEM := selfSignedCertificate(t, "api.example.test", "*.uploaded.test")
+	require.NoError(t, repo.Save(context.Background(), &models.TLSCertificate{
+		EnvironmentID: "e1",
+		Hostnames:     []string{"api.example.test", "*.uploaded.test"},
+		Certificate:   certPEM,
+		PrivateKey:    keyPEM,
+	}))
+	require.NoError(t, certs.Reload(context.Background()))
+
+	uploaded, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+	for _, host := range []string{"api.example.test", "orders.uploaded.test"} {
+		cert, err := certs.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+		require.NoError(t, err, host)
+		assert.Equal(t, uploaded.Certificate[0], cert.Certificate[0], host)
+	}
+
+	// 上传证书的主机名走 TLS 握手
+	addr := startMockTLSServer(t, newDefaultResponseMockService(), certs)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM([]byte(certPEM)))
+	resp, err := newTLSClient(roots, addr).Get("https://orders.uploaded.test/p1/e1/api/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	_, err = repo.Delete(context.Background(), "e1")
+	require.NoError(t, err)
+	certs.CertificatesChanged(context.Background())
+
+	cert, err := certs.GetCertificate(&tls.ClientHelloInfo{ServerName: "api.example.test"})
+	require.NoError(t, err)
+	assert.Equal(t, "api.example.test", cert.Leaf.Subject.CommonName)
+	_, err = certs.GetCertificate(&tls.ClientHelloInfo{ServerName: "orders.uploaded.test"})
+	assert.Error(t, err)
+}
+
+// TestTLSService_PersistCA 测试根 CA 保存到目录并在重启后复用
+func TestTLSService_PersistCA(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewTLSService(dir, nil, newMemoryTLSCertificateRepository())
+	require.NoError(t, err)
+	second, err := NewTLSService(dir, nil, newMemoryTLSCertificateRepository())
+	require.NoError(t, err)
+
+	assert.Equal(t, first.CACertificatePEM(), second.CACertificatePEM())
+	assert.FileExists(t, dir+"/"+caCertFile)
+	assert.FileExists(t, dir+"/"+caKeyFile)
+}
+
+// TestMockServer_H2C 测试明文端口支持 HTTP/2（h2c）
+func TestMockServer_H2C(t *testing.T) {
+	r := newMockRouter(newDefaultResponseMockService())
+	r.UseH2C = true
+	server := httptest.NewServer(r.Handler())
+	defer server.Close()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	resp, err := client.Get(server.URL + "/p1/e1/api/users")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// staticClientAuthProvider 固定的环境客户端证书模式
+type staticClientAuthProvider map[string]models.ClientAuthMode
+
+func (p staticClientAuthProvider) ClientAuthMode(ctx context.Context, environmentID string) models.ClientAuthMode {
+	if mode, ok := p[environmentID]; ok {
+		return mode
+	}
+	return models.ClientAuthRequest
+}
+
+// TestMockServer_ClientCertificate 测试按环境的客户端证书模式处理 mTLS 请求
+func TestMockServer_ClientCertificate(t *testing.T) {
+	certs, err := NewTLSService("", nil, newMemoryTLSCertificateRepository())
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	received := make(map[string]*adapter.ClientCertificate)
+	matchEngine := new(MockMatchEngine)
+	matchEngine.On("Match", mock.Anything, mock.Anything, "p1", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[args.String(3)] = adapter.GetClientCertificate(args.Get(1).(*adapter.Request))
+	}).Return(nil, nil)
+	mockExecutor := new(MockMockExecutor)
+	mockExecutor.On("GetDefaultResponse").Return(&adapter.Response{StatusCode: http.StatusNotFound, Body: []byte(`{}`)})
+	service := NewMockService(matchEngine, mockExecutor)
+	service.SetClientAuthProvider(staticClientAuthProvider{
+		"required": models.ClientAuthRequire,
+		"ignored":  models.ClientAuthIgnore,
+	})
+	addr := startMockTLSServer(t, service, certs)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certs.CACertificatePEM()))
+	certPEM, keyPEM := selfSignedCertificate(t, "partner-a")
+	clientCert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+	anonymous := newTLSClient(roots, addr)
+	partner := newTLSClient(roots, addr, clientCert)
+
+	get := func(client *http.Client, environmentID string) int {
+		resp, err := client.Get("https://localhost/p1/" + environmentID + "/api/orders")
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, get(anonymous, "required"))
+	assert.Equal(t, http.StatusNotFound, get(partner, "required"))
+	assert.Equal(t, http.StatusNotFound, get(partner, "ignored"))
+	assert.Equal(t, http.StatusNotFound, get(partner, "requested"))
+	assert.Equal(t, http.StatusNotFound, get(anonymous, "anonymous"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotNil(t, received["required"])
+	assert.Equal(t, "partner-a", received["required"].SubjectCN)
+	assert.Equal(t, []string{"partner-a"}, received["required"].SANs)
+	assert.Len(t, received["required"].Fingerprint, 64)
+	assert.Nil(t, received["ignored"])
+	require.NotNil(t, received["requested"])
+	assert.Nil(t, received["anonymous"])
+}
@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/engine"
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxVerificationLogs 单次校验最多加载的请求日志数，超过时返回错误而不是截断
+	maxVerificationLogs = 100000
+	// verificationPageSize 分页加载请求日志的每页条数
+	verificationPageSize = 1000
+	// logFlushTimeout 校验前等待请求日志写入的最长时间
+	logFlushTimeout = 5 * time.Second
+	// maxNearMisses 返回的最接近请求数
+	maxNearMisses = 3
+	// maxMatchedRequests 返回的已匹配请求数
+	maxMatchedRequests = 20
+)
+
+// ErrInvalidMatcher 请求校验条件无效
+var ErrInvalidMatcher = errors.New("invalid request matcher")
+
+// ErrTooManyLogs 匹配时间范围内的请求日志过多
+var ErrTooManyLogs = errors.New("too many request logs to verify")
+
+// LogFlusher 请求日志刷新器，校验前等待异步保存的日志写入
+type LogFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// VerificationService 请求校验服务接口
+type VerificationService interface {
+	// Verify 校验匹配条件的请求次数
+	Verify(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResult, error)
+	// VerifySequence 校验请求按顺序出现
+	VerifySequence(ctx context.Context, req *models.SequenceVerificationRequest) (*models.VerificationResult, error)
+}
+
+type verificationService struct {
+	logRepo repository.RequestLogRepository
+	flusher LogFlusher
+}
+
+// NewVerificationService 创建请求校验服务，flusher 可为空
+func NewVerificationService(logRepo repository.RequestLogRepository, flusher LogFlusher) VerificationService {
+	return &verificationService{
+		logRepo: logRepo,
+		flusher: flusher,
+	}
+}
+
+// Verify 校验匹配条件的请求次数
+func (s *verificationService) Verify(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResult, error) {
+	matcher, err := compileRequestMatcher(req.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := s.loadLogs(ctx, req.ProjectID, req.EnvironmentID, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*models.RequestLog
+	var misses []models.NearMiss
+	for _, log := range logs {
+		if mismatches := matcher.mismatches(log); len(mismatches) == 0 {
+			matched = append(matched, log)
+		} else {
+			misses = append(misses, models.NearMiss{Request: log, Mismatches: mismatches})
+		}
+	}
+
+	passed, message := checkCount(req.Count, len(matched))
+	result := &models.VerificationResult{
+		Passed:          passed,
+		Message:         message,
+		MatchedCount:    len(matched),
+		MatchedRequests: limitLogs(matched, maxMatchedRequests),
+	}
+	if !passed {
+		result.NearMisses = closestMisses(misses)
+	}
+	return result, nil
+}
+
+// VerifySequence 校验请求按顺序出现（中间允许穿插其他请求）
+func (s *verificationService) VerifySequence(ctx context.Context, req *models.SequenceVerificationRequest) (*models.VerificationResult, error) {
+	if len(req.Requests) == 0 {
+		return nil, fmt.Errorf("%w: requests are empty", ErrInvalidMatcher)
+	}
+
+	matchers := make([]*requestMatcher, 0, len(req.Requests))
+	for i, r := range req.Requests {
+		matcher, err := compileRequestMatcher(r)
+		if err != nil {
+			return nil, fmt.Errorf("requests[%d]: %w", i, err)
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	logs, err := s.loadLogs(ctx, req.ProjectID, req.EnvironmentID, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*models.RequestLog
+	var misses []models.NearMiss
+	step := 0
+	for _, log := range logs {
+		mismatches := matchers[step].mismatches(log)
+		if len(mismatches) > 0 {
+			misses = append(misses, models.NearMiss{Request: log, Mismatches: mismatches})
+			continue
+		}
+
+		matched = append(matched, log)
+		misses = nil
+		step++
+		if step == len(matchers) {
+			return &models.VerificationResult{
+				Passed:          true,
+				Message:         fmt.Sprintf("all %d requests were received in order", len(matchers)),
+				MatchedCount:    len(matched),
+				MatchedRequests: matched,
+			}, nil
+		}
+	}
+
+	failedIndex := step
+	return &models.VerificationResult{
+		Passed:          false,
+		Message:         fmt.Sprintf("request %d of %d was not received in order", step+1, len(matchers)),
+		MatchedCount:    len(matched),
+		MatchedRequests: matched,
+		FailedIndex:     &failedIndex,
+		NearMisses:      closestMisses(misses),
+	}, nil
+}
+
+// loadLogs 按时间正序分页加载全部请求日志，超过 maxVerificationLogs 时返回 ErrTooManyLogs
+func (s *verificationService) loadLogs(ctx context.Context, projectID, environmentID string, startTime, endTime *time.Time) ([]*models.RequestLog, error) {
+	// 等待已响应请求的日志写入，避免调用 Mock 后立即校验时漏计
+	if s.flusher != nil {
+		flushCtx, cancel := context.WithTimeout(ctx, logFlushTimeout)
+		err := s.flusher.Flush(flushCtx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to flush request logs: %w", err)
+		}
+	}
+
+	filter := repository.RequestLogFilter{
+		ProjectID:     projectID,
+		EnvironmentID: environmentID,
+		PageSize:      verificationPageSize,
+		SortBy:        "timestamp",
+		SortOrder:     1,
+	}
+	if startTime != nil {
+		filter.StartTime = *startTime
+	}
+	if endTime != nil {
+		filter.EndTime = *endTime
+	}
+
+	var logs []*models.RequestLog
+	for page := 1; ; page++ {
+		filter.Page = page
+		pageLogs, total, err := s.logRepo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load request logs: %w", err)
+		}
+		if total > maxVerificationLogs {
+			return nil, fmt.Errorf("%w: %d logs match, narrow the time range to at most %d", ErrTooManyLogs, total, maxVerificationLogs)
+		}
+		logs = append(logs, pageLogs...)
+		if len(pageLogs) < verificationPageSize || int64(len(logs)) >= total {
+			return logs, nil
+		}
+	}
+}
+
+// checkCount 检查调用次数约束
+func checkCount(constraint *models.CountConstraint, count int) (bool, string) {
+	switch {
+	case constraint == nil:
+		return countResult(count >= 1, "at least 1", count)
+	case constraint.Never:
+		return countResult(count == 0, "no", count)
+	case constraint.Exactly != nil:
+		return countResult(count == *constraint.Exactly, fmt.Sprintf("exactly %d", *constraint.Exactly), count)
+	}
+
+	passed := true
+	var expected []string
+	if constraint.AtLeast != nil {
+		passed = passed && count >= *constraint.AtLeast
+		expected = append(expected, fmt.Sprintf("at least %d", *constraint.AtLeast))
+	}
+	if constraint.AtMost != nil {
+		passed = passed && count <= *constraint.AtMost
+		expected = append(expected, fmt.Sprintf("at most %d", *constraint.AtMost))
+	}
+	if len(expected) == 0 {
+		return countResult(count >= 1, "at least 1", count)
+	}
+	return countResult(passed, strings.Join(expected, " and "), count)
+}
+
+// countResult 生成次数校验结果描述
+func countResult(passed bool, expected string, count int) (bool, string) {
+	return passed, fmt.Sprintf("expected %s matching request(s), found %d", expected, count)
+}
+
+// closestMisses 按不匹配项数量排序，返回最接近的请求（数量相同时越新越靠前）
+func closestMisses(misses []models.NearMiss) []models.NearMiss {
+	sorted := make([]models.NearMiss, len(misses))
+	for i := range misses {
+		sorted[len(misses)-1-i] = misses[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Mismatches) < len(sorted[j].Mismatches)
+	})
+	if len(sorted) > maxNearMisses {
+		sorted = sorted[:maxNearMisses]
+	}
+	return sorted
+}
+
+// limitLogs 限制返回的日志数量
+func limitLogs(logs []*models.RequestLog, limit int) []*models.RequestLog {
+	if len(logs) > limit {
+		return logs[:limit]
+	}
+	return logs
+}
+
+// compiledBodyPath 已编译的请求体 JSONPath 条件
+type compiledBodyPath struct {
+	path      *engine.JSONPath
+	condition engine.PathCondition
+}
+
+// requestMatcher 已编译的请求校验条件
+type requestMatcher struct {
+	source    models.RequestMatcher
+	pathRegex *regexp.Regexp
+	headers   map[string]*regexp.Regexp
+	query     map[string]*regexp.Regexp
+	bodyPaths []compiledBodyPath
+}
+
+// compileRequestMatcher 预编译正则与 JSONPath
+func compileRequestMatcher(source models.RequestMatcher) (*requestMatcher, error) {
+	matcher := &requestMatcher{
+		source:  source,
+		headers: make(map[string]*regexp.Regexp, len(source.Headers)),
+		query:   make(map[string]*regexp.Regexp, len(source.Query)),
+	}
+
+	var err error
+	if source.PathRegex != "" {
+		if matcher.pathRegex, err = regexp.Compile(source.PathRegex); err != nil {
+			return nil, fmt.Errorf("%w: path_regex: %v", ErrInvalidMatcher, err)
+		}
+	}
+	for name, pattern := range source.Headers {
+		if matcher.headers[name], err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%w: header %s: %v", ErrInvalidMatcher, name, err)
+		}
+	}
+	for name, pattern := range source.Query {
+		if matcher.query[name], err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%w: query %s: %v", ErrInvalidMatcher, name, err)
+		}
+	}
+	for _, bodyPath := range source.BodyPaths {
+		jsonPath, err := engine.ParseJSONPath(bodyPath.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMatcher, err)
+		}
+		matcher.bodyPaths = append(matcher.bodyPaths, compiledBodyPath{
+			path: jsonPath,
+			condition: engine.PathCondition{
+				Path:  bodyPath.Path,
+				Op:    bodyPath.Op,
+				Value: bodyPath.Value,
+			},
+		})
+	}
+
+	return matcher, nil
+}
+
+// mismatches 返回请求日志与条件的所有不匹配项，为空表示匹配
+func (m *requestMatcher) mismatches(log *models.RequestLog) []string {
+	var result []string
+	source := m.source
+
+	if source.Method != "" && !strings.EqualFold(log.Method, source.Method) {
+		result = append(result, fmt.Sprintf("method: expected %s, got %s", strings.ToUpper(source.Method), log.Method))
+	}
+	if m.pathRegex != nil {
+		if !m.pathRegex.MatchString(log.Path) {
+			result = append(result, fmt.Sprintf("path: %s does not match %s", log.Path, source.PathRegex))
+		}
+	} else if source.Path != "" && !engine.MatchPath(log.Path, source.Path) {
+		result = append(result, fmt.Sprintf("path: expected %s, got %s", source.Path, log.Path))
+	}
+	if source.RuleID != "" && log.RuleID != source.RuleID {
+		result = append(result, fmt.Sprintf("rule_id: expected %s, got %s", source.RuleID, log.RuleID))
+	}
+
+	request := normalizeLogValue(log.Request)
+	requestData, _ := request.(map[string]interface{})
+
+	headers, _ := requestData["headers"].(map[string]interface{})
+	for name, re := range m.headers {
+		value, found := lookupHeader(headers, name)
+		if !found {
+			result = append(result, fmt.Sprintf("header %s: missing", name))
+		} else if !re.MatchString(value) {
+			result = append(result, fmt.Sprintf("header %s: %q does not match %s", name, value, source.Headers[name]))
+		}
+	}
+
+	if len(m.query) > 0 {
+		rawQuery, _ := requestData["query"].(string)
+		values, _ := url.ParseQuery(rawQuery)
+		for name, re := range m.query {
+			if !values.Has(name) {
+				result = append(result, fmt.Sprintf("query %s: missing", name))
+			} else if value := values.Get(name); !re.MatchString(value) {
+				result = append(result, fmt.Sprintf("query %s: %q does not match %s", name, value, source.Query[name]))
+			}
+		}
+	}
+
+	body := requestData["body"]
+	for _, bodyPath := range m.bodyPaths {
+		matched, err := engine.EvaluatePathCondition(bodyPath.condition, bodyPath.path.Find(body))
+		if err != nil {
+			result = append(result, fmt.Sprintf("body %s: %v", bodyPath.condition.Path, err))
+		} else if !matched {
+			result = append(result, fmt.Sprintf("body %s: condition not satisfied", bodyPath.condition.Path))
+		}
+	}
+
+	return result
+}
+
+// lookupHeader 不区分大小写查找日志中的请求头
+func lookupHeader(headers map[string]interface{}, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			str, _ := value.(string)
+			return str, true
+		}
+	}
+	return "", false
+}
+
+// normalizeLogValue 将 BSON 解码得到的文档、数组类型转换为普通 map 与切片
+func normalizeLogValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalizeLogValue(item)
+		}
+		return result
+	case primitive.M:
+		return normalizeLogValue(map[string]interface{}(v))
+	case primitive.D:
+		result := make(map[string]interface{}, len(v))
+		for _, elem := range v {
+			result[elem.Key] = normalizeLogValue(elem.Value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeLogValue(item)
+		}
+		return result
+	case primitive.A:
+		return normalizeLogValue([]interface{}(v))
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	default:
+		return v
+	}
+}
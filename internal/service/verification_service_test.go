@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gomockserver/mockserver/internal/models"
+	"github.com/gomockserver/mockserver/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// verificationLogs 按时间顺序的请求日志
+func verificationLogs() []*models.RequestLog {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []*models.RequestLog{
+		{
+			ID: "1", Method: "POST", Path: "/api/orders", Timestamp: base,
+			Request: map[string]interface{}{
+				"headers": map[string]interface{}{"Content-Type": "application/json"},
+				"query":   "source=web",
+				"body":    map[string]interface{}{"item": "book", "qty": 2},
+			},
+		},
+		{
+			ID: "2", Method: "GET", Path: "/api/orders/42", Timestamp: base.Add(time.Second),
+			Request: map[string]interface{}{"headers": primitive.M{"X-Trace": "abc"}},
+		},
+		{
+			ID: "3", Method: "POST", Path: "/api/orders", Timestamp: base.Add(2 * time.Second),
+			Request: map[string]interface{}{
+				"headers": map[string]interface{}{"Content-Type": "application/json"},
+				"body":    primitive.D{{Key: "item", Value: "pen"}, {Key: "qty", Value: int32(10)}},
+			},
+		},
+	}
+}
+
+func newVerificationServiceWithLogs(logs []*models.RequestLog) (VerificationService, *MockRequestLogRepositoryForCleanup) {
+	repo := new(MockRequestLogRepositoryForCleanup)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f repository.RequestLogFilter) bool {
+		return f.ProjectID == "p1" && f.EnvironmentID == "e1" && f.SortOrder == 1
+	})).Return(logs, int64(len(logs)), nil)
+	return NewVerificationService(repo, nil), repo
+}
+
+func TestVerificationService_Verify(t *testing.T) {
+	svc, _ := newVerificationServiceWithLogs(verificationLogs())
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		matcher models.RequestMatcher
+		count   *models.CountConstraint
+		passed  bool
+		matched int
+	}{
+		{"默认至少一次", models.RequestMatcher{Method: "post", Path: "/api/orders"}, nil, true, 2},
+		{"精确次数", models.RequestMatcher{Method: "POST"}, &models.CountConstraint{Exactly: intPtr(2)}, true, 2},
+		{"精确次数失败", models.RequestMatcher{Method: "POST"}, &models.CountConstraint{Exactly: intPtr(1)}, false, 2},
+		{"从未调用", models.RequestMatcher{Method: "DELETE"}, &models.CountConstraint{Never: true}, true, 0},
+		{"次数范围", models.RequestMatcher{}, &models.CountConstraint{AtLeast: intPtr(1), AtMost: intPtr(2)}, false, 3},
+		{"路径参数", models.RequestMatcher{Path: "/api/orders/:id"}, nil, true, 1},
+		{"路径正则", models.RequestMatcher{PathRegex: "^/api/orders/\\d+$"}, nil, true, 1},
+		{"请求头正则", models.RequestMatcher{Headers: map[string]string{"x-trace": "^ab"}}, nil, true, 1},
+		{"查询参数", models.RequestMatcher{Query: map[string]string{"source": "web"}}, nil, true, 1},
+		{
+			"请求体 JSONPath",
+			models.RequestMatcher{BodyPaths: []models.BodyPathMatcher{{Path: "$.qty", Op: "gt", Value: 5}}},
+			&models.CountConstraint{Exactly: intPtr(1)}, true, 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.Verify(ctx, &models.VerificationRequest{
+				ProjectID: "p1", EnvironmentID: "e1", Request: tt.matcher, Count: tt.count,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.passed, result.Passed, result.Message)
+			assert.Equal(t, tt.matched, result.MatchedCount)
+		})
+	}
+}
+
+func TestVerificationService_NearMisses(t *testing.T) {
+	svc, _ := newVerificationServiceWithLogs(verificationLogs())
+
+	result, err := svc.Verify(context.Background(), &models.VerificationRequest{
+		ProjectID: "p1", EnvironmentID: "e1",
+		Request: models.RequestMatcher{
+			Method:    "POST",
+			Path:      "/api/orders",
+			BodyPaths: []models.BodyPathMatcher{{Path: "$.item", Value: "pencil"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "expected at least 1 matching request(s), found 0", result.Message)
+
+	require.Len(t, result.NearMisses, 3)
+	// 只差请求体的两个请求最接近，较新的排在前面
+	assert.Equal(t, "3", result.NearMisses[0].Request.ID)
+	assert.Equal(t, "1", result.NearMisses[1].Request.ID)
+	assert.Equal(t, []string{"body $.item: condition not satisfied"}, result.NearMisses[0].Mismatches)
+	assert.Equal(t, "2", result.NearMisses[2].Request.ID)
+	assert.Len(t, result.NearMisses[2].Mismatches, 3)
+}
+
+func TestVerificationService_VerifySequence(t *testing.T) {
+	svc, _ := newVerificationServiceWithLogs(verificationLogs())
+	ctx := context.Background()
+
+	result, err := svc.VerifySequence(ctx, &models.SequenceVerificationRequest{
+		ProjectID: "p1", EnvironmentID: "e1",
+		Requests: []models.RequestMatcher{
+			{Method: "POST", Path: "/api/orders"},
+			{Method: "POST", Path: "/api/orders", BodyPaths: []models.BodyPathMatcher{{Path: "$.item", Value: "pen"}}},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Passed, result.Message)
+	assert.Equal(t, 2, result.MatchedCount)
+
+	result, err = svc.VerifySequence(ctx, &models.SequenceVerificationRequest{
+		ProjectID: "p1", EnvironmentID: "e1",
+		Requests: []models.RequestMatcher{
+			{Method: "GET"},
+			{Method: "POST", BodyPaths: []models.BodyPathMatcher{{Path: "$.item", Value: "book"}}},
+		},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	require.NotNil(t, result.FailedIndex)
+	assert.Equal(t, 1, *result.FailedIndex)
+	require.Len(t, result.NearMisses, 1)
+	assert.Equal(t, "3", result.NearMisses[0].Request.ID)
+}
+
+func TestVerificationService_Errors(t *testing.T) {
+	svc, _ := newVerificationServiceWithLogs(nil)
+
+	_, err := svc.Verify(context.Background(), &models.VerificationRequest{
+		ProjectID: "p1", EnvironmentID: "e1",
+		Request: models.RequestMatcher{PathRegex: "("},
+	})
+	assert.ErrorIs(t, err, ErrInvalidMatcher)
+
+	_, err = svc.VerifySequence(context.Background(), &models.SequenceVerificationRequest{
+		ProjectID: "p1", EnvironmentID: "e1",
+		Requests: []models.RequestMatcher{{BodyPaths: []models.BodyPathMatcher{{Path: "$["}}}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidMatcher)
+
+	repo := new(MockRequestLogRepositoryForCleanup)
+	repo.On("List", mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("db down"))
+	_, err = NewVerificationService(repo, nil).Verify(context.Background(), &models.VerificationRequest{ProjectID: "p1", EnvironmentID: "e1"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidMatcher)
+}
+
+// stubLogFlusher 记录是否被调用的日志刷新器
+type stubLogFlusher struct {
+	flushed bool
+}
+
+func (f *stubLogFlusher) Flush(ctx context.Context) error {
+	f.flushed = true
+	return nil
+}
+
+func TestVerificationService_LoadsAllPages(t *testing.T) {
+	logs := make([]*models.RequestLog, verificationPageSize+5)
+	for i := range logs {
+		logs[i] = &models.RequestLog{ID: fmt.Sprint(i), Method: "GET", Path: "/api/ping"}
+	}
+
+	repo := new(MockRequestLogRepositoryForCleanup)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f repository.RequestLogFilter) bool { return f.Page == 1 })).
+		Return(logs[:verificationPageSize], int64(len(logs)), nil)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f repository.RequestLogFilter) bool { return f.Page == 2 })).
+		Return(logs[verificationPageSize:], int64(len(logs)), nil)
+
+	flusher := &stubLogFlusher{}
+	exactly := len(logs)
+	result, err := NewVerificationService(repo, flusher).Verify(context.Background(), &models.VerificationRequest{
+		ProjectID: "p1", EnvironmentID: "e1",
+		Request: models.RequestMatcher{Path: "/api/ping"},
+		Count:   &models.CountConstraint{Exactly: &exactly},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, len(logs), result.MatchedCount)
+	assert.True(t, flusher.flushed)
+}
+
+func TestVerificationService_TooManyLogs(t *testing.T) {
+	repo := new(MockRequestLogRepositoryForCleanup)
+	repo.On("List", mock.Anything, mock.Anything).Return([]*models.RequestLog{}, int64(maxVerificationLogs+1), nil)
+
+	_, err := NewVerificationService(repo, nil).Verify(context.Background(), &models.VerificationRequest{ProjectID: "p1", EnvironmentID: "e1"})
+	assert.ErrorIs(t, err, ErrTooManyLogs)
+}